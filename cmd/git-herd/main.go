@@ -3,13 +3,23 @@ package main
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/entro314-labs/git-herd/internal/config"
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/internal/report"
+	"github.com/entro314-labs/git-herd/internal/service"
 	"github.com/entro314-labs/git-herd/internal/worker"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
@@ -58,39 +68,712 @@ found in the specified directory and its subdirectories.`,
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Setup signal handling for graceful shutdown
-			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-			defer cancel()
+			return run(cfg, args)
+		},
+	}
+
+	// Setup configuration flags
+	config.SetupFlags(rootCmd, cfg)
+
+	rootCmd.AddCommand(newRunCommand(cfg))
+	rootCmd.AddCommand(newStatusCommand(cfg))
+	rootCmd.AddCommand(newCloneCommand(cfg))
+	rootCmd.AddCommand(newVersionsCommand(cfg))
+	rootCmd.AddCommand(newCheckoutCommand(cfg))
+	rootCmd.AddCommand(newLockCommand(cfg))
+	rootCmd.AddCommand(newStashCommand(cfg))
+	rootCmd.AddCommand(newSyncCommand(cfg))
+	rootCmd.AddCommand(newPruneBranchesCommand(cfg))
+	rootCmd.AddCommand(newHealCommand(cfg))
+	rootCmd.AddCommand(newExecCommand(cfg))
+	rootCmd.AddCommand(newRemotesCommand(cfg))
+	rootCmd.AddCommand(newApplyCommand(cfg))
+	rootCmd.AddCommand(newChangelogCommand(cfg))
+	rootCmd.AddCommand(newReleasesCommand(cfg))
+	rootCmd.AddCommand(newWatchCommand(cfg))
+	rootCmd.AddCommand(newHistoryCommand(cfg))
+	rootCmd.AddCommand(newInstallServiceCommand(cfg))
 
-			// Add timeout if specified
-			if cfg.Timeout > 0 {
-				ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
-				defer cancel()
+	return rootCmd
+}
+
+// newRunCommand creates `git-herd run <pipeline>`, shorthand for --pipeline <pipeline>
+func newRunCommand(cfg *types.Config) *cobra.Command {
+	runCmd := &cobra.Command{
+		Use:   "run <pipeline> [path]",
+		Short: "Run the operations of a pipeline on every repository in one pass",
+		Long: `git-herd run runs the operations of a pipeline declared under pipelines in the config file
+on every git repository found in the specified directory, one after the other per repository,
+after scanning for them once. Each step picks up where the one before left the repository; a
+step that fails stops the pipeline for that repository, one that skips it does not. The
+outcome of every step is shown with each result and in --save-report. --resume-pipeline
+picks each repository up at the step the last run of the pipeline failed at.`,
+		Example: `  # git-herd.yaml: pipelines: {morning: [fetch, prune-branches, status]}
+  git-herd run morning ~/Projects
+  git-herd run morning --resume-pipeline ~/Projects`,
+		Args: cobra.RangeArgs(1, 2),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// A changed flag takes precedence over the config file when the configuration is loaded
+			if err := cmd.Flags().Set("pipeline", args[0]); err != nil {
+				return err
 			}
+			return cmd.Root().PersistentPreRunE(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cfg, args[1:])
+		},
+	}
+
+	config.SetupFlags(runCmd, cfg)
+	_ = runCmd.Flags().MarkHidden("operation")
+	_ = runCmd.Flags().MarkHidden("pipeline")
+
+	return runCmd
+}
+
+// newStatusCommand creates `git-herd status`, shorthand for --operation status
+func newStatusCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationStatus, &cobra.Command{
+		Use:   "status [path]",
+		Short: "Show branch, ahead/behind, dirty state and stashes of every repository",
+		Long: `git-herd status shows, for every git repository found in the specified directory,
+the current branch, how far it is ahead of and behind its upstream, whether it has
+uncommitted changes, and how many stashes it has. Nothing is fetched unless --fetch-first
+is given.`,
+	})
+}
+
+// newCloneCommand creates `git-herd clone`, shorthand for --operation clone
+func newCloneCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationClone, &cobra.Command{
+		Use:   "clone --manifest repos.yaml [path]",
+		Short: "Clone the repositories listed in a manifest",
+		Long: `git-herd clone clones every repository listed in the manifest given with --manifest
+into the specified directory, concurrently. Each entry has a url and optionally a path
+below the directory (defaulting to the repository name) and a branch to check out.
+Repositories that are already cloned are skipped, so the manifest can be re-run as it grows.`,
+	})
+}
 
-			// Determine root path
-			rootPath := "."
-			if len(args) > 0 {
-				rootPath = args[0]
+// newVersionsCommand creates `git-herd versions`, shorthand for --operation versions
+func newVersionsCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationVersions, &cobra.Command{
+		Use:   "versions --manifest repos.yaml [path]",
+		Short: "Check the repositories in a manifest against the minimum version each expects",
+		Long: `git-herd versions compares, for every repository in the manifest given with --manifest that
+has a version, the highest semver tag its HEAD contains with that version, and reports which
+checkouts are behind and exactly where they are (git describe). Entries are found below the
+specified directory by path, as for clone. With --checkout-tag, repositories that are behind
+have the expected tag checked out, detached; nothing is fetched, so fetch tags first.`,
+		Example: `  git-herd versions --manifest fleet.yaml ~/services
+  git-herd versions --manifest fleet.yaml --checkout-tag --dry-run ~/services`,
+	})
+}
+
+// newCheckoutCommand creates `git-herd checkout`, shorthand for --operation checkout
+func newCheckoutCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationCheckout, &cobra.Command{
+		Use:   "checkout (--branch <name> | --lock herd.lock) [path]",
+		Short: "Switch every repository to a branch, or to the commit a lockfile pins it to",
+		Long: `git-herd checkout switches every git repository found in the specified directory to the
+branch given with --branch, creating it from the remote's with --create. With --lock, the
+repositories listed in the lockfile are checked out at the commit or tag it records instead,
+on the recorded branch while that still points there. Repositories with uncommitted changes
+are never switched.`,
+		Example: `  git-herd checkout --branch release/2.0 --create ~/Projects
+  git-herd checkout --lock herd.lock ~/Projects`,
+	})
+}
+
+// newLockCommand creates `git-herd lock`, which writes a lockfile of the commit every
+// repository is on to stdout
+func newLockCommand(cfg *types.Config) *cobra.Command {
+	lockCmd := &cobra.Command{
+		Use:   "lock [path]",
+		Short: "Write a lockfile of the commit every repository is on",
+		Long: `git-herd lock records, for every git repository found in the specified directory, its
+remote URL, path, branch and the commit HEAD is on, and writes them to stdout as a lockfile.
+git-herd checkout --lock restores that exact state later, e.g. after a risky experiment, and
+git-herd clone --manifest recreates the workspace from the same file. Uncommitted changes are
+not captured; the repositories that have any are named on stderr.`,
+		Example: `  git-herd lock ~/Projects > herd.lock
+  git-herd checkout --lock herd.lock ~/Projects`,
+		Args: cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Root().PersistentPreRunE(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootPath := pathArg(cfg, args, 0)
+			if err := git.NewScanner(cfg).CheckRoot(rootPath, config.FileUsed() != ""); err != nil {
+				return err
+			}
+			entries, err := git.Snapshot(cmd.Context(), cfg, rootPath)
+			if err != nil {
+				return err
 			}
+			for _, entry := range entries {
+				if entry.Dirty {
+					fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  %s has uncommitted changes, which the lockfile does not capture\n", entry.Path)
+				}
+			}
+			return git.WriteLock(cmd.OutOrStdout(), entries)
+		},
+	}
+	config.SetupFlags(lockCmd, cfg)
+	_ = lockCmd.Flags().MarkHidden("operation")
 
-			// Validate path
-			info, err := os.Stat(rootPath)
+	diffCmd := &cobra.Command{
+		Use:   "diff <old.lock> [path]",
+		Short: "Show how every repository moved since a lockfile was written",
+		Long: `git-herd lock diff compares the repositories found in the specified directory with a
+lockfile git-herd lock wrote earlier and shows, for every repository that changed, which
+branch it is on now and how many commits it moved by, and which repositories are new or gone,
+e.g. to see what a week of syncing actually changed.`,
+		Example: `  git-herd lock diff herd.lock ~/Projects`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			old, err := git.ReadLock(args[0])
 			if err != nil {
-				return fmt.Errorf("stat path %s: %w", rootPath, err)
+				return err
 			}
-			if !info.IsDir() {
-				return fmt.Errorf("path is not a directory: %s", rootPath)
+			rootPath := pathArg(cfg, args, 1)
+			if err := git.NewScanner(cfg).CheckRoot(rootPath, config.FileUsed() != ""); err != nil {
+				return err
 			}
+			changes, err := git.DiffLock(cmd.Context(), cfg, rootPath, old)
+			if err != nil {
+				return err
+			}
+			return report.WriteLockDiff(cmd.OutOrStdout(), changes)
+		},
+	}
+	config.SetupFlags(diffCmd, cfg)
+	_ = diffCmd.Flags().MarkHidden("operation")
+	lockCmd.AddCommand(diffCmd)
 
-			// Create and execute manager
-			manager := worker.New(cfg)
-			return manager.Execute(ctx, rootPath)
+	return lockCmd
+}
+
+// newStashCommand creates `git-herd stash`, shorthand for --operation stash, and its
+// `git-herd stash pop` subcommand, shorthand for --operation stash-pop
+func newStashCommand(cfg *types.Config) *cobra.Command {
+	stashCmd := newOperationCommand(cfg, types.OperationStash, &cobra.Command{
+		Use:   "stash [path]",
+		Short: "Stash the uncommitted changes of every dirty repository",
+		Long: `git-herd stash stashes the uncommitted changes, untracked files included, of every
+git repository found in the specified directory that has any, so they can be pulled.
+git-herd stash pop restores them afterwards.`,
+	})
+	stashCmd.AddCommand(newOperationCommand(cfg, types.OperationStashPop, &cobra.Command{
+		Use:   "pop [path]",
+		Short: "Restore the changes git-herd stash stashed",
+		Long: `git-herd stash pop pops the most recent stash of every git repository found in the
+specified directory, provided git-herd stash created it. Stashes made by hand are left alone.`,
+	}))
+	return stashCmd
+}
+
+// newSyncCommand creates `git-herd sync`, shorthand for --operation sync
+func newSyncCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationSync, &cobra.Command{
+		Use:   "sync [path]",
+		Short: "Bring every repository's default branch up to date without leaving the current branch",
+		Long: `git-herd sync checks out the default branch of every git repository found in the
+specified directory, pulls it, and checks out the branch the repository was on again, so
+main stays fresh while feature branches are left as they are. When the pull fails, the
+original branch is restored before the failure is reported.`,
+	})
+}
+
+// newPruneBranchesCommand creates `git-herd prune-branches`, shorthand for
+// --operation prune-branches
+func newPruneBranchesCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationPruneBranches, &cobra.Command{
+		Use:   "prune-branches [path]",
+		Short: "Delete local branches that are merged or whose upstream is gone",
+		Long: `git-herd prune-branches deletes, in every git repository found in the specified
+directory, the local branches already merged into the default branch and those whose
+upstream branch is gone from the remote. The current and default branches are kept.
+Use --dry-run to see exactly which branches each repository would lose.`,
+	})
+}
+
+// newHealCommand creates `git-herd heal`, shorthand for --operation heal
+func newHealCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationHeal, &cobra.Command{
+		Use:   "heal [path]",
+		Short: "Put repositories with a detached HEAD back on a branch",
+		Long: `git-herd heal checks out a branch in every git repository found in the specified
+directory whose HEAD is detached: a local branch at the detached commit, or else the local
+branch that most recently had the commit, or else the default branch. A commit that no
+branch or tag contains is left checked out rather than orphaned, and repositories with
+uncommitted changes are skipped. Use --dry-run to see which branch each would end up on.`,
+	})
+}
+
+// newExecCommand creates `git-herd exec [path] -- <command>`, shorthand for --operation exec
+// --exec <command>
+func newExecCommand(cfg *types.Config) *cobra.Command {
+	execCmd := newOperationCommand(cfg, types.OperationExec, &cobra.Command{
+		Use:   "exec [path] -- <command>",
+		Short: "Run a shell command in every repository",
+		Long: `git-herd exec runs the command after -- with the shell in every git repository found
+in the specified directory, through the same worker pool as the other operations. Each
+repository's output and exit code end up in the results and in --save-report; a non-zero
+exit fails the repository. Dirty repositories are not skipped.`,
+		Example: `  git-herd exec ~/Projects -- git log -1 --oneline
+  git-herd exec -- 'make test && make lint'`,
+	})
+
+	// Only the path comes before --, and the command after it is required
+	execCmd.Args = func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 || dash == len(args) {
+			return fmt.Errorf("exec requires a command after --")
+		}
+		return cobra.MaximumNArgs(1)(cmd, args[:dash])
+	}
+	preRun := execCmd.PersistentPreRunE
+	execCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		command := strings.Join(args[cmd.ArgsLenAtDash():], " ")
+		if err := cmd.Flags().Set("exec", command); err != nil {
+			return err
+		}
+		return preRun(cmd, args)
+	}
+	execCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return run(cfg, args[:cmd.ArgsLenAtDash()])
+	}
+	_ = execCmd.Flags().MarkHidden("exec")
+
+	return execCmd
+}
+
+// newRemotesCommand creates `git-herd remotes` and its `set-url` subcommand, shorthand for
+// --operation set-url --url-match <old> --url-replace <new>
+func newRemotesCommand(cfg *types.Config) *cobra.Command {
+	remotesCmd := &cobra.Command{
+		Use:   "remotes",
+		Short: "Manage the remotes of every repository",
+	}
+
+	var match, replace string
+	setURLCmd := newOperationCommand(cfg, types.OperationSetURL, &cobra.Command{
+		Use:   "set-url [path]",
+		Short: "Rewrite remote URLs in every repository",
+		Long: `git-herd remotes set-url replaces --match with --replace in the fetch and push URLs
+of every remote of every git repository found in the specified directory, e.g. to move
+to a new Git host or switch between HTTPS and SSH. Repositories without a matching URL
+are left alone. Use --dry-run to see every rewrite before making it.`,
+		Example: `  git-herd remotes set-url --match 'git@old-host:' --replace 'git@new-host:' --dry-run
+  git-herd remotes set-url ~/Projects --match 'https://github.com/' --replace 'git@github.com:'`,
+	})
+	setURLCmd.Flags().StringVarP(&match, "match", "", "", "Part of the remote URLs to rewrite")
+	setURLCmd.Flags().StringVarP(&replace, "replace", "", "", "What to replace --match with")
+	_ = setURLCmd.MarkFlagRequired("match")
+
+	// --match and --replace stand in for the hidden url-match and url-replace
+	preRun := setURLCmd.PersistentPreRunE
+	setURLCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Flags().Set("url-match", match); err != nil {
+			return err
+		}
+		if err := cmd.Flags().Set("url-replace", replace); err != nil {
+			return err
+		}
+		return preRun(cmd, args)
+	}
+	_ = setURLCmd.Flags().MarkHidden("url-match")
+	_ = setURLCmd.Flags().MarkHidden("url-replace")
+
+	remotesCmd.AddCommand(setURLCmd)
+	return remotesCmd
+}
+
+// newApplyCommand creates `git-herd apply`, shorthand for --operation apply with --apply-script
+// or --apply-patch, --commit-message, --apply-branch and --apply-push
+func newApplyCommand(cfg *types.Config) *cobra.Command {
+	var script, patch, message, branch string
+	var push bool
+	applyCmd := newOperationCommand(cfg, types.OperationApply, &cobra.Command{
+		Use:   "apply [path] (--script <file> | --patch <file>) -m <message>",
+		Short: "Commit a scripted change to every repository",
+		Long: `git-herd apply runs an executable script in, or applies a patch to, every git
+repository found in the specified directory and commits what changed, e.g. to bump a CI
+config across a fleet of repositories. The commit message is a Go template: {{.Name}},
+{{.Branch}}, {{.Path}} and {{.Remote}} are the repository's. With --new-branch the change is
+committed to a new branch, which --push pushes for review. Repositories with uncommitted
+changes are skipped, and a failed change is undone. Use --dry-run to check that a patch
+applies everywhere before committing anything.`,
+		Example: `  git-herd apply ~/Projects --script ./bump-ci.sh -m 'Bump CI config' --new-branch ci-bump --push
+  git-herd apply --patch fix.patch -m 'Fix typo in {{.Name}}' --dry-run`,
+	})
+	applyCmd.Flags().StringVarP(&script, "script", "", "", "Executable script to run in every repository")
+	applyCmd.Flags().StringVarP(&patch, "patch", "", "", "Patch file to apply to every repository")
+	applyCmd.Flags().StringVarP(&message, "message", "m", "", "Commit message template")
+	applyCmd.Flags().StringVarP(&branch, "new-branch", "", "", "Branch to create and commit the change to")
+	applyCmd.Flags().BoolVarP(&push, "push", "", false, "Push the new branch to the remote")
+	applyCmd.MarkFlagsOneRequired("script", "patch")
+	applyCmd.MarkFlagsMutuallyExclusive("script", "patch")
+	_ = applyCmd.MarkFlagRequired("message")
+
+	// These flags stand in for the hidden apply-* and commit-message, which are only set when
+	// given so the configuration file can still provide a branch
+	preRun := applyCmd.PersistentPreRunE
+	applyCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		for name, target := range map[string]string{
+			"script": "apply-script", "patch": "apply-patch", "message": "commit-message",
+			"new-branch": "apply-branch", "push": "apply-push",
+		} {
+			if flag := cmd.Flag(name); flag.Changed {
+				if err := cmd.Flags().Set(target, flag.Value.String()); err != nil {
+					return err
+				}
+			}
+		}
+		return preRun(cmd, args)
+	}
+	for _, name := range []string{"apply-script", "apply-patch", "commit-message", "apply-branch", "apply-push"} {
+		_ = applyCmd.Flags().MarkHidden(name)
+	}
+
+	return applyCmd
+}
+
+// newChangelogCommand creates `git-herd changelog`, shorthand for --operation changelog
+func newChangelogCommand(cfg *types.Config) *cobra.Command {
+	changelogCmd := newOperationCommand(cfg, types.OperationChangelog, &cobra.Command{
+		Use:   "changelog --since <date> [path]",
+		Short: "Collect the commits made across every repository since a date into one document",
+		Long: `git-herd changelog collects the subjects of the commits made on the current branch of
+every git repository found in the specified directory since --since, merges aside, into one
+markdown document with a section per repository, e.g. for a monthly summary of what changed
+across a stack. With --conventional each section is grouped by conventional-commit type
+(feat, fix, perf, ...). Nothing is fetched, so fetch first for the remote's latest commits.`,
+		Example: `  git-herd changelog ~/Projects --since 2024-01-01
+  git-herd changelog --since 2024-06-01 --conventional --changelog-file june.md`,
+	})
+	_ = changelogCmd.MarkFlagRequired("since")
+	return changelogCmd
+}
+
+// newReleasesCommand creates `git-herd releases`, shorthand for --operation releases
+func newReleasesCommand(cfg *types.Config) *cobra.Command {
+	return newOperationCommand(cfg, types.OperationReleases, &cobra.Command{
+		Use:   "releases [path]",
+		Short: "List the latest tag of every repository and the work unreleased since",
+		Long: `git-herd releases lists, for every git repository found in the specified directory, the
+latest tag reachable from the current branch, how long ago it was made, and the commits
+since, for a review of release cadence. Repositories whose oldest unreleased commit is older
+than --unreleased-days are flagged as overdue; a repository without tags has all of its
+history unreleased. Nothing is fetched, so fetch first for the remote's latest tags.`,
+		Example: `  git-herd releases ~/Projects
+  git-herd releases --unreleased-days 14 ~/Projects`,
+	})
+}
+
+// newWatchCommand creates `git-herd watch`, which keeps the repositories fresh by fetching them
+// and reading their status again every --interval
+func newWatchCommand(cfg *types.Config) *cobra.Command {
+	var interval time.Duration
+	watchCmd := &cobra.Command{
+		Use:   "watch [path]",
+		Short: "Fetch every repository and show its status again every --interval",
+		Long: `git-herd watch keeps every git repository found in the specified directory up to date
+until interrupted: every --interval it scans the directory again, fetches each repository
+and reads its status, as git-herd status --fetch-first does. The TUI keeps a table of the
+latest status of every repository on screen between rounds, and r starts a round right away;
+in plain mode each round prints its results. Each round has --timeout to finish, and one that
+fails does not end the watch.`,
+		Example: `  git-herd watch ~/Projects --interval 15m
+  git-herd watch ~/Projects --plain --interval 1h >> watch.log`,
+		Args: cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// A changed flag takes precedence over the config file when the configuration is loaded
+			if err := cmd.Flags().Set("operation", string(types.OperationStatus)); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("fetch-first") {
+				if err := cmd.Flags().Set("fetch-first", "true"); err != nil {
+					return err
+				}
+			}
+			return cmd.Root().PersistentPreRunE(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval < time.Minute {
+				return fmt.Errorf("--interval must be at least 1m, got %v", interval)
+			}
+			return watch(cfg, args, interval)
 		},
 	}
+	watchCmd.Flags().DurationVarP(&interval, "interval", "", 15*time.Minute, "Time between rounds")
+	config.SetupFlags(watchCmd, cfg)
+	_ = watchCmd.Flags().MarkHidden("operation")
 
-	// Setup configuration flags
-	config.SetupFlags(rootCmd, cfg)
+	return watchCmd
+}
 
-	return rootCmd
+// newHistoryCommand creates `git-herd history` and its `chart` subcommand, which work on the
+// history file rather than on repositories
+func newHistoryCommand(cfg *types.Config) *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Work with the outcomes recorded across runs",
+		// Nothing but the history file is configured, so the full configuration is not loaded
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if value, ok := os.LookupEnv(config.EnvVar("history-file")); ok && !cmd.Flag("history-file").Changed {
+				cfg.HistoryFile = value
+			}
+			return nil
+		},
+	}
+	historyCmd.PersistentFlags().StringVarP(&cfg.HistoryFile, "history-file", "", cfg.HistoryFile, "File recording per-repository outcomes across runs")
+
+	var out string
+	chartCmd := &cobra.Command{
+		Use:   "chart",
+		Short: "Chart success rate, duration and failure categories over time",
+		Long: `git-herd history chart renders the runs recorded in the history file as a
+self-contained HTML page with charts of each run's success rate, duration, and failures
+by category (timeout, auth, network, diverged, other).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.HistoryFile == "" {
+				return fmt.Errorf("--history-file is empty, so there is no history to chart")
+			}
+			store, err := history.Load(cfg.HistoryFile)
+			if err != nil {
+				return err
+			}
+			if err := report.WriteTrends(out, store.Runs()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "📈 Trends written to: %s\n", out)
+			return nil
+		},
+	}
+	chartCmd.Flags().StringVarP(&out, "out", "", "trends.html", "HTML file to write the charts to")
+	historyCmd.AddCommand(chartCmd)
+
+	return historyCmd
+}
+
+// newInstallServiceCommand creates `git-herd install-service`, which writes a user-level
+// systemd service and timer, or a launchd agent on macOS, that runs git-herd every --interval
+// with the flags it is given
+func newInstallServiceCommand(cfg *types.Config) *cobra.Command {
+	spec := service.Spec{Name: "git-herd"}
+	var print bool
+	installCmd := &cobra.Command{
+		Use:   "install-service [path]",
+		Short: "Run git-herd on a schedule with systemd or launchd",
+		Long: `git-herd install-service writes a user-level systemd service and timer, or a launchd
+agent on macOS, that runs git-herd on the specified directory every --interval. The other
+flags given are passed on to every run, which starts in the current directory so a
+git-herd.yaml there is picked up, and are checked the way a run checks them before anything
+is written. The service does not inherit the shell's environment, so credentials such as
+SSH_AUTH_SOCK or GITHUB_TOKEN belong in the configuration or the unit.`,
+		Example: `  git-herd install-service ~/Projects --interval 1h -o pull --skip-dirty
+  git-herd install-service ~/Projects --interval 30m --print`,
+		Args: cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Root().PersistentPreRunE(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if spec.Interval < time.Minute {
+				return fmt.Errorf("--interval must be at least 1m, got %v", spec.Interval)
+			}
+			command, err := scheduledCommand(cmd, cfg, args)
+			if err != nil {
+				return err
+			}
+			spec.Command = command
+			if spec.Dir, err = os.Getwd(); err != nil {
+				return err
+			}
+
+			files, err := service.Files(spec, runtime.GOOS, "")
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if print {
+				for _, file := range files {
+					fmt.Fprintf(out, "# %s\n%s\n", file.Path, file.Content)
+				}
+				return nil
+			}
+			if err := service.Write(files); err != nil {
+				return err
+			}
+			for _, file := range files {
+				fmt.Fprintf(out, "📝 Wrote %s\n", file.Path)
+			}
+			fmt.Fprintln(out, "To start it, run:")
+			for _, step := range service.NextSteps(spec, runtime.GOOS, files) {
+				fmt.Fprintf(out, "  %s\n", step)
+			}
+			return nil
+		},
+	}
+	installCmd.Flags().DurationVarP(&spec.Interval, "interval", "", time.Hour, "Time between scheduled runs")
+	installCmd.Flags().StringVarP(&spec.Name, "name", "", spec.Name, "Name of the systemd units or launchd agent")
+	installCmd.Flags().BoolVarP(&print, "print", "", false, "Print the files instead of writing them")
+	config.SetupFlags(installCmd, cfg)
+
+	return installCmd
+}
+
+// scheduledCommand returns the git-herd invocation a scheduled run makes: this executable with
+// the configuration flags given to install-service, always in plain mode, on the absolute path
+func scheduledCommand(cmd *cobra.Command, cfg *types.Config, args []string) ([]string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locate the git-herd executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	command := []string{executable}
+	for _, key := range config.Keys() {
+		flag := cmd.Flag(key)
+		if flag == nil || !flag.Changed || key == "plain" {
+			continue
+		}
+		// Slice and map flags are repeated once per value, so values with commas survive
+		switch value := flag.Value.(type) {
+		case interface{ GetSlice() []string }:
+			for _, item := range value.GetSlice() {
+				command = append(command, "--"+key+"="+item)
+			}
+		default:
+			if key == "label" {
+				for _, pair := range cfg.LabelPairs() {
+					command = append(command, "--label="+pair)
+				}
+				continue
+			}
+			if key == "operation-workers" {
+				for _, op := range slices.Sorted(maps.Keys(cfg.OperationWorkers)) {
+					command = append(command, fmt.Sprintf("--operation-workers=%s=%d", op, cfg.OperationWorkers[op]))
+				}
+				continue
+			}
+			if key == "pipelines" {
+				for _, name := range slices.Sorted(maps.Keys(cfg.Pipelines)) {
+					command = append(command, "--pipelines="+name+"="+strings.Join(cfg.Pipelines[name], " "))
+				}
+				continue
+			}
+			command = append(command, "--"+key+"="+flag.Value.String())
+		}
+	}
+	command = append(command, "--plain")
+
+	path, err := filepath.Abs(pathArg(cfg, args, 0))
+	if err != nil {
+		return nil, err
+	}
+	return append(command, path), nil
+}
+
+// newOperationCommand completes cmd as a subcommand that runs op. It takes the same flags as
+// the root command; the operation is preset and hidden.
+func newOperationCommand(cfg *types.Config, op types.OperationType, cmd *cobra.Command) *cobra.Command {
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// A changed flag takes precedence over the config file when the configuration is loaded
+		if err := cmd.Flags().Set("operation", string(op)); err != nil {
+			return err
+		}
+		return cmd.Root().PersistentPreRunE(cmd, args)
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return run(cfg, args)
+	}
+
+	config.SetupFlags(cmd, cfg)
+	_ = cmd.Flags().MarkHidden("operation")
+
+	return cmd
+}
+
+// pathArg returns the path args give at index i, or else the path configured, e.g. by a
+// profile, or else the current directory
+func pathArg(cfg *types.Config, args []string, i int) string {
+	switch {
+	case len(args) > i:
+		return args[i]
+	case cfg.Path != "":
+		return git.ExpandHome(cfg.Path)
+	default:
+		return "."
+	}
+}
+
+// run processes the repositories under the path in args with the loaded configuration
+func run(cfg *types.Config, args []string) error {
+	// Setup signal handling for graceful shutdown
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Spread scheduled runs across machines before the timeout starts counting
+	if err := worker.WaitForJitter(ctx, cfg); err != nil {
+		return fmt.Errorf("waiting for jitter: %w", err)
+	}
+
+	// Add timeout if specified
+	if cfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	// Determine root path
+	rootPath := pathArg(cfg, args, 0)
+
+	// Clones bootstrap a workspace, so their root may not exist yet
+	if cfg.Operation == types.OperationClone {
+		if err := os.MkdirAll(rootPath, 0o755); err != nil {
+			return fmt.Errorf("create clone root %s: %w", rootPath, err)
+		}
+	}
+
+	if err := checkRootPath(cfg, rootPath); err != nil {
+		return err
+	}
+
+	// Create and execute manager
+	manager := worker.New(cfg)
+	return manager.Execute(ctx, rootPath)
+}
+
+// watch runs the loaded configuration on the repositories under the path in args every
+// interval, until interrupted
+func watch(cfg *types.Config, args []string, interval time.Duration) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	rootPath := pathArg(cfg, args, 0)
+	if err := checkRootPath(cfg, rootPath); err != nil {
+		return err
+	}
+	return worker.Watch(ctx, cfg, rootPath, interval)
+}
+
+// checkRootPath checks that the path to process is a directory git-herd may walk, on a disk with
+// room for what the operation downloads
+func checkRootPath(cfg *types.Config, rootPath string) error {
+	info, err := os.Stat(rootPath)
+	if err != nil {
+		return fmt.Errorf("stat path %s: %w", rootPath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path is not a directory: %s", rootPath)
+	}
+	if err := git.NewScanner(cfg).CheckRoot(rootPath, config.FileUsed() != ""); err != nil {
+		return err
+	}
+	return git.CheckFreeSpace(cfg, rootPath)
 }
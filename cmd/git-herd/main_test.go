@@ -3,14 +3,26 @@ package main
 import (
 	"bytes"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/entro314-labs/git-herd/internal/config"
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
+func TestMain(m *testing.M) {
+	// Commands that walk a directory would otherwise record it in the user's index
+	_ = os.Setenv("GIT_HERD_INDEX_FILE", "")
+	os.Exit(m.Run())
+}
+
 func TestBuildVersion(t *testing.T) {
 	// Note: Cannot use t.Parallel() on subtests because they modify global package variables
 	tests := []struct {
@@ -135,6 +147,21 @@ func TestRootCommandInvalidPath(t *testing.T) {
 	}
 }
 
+func TestRootCommandFilesystemRoot(t *testing.T) {
+	rootCmd := newRootCommand(config.DefaultConfig())
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	rootCmd.SetArgs([]string{"--dry-run", "--plain", "--history-file", "", root})
+
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--force-root") {
+		t.Errorf("Expected the filesystem root to be refused, got %v", err)
+	}
+}
+
 func TestRootCommandValidPath(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -189,6 +216,479 @@ func TestRootCommandFlags(t *testing.T) {
 	}
 }
 
+func TestStatusCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"status", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected status to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationStatus {
+		t.Errorf("Expected status subcommand to run the status operation, got %q", cfg.Operation)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"run", "morning", "--pipelines", "morning=fetch status", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected the pipeline to run, got %v", err)
+	}
+	if cfg.Pipeline != "morning" || cfg.Operation != types.OperationStatus {
+		t.Errorf("Expected run to select the morning pipeline ending in status, got %q ending in %q", cfg.Pipeline, cfg.Operation)
+	}
+	if !slices.Equal(cfg.PipelineSteps(), []types.PipelineStep{{Operation: types.OperationFetch}, {Operation: types.OperationStatus}}) {
+		t.Errorf("Expected the steps fetch and status, got %v", cfg.PipelineSteps())
+	}
+
+	rootCmd = newRootCommand(config.DefaultConfig())
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"run", "evening", "--plain", t.TempDir()})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "unknown pipeline") {
+		t.Errorf("Expected an undeclared pipeline to be rejected, got %v", err)
+	}
+}
+
+func TestWatchCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"watch", "--interval", "30s", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "at least 1m") {
+		t.Errorf("Expected an interval under a minute to be rejected, got %v", err)
+	}
+	if cfg.Operation != types.OperationStatus || !cfg.FetchFirst {
+		t.Errorf("Expected watch to run status with --fetch-first, got %q and %v", cfg.Operation, cfg.FetchFirst)
+	}
+
+	rootCmd = newRootCommand(config.DefaultConfig())
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"watch", "--plain", "--history-file", "", filepath.Join(t.TempDir(), "missing")})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected watch to reject a missing path")
+	}
+}
+
+func TestCloneCommand(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "repos.yaml")
+	if err := os.WriteFile(manifest, []byte("repos:\n  - url: https://github.com/acme/api.git\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	root := filepath.Join(t.TempDir(), "workspace")
+	rootCmd.SetArgs([]string{"clone", "--manifest", manifest, "--dry-run", "--plain", "--history-file", "", root})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected clone to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationClone || cfg.CloneManifest != manifest {
+		t.Errorf("Expected clone subcommand to run the clone operation with the manifest, got %q with %q", cfg.Operation, cfg.CloneManifest)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		t.Errorf("Expected the clone root to be created, got %v", err)
+	}
+}
+
+func TestStashCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want types.OperationType
+	}{
+		{[]string{"stash"}, types.OperationStash},
+		{[]string{"stash", "pop"}, types.OperationStashPop},
+	}
+
+	for _, tt := range tests {
+		cfg := config.DefaultConfig()
+		rootCmd := newRootCommand(cfg)
+
+		var buf bytes.Buffer
+		rootCmd.SetOut(&buf)
+		rootCmd.SetErr(&buf)
+		rootCmd.SetArgs(append(tt.args, "--dry-run", "--plain", "--history-file", "", t.TempDir()))
+
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Expected %v to succeed, got %v", tt.args, err)
+		}
+		if cfg.Operation != tt.want {
+			t.Errorf("Expected %v to run the %s operation, got %q", tt.args, tt.want, cfg.Operation)
+		}
+	}
+}
+
+func TestSyncCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"sync", "--dry-run", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected sync to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationSync {
+		t.Errorf("Expected sync to run the sync operation, got %q", cfg.Operation)
+	}
+}
+
+func TestPruneBranchesCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"prune-branches", "--dry-run", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected prune-branches to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationPruneBranches {
+		t.Errorf("Expected prune-branches to run the prune-branches operation, got %q", cfg.Operation)
+	}
+}
+
+func TestHealCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"heal", "--dry-run", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected heal to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationHeal {
+		t.Errorf("Expected operation heal, got %q", cfg.Operation)
+	}
+}
+
+func TestCheckoutCommand(t *testing.T) {
+	lockfile := filepath.Join(t.TempDir(), "herd.lock")
+	if err := os.WriteFile(lockfile, []byte("repos: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"checkout", "--lock", lockfile, "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected checkout --lock to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationCheckout || cfg.Lock != lockfile {
+		t.Errorf("Expected operation checkout with the lockfile, got %q with %q", cfg.Operation, cfg.Lock)
+	}
+}
+
+func TestLockCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var out, errOut bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&errOut)
+	rootCmd.SetArgs([]string{"lock", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected lock to succeed, got %v (%s)", err, errOut.String())
+	}
+	if !strings.HasPrefix(out.String(), "# git-herd lockfile") || !strings.HasSuffix(out.String(), "repos: []\n") {
+		t.Errorf("Expected an empty lockfile on stdout, got:\n%s", out.String())
+	}
+}
+
+func TestLockDiffCommand(t *testing.T) {
+	lockfile := filepath.Join(t.TempDir(), "herd.lock")
+	if err := os.WriteFile(lockfile, []byte("repos:\n  - path: api\n    ref: 9f8e7d6c5b4a39281706f5e4d3c2b1a098765432\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := newRootCommand(config.DefaultConfig())
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs([]string{"lock", "diff", lockfile, "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected lock diff to succeed, got %v", err)
+	}
+	if !strings.Contains(out.String(), "api: gone (was detached at 9f8e7d6c)") {
+		t.Errorf("Expected api to be reported gone, got:\n%s", out.String())
+	}
+}
+
+func TestVersionsCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	manifest := filepath.Join(t.TempDir(), "fleet.yaml")
+	if err := os.WriteFile(manifest, []byte("repos:\n  - path: api\n    version: v1.4.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"versions", "--manifest", manifest, "--checkout-tag", "--dry-run", "--plain", "--history-file", "", t.TempDir()})
+
+	// api is not there to check, so the run fails, but only once the manifest has been read
+	err := rootCmd.Execute()
+	if cfg.Operation != types.OperationVersions || !cfg.CheckoutTag {
+		t.Errorf("Expected operation versions with checkout-tag, got %q", cfg.Operation)
+	}
+	if err == nil || !strings.Contains(err.Error(), "1 repositories failed") {
+		t.Errorf("Expected the missing checkout to fail, got %v", err)
+	}
+}
+
+func TestReleasesCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"releases", "--unreleased-days", "14", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected releases to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationReleases || cfg.UnreleasedDays != 14 {
+		t.Errorf("Expected operation releases with 14 days, got %q with %d", cfg.Operation, cfg.UnreleasedDays)
+	}
+}
+
+func TestExecCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"exec", "--dry-run", "--plain", "--history-file", "", t.TempDir(), "--", "git", "log", "-1", "--oneline"})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected exec to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationExec || cfg.Exec != "git log -1 --oneline" {
+		t.Errorf("Expected exec to run the command after --, got %q running %q", cfg.Operation, cfg.Exec)
+	}
+
+	rootCmd = newRootCommand(config.DefaultConfig())
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"exec", "--plain", t.TempDir()})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected exec without a command to fail")
+	}
+}
+
+func TestRemotesSetURLCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"remotes", "set-url", "--match", "git@old-host:", "--replace", "git@new-host:", "--dry-run", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected remotes set-url to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationSetURL || cfg.URLMatch != "git@old-host:" || cfg.URLReplace != "git@new-host:" {
+		t.Errorf("Expected set-url from git@old-host: to git@new-host:, got %q from %q to %q", cfg.Operation, cfg.URLMatch, cfg.URLReplace)
+	}
+
+	rootCmd = newRootCommand(config.DefaultConfig())
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"remotes", "set-url", "--plain", t.TempDir()})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected remotes set-url without --match to fail")
+	}
+}
+
+func TestApplyCommand(t *testing.T) {
+	patch := filepath.Join(t.TempDir(), "fix.patch")
+	if err := os.WriteFile(patch, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"apply", "--patch", patch, "-m", "Fix {{.Name}}", "--new-branch", "fix", "--push", "--dry-run", "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected apply to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationApply || cfg.ApplyPatch != patch || cfg.CommitMessage != "Fix {{.Name}}" ||
+		cfg.ApplyBranch != "fix" || !cfg.ApplyPush {
+		t.Errorf("Expected apply of %s on branch fix with push, got %q of %q on %q (push %v)", patch, cfg.Operation, cfg.ApplyPatch, cfg.ApplyBranch, cfg.ApplyPush)
+	}
+
+	for _, args := range [][]string{
+		{"apply", "-m", "Fix", "--plain", t.TempDir()},
+		{"apply", "--patch", patch, "--plain", t.TempDir()},
+		{"apply", "--patch", patch, "--script", patch, "-m", "Fix", "--plain", t.TempDir()},
+	} {
+		rootCmd = newRootCommand(config.DefaultConfig())
+		rootCmd.SetOut(&buf)
+		rootCmd.SetErr(&buf)
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err == nil {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+}
+
+func TestChangelogCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	out := filepath.Join(t.TempDir(), "june.md")
+	rootCmd.SetArgs([]string{"changelog", "--since", "2024-06-01", "--conventional", "--changelog-file", out, "--plain", "--history-file", "", t.TempDir()})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected changelog to succeed, got %v", err)
+	}
+	if cfg.Operation != types.OperationChangelog || cfg.Since != "2024-06-01" || !cfg.Conventional {
+		t.Errorf("Expected a conventional changelog since 2024-06-01, got %q since %q (conventional %v)", cfg.Operation, cfg.Since, cfg.Conventional)
+	}
+
+	rootCmd = newRootCommand(config.DefaultConfig())
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"changelog", "--plain", t.TempDir()})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected changelog without --since to fail")
+	}
+}
+
+func TestInstallServiceCommand(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("install-service supports linux and darwin")
+	}
+	root := t.TempDir()
+
+	rootCmd := newRootCommand(config.DefaultConfig())
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"install-service", "--interval", "30m", "--print", "-o", "pull", "--exclude", "a,b", "--label", "host=laptop", "--operation-workers", "pull=4,fetch=20", "--history-file", "", root})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected install-service --print to succeed, got %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"--operation=pull", "--exclude=a --exclude=b", "--label=host=laptop", "--operation-workers=fetch=20 --operation-workers=pull=4", "--plain " + root} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected the scheduled command to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"install-service", "--interval", "30s", "--print", root},
+		{"install-service", "-o", "launch", "--print", root},
+	} {
+		rootCmd = newRootCommand(config.DefaultConfig())
+		rootCmd.SetOut(&buf)
+		rootCmd.SetErr(&buf)
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err == nil {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+}
+
+func TestHistoryChartCommand(t *testing.T) {
+	dir := t.TempDir()
+	historyFile := filepath.Join(dir, "history.json")
+	store, err := history.Load(historyFile)
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	run := time.Now().Add(-time.Hour)
+	store.Add("/repos/a", history.Record{Run: run, Time: run.Add(time.Second), Operation: types.OperationFetch, Duration: time.Second})
+	store.Add("/repos/b", history.Record{Run: run, Time: run.Add(time.Second), Operation: types.OperationFetch, Duration: time.Second, Error: "connection refused"})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Failed to save history: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	rootCmd := newRootCommand(cfg)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	out := filepath.Join(dir, "trends.html")
+	rootCmd.SetArgs([]string{"history", "chart", "--history-file", historyFile, "--out", out})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected history chart to succeed, got %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Expected the trends to be written: %v", err)
+	}
+	if !strings.Contains(string(data), "1 runs") {
+		t.Errorf("Expected the trends to cover one run, got %s", data)
+	}
+}
+
+func TestHistoryChartCommandEnv(t *testing.T) {
+	t.Setenv("GIT_HERD_HISTORY_FILE", "")
+
+	rootCmd := newRootCommand(config.DefaultConfig())
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"history", "chart", "--out", filepath.Join(t.TempDir(), "trends.html")})
+
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--history-file is empty") {
+		t.Errorf("Expected GIT_HERD_HISTORY_FILE to disable the history, got %v", err)
+	}
+}
+
 func TestRootCommandVersion(t *testing.T) {
 	cfg := config.DefaultConfig()
 	rootCmd := newRootCommand(cfg)
@@ -307,6 +807,17 @@ func TestArgumentHandling(t *testing.T) {
 			wantErr:  true,
 			errMatch: "stat path",
 		},
+		{
+			name:     "configured path",
+			args:     []string{"--dry-run", "--plain", "--path", "/non/existent/configured"},
+			wantErr:  true,
+			errMatch: "stat path /non/existent/configured",
+		},
+		{
+			name:    "argument over configured path",
+			args:    []string{"--dry-run", "--plain", "--path", "/non/existent/configured", "."},
+			wantErr: false,
+		},
 		{
 			name:     "too many arguments",
 			args:     []string{"--dry-run", "--plain", "path1", "path2"},
@@ -0,0 +1,115 @@
+// Package auditlog appends a line for every change git-herd makes to a repository to a log that
+// everyone who runs it on a machine can share, recording who ran it
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// Entry is one operation on one repository, as a JSON line in the log
+type Entry struct {
+	Time      time.Time           `json:"time"`
+	User      string              `json:"user"`                // OS user the operation ran as
+	UID       string              `json:"uid,omitempty"`       // Their user ID, empty where there is none
+	SudoUser  string              `json:"sudo_user,omitempty"` // The user who ran sudo, when run through it
+	Host      string              `json:"host,omitempty"`
+	Operation types.OperationType `json:"operation"`
+	Repo      string              `json:"repo"`            // Absolute path of the repository
+	Owner     string              `json:"owner,omitempty"` // Owner of the repository when someone else
+	Error     string              `json:"error,omitempty"`
+}
+
+// RunAs describes the OS user running git-herd, e.g. "alice" or "root (sudo from alice)"
+func RunAs() string {
+	name, _ := currentUser()
+	if sudo := os.Getenv("SUDO_USER"); sudo != "" && sudo != name {
+		return fmt.Sprintf("%s (sudo from %s)", name, sudo)
+	}
+	return name
+}
+
+// currentUser returns the name and ID of the OS user running git-herd
+func currentUser() (string, string) {
+	if u, err := user.Current(); err == nil {
+		return u.Username, u.Uid
+	}
+	uid := os.Getuid()
+	if uid < 0 {
+		return os.Getenv("USER"), ""
+	}
+	return os.Getenv("USER"), strconv.Itoa(uid)
+}
+
+// Log appends entries to the log file at its path. Each entry is a single write to a file opened
+// for appending, so runs by different users can share one log without interleaving lines. It is
+// safe for concurrent use.
+type Log struct {
+	path     string
+	user     string
+	uid      string
+	sudoUser string
+	host     string
+
+	mu  sync.Mutex
+	err error // First error appending, kept so a run warns once rather than per repository
+}
+
+// New returns a log appending to the file at path, which is created when first written to
+func New(path string) *Log {
+	l := &Log{path: path, sudoUser: os.Getenv("SUDO_USER")}
+	l.user, l.uid = currentUser()
+	l.host, _ = os.Hostname()
+	return l
+}
+
+// Append fills in who ran the operation and where, and appends the entry to the log
+func (l *Log) Append(entry Entry) error {
+	entry.User, entry.UID, entry.SudoUser, entry.Host = l.user, l.uid, l.sudoUser, l.host
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.write(entry)
+	if err != nil && l.err == nil {
+		l.err = err
+	}
+	return err
+}
+
+// Err returns the first error appending to the log, nil if every entry was written
+func (l *Log) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// write appends one entry as a line of JSON
+func (l *Log) write(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit log entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,86 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestLogAppend(t *testing.T) {
+	t.Setenv("SUDO_USER", "alice")
+	path := filepath.Join(t.TempDir(), "nested", "audit.log")
+	log := New(path)
+
+	var wg sync.WaitGroup
+	for _, repo := range []string{"/srv/api", "/srv/web", "/srv/docs"} {
+		wg.Go(func() {
+			if err := log.Append(Entry{Operation: types.OperationPull, Repo: repo}); err != nil {
+				t.Errorf("Append() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if err := log.Append(Entry{Operation: types.OperationPush, Repo: "/srv/api", Owner: "bob", Error: "rejected"}); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Line %q is not an entry: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.User == "" || entry.SudoUser != "alice" || entry.Time.IsZero() {
+			t.Errorf("Expected who ran it and when, got %+v", entry)
+		}
+	}
+	if last := entries[3]; last.Operation != types.OperationPush || last.Owner != "bob" || last.Error != "rejected" {
+		t.Errorf("Expected the push entry last, got %+v", last)
+	}
+	if log.Err() != nil {
+		t.Errorf("Err() = %v, want nil", log.Err())
+	}
+}
+
+func TestLogAppendError(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log := New(filepath.Join(blocker, "audit.log"))
+	if err := log.Append(Entry{Operation: types.OperationPull, Repo: "/srv/api"}); err == nil {
+		t.Fatal("Append() below a file succeeded, want error")
+	}
+	if log.Err() == nil {
+		t.Error("Err() = nil after a failed append")
+	}
+}
+
+func TestRunAs(t *testing.T) {
+	t.Setenv("SUDO_USER", "")
+	name, _ := currentUser()
+	if got := RunAs(); got != name {
+		t.Errorf("RunAs() = %q, want %q", got, name)
+	}
+	t.Setenv("SUDO_USER", "someone-else")
+	if got, want := RunAs(), name+" (sudo from someone-else)"; got != want {
+		t.Errorf("RunAs() through sudo = %q, want %q", got, want)
+	}
+}
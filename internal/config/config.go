@@ -3,14 +3,23 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/internal/index"
+	"github.com/entro314-labs/git-herd/internal/policy"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
@@ -30,25 +39,137 @@ func DefaultConfig() *types.Config {
 		SaveReport:   "",
 		DiscardFiles: []string{},
 		ExportScan:   "",
+		EmailDomains: []string{},
+		Protected:    []string{},
+		RequiredFiles: []string{
+			"LICENSE*",
+			"SECURITY.md",
+			"CODEOWNERS|.github/CODEOWNERS|docs/CODEOWNERS",
+			".github/workflows",
+		},
+		RateLimitRetries: 3,
+		SSHMultiplex:     true,
+		IPFamily:         types.IPFamilyAuto,
+		Output:           types.OutputText,
+		LogDest:          types.LogDestAuto,
+		Remote:           "origin",
+		PullStrategy:     types.PullFastForward,
+		Nested:           types.NestedInclude,
+		MinFreeMB:        1024,
+		HistoryFile:      history.DefaultPath(),
+		IndexFile:        index.DefaultPath(),
+		DiffMaxBytes:     2048,
+		IssueAfter:       3,
+		PreflightTimeout: 10 * time.Second,
+		ChangelogFile:    "changelog.md",
+		UnreleasedDays:   30,
 	}
 }
 
 // SetupFlags configures command line flags for the root command
 func SetupFlags(cmd *cobra.Command, config *types.Config) {
 	// Flags
-	cmd.Flags().VarP(newOperationValue(&config.Operation), "operation", "o", "Operation to perform: fetch, pull, or scan")
+	cmd.Flags().VarP(newOperationValue(&config.Operation), "operation", "o", "Operation to perform: fetch, pull, push, sync, checkout, stash, stash-pop, maintenance, prune-branches, set-url, exec, apply, heal, scan, audit-files, audit-email, status, changelog, releases, versions, or clone")
 	cmd.Flags().IntVarP(&config.Workers, "workers", "w", 5, "Number of concurrent workers")
+	cmd.Flags().StringToIntVarP(&config.OperationWorkers, "operation-workers", "", nil, "Concurrent workers per operation, as operation=count (repeatable), e.g. fetch=20,pull=4; --workers overrides")
+	cmd.Flags().StringVarP(&config.Profile, "profile", "", "", "Apply the settings of this profile, declared under profiles in the config file; flags still win")
+	cmd.Flags().StringVarP(&config.Path, "path", "", "", "Directory to process when none is given as an argument (default the current directory)")
+	cmd.Flags().StringVarP(&config.Pipeline, "pipeline", "", "", "Run the operations of this pipeline, declared under pipelines, on every repository in turn instead of --operation")
+	cmd.Flags().StringToStringVarP(new(map[string]string), "pipelines", "", nil, "Declare a pipeline as name=operations (repeatable), e.g. morning='fetch prune-branches status' or morning='fetch, pull if behind > 0'; the config file takes a list of operations per pipeline")
+	cmd.Flags().BoolVarP(&config.ResumePipeline, "resume-pipeline", "", false, "Resume the pipeline on each repository from the first step its last run failed or skipped, per the history, passing over those it went through")
 	cmd.Flags().BoolVarP(&config.DryRun, "dry-run", "n", false, "Show what would be done without executing")
 	cmd.Flags().BoolVarP(&config.Recursive, "recursive", "r", true, "Process repositories recursively")
 	cmd.Flags().BoolVarP(&config.SkipDirty, "skip-dirty", "s", true, "Skip repositories with uncommitted changes")
 	cmd.Flags().BoolVarP(&config.Verbose, "verbose", "v", false, "Enable verbose logging")
 	cmd.Flags().BoolVarP(&config.PlainMode, "plain", "p", false, "Use plain text output instead of TUI")
+	cmd.Flags().BoolVarP(&config.Select, "select", "", false, "Choose the repositories to process from a checklist after the scan, in the TUI")
 	cmd.Flags().BoolVarP(&config.FullSummary, "full-summary", "f", false, "Display full summary of all repositories")
 	cmd.Flags().StringVarP(&config.SaveReport, "save-report", "", "", "Save detailed report to file (e.g., report.txt)")
 	cmd.Flags().DurationVarP(&config.Timeout, "timeout", "t", 5*time.Minute, "Overall operation timeout")
-	cmd.Flags().StringSliceVarP(&config.ExcludeDirs, "exclude", "e", []string{".git", "node_modules", "vendor"}, "Directories to exclude")
+	cmd.Flags().StringSliceVarP(&config.ExcludeDirs, "exclude", "e", []string{".git", "node_modules", "vendor"}, "Directories to exclude: names or globs (tmp-*), or paths from the scan root (**/build)")
+	cmd.Flags().StringSliceVarP(&config.IncludeDirs, "include", "i", []string{}, "Only process repositories in directories matching these names, globs or paths, like --exclude")
+	cmd.Flags().IntVarP(&config.MaxDepth, "max-depth", "", 0, "Directory levels below the path to look for repositories in (0 for no limit)")
+	cmd.Flags().BoolVarP(&config.FollowSymlinks, "follow-symlinks", "", false, "Follow symlinked directories when looking for repositories, walking each directory once")
+	cmd.Flags().VarP(newNestedValue(&config.Nested), "nested", "", "Repositories inside other repositories (vendored checkouts, fixtures): include, skip, or only")
+	cmd.Flags().BoolVarP(&config.ForceRoot, "force-root", "", false, "Allow walking the filesystem root, or the home directory without a config file, and more than 200000 directories")
+	cmd.Flags().BoolVarP(&config.Cached, "cached", "", false, "Take the repositories from the index of the last walk of the path instead of walking it again")
+	cmd.Flags().BoolVarP(&config.Refresh, "refresh", "", false, "Walk the path and rebuild its index, even with --cached")
+	cmd.Flags().StringVarP(&config.IndexFile, "index-file", "", config.IndexFile, "File recording the repositories each walk found, for --cached (empty disables the index)")
+	cmd.Flags().StringVarP(&config.Filter, "filter", "", "", "Only process the discovered repositories whose name or path matches this glob (api-*) or regular expression between slashes (/^api-v[0-9]+$/)")
+	cmd.Flags().StringSliceVarP(&config.Only, "only", "", []string{}, "Only process the repositories in all of these states: dirty, clean, ahead, behind, detached or no-upstream")
+	cmd.Flags().StringVarP(&config.Group, "group", "", "", "Only process the repositories in this group")
+	cmd.Flags().StringToStringVarP(new(map[string]string), "groups", "", nil, "Declare a group as name=pattern (repeatable), e.g. clientA=~/work/clientA/*; the config file takes a list of patterns per group")
+	cmd.Flags().StringVarP(&config.OnBranch, "on-branch", "", "", "Only process the repositories whose current branch matches this name or glob, e.g. main or release/*")
+	cmd.Flags().StringSliceVarP(&config.RemoteHosts, "remote-host", "", []string{}, "Only process the repositories whose remote is on one of these hosts, e.g. github.com")
+	cmd.Flags().StringSliceVarP(&config.ExcludeRemotes, "exclude-remote", "", []string{}, "Skip the repositories whose remote matches one of these host/path patterns, e.g. github.com/legacy-org/*")
 	cmd.Flags().StringSliceVarP(&config.DiscardFiles, "discard-files", "d", []string{}, "File patterns to discard changes before pull/fetch (e.g., package.json,package-lock.json)")
 	cmd.Flags().StringVarP(&config.ExportScan, "export-scan", "", "", "Export repository scan to markdown file (use with -o scan)")
+	cmd.Flags().BoolVarP(&config.Manifests, "manifests", "", false, "Detect dependency manifests (go.mod, package.json, pyproject.toml) during scan")
+	cmd.Flags().BoolVarP(&config.SecurityCheck, "security-check", "", false, "Report executable hooks and suspicious local git config during scan")
+	cmd.Flags().StringSliceVarP(&config.RequiredFiles, "required-files", "", config.RequiredFiles, "Files each repository must contain for audit-files (globs allowed, '|' separates alternatives)")
+	cmd.Flags().DurationVarP(&config.Budget, "budget", "", 0, "Time budget: process the stalest repositories first and stop starting new ones when it runs out")
+	cmd.Flags().StringSliceVarP(&config.Protected, "protected", "", []string{}, "Repository paths or globs that only ever get read-only operations")
+	cmd.Flags().StringSliceVarP(&config.EmailDomains, "email-domains", "", []string{}, "Allowed user.email domains for audit-email (e.g., example.com)")
+	cmd.Flags().IntVarP(&config.MinFreeMB, "min-free-mb", "", config.MinFreeMB, "Free disk space, in MiB, fetch, pull, sync and clone need to start, so they don't run out halfway (0 disables the check)")
+	cmd.Flags().IntVarP(&config.RateLimitRetries, "rate-limit-retries", "", 3, "Times to retry a fetch/pull after the forge rate-limits it (honoring Retry-After)")
+	cmd.Flags().BoolVarP(&config.SSHMultiplex, "ssh-multiplex", "", true, "Reuse one SSH connection per host (ControlMaster) for git commands run via the CLI")
+	cmd.Flags().VarP(newIPFamilyValue(&config.IPFamily), "ip-family", "", "IP family for network connections: 4, 6, or auto")
+	cmd.Flags().BoolVarP(&config.Preflight, "preflight", "", false, "Check that every repository's remote answers git ls-remote before processing, failing unreachable ones up front (use with -o fetch, pull, push or sync)")
+	cmd.Flags().DurationVarP(&config.PreflightTimeout, "preflight-timeout", "", config.PreflightTimeout, "How long each --preflight check may take")
+	cmd.Flags().BoolVarP(&config.AddSafeDirectory, "add-safe-directory", "", false, "Add repositories owned by another user to git's global safe.directory instead of skipping them")
+	cmd.Flags().BoolVarP(&config.SharedWorkspace, "shared-workspace", "", false, "Only ever run read-only analysis on repositories owned by another user, e.g. on a shared server")
+	cmd.Flags().StringSliceVarP(&config.AllowOwners, "allow-owner", "", []string{}, "Users (names or IDs) whose repositories --shared-workspace still lets operations write to")
+	cmd.Flags().StringVarP(&config.AuditLog, "audit-log", "", "", "File to append every mutating operation to, with the OS user who ran it (shareable by all users)")
+	cmd.Flags().DurationVarP(&config.Jitter, "jitter", "", 0, "Delay the start by up to this long, derived from the hostname, so scheduled runs on many machines don't stampede the forge")
+	cmd.Flags().StringVarP(&config.JitterSeed, "jitter-seed", "", "", "Seed for the jitter delay instead of the hostname")
+	cmd.Flags().StringVarP(&config.HistoryFile, "history-file", "", config.HistoryFile, "File recording per-repository outcomes across runs (empty disables history)")
+	cmd.Flags().DurationVarP(&config.RepoTimeout, "repo-timeout", "", 0, "Timeout for each repository (0 for none); also the fallback for adaptive timeouts")
+	cmd.Flags().Float64VarP(&config.AdaptiveTimeout, "adaptive-timeout", "", 0, "Time out each repository at this multiple of its p95 duration from history, e.g. 3 (0 disables)")
+	cmd.Flags().StringVarP(&config.IssueRepo, "issue-repo", "", "", "GitHub repository (owner/name) to open or update an issue in for each repository failing --issue-after runs in a row (token from GITHUB_TOKEN)")
+	cmd.Flags().IntVarP(&config.IssueAfter, "issue-after", "", config.IssueAfter, "Consecutive failed runs before an issue is filed (use with --issue-repo)")
+	cmd.Flags().StringToStringVarP(&config.Labels, "label", "", nil, "Label attached to the report, summary file, TAP output, history and issues, as key=value (repeatable), e.g. --label host=laptop")
+	cmd.Flags().BoolVarP(&config.NotifyDryRun, "notify-dry-run", "", false, "Print the issues --issue-repo would file instead of filing them, and write report files to a temporary directory")
+	cmd.Flags().StringVarP(&config.SummaryFile, "summary-file", "", "", "Always write a JSON run summary (counts, duration, status) to this file, e.g. for CI")
+	cmd.Flags().StringVarP(&config.Badge, "badge", "", "", "Always write an SVG status badge (shields.io style) with the run's success ratio to this file")
+	cmd.Flags().VarP(newOutputValue(&config.Output), "output", "", "Output format for results: text or tap (TAP goes to stdout, everything else to stderr)")
+	cmd.Flags().VarP(newLogDestValue(&config.LogDest), "log-dest", "", "Where progress messages and logs go: stdout, stderr, or auto (wherever results go)")
+	cmd.Flags().StringVarP(&config.Remote, "remote", "", "origin", "Remote to fetch and pull from and push to; repositories without it are skipped")
+	cmd.Flags().BoolVarP(&config.SetUpstream, "set-upstream", "", false, "Set the upstream of branches that have none to <remote>/<branch> when the remote has that branch")
+	cmd.Flags().BoolVarP(&config.ExportDiffs, "export-diffs", "", false, "Include per-file change stats and a truncated diff of uncommitted changes in the scan export")
+	cmd.Flags().IntVarP(&config.DiffMaxBytes, "diff-max-bytes", "", 2048, "Size cap of each file's diff in the scan export (0 for change stats only)")
+	cmd.Flags().StringVarP(&config.VSCodeWorkspace, "emit-vscode-workspace", "", "", "Write a VS Code multi-root workspace with the discovered repositories (use with -o scan)")
+	cmd.Flags().StringVarP(&config.ProjectList, "emit-project-list", "", "", "Write the discovered repository paths, one per line, e.g. for JetBrains IDEs (use with -o scan)")
+	cmd.Flags().StringVarP(&config.TmuxSession, "emit-tmux-session", "", "", "Write a tmuxp session config with one window per repository needing attention (failed, dirty, flagged or non-compliant)")
+	cmd.Flags().BoolVarP(&config.FetchFirst, "fetch-first", "", false, "Fetch from the remote before computing status, so ahead/behind counts are current (use with -o status)")
+	cmd.Flags().IntVarP(&config.OwnersMonths, "owners-months", "", 0, "Report each repository's top committers over this many months, honoring .mailmap (scan, audits and status; 0 disables)")
+	cmd.Flags().BoolVarP(&config.ForceWithLease, "force-with-lease", "", false, "Push branches that diverged from their upstream, as long as the remote branch is still where it was last fetched (use with -o push)")
+	cmd.Flags().StringVarP(&config.CloneManifest, "manifest", "", "", "YAML file listing the repositories to clone, each with a url and optional path and branch, or to check against the minimum version each lists (use with clone or versions)")
+	cmd.Flags().BoolVarP(&config.SkipLocked, "skip-locked", "", false, "Skip pulling repositories whose git-crypt or transcrypt files are locked, since pulled ciphertext looks like noise")
+	cmd.Flags().StringVarP(&config.Branch, "branch", "", "", "Branch to switch every repository to (use with -o checkout)")
+	cmd.Flags().StringVarP(&config.Lock, "lock", "", "", "Lockfile listing the ref (commit or tag) to check out in each repository, instead of a branch (use with -o checkout)")
+	cmd.Flags().BoolVarP(&config.CreateBranch, "create", "", false, "Create the branch where it only exists on the remote, tracking <remote>/<branch> (use with -o checkout)")
+	cmd.Flags().BoolVarP(&config.PruneOnly, "prune-only", "", false, "Only prune remote-tracking branches whose branch is gone from the remote, without garbage collection (use with -o maintenance)")
+	cmd.Flags().BoolVarP(&config.Repack, "repack", "", false, "Also repack all objects into a single pack after gc (use with -o maintenance)")
+	cmd.Flags().BoolVarP(&config.DeleteUnpushed, "delete-unpushed", "", false, "Also delete branches whose upstream is gone while they hold commits no remote branch contains (use with -o prune-branches)")
+	cmd.Flags().VarP(newPullStrategyValue(&config.PullStrategy), "pull-strategy", "", "How pull and sync handle branches that diverged from the remote: ff-only (skip them), merge, or rebase")
+	cmd.Flags().BoolVarP(&config.LFS, "lfs", "", false, "Download Git LFS objects with git lfs fetch after a fetch, or check them out with git lfs pull after a pull (use with -o fetch or pull)")
+	cmd.Flags().IntVarP(&config.Depth, "depth", "", 0, "Fetch only this many commits of history, making or keeping clones shallow (use with -o fetch or pull)")
+	cmd.Flags().BoolVarP(&config.Unshallow, "unshallow", "", false, "Fetch the complete history of shallow clones (use with -o fetch or pull)")
+	cmd.Flags().BoolVarP(&config.Submodules, "submodules", "", false, "Recurse into submodules: fetch them too, or check them out at the recorded commits after a pull (use with -o fetch or pull)")
+	cmd.Flags().StringVarP(&config.Exec, "exec", "", "", "Shell command to run in every repository (use with -o exec)")
+	cmd.Flags().StringVarP(&config.URLMatch, "url-match", "", "", "Part of the remote URLs to rewrite, e.g. git@old-host: (use with -o set-url)")
+	cmd.Flags().StringVarP(&config.URLReplace, "url-replace", "", "", "What to replace --url-match with in remote URLs (use with -o set-url)")
+	cmd.Flags().StringVarP(&config.ApplyScript, "apply-script", "", "", "Executable script to run in every repository, whose changes are committed (use with -o apply)")
+	cmd.Flags().StringVarP(&config.ApplyPatch, "apply-patch", "", "", "Patch file to apply to every repository with git apply and commit (use with -o apply)")
+	cmd.Flags().StringVarP(&config.CommitMessage, "commit-message", "", "", "Commit message for the change, a template such as 'Bump CI in {{.Name}}' (use with -o apply)")
+	cmd.Flags().StringVarP(&config.ApplyBranch, "apply-branch", "", "", "Create this branch and commit the change to it instead of the current branch (use with -o apply)")
+	cmd.Flags().BoolVarP(&config.ApplyPush, "apply-push", "", false, "Push the new branch to the remote after committing (use with -o apply and --apply-branch)")
+	cmd.Flags().StringVarP(&config.Since, "since", "", "", "Collect the commits made since this date, YYYY-MM-DD (use with -o changelog)")
+	cmd.Flags().BoolVarP(&config.Conventional, "conventional", "", false, "Group the changelog by conventional-commit type (feat, fix, ...) (use with -o changelog)")
+	cmd.Flags().StringVarP(&config.ChangelogFile, "changelog-file", "", config.ChangelogFile, "Markdown file the changelog is written to (use with -o changelog)")
+	cmd.Flags().BoolVarP(&config.CheckoutTag, "checkout-tag", "", false, "Check out the expected tag in repositories that are behind it (use with -o versions)")
+	cmd.Flags().IntVarP(&config.UnreleasedDays, "unreleased-days", "", config.UnreleasedDays, "Flag repositories whose oldest commit since their latest tag is older than this many days (use with -o releases)")
+	cmd.Flags().BoolVarP(&config.AutoStash, "autostash", "", false, "Stash uncommitted changes before pulling dirty repositories and pop them afterwards, instead of skipping them (use with -o pull)")
 }
 
 // operationValue implements pflag.Value for OperationType
@@ -73,8 +194,157 @@ func (o *operationValue) Type() string {
 	return "string"
 }
 
+// outputValue implements pflag.Value for OutputFormat
+type outputValue struct {
+	target *types.OutputFormat
+}
+
+func newOutputValue(target *types.OutputFormat) *outputValue {
+	return &outputValue{target: target}
+}
+
+func (o *outputValue) String() string {
+	return string(*o.target)
+}
+
+func (o *outputValue) Set(s string) error {
+	*o.target = types.OutputFormat(s)
+	return nil
+}
+
+func (o *outputValue) Type() string {
+	return "string"
+}
+
+// logDestValue implements pflag.Value for LogDest
+type logDestValue struct {
+	target *types.LogDest
+}
+
+func newLogDestValue(target *types.LogDest) *logDestValue {
+	return &logDestValue{target: target}
+}
+
+func (d *logDestValue) String() string {
+	return string(*d.target)
+}
+
+func (d *logDestValue) Set(s string) error {
+	*d.target = types.LogDest(s)
+	return nil
+}
+
+func (d *logDestValue) Type() string {
+	return "string"
+}
+
+// ipFamilyValue implements pflag.Value for IPFamily
+type ipFamilyValue struct {
+	target *types.IPFamily
+}
+
+func newIPFamilyValue(target *types.IPFamily) *ipFamilyValue {
+	return &ipFamilyValue{target: target}
+}
+
+func (f *ipFamilyValue) String() string {
+	return string(*f.target)
+}
+
+func (f *ipFamilyValue) Set(s string) error {
+	*f.target = types.IPFamily(s)
+	return nil
+}
+
+func (f *ipFamilyValue) Type() string {
+	return "string"
+}
+
+// pullStrategyValue implements pflag.Value for PullStrategy
+type pullStrategyValue struct {
+	target *types.PullStrategy
+}
+
+func newPullStrategyValue(target *types.PullStrategy) *pullStrategyValue {
+	return &pullStrategyValue{target: target}
+}
+
+func (s *pullStrategyValue) String() string {
+	return string(*s.target)
+}
+
+func (s *pullStrategyValue) Set(value string) error {
+	*s.target = types.PullStrategy(value)
+	return nil
+}
+
+func (s *pullStrategyValue) Type() string {
+	return "string"
+}
+
+// nestedValue implements pflag.Value for NestedMode
+type nestedValue struct {
+	target *types.NestedMode
+}
+
+func newNestedValue(target *types.NestedMode) *nestedValue {
+	return &nestedValue{target: target}
+}
+
+func (n *nestedValue) String() string {
+	return string(*n.target)
+}
+
+func (n *nestedValue) Set(value string) error {
+	*n.target = types.NestedMode(value)
+	return nil
+}
+
+func (n *nestedValue) Type() string {
+	return "string"
+}
+
+// envPrefix prefixes the environment variable of every configuration key
+const envPrefix = "GIT_HERD"
+
+// configKeys are the configuration keys, each set by the flag, environment variable and config
+// file entry of the same name
+var configKeys = []string{
+	"operation", "workers", "operation-workers", "profile", "path", "pipeline", "pipelines", "resume-pipeline", "dry-run", "recursive", "skip-dirty",
+	"verbose", "plain", "select", "full-summary", "save-report", "timeout", "exclude", "include",
+	"max-depth", "follow-symlinks", "nested", "force-root", "cached", "refresh", "index-file", "filter", "group", "groups", "only", "on-branch", "remote-host", "exclude-remote", "discard-files", "export-scan", "required-files", "manifests", "security-check",
+	"email-domains", "protected", "budget", "min-free-mb", "rate-limit-retries",
+	"ssh-multiplex", "ip-family", "jitter", "jitter-seed", "add-safe-directory",
+	"shared-workspace", "allow-owner", "audit-log",
+	"history-file", "repo-timeout", "adaptive-timeout", "summary-file",
+	"output", "remote", "set-upstream", "export-diffs", "diff-max-bytes",
+	"emit-vscode-workspace", "emit-project-list", "emit-tmux-session", "fetch-first",
+	"owners-months", "force-with-lease", "manifest",
+	"skip-locked", "branch", "lock", "create", "autostash", "prune-only", "repack", "delete-unpushed", "pull-strategy",
+	"log-dest", "submodules", "badge", "exec", "issue-repo", "issue-after",
+	"notify-dry-run", "url-match", "url-replace", "lfs", "label",
+	"depth", "unshallow", "preflight", "preflight-timeout", "apply-script", "apply-patch", "commit-message", "apply-branch", "apply-push",
+	"since", "conventional", "changelog-file", "unreleased-days", "checkout-tag",
+}
+
+// Keys returns the configuration keys, which are also the names of their flags
+func Keys() []string {
+	return slices.Clone(configKeys)
+}
+
+// EnvVar returns the environment variable that sets a configuration key, e.g. GIT_HERD_DRY_RUN
+// for dry-run
+func EnvVar(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// flags are the flags of the command the configuration is loaded for, set by SetupViper
+var flags *pflag.FlagSet
+
 // SetupViper configures viper for configuration file support
 func SetupViper(cmd *cobra.Command) error {
+	flags = cmd.Flags()
+
 	// Setup viper for configuration file support
 	viper.SetConfigName("git-herd")
 	viper.SetConfigType("yaml")
@@ -83,18 +353,14 @@ func SetupViper(cmd *cobra.Command) error {
 		viper.AddConfigPath(filepath.Join(configDir, "git-herd"))
 	}
 
-	viper.SetEnvPrefix("GIT_HERD")
+	viper.SetEnvPrefix(envPrefix)
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
+	// An empty variable clears a setting, e.g. GIT_HERD_HISTORY_FILE= disables history
+	viper.AllowEmptyEnv(true)
 
 	// Bind flags to viper
-	flags := []string{
-		"operation", "workers", "dry-run", "recursive", "skip-dirty",
-		"verbose", "plain", "full-summary", "save-report", "timeout", "exclude",
-		"discard-files", "export-scan",
-	}
-
-	for _, name := range flags {
+	for _, name := range configKeys {
 		flag := cmd.Flags().Lookup(name)
 		if flag == nil {
 			return fmt.Errorf("missing flag definition: %s", name)
@@ -119,48 +385,631 @@ func SetupViper(cmd *cobra.Command) error {
 	return nil
 }
 
+// FileUsed returns the config file the configuration was read from, "" when there is none
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
 // LoadConfig loads and validates configuration
 func LoadConfig() (*types.Config, error) {
 	config := DefaultConfig()
 
-	// Load from viper (which includes file and flags)
-	if err := viper.Unmarshal(config); err != nil {
+	// Load from viper (which includes file, environment and flags)
+	if err := viper.Unmarshal(config, viper.DecodeHook(decodeHook())); err != nil {
 		return nil, fmt.Errorf("unmarshal config: %w", err)
 	}
 
+	var profiled []string
+	if config.Profile != "" {
+		var err error
+		if profiled, err = applyProfile(config); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := ValidateConfig(config); err != nil {
 		return nil, err
 	}
 
+	// The operation's own worker count wins over the general one, unless that was given for
+	// this run or by the profile
+	if workers, ok := config.OperationWorkers[string(config.Operation)]; ok && !explicit("workers") && !slices.Contains(profiled, "workers") {
+		config.Workers = workers
+	}
+
 	return config, nil
 }
 
+// decodeHook converts configuration values into the types of their Config fields
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		decodeEnvString,
+	)
+}
+
+// applyProfile sets the settings of the profile config.Profile names, declared under profiles
+// in the config file, e.g. profiles: {work: {path: ~/work, operation: pull, workers: 10}}. They
+// win over the rest of the file, but not over the flags and environment variables given for
+// this run. It returns the keys the profile set.
+func applyProfile(config *types.Config) ([]string, error) {
+	profiles := viper.GetStringMap("profiles")
+	// Viper lowercases keys, so profile names are case-insensitive
+	settings, ok := profiles[strings.ToLower(config.Profile)]
+	if !ok {
+		if len(profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: the config file declares no profiles", config.Profile)
+		}
+		return nil, fmt.Errorf("unknown profile %q: the config file declares %s", config.Profile, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+	values, ok := settings.(map[string]any)
+	if !ok && settings != nil {
+		return nil, fmt.Errorf("profile %s: expected settings by key, e.g. workers: 10", config.Profile)
+	}
+
+	overrides := make(map[string]any, len(values))
+	for key, value := range values {
+		if key == "profile" || !slices.Contains(configKeys, key) {
+			return nil, fmt.Errorf("profile %s: unknown setting %s", config.Profile, key)
+		}
+		if !explicit(key) {
+			overrides[key] = value
+		}
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       decodeHook(),
+		WeaklyTypedInput: true,
+		ZeroFields:       true, // A profile's lists and maps replace the file's rather than add to them
+		Result:           config,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(overrides); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", config.Profile, err)
+	}
+	return slices.Sorted(maps.Keys(overrides)), nil
+}
+
+// explicit reports whether a configuration key was set for this run, by its flag or its
+// environment variable, rather than by the configuration file or its default
+func explicit(key string) bool {
+	if flags != nil && flags.Changed(key) {
+		return true
+	}
+	_, ok := os.LookupEnv(EnvVar(key))
+	return ok
+}
+
+// decodeEnvString converts the strings environment variables carry into lists and maps: a list
+// is comma-separated, e.g. GIT_HERD_EXCLUDE=".git,node_modules", and a map is comma-separated
+// key=value pairs, e.g. GIT_HERD_LABEL="host=ci,env=prod"
+func decodeEnvString(from, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || from.Kind() != reflect.String {
+		return data, nil
+	}
+
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	switch to.Kind() {
+	case reflect.Slice:
+		if items == nil {
+			return []string{}, nil
+		}
+		return items, nil
+	case reflect.Map:
+		pairs := make(map[string]string, len(items))
+		for _, item := range items {
+			key, val, found := strings.Cut(item, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid key=value pair %q", item)
+			}
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+		return pairs, nil
+	default:
+		return data, nil
+	}
+}
+
 // ValidateConfig validates and normalizes configuration
 func ValidateConfig(config *types.Config) error {
 	if config.Workers <= 0 {
 		return fmt.Errorf("workers must be greater than 0")
 	}
 
+	for operation, workers := range config.OperationWorkers {
+		if !isOperation(types.OperationType(operation)) {
+			return fmt.Errorf("invalid operation-workers: %s is not an operation", operation)
+		}
+		if workers <= 0 {
+			return fmt.Errorf("operation-workers for %s must be greater than 0", operation)
+		}
+	}
+
 	if config.Timeout < 0 {
 		return fmt.Errorf("timeout must be non-negative")
 	}
 
+	for _, pattern := range slices.Concat(config.ExcludeDirs, config.IncludeDirs) {
+		if _, err := path.Match(filepath.ToSlash(pattern), ""); err != nil {
+			return fmt.Errorf("invalid directory pattern: %s", pattern)
+		}
+	}
+
+	config.Filter = strings.TrimSpace(config.Filter)
+	filterRegexp, err := config.FilterRegexp()
+	if err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	if _, err := path.Match(filepath.ToSlash(config.Filter), ""); filterRegexp == nil && err != nil {
+		return fmt.Errorf("invalid filter: %s", config.Filter)
+	}
+
+	for i, state := range config.Only {
+		config.Only[i] = strings.ToLower(strings.TrimSpace(state))
+		if !slices.Contains(types.RepoStates, types.RepoState(config.Only[i])) {
+			return fmt.Errorf("invalid only: %s (must be one of dirty, clean, ahead, behind, detached, no-upstream)", state)
+		}
+	}
+	if _, err := path.Match(config.OnBranch, ""); err != nil {
+		return fmt.Errorf("invalid on-branch: %s", config.OnBranch)
+	}
+
+	config.Group = strings.TrimSpace(config.Group)
+	for name, patterns := range config.Groups {
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "/\\") {
+			return fmt.Errorf("invalid group name %q: must be non-empty, without slashes", name)
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(filepath.ToSlash(pattern), ""); err != nil {
+				return fmt.Errorf("invalid pattern for group %s: %s", name, pattern)
+			}
+		}
+	}
+
+	if config.MaxDepth < 0 {
+		return fmt.Errorf("max-depth must be non-negative")
+	}
+
+	if config.Cached && config.IndexFile == "" {
+		return fmt.Errorf("cached requires an index-file to read the repositories from")
+	}
+
+	if config.Budget < 0 {
+		return fmt.Errorf("budget must be non-negative")
+	}
+
+	if config.MinFreeMB < 0 {
+		return fmt.Errorf("min-free-mb must be non-negative")
+	}
+
+	if config.RateLimitRetries < 0 {
+		return fmt.Errorf("rate-limit-retries must be non-negative")
+	}
+
+	if config.Jitter < 0 {
+		return fmt.Errorf("jitter must be non-negative")
+	}
+
+	if len(config.AllowOwners) > 0 && !config.SharedWorkspace {
+		return fmt.Errorf("allow-owner requires shared-workspace")
+	}
+
+	if config.RepoTimeout < 0 {
+		return fmt.Errorf("repo-timeout must be non-negative")
+	}
+
+	if config.AdaptiveTimeout < 0 {
+		return fmt.Errorf("adaptive-timeout must be non-negative")
+	}
+
+	config.IssueRepo = strings.TrimSpace(config.IssueRepo)
+	if config.IssueRepo != "" {
+		if owner, name, ok := strings.Cut(config.IssueRepo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid issue-repo: %s (must be owner/name)", config.IssueRepo)
+		}
+		if config.IssueAfter < 1 {
+			return fmt.Errorf("issue-after must be at least 1")
+		}
+		if config.HistoryFile == "" {
+			return fmt.Errorf("issue-repo requires a history-file to count failed runs in")
+		}
+	}
+
+	for key := range config.Labels {
+		if key == "" || strings.ContainsAny(key, " \t=,") {
+			return fmt.Errorf("invalid label key %q: must be non-empty, without spaces, '=' or ','", key)
+		}
+	}
+
+	switch family := types.IPFamily(strings.ToLower(strings.TrimSpace(string(config.IPFamily)))); family {
+	case "", types.IPFamilyAuto:
+		config.IPFamily = types.IPFamilyAuto
+	case types.IPFamily4, types.IPFamily6:
+		config.IPFamily = family
+	default:
+		return fmt.Errorf("invalid ip-family: %s (must be '4', '6', or 'auto')", config.IPFamily)
+	}
+
+	switch strategy := types.PullStrategy(strings.ToLower(strings.TrimSpace(string(config.PullStrategy)))); strategy {
+	case "", types.PullFastForward:
+		config.PullStrategy = types.PullFastForward
+	case types.PullMerge, types.PullRebase:
+		config.PullStrategy = strategy
+	default:
+		return fmt.Errorf("invalid pull-strategy: %s (must be 'ff-only', 'merge', or 'rebase')", config.PullStrategy)
+	}
+
+	switch nested := types.NestedMode(strings.ToLower(strings.TrimSpace(string(config.Nested)))); nested {
+	case "", types.NestedInclude:
+		config.Nested = types.NestedInclude
+	case types.NestedSkip, types.NestedOnly:
+		config.Nested = nested
+	default:
+		return fmt.Errorf("invalid nested: %s (must be 'include', 'skip', or 'only')", config.Nested)
+	}
+
+	config.Remote = strings.TrimSpace(config.Remote)
+	if config.Remote == "" {
+		return fmt.Errorf("remote must not be empty")
+	}
+
+	switch output := types.OutputFormat(strings.ToLower(strings.TrimSpace(string(config.Output)))); output {
+	case "", types.OutputText:
+		config.Output = types.OutputText
+	case types.OutputTAP:
+		config.Output = output
+	default:
+		return fmt.Errorf("invalid output: %s (must be 'text' or 'tap')", config.Output)
+	}
+
+	switch dest := types.LogDest(strings.ToLower(strings.TrimSpace(string(config.LogDest)))); dest {
+	case "", types.LogDestAuto:
+		config.LogDest = types.LogDestAuto
+	case types.LogDestStdout, types.LogDestStderr:
+		config.LogDest = dest
+	default:
+		return fmt.Errorf("invalid log-dest: %s (must be 'stdout', 'stderr', or 'auto')", config.LogDest)
+	}
+
+	if config.LogDest == types.LogDestStdout && config.Output == types.OutputTAP {
+		return fmt.Errorf("log-dest stdout would mix logs into the TAP output")
+	}
+
+	if config.Select && (config.PlainMode || config.Verbose || config.Output == types.OutputTAP) {
+		return fmt.Errorf("select chooses repositories in the TUI, which plain, verbose and output tap turn off")
+	}
+
+	if err := validatePipelines(config); err != nil {
+		return err
+	}
+
 	operation := strings.ToLower(strings.TrimSpace(string(config.Operation)))
 	if operation == "" {
 		config.Operation = types.OperationFetch
 	} else {
 		config.Operation = types.OperationType(operation)
-		switch config.Operation {
-		case types.OperationFetch, types.OperationPull, types.OperationScan:
-			// valid
-		default:
-			return fmt.Errorf("invalid operation: %s (must be 'fetch', 'pull', or 'scan')", config.Operation)
+		if !isOperation(config.Operation) {
+			return fmt.Errorf("invalid operation: %s (must be 'fetch', 'pull', 'push', 'sync', 'checkout', 'stash', 'stash-pop', 'maintenance', 'prune-branches', 'set-url', 'exec', 'apply', 'heal', 'scan', 'audit-files', 'audit-email', 'status', 'changelog', 'releases', 'versions', or 'clone')", config.Operation)
+		}
+	}
+
+	// With a pipeline, the options of an operation are valid when any of its steps runs it, and
+	// each step that runs an operation needs that operation's settings
+	ops := []types.OperationType{config.Operation}
+	if config.Pipeline != "" {
+		ops = nil
+		for _, step := range config.PipelineSteps() {
+			ops = append(ops, step.Operation)
+		}
+	}
+	runs := func(operations ...types.OperationType) bool {
+		return slices.ContainsFunc(ops, func(op types.OperationType) bool { return slices.Contains(operations, op) })
+	}
+
+	if config.Preflight {
+		if !runs(types.OperationFetch, types.OperationPull, types.OperationPush, types.OperationSync) {
+			return fmt.Errorf("preflight requires operation 'fetch', 'pull', 'push' or 'sync'")
 		}
+		if config.PreflightTimeout <= 0 {
+			return fmt.Errorf("preflight-timeout must be greater than 0")
+		}
+	}
+
+	if runs(types.OperationAuditFiles) && len(config.RequiredFiles) == 0 {
+		return fmt.Errorf("audit-files requires at least one required file")
+	}
+
+	if runs(types.OperationAuditEmail) && len(config.EmailDomains) == 0 {
+		return fmt.Errorf("audit-email requires at least one allowed email domain")
+	}
+
+	config.Branch = strings.TrimSpace(config.Branch)
+	if runs(types.OperationCheckout) && (config.Branch == "") == (config.Lock == "") {
+		return fmt.Errorf("checkout requires either a branch (--branch) or a lockfile (--lock)")
+	}
+
+	if config.Lock != "" && !runs(types.OperationCheckout) {
+		return fmt.Errorf("lock requires operation 'checkout'")
+	}
+
+	if config.Branch != "" && !runs(types.OperationCheckout) {
+		return fmt.Errorf("branch requires operation 'checkout'")
+	}
+
+	if config.CreateBranch && !runs(types.OperationCheckout) {
+		return fmt.Errorf("create requires operation 'checkout'")
 	}
 
-	if config.ExportScan != "" && config.Operation != types.OperationScan {
+	if runs(types.OperationVersions) && config.CloneManifest == "" {
+		return fmt.Errorf("versions requires a manifest (--manifest)")
+	}
+
+	if config.CheckoutTag && !runs(types.OperationVersions) {
+		return fmt.Errorf("checkout-tag requires operation 'versions'")
+	}
+
+	if runs(types.OperationClone) && config.CloneManifest == "" {
+		return fmt.Errorf("clone requires a manifest (--manifest)")
+	}
+
+	if config.CloneManifest != "" && !runs(types.OperationClone, types.OperationVersions) {
+		return fmt.Errorf("manifest requires operation 'clone' or 'versions'")
+	}
+
+	if config.ExportScan != "" && !runs(types.OperationScan) {
 		return fmt.Errorf("export-scan requires operation 'scan'")
 	}
 
+	if config.OwnersMonths < 0 {
+		return fmt.Errorf("owners-months must be non-negative")
+	}
+
+	if config.OwnersMonths > 0 && !slices.ContainsFunc(ops, types.OperationType.IsAnalysis) {
+		return fmt.Errorf("owners-months requires an analysis operation (scan, audit-files, audit-email, status, changelog, or releases)")
+	}
+
+	if config.ForceWithLease && !runs(types.OperationPush) {
+		return fmt.Errorf("force-with-lease requires operation 'push'")
+	}
+
+	if config.PruneOnly && !runs(types.OperationMaintenance) {
+		return fmt.Errorf("prune-only requires operation 'maintenance'")
+	}
+
+	if config.Repack && !runs(types.OperationMaintenance) {
+		return fmt.Errorf("repack requires operation 'maintenance'")
+	}
+
+	if config.PruneOnly && config.Repack {
+		return fmt.Errorf("prune-only and repack are mutually exclusive")
+	}
+
+	if config.DeleteUnpushed && !runs(types.OperationPruneBranches) {
+		return fmt.Errorf("delete-unpushed requires operation 'prune-branches'")
+	}
+
+	config.Exec = strings.TrimSpace(config.Exec)
+	if runs(types.OperationExec) && config.Exec == "" {
+		return fmt.Errorf("exec requires a command (git-herd exec -- <command>)")
+	}
+
+	if config.Exec != "" && !runs(types.OperationExec) {
+		return fmt.Errorf("exec command requires operation 'exec'")
+	}
+
+	if runs(types.OperationSetURL) && config.URLMatch == "" {
+		return fmt.Errorf("set-url requires url-match (git-herd remotes set-url --match <old> --replace <new>)")
+	}
+
+	if (config.URLMatch != "" || config.URLReplace != "") && !runs(types.OperationSetURL) {
+		return fmt.Errorf("url-match and url-replace require operation 'set-url'")
+	}
+
+	applying := runs(types.OperationApply)
+	if applying && (config.ApplyScript == "") == (config.ApplyPatch == "") {
+		return fmt.Errorf("apply requires either apply-script or apply-patch (git-herd apply --script <file> -m <message>)")
+	}
+
+	if !applying && (config.ApplyScript != "" || config.ApplyPatch != "" || config.CommitMessage != "" ||
+		config.ApplyBranch != "" || config.ApplyPush) {
+		return fmt.Errorf("apply-script, apply-patch, commit-message, apply-branch and apply-push require operation 'apply'")
+	}
+
+	// The script and patch are run from every repository, so they are resolved against where
+	// git-herd was started
+	for _, file := range []*string{&config.ApplyScript, &config.ApplyPatch} {
+		if *file == "" {
+			continue
+		}
+		abs, err := filepath.Abs(*file)
+		if err != nil {
+			return fmt.Errorf("invalid apply file %s: %w", *file, err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return fmt.Errorf("apply file: %w", err)
+		}
+		*file = abs
+	}
+
+	if applying && strings.TrimSpace(config.CommitMessage) == "" {
+		return fmt.Errorf("apply requires commit-message")
+	}
+
+	if applying {
+		if _, err := config.RenderCommitMessage(types.GitRepo{}); err != nil {
+			return fmt.Errorf("invalid commit-message template: %w", err)
+		}
+	}
+
+	config.ApplyBranch = strings.TrimSpace(config.ApplyBranch)
+	if config.ApplyPush && config.ApplyBranch == "" {
+		return fmt.Errorf("apply-push requires apply-branch, so the change is pushed for review rather than to the current branch")
+	}
+
+	if runs(types.OperationChangelog) {
+		if config.Since == "" {
+			return fmt.Errorf("changelog requires since (git-herd changelog --since 2024-01-01)")
+		}
+		if _, err := time.Parse(time.DateOnly, config.Since); err != nil {
+			return fmt.Errorf("invalid since: %s (must be a date such as 2024-01-01)", config.Since)
+		}
+		if config.ChangelogFile == "" {
+			return fmt.Errorf("changelog requires changelog-file")
+		}
+	} else if config.Since != "" || config.Conventional {
+		return fmt.Errorf("since and conventional require operation 'changelog'")
+	}
+
+	if runs(types.OperationReleases) && config.UnreleasedDays < 1 {
+		return fmt.Errorf("unreleased-days must be at least 1")
+	}
+
+	if config.Submodules && !runs(types.OperationFetch, types.OperationPull) {
+		return fmt.Errorf("submodules requires operation 'fetch' or 'pull'")
+	}
+
+	if config.LFS && !runs(types.OperationFetch, types.OperationPull) {
+		return fmt.Errorf("lfs requires operation 'fetch' or 'pull'")
+	}
+
+	if config.Depth < 0 {
+		return fmt.Errorf("depth must be at least 0")
+	}
+
+	if (config.Depth > 0 || config.Unshallow) && !runs(types.OperationFetch, types.OperationPull) {
+		return fmt.Errorf("depth and unshallow require operation 'fetch' or 'pull'")
+	}
+
+	if config.Depth > 0 && config.Unshallow {
+		return fmt.Errorf("depth and unshallow cannot be used together")
+	}
+
+	if config.AutoStash && !runs(types.OperationPull) {
+		return fmt.Errorf("autostash requires operation 'pull'")
+	}
+
+	if config.SkipLocked && !runs(types.OperationPull) {
+		return fmt.Errorf("skip-locked requires operation 'pull'")
+	}
+
+	if config.FetchFirst && !runs(types.OperationStatus) {
+		return fmt.Errorf("fetch-first requires operation 'status'")
+	}
+
+	if config.VSCodeWorkspace != "" && !runs(types.OperationScan) {
+		return fmt.Errorf("emit-vscode-workspace requires operation 'scan'")
+	}
+
+	if config.ProjectList != "" && !runs(types.OperationScan) {
+		return fmt.Errorf("emit-project-list requires operation 'scan'")
+	}
+
+	if config.ExportDiffs && config.ExportScan == "" {
+		return fmt.Errorf("export-diffs requires export-scan")
+	}
+
+	if config.DiffMaxBytes < 0 {
+		return fmt.Errorf("diff-max-bytes must be non-negative")
+	}
+
+	return nil
+}
+
+// validatePipelines normalizes the declared pipelines into their steps, listed in the config
+// file or separated by commas or spaces, and checks each step is an operation that can be one;
+// the options of those operations are checked against all of the pipeline's steps together. A
+// step may run on a condition, e.g. "pull if behind > 0 && clean", which ends at the next comma.
+// The pipeline's last step becomes the run's operation.
+func validatePipelines(config *types.Config) error {
+	pipelines := make(map[string][]string, len(config.Pipelines))
+	for name, entries := range config.Pipelines {
+		name = strings.ToLower(strings.TrimSpace(name))
+		var steps []string
+		for _, entry := range entries {
+			for part := range strings.SplitSeq(entry, ",") {
+				ops, condition, conditional := strings.Cut(part, " if ")
+				names := strings.Fields(strings.ToLower(ops))
+				if conditional && len(names) == 0 {
+					return fmt.Errorf("invalid pipeline %s: condition %q without an operation", name, strings.TrimSpace(condition))
+				}
+				for i, op := range names {
+					if err := validateStep(types.OperationType(op)); err != nil {
+						return fmt.Errorf("invalid pipeline %s: %w", name, err)
+					}
+					if conditional && i == len(names)-1 {
+						compiled, err := policy.Compile(condition)
+						if err != nil {
+							return fmt.Errorf("invalid pipeline %s: %s: %w", name, op, err)
+						}
+						op += " if " + compiled.String()
+					}
+					steps = append(steps, op)
+				}
+			}
+		}
+		if name == "" || len(steps) == 0 {
+			return fmt.Errorf("invalid pipeline %q: must have a name and at least one operation", name)
+		}
+		pipelines[name] = steps
+	}
+	if len(pipelines) > 0 {
+		config.Pipelines = pipelines
+	}
+
+	config.Pipeline = strings.ToLower(strings.TrimSpace(config.Pipeline))
+	if config.Pipeline == "" {
+		if config.ResumePipeline {
+			return fmt.Errorf("resume-pipeline needs a pipeline to resume, e.g. git-herd run <pipeline> --resume-pipeline")
+		}
+		return nil
+	}
+	if config.ResumePipeline && config.HistoryFile == "" {
+		return fmt.Errorf("resume-pipeline requires a history-file to find where the last run stopped")
+	}
+	if _, ok := pipelines[config.Pipeline]; !ok {
+		if len(pipelines) == 0 {
+			return fmt.Errorf("unknown pipeline %q: no pipelines are declared", config.Pipeline)
+		}
+		return fmt.Errorf("unknown pipeline %q: declared are %s", config.Pipeline, strings.Join(slices.Sorted(maps.Keys(pipelines)), ", "))
+	}
+
+	steps := config.PipelineSteps()
+	config.Operation = steps[len(steps)-1].Operation
 	return nil
 }
+
+// validateStep checks an operation can be a step of a pipeline
+func validateStep(op types.OperationType) error {
+	if !isOperation(op) {
+		return fmt.Errorf("%s is not an operation", op)
+	}
+	// Clone finds its repositories in a manifest rather than on disk
+	if op == types.OperationClone {
+		return fmt.Errorf("clone cannot be a step")
+	}
+	return nil
+}
+
+// isOperation reports whether op is one of the operations git-herd performs
+func isOperation(op types.OperationType) bool {
+	switch op {
+	case types.OperationFetch, types.OperationPull, types.OperationScan, types.OperationAuditFiles,
+		types.OperationAuditEmail, types.OperationStatus, types.OperationPush, types.OperationClone,
+		types.OperationCheckout, types.OperationStash, types.OperationStashPop, types.OperationMaintenance,
+		types.OperationPruneBranches, types.OperationExec, types.OperationSync, types.OperationSetURL,
+		types.OperationApply, types.OperationChangelog, types.OperationHeal, types.OperationReleases,
+		types.OperationVersions:
+		return true
+	default:
+		return false
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/internal/index"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
@@ -34,6 +38,30 @@ func TestDefaultConfig(t *testing.T) {
 		SaveReport:   "",
 		DiscardFiles: []string{},
 		ExportScan:   "",
+		EmailDomains: []string{},
+		Protected:    []string{},
+		RequiredFiles: []string{
+			"LICENSE*",
+			"SECURITY.md",
+			"CODEOWNERS|.github/CODEOWNERS|docs/CODEOWNERS",
+			".github/workflows",
+		},
+		RateLimitRetries: 3,
+		SSHMultiplex:     true,
+		IPFamily:         types.IPFamilyAuto,
+		Output:           types.OutputText,
+		LogDest:          types.LogDestAuto,
+		Remote:           "origin",
+		PullStrategy:     types.PullFastForward,
+		Nested:           types.NestedInclude,
+		MinFreeMB:        1024,
+		HistoryFile:      history.DefaultPath(),
+		IndexFile:        index.DefaultPath(),
+		DiffMaxBytes:     2048,
+		IssueAfter:       3,
+		PreflightTimeout: 10 * time.Second,
+		ChangelogFile:    "changelog.md",
+		UnreleasedDays:   30,
 	}
 
 	if !reflect.DeepEqual(cfg, expected) {
@@ -73,17 +101,105 @@ func TestSetupFlags(t *testing.T) {
 	}{
 		{"operation", "o", "fetch"},
 		{"workers", "w", 5},
+		{"operation-workers", "", map[string]int{}},
+		{"profile", "", ""},
+		{"path", "", ""},
+		{"pipeline", "", ""},
+		{"pipelines", "", map[string]string{}},
+		{"resume-pipeline", "", false},
 		{"dry-run", "n", false},
 		{"recursive", "r", true},
 		{"skip-dirty", "s", true},
 		{"verbose", "v", false},
 		{"plain", "p", false},
+		{"select", "", false},
 		{"full-summary", "f", false},
 		{"save-report", "", ""},
 		{"timeout", "t", 5 * time.Minute},
 		{"exclude", "e", []string{".git", "node_modules", "vendor"}},
+		{"include", "i", []string{}},
+		{"max-depth", "", 0},
+		{"follow-symlinks", "", false},
+		{"nested", "", "include"},
+		{"force-root", "", false},
+		{"cached", "", false},
+		{"refresh", "", false},
+		{"index-file", "", index.DefaultPath()},
+		{"filter", "", ""},
+		{"group", "", ""},
+		{"groups", "", map[string]string{}},
+		{"only", "", []string{}},
+		{"on-branch", "", ""},
+		{"remote-host", "", []string{}},
+		{"exclude-remote", "", []string{}},
 		{"discard-files", "d", []string{}},
 		{"export-scan", "", ""},
+		{"required-files", "", []string{"LICENSE*", "SECURITY.md", "CODEOWNERS|.github/CODEOWNERS|docs/CODEOWNERS", ".github/workflows"}},
+		{"manifests", "", false},
+		{"security-check", "", false},
+		{"email-domains", "", []string{}},
+		{"protected", "", []string{}},
+		{"budget", "", time.Duration(0)},
+		{"min-free-mb", "", 1024},
+		{"rate-limit-retries", "", 3},
+		{"ssh-multiplex", "", true},
+		{"ip-family", "", "auto"},
+		{"jitter", "", time.Duration(0)},
+		{"jitter-seed", "", ""},
+		{"history-file", "", history.DefaultPath()},
+		{"repo-timeout", "", time.Duration(0)},
+		{"adaptive-timeout", "", 0.0},
+		{"summary-file", "", ""},
+		{"output", "", "text"},
+		{"remote", "", "origin"},
+		{"set-upstream", "", false},
+		{"export-diffs", "", false},
+		{"diff-max-bytes", "", 2048},
+		{"emit-vscode-workspace", "", ""},
+		{"emit-project-list", "", ""},
+		{"emit-tmux-session", "", ""},
+		{"fetch-first", "", false},
+		{"owners-months", "", 0},
+		{"force-with-lease", "", false},
+		{"manifest", "", ""},
+		{"skip-locked", "", false},
+		{"branch", "", ""},
+		{"lock", "", ""},
+		{"create", "", false},
+		{"autostash", "", false},
+		{"prune-only", "", false},
+		{"repack", "", false},
+		{"delete-unpushed", "", false},
+		{"pull-strategy", "", "ff-only"},
+		{"log-dest", "", "auto"},
+		{"submodules", "", false},
+		{"lfs", "", false},
+		{"depth", "", 0},
+		{"unshallow", "", false},
+		{"preflight", "", false},
+		{"preflight-timeout", "", 10 * time.Second},
+		{"add-safe-directory", "", false},
+		{"shared-workspace", "", false},
+		{"allow-owner", "", []string{}},
+		{"audit-log", "", ""},
+		{"apply-script", "", ""},
+		{"apply-patch", "", ""},
+		{"commit-message", "", ""},
+		{"apply-branch", "", ""},
+		{"apply-push", "", false},
+		{"since", "", ""},
+		{"conventional", "", false},
+		{"changelog-file", "", "changelog.md"},
+		{"unreleased-days", "", 30},
+		{"checkout-tag", "", false},
+		{"badge", "", ""},
+		{"exec", "", ""},
+		{"url-match", "", ""},
+		{"url-replace", "", ""},
+		{"issue-repo", "", ""},
+		{"issue-after", "", "3"},
+		{"notify-dry-run", "", false},
+		{"label", "", map[string]string{}},
 	}
 
 	for _, tt := range tests {
@@ -110,6 +226,19 @@ func TestSetupFlags(t *testing.T) {
 	}
 }
 
+func TestLabelFlag(t *testing.T) {
+	cfg := DefaultConfig()
+	cmd := &cobra.Command{}
+	SetupFlags(cmd, cfg)
+
+	if err := cmd.ParseFlags([]string{"--label", "host=laptop", "--label", "env=home"}); err != nil {
+		t.Fatalf("Failed to parse labels: %v", err)
+	}
+	if got, want := cfg.LabelPairs(), []string{"env=home", "host=laptop"}; !slices.Equal(got, want) {
+		t.Errorf("LabelPairs() = %v, want %v", got, want)
+	}
+}
+
 func TestSetupFlagsModifiesConfig(t *testing.T) {
 	cfg := DefaultConfig()
 	cmd := &cobra.Command{}
@@ -160,9 +289,21 @@ func TestSetupViper(t *testing.T) {
 
 	// Test that flags are bound to viper
 	expectedBindings := []string{
-		"operation", "workers", "dry-run", "recursive", "skip-dirty",
-		"verbose", "plain", "full-summary", "save-report", "timeout", "exclude",
-		"discard-files", "export-scan",
+		"operation", "workers", "operation-workers", "profile", "path", "pipeline", "pipelines", "resume-pipeline", "dry-run", "recursive", "skip-dirty",
+		"verbose", "plain", "select", "full-summary", "save-report", "timeout", "exclude", "include",
+		"max-depth", "follow-symlinks", "nested", "force-root", "cached", "refresh", "index-file", "filter", "group", "groups", "only", "on-branch", "remote-host", "exclude-remote", "discard-files", "export-scan", "required-files", "manifests", "security-check",
+		"email-domains", "protected", "budget", "min-free-mb", "rate-limit-retries",
+		"ssh-multiplex", "ip-family", "jitter", "jitter-seed", "add-safe-directory",
+		"shared-workspace", "allow-owner", "audit-log",
+		"history-file", "repo-timeout", "adaptive-timeout", "summary-file",
+		"output", "remote", "set-upstream", "export-diffs", "diff-max-bytes",
+		"emit-vscode-workspace", "emit-project-list", "emit-tmux-session", "fetch-first",
+		"owners-months", "force-with-lease", "manifest",
+		"skip-locked", "branch", "lock", "create", "autostash", "prune-only", "repack", "delete-unpushed", "pull-strategy",
+		"log-dest", "submodules", "badge", "exec", "issue-repo", "issue-after",
+		"notify-dry-run", "url-match", "url-replace", "lfs", "label",
+		"depth", "unshallow", "preflight", "preflight-timeout", "apply-script", "apply-patch", "commit-message", "apply-branch", "apply-push",
+		"since", "conventional", "changelog-file", "unreleased-days", "checkout-tag",
 	}
 
 	for _, binding := range expectedBindings {
@@ -256,6 +397,109 @@ func TestLoadConfigEnvOverrides(t *testing.T) {
 	}
 }
 
+func TestLoadConfigEnvListsAndMaps(t *testing.T) {
+	viper.Reset()
+
+	t.Setenv("GIT_HERD_EXCLUDE", ".git, dist,,build")
+	t.Setenv("GIT_HERD_REQUIRED_FILES", "")
+	t.Setenv("GIT_HERD_HISTORY_FILE", "")
+	t.Setenv("GIT_HERD_LABEL", "host=ci, env=prod")
+	t.Setenv("GIT_HERD_TIMEOUT", "90s")
+	t.Setenv("GIT_HERD_DRY_RUN", "true")
+	t.Setenv("GIT_HERD_IP_FAMILY", "6")
+
+	cmd := &cobra.Command{}
+	SetupFlags(cmd, DefaultConfig())
+	if err := SetupViper(cmd); err != nil {
+		t.Fatalf("SetupViper() error = %v", err)
+	}
+
+	loadedCfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if want := []string{".git", "dist", "build"}; !slices.Equal(loadedCfg.ExcludeDirs, want) {
+		t.Errorf("Expected ExcludeDirs = %v from env, got %v", want, loadedCfg.ExcludeDirs)
+	}
+	if len(loadedCfg.RequiredFiles) != 0 || loadedCfg.HistoryFile != "" {
+		t.Errorf("Expected empty env vars to clear RequiredFiles and HistoryFile, got %v and %q", loadedCfg.RequiredFiles, loadedCfg.HistoryFile)
+	}
+	if want := []string{"env=prod", "host=ci"}; !slices.Equal(loadedCfg.LabelPairs(), want) {
+		t.Errorf("Expected labels %v from env, got %v", want, loadedCfg.LabelPairs())
+	}
+	if loadedCfg.Timeout != 90*time.Second || !loadedCfg.DryRun || loadedCfg.IPFamily != types.IPFamily6 {
+		t.Errorf("Expected timeout 90s, dry run and IPv6 from env, got %v, %v and %q", loadedCfg.Timeout, loadedCfg.DryRun, loadedCfg.IPFamily)
+	}
+}
+
+func TestLoadConfigEnvInvalidMap(t *testing.T) {
+	viper.Reset()
+
+	t.Setenv("GIT_HERD_LABEL", "laptop")
+
+	cmd := &cobra.Command{}
+	SetupFlags(cmd, DefaultConfig())
+	if err := SetupViper(cmd); err != nil {
+		t.Fatalf("SetupViper() error = %v", err)
+	}
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected a label without = to be rejected")
+	}
+}
+
+func TestEveryConfigFieldHasKey(t *testing.T) {
+	t.Parallel()
+
+	cmd := &cobra.Command{}
+	SetupFlags(cmd, DefaultConfig())
+
+	fields := reflect.TypeFor[types.Config]()
+	for i := range fields.NumField() {
+		key := fields.Field(i).Tag.Get("mapstructure")
+		if !slices.Contains(configKeys, key) {
+			t.Errorf("Config.%s (%q) is not bound, so %s would not set it", fields.Field(i).Name, key, EnvVar(key))
+		}
+		if cmd.Flags().Lookup(key) == nil {
+			t.Errorf("Config.%s (%q) has no flag", fields.Field(i).Name, key)
+		}
+	}
+}
+
+func TestEnvVar(t *testing.T) {
+	t.Parallel()
+
+	if got, want := EnvVar("emit-vscode-workspace"), "GIT_HERD_EMIT_VSCODE_WORKSPACE"; got != want {
+		t.Errorf("EnvVar() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadConfigPipelinePostHook(t *testing.T) {
+	viper.Reset()
+
+	cmd := &cobra.Command{}
+	SetupFlags(cmd, DefaultConfig())
+	args := []string{"--pipeline", "deploy", "--pipelines", "deploy=pull, exec if changed", "--exec", "make install"}
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := SetupViper(cmd); err != nil {
+		t.Fatalf("SetupViper() error = %v", err)
+	}
+
+	// The command is for the exec step, though the pull step runs first
+	loadedCfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := loadedCfg.Pipelines["deploy"]; !reflect.DeepEqual(got, []string{"pull", "exec if changed"}) {
+		t.Errorf("Expected the steps pull and exec if changed, got %q", got)
+	}
+	if loadedCfg.Operation != types.OperationExec || loadedCfg.Exec != "make install" {
+		t.Errorf("Expected the exec step to run make install, got %q %q", loadedCfg.Operation, loadedCfg.Exec)
+	}
+}
+
 func TestLoadConfigFlagOverridesEnv(t *testing.T) {
 	viper.Reset()
 
@@ -283,6 +527,122 @@ func TestLoadConfigFlagOverridesEnv(t *testing.T) {
 	}
 }
 
+func TestLoadConfigOperationWorkers(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		want int
+	}{
+		{"operation's own", []string{"--operation", "fetch"}, "", 20},
+		{"operation without its own", []string{"--operation", "exec", "--exec", "true"}, "", 5},
+		{"flag overrides", []string{"--operation", "pull", "--workers", "8"}, "", 8},
+		{"environment overrides", []string{"--operation", "pull"}, "6", 6},
+		{"flag sets them", []string{"--operation", "pull", "--operation-workers", "pull=2"}, "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			if tt.env != "" {
+				t.Setenv("GIT_HERD_WORKERS", tt.env)
+			}
+
+			cmd := &cobra.Command{}
+			SetupFlags(cmd, DefaultConfig())
+			if err := cmd.Flags().Parse(tt.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+			if err := SetupViper(cmd); err != nil {
+				t.Fatalf("SetupViper() error = %v", err)
+			}
+			if !cmd.Flags().Changed("operation-workers") {
+				viper.Set("operation-workers", map[string]int{"fetch": 20, "pull": 4})
+			}
+
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.Workers != tt.want {
+				t.Errorf("Workers = %d, want %d", cfg.Workers, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigProfile(t *testing.T) {
+	dir := t.TempDir()
+	configContent := `
+workers: 8
+operation: fetch
+exclude: [.git, node_modules]
+operation-workers:
+  pull: 4
+profiles:
+  work:
+    path: ~/work
+    operation: pull
+    workers: 10
+    exclude: [vendor]
+  oss:
+    dry-run: true
+  broken:
+    worker: 3
+`
+	if err := os.WriteFile(filepath.Join(dir, "git-herd.yaml"), []byte(configContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	load := func(args ...string) (*types.Config, error) {
+		viper.Reset()
+		cmd := &cobra.Command{}
+		SetupFlags(cmd, DefaultConfig())
+		if err := cmd.Flags().Parse(args); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if err := SetupViper(cmd); err != nil {
+			t.Fatalf("SetupViper() error = %v", err)
+		}
+		return LoadConfig()
+	}
+
+	cfg, err := load("--profile", "work")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	// The profile's workers win over the file's, and over its operation-workers
+	if cfg.Path != "~/work" || cfg.Operation != types.OperationPull || cfg.Workers != 10 {
+		t.Errorf("Expected the work profile's path, operation and workers, got %q, %q, %d", cfg.Path, cfg.Operation, cfg.Workers)
+	}
+	if !slices.Equal(cfg.ExcludeDirs, []string{"vendor"}) {
+		t.Errorf("Expected the profile's exclude to replace the file's, got %v", cfg.ExcludeDirs)
+	}
+
+	cfg, err = load("--profile", "work", "--workers", "3", "--operation", "fetch")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Workers != 3 || cfg.Operation != types.OperationFetch {
+		t.Errorf("Expected flags to win over the profile, got %d workers for %q", cfg.Workers, cfg.Operation)
+	}
+
+	cfg, err = load("--profile", "oss")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !cfg.DryRun || cfg.Workers != 8 || cfg.Path != "" {
+		t.Errorf("Expected the oss profile on top of the file, got dry-run %v, %d workers, path %q", cfg.DryRun, cfg.Workers, cfg.Path)
+	}
+
+	if _, err := load("--profile", "home"); err == nil || !strings.Contains(err.Error(), "broken, oss, work") {
+		t.Errorf("Expected an unknown profile to list the declared ones, got %v", err)
+	}
+	if _, err := load("--profile", "broken"); err == nil || !strings.Contains(err.Error(), "unknown setting worker") {
+		t.Errorf("Expected an unknown setting to be rejected, got %v", err)
+	}
+}
+
 func TestLoadConfigWithInvalidData(t *testing.T) {
 	// Reset viper before test
 	viper.Reset()
@@ -486,6 +846,20 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "operation workers for an unknown operation",
+			modify: func(cfg *types.Config) {
+				cfg.OperationWorkers = map[string]int{"fetchh": 20}
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero operation workers",
+			modify: func(cfg *types.Config) {
+				cfg.OperationWorkers = map[string]int{"pull": 0}
+			},
+			wantErr: true,
+		},
 		{
 			name: "negative timeout",
 			modify: func(cfg *types.Config) {
@@ -494,25 +868,995 @@ func TestConfigValidation(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "export scan requires scan operation",
+			name: "negative max depth",
 			modify: func(cfg *types.Config) {
-				cfg.ExportScan = "report.md"
-				cfg.Operation = types.OperationFetch
+				cfg.MaxDepth = -1
 			},
 			wantErr: true,
 		},
 		{
-			name: "operation normalization",
+			name: "glob filter",
 			modify: func(cfg *types.Config) {
-				cfg.Operation = "PULL"
+				cfg.Filter = "api-*"
 			},
 			wantErr: false,
-			check: func(cfg *types.Config) error {
-				if cfg.Operation != types.OperationPull {
-					return fmt.Errorf("expected %q, got %q", types.OperationPull, cfg.Operation)
-				}
-				return nil
+		},
+		{
+			name: "invalid glob filter",
+			modify: func(cfg *types.Config) {
+				cfg.Filter = "api-["
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid regexp filter",
+			modify: func(cfg *types.Config) {
+				cfg.Filter = "/api-(/"
+			},
+			wantErr: true,
+		},
+		{
+			name: "only states",
+			modify: func(cfg *types.Config) {
+				cfg.Only = []string{"Dirty", " behind"}
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid only state",
+			modify: func(cfg *types.Config) {
+				cfg.Only = []string{"stale"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid group name",
+			modify: func(cfg *types.Config) {
+				cfg.Groups = map[string][]string{"clients/acme": {"acme-*"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid group pattern",
+			modify: func(cfg *types.Config) {
+				cfg.Groups = map[string][]string{"acme": {"acme-["}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "declared groups",
+			modify: func(cfg *types.Config) {
+				cfg.Group = "acme"
+				cfg.Groups = map[string][]string{"acme": {"acme-*", "~/work/acme/*"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "declared pipeline",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "Morning"
+				cfg.Pipelines = map[string][]string{"morning": {"fetch", "prune-branches status"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown pipeline",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "evening"
+				cfg.Pipelines = map[string][]string{"morning": {"fetch"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "pipeline with an unknown operation",
+			modify: func(cfg *types.Config) {
+				cfg.Pipelines = map[string][]string{"morning": {"fetch", "prune"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "pipeline with conditional steps",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "morning"
+				cfg.Pipelines = map[string][]string{"morning": {"fetch, Pull if behind > 0 && clean", "status if changed"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "pipeline with an invalid condition",
+			modify: func(cfg *types.Config) {
+				cfg.Pipelines = map[string][]string{"morning": {"fetch", "pull if behnd > 0"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "resume without a pipeline",
+			modify: func(cfg *types.Config) {
+				cfg.ResumePipeline = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "resume without history",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "morning"
+				cfg.Pipelines = map[string][]string{"morning": {"fetch", "status"}}
+				cfg.ResumePipeline = true
+				cfg.HistoryFile = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "resume a pipeline",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "morning"
+				cfg.Pipelines = map[string][]string{"morning": {"fetch", "status"}}
+				cfg.ResumePipeline = true
+			},
+		},
+		{
+			name: "pipeline steps with their own options",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "morning"
+				cfg.Pipelines = map[string][]string{"morning": {"fetch", "pull", "prune-branches", "changelog", "status"}}
+				cfg.Since = "2024-01-01"
+				cfg.ChangelogFile = "CHANGELOG.md"
+				cfg.Submodules = true
+				cfg.LFS = true
+				cfg.Depth = 10
+				cfg.AutoStash = true
+				cfg.DeleteUnpushed = true
+				cfg.FetchFirst = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "pipeline option without a step for it",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "morning"
+				cfg.Pipelines = map[string][]string{"morning": {"fetch", "status"}}
+				cfg.AutoStash = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "pipeline step missing its settings",
+			modify: func(cfg *types.Config) {
+				cfg.Pipeline = "morning"
+				cfg.Pipelines = map[string][]string{"morning": {"checkout", "status"}}
 			},
+			wantErr: true,
+		},
+		{
+			name: "invalid on-branch glob",
+			modify: func(cfg *types.Config) {
+				cfg.OnBranch = "release/["
+			},
+			wantErr: true,
+		},
+		{
+			name: "cached requires an index",
+			modify: func(cfg *types.Config) {
+				cfg.Cached = true
+				cfg.IndexFile = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative min free space",
+			modify: func(cfg *types.Config) {
+				cfg.MinFreeMB = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative budget",
+			modify: func(cfg *types.Config) {
+				cfg.Budget = -1 * time.Minute
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative rate limit retries",
+			modify: func(cfg *types.Config) {
+				cfg.RateLimitRetries = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "allow-owner requires a shared workspace",
+			modify: func(cfg *types.Config) {
+				cfg.AllowOwners = []string{"alice"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative jitter",
+			modify: func(cfg *types.Config) {
+				cfg.Jitter = -1 * time.Minute
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative repo timeout",
+			modify: func(cfg *types.Config) {
+				cfg.RepoTimeout = -1 * time.Second
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative adaptive timeout",
+			modify: func(cfg *types.Config) {
+				cfg.AdaptiveTimeout = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "ipv6 only",
+			modify: func(cfg *types.Config) {
+				cfg.IPFamily = types.IPFamily6
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid ip family",
+			modify: func(cfg *types.Config) {
+				cfg.IPFamily = "ipv5"
+			},
+			wantErr: true,
+		},
+		{
+			name: "log dest normalization",
+			modify: func(cfg *types.Config) {
+				cfg.LogDest = " STDERR "
+			},
+			wantErr: false,
+			check: func(cfg *types.Config) error {
+				if cfg.LogDest != types.LogDestStderr {
+					return fmt.Errorf("expected %q, got %q", types.LogDestStderr, cfg.LogDest)
+				}
+				return nil
+			},
+		},
+		{
+			name: "invalid log dest",
+			modify: func(cfg *types.Config) {
+				cfg.LogDest = "syslog"
+			},
+			wantErr: true,
+		},
+		{
+			name: "logs on stdout with tap output",
+			modify: func(cfg *types.Config) {
+				cfg.Output = types.OutputTAP
+				cfg.LogDest = types.LogDestStdout
+			},
+			wantErr: true,
+		},
+		{
+			name: "select in plain mode",
+			modify: func(cfg *types.Config) {
+				cfg.Select = true
+				cfg.PlainMode = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "nested normalization",
+			modify: func(cfg *types.Config) {
+				cfg.Nested = " Only "
+			},
+			check: func(cfg *types.Config) error {
+				if cfg.Nested != types.NestedOnly {
+					return fmt.Errorf("expected %q, got %q", types.NestedOnly, cfg.Nested)
+				}
+				return nil
+			},
+		},
+		{
+			name: "invalid nested",
+			modify: func(cfg *types.Config) {
+				cfg.Nested = "deep"
+			},
+			wantErr: true,
+		},
+		{
+			name: "pull strategy normalization",
+			modify: func(cfg *types.Config) {
+				cfg.PullStrategy = " Rebase "
+			},
+			wantErr: false,
+			check: func(cfg *types.Config) error {
+				if cfg.PullStrategy != types.PullRebase {
+					return fmt.Errorf("expected %q, got %q", types.PullRebase, cfg.PullStrategy)
+				}
+				return nil
+			},
+		},
+		{
+			name: "invalid pull strategy",
+			modify: func(cfg *types.Config) {
+				cfg.PullStrategy = "squash"
+			},
+			wantErr: true,
+		},
+		{
+			name: "tap output normalization",
+			modify: func(cfg *types.Config) {
+				cfg.Output = " TAP "
+			},
+			wantErr: false,
+			check: func(cfg *types.Config) error {
+				if cfg.Output != types.OutputTAP {
+					return fmt.Errorf("expected %q, got %q", types.OutputTAP, cfg.Output)
+				}
+				return nil
+			},
+		},
+		{
+			name: "empty remote",
+			modify: func(cfg *types.Config) {
+				cfg.Remote = " "
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid output",
+			modify: func(cfg *types.Config) {
+				cfg.Output = "xml"
+			},
+			wantErr: true,
+		},
+		{
+			name: "export scan requires scan operation",
+			modify: func(cfg *types.Config) {
+				cfg.ExportScan = "report.md"
+				cfg.Operation = types.OperationFetch
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative owners months",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = types.OperationScan
+				cfg.OwnersMonths = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "owners months requires analysis operation",
+			modify: func(cfg *types.Config) {
+				cfg.OwnersMonths = 6
+			},
+			wantErr: true,
+		},
+		{
+			name: "owners months with audit",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = types.OperationAuditFiles
+				cfg.OwnersMonths = 6
+			},
+			wantErr: false,
+		},
+		{
+			name: "force with lease requires push operation",
+			modify: func(cfg *types.Config) {
+				cfg.ForceWithLease = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "push with force with lease",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "push"
+				cfg.ForceWithLease = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "clone requires a manifest",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "clone"
+			},
+			wantErr: true,
+		},
+		{
+			name: "manifest requires clone operation",
+			modify: func(cfg *types.Config) {
+				cfg.CloneManifest = "repos.yaml"
+			},
+			wantErr: true,
+		},
+		{
+			name: "clone with manifest",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "clone"
+				cfg.CloneManifest = "repos.yaml"
+			},
+			wantErr: false,
+		},
+		{
+			name: "versions with manifest",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "versions"
+				cfg.CloneManifest = "repos.yaml"
+				cfg.CheckoutTag = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "versions requires a manifest",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "versions"
+			},
+			wantErr: true,
+		},
+		{
+			name: "checkout tag requires versions",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "clone"
+				cfg.CloneManifest = "repos.yaml"
+				cfg.CheckoutTag = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "skip locked requires pull operation",
+			modify: func(cfg *types.Config) {
+				cfg.SkipLocked = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "pull with skip locked",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "pull"
+				cfg.SkipLocked = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "autostash requires pull operation",
+			modify: func(cfg *types.Config) {
+				cfg.AutoStash = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "pull with autostash",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "pull"
+				cfg.AutoStash = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "submodules requires fetch or pull",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "push"
+				cfg.Submodules = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "lfs requires fetch or pull",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "scan"
+				cfg.LFS = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "fetch with depth",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "fetch"
+				cfg.Depth = 50
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative depth",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "fetch"
+				cfg.Depth = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "unshallow requires fetch or pull",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "scan"
+				cfg.Unshallow = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "depth and unshallow together",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "pull"
+				cfg.Depth = 1
+				cfg.Unshallow = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "pull with lfs",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "pull"
+				cfg.LFS = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "fetch with submodules",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "fetch"
+				cfg.Submodules = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "issue repo must be owner/name",
+			modify: func(cfg *types.Config) {
+				cfg.IssueRepo = "acme"
+			},
+			wantErr: true,
+		},
+		{
+			name: "issue repo requires history",
+			modify: func(cfg *types.Config) {
+				cfg.IssueRepo = "acme/ops"
+				cfg.HistoryFile = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "issue after must be positive",
+			modify: func(cfg *types.Config) {
+				cfg.IssueRepo = "acme/ops"
+				cfg.HistoryFile = "history.json"
+				cfg.IssueAfter = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "issue repo with history",
+			modify: func(cfg *types.Config) {
+				cfg.IssueRepo = "acme/ops"
+				cfg.HistoryFile = "history.json"
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid label key",
+			modify: func(cfg *types.Config) {
+				cfg.Labels = map[string]string{"my host": "laptop"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "labels",
+			modify: func(cfg *types.Config) {
+				cfg.Labels = map[string]string{"host": "laptop", "env": "home"}
+			},
+			wantErr: false,
+		},
+		{
+			name: "exec requires a command",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "exec"
+				cfg.Exec = "  "
+			},
+			wantErr: true,
+		},
+		{
+			name: "exec command requires exec operation",
+			modify: func(cfg *types.Config) {
+				cfg.Exec = "make test"
+			},
+			wantErr: true,
+		},
+		{
+			name: "exec with command",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "exec"
+				cfg.Exec = "make test"
+			},
+			wantErr: false,
+		},
+		{
+			name: "pull with preflight",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "pull"
+				cfg.Preflight = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "preflight requires a network operation",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "status"
+				cfg.Preflight = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "preflight without a timeout",
+			modify: func(cfg *types.Config) {
+				cfg.Preflight = true
+				cfg.PreflightTimeout = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid heal",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "heal"
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid changelog",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "changelog"
+				cfg.Since = "2024-01-01"
+				cfg.Conventional = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "changelog without since",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "changelog"
+			},
+			wantErr: true,
+		},
+		{
+			name: "changelog with an invalid since",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "changelog"
+				cfg.Since = "last month"
+			},
+			wantErr: true,
+		},
+		{
+			name: "changelog without a file",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "changelog"
+				cfg.Since = "2024-01-01"
+				cfg.ChangelogFile = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "since requires changelog",
+			modify: func(cfg *types.Config) {
+				cfg.Since = "2024-01-01"
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid releases",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "releases"
+				cfg.UnreleasedDays = 90
+			},
+			wantErr: false,
+		},
+		{
+			name: "releases with unreleased-days below 1",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "releases"
+				cfg.UnreleasedDays = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid apply",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "apply"
+				cfg.ApplyPatch = "config_test.go"
+				cfg.CommitMessage = "Fix {{.Name}}"
+				cfg.ApplyBranch = "fix"
+				cfg.ApplyPush = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "apply requires a script or patch",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "apply"
+				cfg.CommitMessage = "Fix"
+			},
+			wantErr: true,
+		},
+		{
+			name: "apply with a missing script",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "apply"
+				cfg.ApplyScript = "missing.sh"
+				cfg.CommitMessage = "Fix"
+			},
+			wantErr: true,
+		},
+		{
+			name: "apply requires commit-message",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "apply"
+				cfg.ApplyPatch = "config_test.go"
+			},
+			wantErr: true,
+		},
+		{
+			name: "apply with an invalid commit-message template",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "apply"
+				cfg.ApplyPatch = "config_test.go"
+				cfg.CommitMessage = "Fix {{.Nope}}"
+			},
+			wantErr: true,
+		},
+		{
+			name: "apply-push requires apply-branch",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "apply"
+				cfg.ApplyPatch = "config_test.go"
+				cfg.CommitMessage = "Fix"
+				cfg.ApplyPush = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "commit-message requires apply operation",
+			modify: func(cfg *types.Config) {
+				cfg.CommitMessage = "Fix"
+			},
+			wantErr: true,
+		},
+		{
+			name: "set-url requires url-match",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "set-url"
+				cfg.URLReplace = "git@new-host:"
+			},
+			wantErr: true,
+		},
+		{
+			name: "url-match requires set-url operation",
+			modify: func(cfg *types.Config) {
+				cfg.URLMatch = "git@old-host:"
+			},
+			wantErr: true,
+		},
+		{
+			name: "set-url with match",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "set-url"
+				cfg.URLMatch = "git@old-host:"
+				cfg.URLReplace = "git@new-host:"
+			},
+			wantErr: false,
+		},
+		{
+			name: "prune only requires maintenance operation",
+			modify: func(cfg *types.Config) {
+				cfg.PruneOnly = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "prune only excludes repack",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "maintenance"
+				cfg.PruneOnly = true
+				cfg.Repack = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "maintenance with repack",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "maintenance"
+				cfg.Repack = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "delete unpushed without prune-branches",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "pull"
+				cfg.DeleteUnpushed = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "prune-branches with delete unpushed",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "prune-branches"
+				cfg.DeleteUnpushed = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "stash pop operation",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "stash-pop"
+			},
+			wantErr: false,
+		},
+		{
+			name: "checkout requires a branch",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "checkout"
+				cfg.Branch = " "
+			},
+			wantErr: true,
+		},
+		{
+			name: "branch requires checkout operation",
+			modify: func(cfg *types.Config) {
+				cfg.Branch = "main"
+			},
+			wantErr: true,
+		},
+		{
+			name: "create requires checkout operation",
+			modify: func(cfg *types.Config) {
+				cfg.CreateBranch = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "checkout with branch and create",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "checkout"
+				cfg.Branch = "release/1.2"
+				cfg.CreateBranch = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "checkout with lock",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "checkout"
+				cfg.Lock = "herd.lock"
+			},
+			wantErr: false,
+		},
+		{
+			name: "checkout with both branch and lock",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "checkout"
+				cfg.Branch = "main"
+				cfg.Lock = "herd.lock"
+			},
+			wantErr: true,
+		},
+		{
+			name: "lock requires checkout operation",
+			modify: func(cfg *types.Config) {
+				cfg.Lock = "herd.lock"
+			},
+			wantErr: true,
+		},
+		{
+			name: "fetch first requires status operation",
+			modify: func(cfg *types.Config) {
+				cfg.FetchFirst = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "status with fetch first",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "Status"
+				cfg.FetchFirst = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "vscode workspace requires scan operation",
+			modify: func(cfg *types.Config) {
+				cfg.VSCodeWorkspace = "all.code-workspace"
+			},
+			wantErr: true,
+		},
+		{
+			name: "project list requires scan operation",
+			modify: func(cfg *types.Config) {
+				cfg.ProjectList = "projects.txt"
+			},
+			wantErr: true,
+		},
+		{
+			name: "editor files with scan operation",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = types.OperationScan
+				cfg.VSCodeWorkspace = "all.code-workspace"
+				cfg.ProjectList = "projects.txt"
+			},
+			wantErr: false,
+		},
+		{
+			name: "export diffs requires export scan",
+			modify: func(cfg *types.Config) {
+				cfg.ExportDiffs = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "export diffs with export scan",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = types.OperationScan
+				cfg.ExportScan = "report.md"
+				cfg.ExportDiffs = true
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative diff max bytes",
+			modify: func(cfg *types.Config) {
+				cfg.DiffMaxBytes = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "operation normalization",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "PULL"
+			},
+			wantErr: false,
+			check: func(cfg *types.Config) error {
+				if cfg.Operation != types.OperationPull {
+					return fmt.Errorf("expected %q, got %q", types.OperationPull, cfg.Operation)
+				}
+				return nil
+			},
+		},
+		{
+			name: "audit-files operation",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = "audit-files"
+			},
+			wantErr: false,
+		},
+		{
+			name: "audit-files requires required files",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = types.OperationAuditFiles
+				cfg.RequiredFiles = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "audit-email requires email domains",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = types.OperationAuditEmail
+			},
+			wantErr: true,
+		},
+		{
+			name: "audit-email with email domains",
+			modify: func(cfg *types.Config) {
+				cfg.Operation = types.OperationAuditEmail
+				cfg.EmailDomains = []string{"example.com"}
+			},
+			wantErr: false,
+		},
+		{
+			name: "exclude and include globs",
+			modify: func(cfg *types.Config) {
+				cfg.ExcludeDirs = []string{"**/build", "tmp-*"}
+				cfg.IncludeDirs = []string{"clients/*"}
+			},
+			wantErr: false,
+		},
+		{
+			name: "malformed include glob",
+			modify: func(cfg *types.Config) {
+				cfg.IncludeDirs = []string{"clients/[a-"}
+			},
+			wantErr: true,
 		},
 		{
 			name: "empty exclude dirs allowed",
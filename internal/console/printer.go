@@ -0,0 +1,53 @@
+// Package console serializes the plain-mode output of concurrent workers
+package console
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Printer writes to an underlying writer one call at a time, so lines written by concurrent
+// workers never interleave. It is safe for concurrent use.
+type Printer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// New creates a Printer writing to out
+func New(out io.Writer) *Printer {
+	return &Printer{out: out}
+}
+
+// Stdout and Stderr print to the standard streams. Stdout is the Printer used when no other
+// is configured.
+var (
+	Stdout = New(os.Stdout)
+	Stderr = New(os.Stderr)
+)
+
+// Write writes p in one piece, so a log handler can share the printer
+func (p *Printer) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.out.Write(b)
+}
+
+// Printf formats and writes a message in one piece
+func (p *Printer) Printf(format string, args ...any) {
+	_, _ = p.Write([]byte(fmt.Sprintf(format, args...)))
+}
+
+// Repof writes a message about one repository, prefixing every line with the repository's
+// name so the messages of repositories processed side by side can be told apart
+func (p *Printer) Repof(name, format string, args ...any) {
+	message := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+
+	var b strings.Builder
+	for line := range strings.SplitSeq(message, "\n") {
+		fmt.Fprintf(&b, "  [%s] %s\n", name, line)
+	}
+	_, _ = p.Write([]byte(b.String()))
+}
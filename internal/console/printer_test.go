@@ -0,0 +1,61 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPrinter_Repof(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	New(&buf).Repof("api", "Discarded changes: %v\nsecond line\n", []string{"package-lock.json"})
+
+	want := "  [api] Discarded changes: [package-lock.json]\n  [api] second line\n"
+	if buf.String() != want {
+		t.Errorf("Repof() wrote %q, want %q", buf.String(), want)
+	}
+}
+
+// chunkedWriter writes a byte at a time, so unsynchronized writers would interleave
+type chunkedWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *chunkedWriter) Write(b []byte) (int, error) {
+	for _, c := range b {
+		w.buf.WriteByte(c)
+	}
+	return len(b), nil
+}
+
+func TestPrinter_ConcurrentLinesStayWhole(t *testing.T) {
+	t.Parallel()
+
+	out := &chunkedWriter{}
+	printer := New(out)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			for j := range 50 {
+				printer.Repof(fmt.Sprintf("repo-%d", i), "message %d", j)
+			}
+		})
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.buf.String(), "\n"), "\n")
+	if len(lines) != 20*50 {
+		t.Fatalf("Expected %d lines, got %d", 20*50, len(lines))
+	}
+	for _, line := range lines {
+		var repo, message int
+		if _, err := fmt.Sscanf(line, "  [repo-%d] message %d", &repo, &message); err != nil {
+			t.Fatalf("Garbled line %q: %v", line, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package fixtures builds small git repositories in known states for tests: clean, dirty,
+// diverged from their upstream, on a detached HEAD, with a submodule, or without a remote. They
+// are built with the git CLI under a fixed identity and fixed dates, so the same fixture has
+// the same commits on every machine, and tests that need them skip where git is missing.
+package fixtures
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Kind is a state a fixture repository is built in
+type Kind string
+
+const (
+	Clean     Kind = "clean"     // A clone up to date with its upstream
+	Dirty     Kind = "dirty"     // A clone with a modified and an untracked file
+	Diverged  Kind = "diverged"  // A clone one commit ahead of its upstream and one behind
+	Detached  Kind = "detached"  // A clone whose HEAD is detached at the tip of its branch
+	Submodule Kind = "submodule" // A clone of a repository with an uninitialized submodule
+	NoRemote  Kind = "no-remote" // A repository without any remote
+)
+
+// Kinds lists every kind of fixture, in the order a scan finds them
+var Kinds = []Kind{Clean, Detached, Dirty, Diverged, NoRemote, Submodule}
+
+// Epoch is the date of the first commit of every fixture; each later commit is a minute later
+var Epoch = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+// Herd builds a repository of each of kinds, all of them when none is given, in a directory
+// named after its kind below a new root, and returns the root. Their upstreams live below
+// another new directory, out of the way of a scan of the root, which Herd returns too.
+func Herd(t testing.TB, kinds ...Kind) (root, upstreams string) {
+	t.Helper()
+
+	if len(kinds) == 0 {
+		kinds = Kinds
+	}
+	root, upstreams = t.TempDir(), t.TempDir()
+	for _, kind := range kinds {
+		Build(t, filepath.Join(root, string(kind)), filepath.Join(upstreams, string(kind)), kind)
+	}
+	return root, upstreams
+}
+
+// Build builds a repository of kind at path, with its upstream, if the kind has one, at upstream
+func Build(t testing.TB, path, upstream string, kind Kind) {
+	t.Helper()
+	RequireGit(t)
+
+	b := &builder{t: t}
+	if kind == NoRemote {
+		b.init(path)
+		b.commit(path, "README.md", "# "+string(kind)+"\n")
+		return
+	}
+
+	b.init(upstream)
+	b.commit(upstream, "README.md", "# "+string(kind)+"\n")
+	if kind == Submodule {
+		lib := upstream + "-lib"
+		b.init(lib)
+		b.commit(lib, "lib.go", "package lib\n")
+		b.git(upstream, "submodule", "add", "--quiet", lib, "lib")
+		b.git(upstream, "commit", "--quiet", "--message", "add lib")
+	}
+	b.git(filepath.Dir(path), "clone", "--quiet", upstream, path)
+
+	switch kind {
+	case Dirty:
+		b.write(path, "README.md", "# "+string(kind)+"\n\nEdited.\n")
+		b.write(path, "notes.txt", "untracked\n")
+	case Diverged:
+		// The upstream's commit is fetched, so the clone knows it is behind without a network
+		b.commit(upstream, "theirs.txt", "theirs\n")
+		b.git(path, "fetch", "--quiet", "origin")
+		b.commit(path, "ours.txt", "ours\n")
+	case Detached:
+		b.git(path, "checkout", "--quiet", "--detach", "HEAD")
+	}
+}
+
+// RequireGit skips the test where the git CLI is missing
+func RequireGit(t testing.TB) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git CLI not available")
+	}
+}
+
+// builder runs the git commands building fixtures, counting commits to date each one
+type builder struct {
+	t       testing.TB
+	commits int
+}
+
+// git runs a git command in dir, failing the test if it fails. The environment gives it a fixed
+// identity and date and keeps the user's git configuration out.
+func (b *builder) git(dir string, args ...string) {
+	b.t.Helper()
+
+	date := Epoch.Add(time.Duration(b.commits) * time.Minute).Format(time.RFC3339)
+	args = append([]string{"-c", "init.defaultBranch=main", "-c", "protocol.file.allow=always"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=git-herd", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=git-herd", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		b.t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+// init creates an empty repository at path
+func (b *builder) init(path string) {
+	b.t.Helper()
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		b.t.Fatal(err)
+	}
+	b.git(path, "init", "--quiet")
+}
+
+// write writes a file in the repository at path without committing it
+func (b *builder) write(path, name, content string) {
+	b.t.Helper()
+
+	if err := os.WriteFile(filepath.Join(path, name), []byte(content), 0644); err != nil {
+		b.t.Fatal(err)
+	}
+}
+
+// commit writes and commits a file in the repository at path
+func (b *builder) commit(path, name, content string) {
+	b.t.Helper()
+
+	b.write(path, name, content)
+	b.git(path, "add", name)
+	b.git(path, "commit", "--quiet", "--message", "add "+name)
+	b.commits++
+}
+
+// durations matches the durations Go prints, e.g. 1.5ms, 250µs or 2m3.5s
+var durations = regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|ms|s)\b|\b\d+m\d+(\.\d+)?s\b|\b\d+h\d+m\d+(\.\d+)?s\b`)
+
+// timestamps matches RFC 3339 times, e.g. 2024-01-01T09:00:00.123456Z or with an offset
+var timestamps = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+
+// dates matches dates, e.g. 2024-01-01
+var dates = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+
+// Normalize replaces what changes from one run of a test to the next in output — the
+// directories the fixtures were built in, durations, timestamps and dates — with placeholders,
+// so it can be compared with a golden file. Each of dirs, e.g. the root of a Herd, becomes
+// $DIR1, $DIR2 and so on.
+func Normalize(output string, dirs ...string) string {
+	for i, dir := range dirs {
+		output = strings.ReplaceAll(output, dir, fmt.Sprintf("$DIR%d", i+1))
+	}
+	output = timestamps.ReplaceAllString(output, "$$TIME")
+	output = dates.ReplaceAllString(output, "$$DATE")
+	return durations.ReplaceAllString(output, "$$DURATION")
+}
+
+// Golden compares got with the golden file at path, failing the test with both when they
+// differ. With update set, it writes got to the file instead.
+func Golden(t testing.TB, path string, got []byte, update bool) {
+	t.Helper()
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run the test with -update to create it): %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Output differs from %s (run the test with -update to accept it):\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
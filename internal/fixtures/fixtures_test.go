@@ -0,0 +1,76 @@
+package fixtures
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitOutput runs a git command in dir and returns its trimmed output
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %s failed: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestHerd(t *testing.T) {
+	t.Parallel()
+	RequireGit(t)
+
+	root, upstreams := Herd(t)
+	repo := func(kind Kind) string { return filepath.Join(root, string(kind)) }
+
+	tests := []struct {
+		kind Kind
+		args []string
+		want string
+	}{
+		{Clean, []string{"status", "--porcelain"}, ""},
+		{Clean, []string{"rev-list", "--left-right", "--count", "HEAD...origin/main"}, "0\t0"},
+		{Dirty, []string{"status", "--porcelain"}, "M README.md\n?? notes.txt"},
+		{Diverged, []string{"rev-list", "--left-right", "--count", "HEAD...origin/main"}, "1\t1"},
+		{Detached, []string{"rev-parse", "--abbrev-ref", "HEAD"}, "HEAD"},
+		{Submodule, []string{"config", "--file", ".gitmodules", "submodule.lib.path"}, "lib"},
+		{NoRemote, []string{"remote"}, ""},
+	}
+	for _, tt := range tests {
+		if got := gitOutput(t, repo(tt.kind), tt.args...); got != tt.want {
+			t.Errorf("%s: git %s = %q, want %q", tt.kind, strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+	if got := gitOutput(t, repo(Clean), "remote", "get-url", "origin"); got != filepath.Join(upstreams, string(Clean)) {
+		t.Errorf("Expected the clean clone's origin below the upstreams, got %s", got)
+	}
+
+	// Fixed identities and dates give a fixture the same commits wherever it is built
+	other := t.TempDir()
+	Build(t, filepath.Join(other, "clone"), filepath.Join(other, "upstream"), Diverged)
+	if a, b := gitOutput(t, repo(Diverged), "rev-parse", "HEAD"), gitOutput(t, filepath.Join(other, "clone"), "rev-parse", "HEAD"); a != b {
+		t.Errorf("Expected the same HEAD in both diverged fixtures, got %s and %s", a, b)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	output := `time=2026-10-16T11:50:08.791Z msg="Starting" path=/tmp/herd/001
+✅ clean (/tmp/herd/001/clean) - 4ms - up to date
+Remote URL: /tmp/herd/002/clean
+Dirty For: less than an hour (since 2026-10-16)
+Took 1m2.5s and 250µs`
+	want := `time=$TIME msg="Starting" path=$DIR1
+✅ clean ($DIR1/clean) - $DURATION - up to date
+Remote URL: $DIR2/clean
+Dirty For: less than an hour (since $DATE)
+Took $DURATION and $DURATION`
+	if got := Normalize(output, "/tmp/herd/001", "/tmp/herd/002"); got != want {
+		t.Errorf("Normalize() =\n%s\nwant\n%s", got, want)
+	}
+}
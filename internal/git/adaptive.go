@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+const (
+	// minTimeoutSamples is how many successful runs a repository needs before its timeout adapts
+	minTimeoutSamples = 3
+
+	// minAdaptiveTimeout keeps quick repositories from getting a timeout too tight to survive a hiccup
+	minAdaptiveTimeout = 10 * time.Second
+)
+
+// errRepoTimeout is the cancellation cause when a repository exceeds its own timeout
+var errRepoTimeout = errors.New("repository timeout")
+
+// loadHistory opens the configured history store, disabling history if it cannot be read
+func loadHistory(config *types.Config) *history.Store {
+	if config.HistoryFile == "" {
+		return nil
+	}
+	store, err := history.Load(config.HistoryFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: history disabled: %v\n", err)
+		return nil
+	}
+	return store
+}
+
+// SaveHistory persists the outcomes recorded during the run
+func (p *Processor) SaveHistory() error {
+	if p.history == nil {
+		return nil
+	}
+	return p.history.Save()
+}
+
+// recordHistory adds a processed repository's outcome to the history store. Dry runs are not
+// recorded because their durations say nothing about real operations.
+func (p *Processor) recordHistory(repo types.GitRepo) {
+	if p.history == nil || p.config.DryRun {
+		return
+	}
+
+	record := history.Record{
+		Run:       p.started,
+		Time:      time.Now(),
+		Operation: p.config.Operation,
+		Duration:  repo.Duration,
+		Labels:    p.config.Labels,
+		Pipeline:  p.pipeline,
+		Step:      p.step,
+	}
+	if repo.Error != nil {
+		record.Error = repo.Error.Error()
+	}
+	p.history.Add(repo.Path, record)
+}
+
+// isFlaky reports whether the repository's history for this operation alternates between
+// success and failure
+func (p *Processor) isFlaky(repoPath string) bool {
+	if p.history == nil {
+		return false
+	}
+	return history.Flaky(p.history.Records(repoPath), p.config.Operation)
+}
+
+// repoTimeout returns the timeout for one repository. With adaptive timeouts enabled and enough
+// history it is a multiple of the repository's p95 successful duration for this operation;
+// otherwise it is the configured repo-timeout (0 for none).
+func (p *Processor) repoTimeout(repoPath string) time.Duration {
+	if p.config.AdaptiveTimeout <= 0 || p.history == nil {
+		return p.config.RepoTimeout
+	}
+
+	var durations []time.Duration
+	for _, record := range p.history.Records(repoPath) {
+		if record.Operation == p.config.Operation && !record.Failed() {
+			durations = append(durations, record.Duration)
+		}
+	}
+	if len(durations) < minTimeoutSamples {
+		return p.config.RepoTimeout
+	}
+
+	timeout := time.Duration(float64(percentile95(durations)) * p.config.AdaptiveTimeout)
+	return max(timeout, minAdaptiveTimeout)
+}
+
+// withRepoTimeout bounds ctx by the repository's timeout, if it has one
+func (p *Processor) withRepoTimeout(ctx context.Context, repoPath string) (context.Context, time.Duration, context.CancelFunc) {
+	timeout := p.repoTimeout(repoPath)
+	if timeout <= 0 {
+		return ctx, 0, func() {}
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, errRepoTimeout)
+	return ctx, timeout, cancel
+}
+
+// percentile95 returns the nearest-rank 95th percentile of durations
+func percentile95(durations []time.Duration) time.Duration {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := (len(sorted)*95 + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,146 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/console"
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestPercentile95(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		want      time.Duration
+	}{
+		{"single", []time.Duration{3 * time.Second}, 3 * time.Second},
+		{"unsorted", []time.Duration{5, 1, 3, 2, 4}, 5},
+		{"outlier beyond p95", append(make([]time.Duration, 99), 1000), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := percentile95(tt.durations); got != tt.want {
+				t.Errorf("percentile95() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newHistoryProcessor(t *testing.T, config *types.Config) *Processor {
+	t.Helper()
+	store, err := history.Load(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Processor{config: config, limiter: NewRateLimiter(), history: store, printer: console.Stdout, shared: newSharedRepos()}
+}
+
+func TestRepoTimeout(t *testing.T) {
+	t.Parallel()
+
+	config := &types.Config{Operation: types.OperationFetch, AdaptiveTimeout: 3, RepoTimeout: time.Minute}
+	p := newHistoryProcessor(t, config)
+
+	if got := p.repoTimeout("/repos/new"); got != time.Minute {
+		t.Errorf("repoTimeout() without history = %v, want the repo-timeout fallback", got)
+	}
+
+	for _, d := range []time.Duration{10, 20, 30} {
+		p.history.Add("/repos/slow", history.Record{Operation: types.OperationFetch, Duration: d * time.Second})
+	}
+	// Failures and other operations say nothing about how long a successful fetch takes
+	p.history.Add("/repos/slow", history.Record{Operation: types.OperationFetch, Duration: time.Hour, Error: "timeout"})
+	p.history.Add("/repos/slow", history.Record{Operation: types.OperationPull, Duration: time.Hour})
+
+	if got := p.repoTimeout("/repos/slow"); got != 90*time.Second {
+		t.Errorf("repoTimeout() = %v, want 3x the p95 of 30s", got)
+	}
+
+	for range minTimeoutSamples {
+		p.history.Add("/repos/quick", history.Record{Operation: types.OperationFetch, Duration: time.Millisecond})
+	}
+	if got := p.repoTimeout("/repos/quick"); got != minAdaptiveTimeout {
+		t.Errorf("repoTimeout() for a quick repository = %v, want the %v floor", got, minAdaptiveTimeout)
+	}
+
+	config.AdaptiveTimeout = 0
+	if got := p.repoTimeout("/repos/slow"); got != time.Minute {
+		t.Errorf("repoTimeout() with adaptive timeouts disabled = %v, want %v", got, time.Minute)
+	}
+}
+
+func TestProcessRepoRecordsHistory(t *testing.T) {
+	t.Parallel()
+
+	p := newHistoryProcessor(t, &types.Config{Operation: types.OperationFetch, Labels: map[string]string{"host": "ci"}})
+	repo := types.GitRepo{Name: "gone", Path: filepath.Join(t.TempDir(), "gone")}
+
+	result := p.ProcessRepo(context.Background(), repo)
+	if result.Error == nil {
+		t.Fatal("ProcessRepo() of a missing repository succeeded")
+	}
+
+	records := p.history.Records(repo.Path)
+	if len(records) != 1 || !records[0].Failed() || records[0].Operation != types.OperationFetch || records[0].Labels["host"] != "ci" {
+		t.Errorf("history = %+v, want one failed fetch labeled host=ci", records)
+	}
+	if records[0].Duration != result.Duration {
+		t.Errorf("recorded duration %v, result duration %v", records[0].Duration, result.Duration)
+	}
+}
+
+func TestProcessRepoFlagsFlaky(t *testing.T) {
+	t.Parallel()
+
+	p := newHistoryProcessor(t, &types.Config{Operation: types.OperationFetch})
+	repo := types.GitRepo{Name: "gone", Path: filepath.Join(t.TempDir(), "gone")}
+
+	if result := p.ProcessRepo(context.Background(), repo); result.Flaky {
+		t.Error("ProcessRepo() flagged a repository without history as flaky")
+	}
+
+	// Together with the failures of the first run and this one, the outcome keeps flipping
+	p.history.Add(repo.Path, history.Record{Operation: types.OperationFetch})
+	p.history.Add(repo.Path, history.Record{Operation: types.OperationFetch, Error: "connection reset"})
+	p.history.Add(repo.Path, history.Record{Operation: types.OperationFetch})
+	if result := p.ProcessRepo(context.Background(), repo); !result.Flaky {
+		t.Error("ProcessRepo() did not flag an alternating repository as flaky")
+	}
+}
+
+func TestProcessRepoDryRunSkipsHistory(t *testing.T) {
+	t.Parallel()
+
+	p := newHistoryProcessor(t, &types.Config{Operation: types.OperationFetch, DryRun: true})
+	repo := types.GitRepo{Name: "gone", Path: filepath.Join(t.TempDir(), "gone")}
+	p.ProcessRepo(context.Background(), repo)
+
+	if records := p.history.Records(repo.Path); len(records) != 0 {
+		t.Errorf("dry run recorded history %+v", records)
+	}
+}
+
+func TestWithRepoTimeoutCause(t *testing.T) {
+	t.Parallel()
+
+	p := newHistoryProcessor(t, &types.Config{RepoTimeout: time.Millisecond})
+	ctx, timeout, cancel := p.withRepoTimeout(context.Background(), "/repos/a")
+	defer cancel()
+
+	if timeout != time.Millisecond {
+		t.Errorf("timeout = %v, want 1ms", timeout)
+	}
+	<-ctx.Done()
+	if !errors.Is(context.Cause(ctx), errRepoTimeout) {
+		t.Errorf("Cause() = %v, want errRepoTimeout", context.Cause(ctx))
+	}
+}
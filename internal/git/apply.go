@@ -0,0 +1,155 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// applyChange runs the configured script or applies the configured patch in the repository
+// (apply) and commits what changed with the templated commit message, on a new branch when
+// --apply-branch is given, which --apply-push then pushes. The commit is recorded in
+// repo.ApplyCommit; a change that leaves the repository alone commits nothing. When the change
+// fails, the repository is put back the way it was. In dry-run mode nothing runs, but a patch
+// is checked to apply.
+func (p *Processor) applyChange(ctx context.Context, repo *types.GitRepo) error {
+	// A clean tree keeps someone's uncommitted work out of the commit and lets a failed change
+	// be undone
+	if !repo.Clean {
+		return errors.New("repository has uncommitted changes (skipped)")
+	}
+	branch := p.config.ApplyBranch
+	if branch == "" && (repo.Branch == "" || repo.Branch == "detached") {
+		return errors.New("detached HEAD: no branch to commit to (skipped)")
+	}
+	if branch != "" && p.gitCommand(ctx, repo.Path, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil {
+		return fmt.Errorf("branch %s already exists (skipped)", branch)
+	}
+	if p.config.ApplyPush && repo.Remote != p.remoteName() {
+		return fmt.Errorf("no remote named %q to push to (skipped)", p.remoteName())
+	}
+	message, err := p.config.RenderCommitMessage(*repo)
+	if err != nil {
+		return fmt.Errorf("failed to render commit message: %w", err)
+	}
+
+	if p.config.DryRun {
+		if p.config.ApplyPatch != "" {
+			repo.ApplyFiles, err = p.checkPatch(ctx, repo)
+		}
+		return err
+	}
+
+	if branch != "" {
+		if output, err := p.gitCommand(ctx, repo.Path, "checkout", "--quiet", "-b", branch).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to create branch %s: %w (output: %s)", branch, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	files, err := p.makeChange(ctx, repo)
+	if err == nil && files > 0 {
+		err = p.commitChange(ctx, repo, message)
+	}
+	if err != nil || files == 0 {
+		if undoErr := p.undoChange(ctx, repo); undoErr != nil {
+			return errors.Join(err, undoErr)
+		}
+		return err
+	}
+	repo.ApplyFiles = files
+	if branch != "" {
+		repo.Branch = branch
+	}
+
+	if p.config.ApplyPush {
+		if err := p.pushBranch(ctx, repo, branch); err != nil {
+			return err
+		}
+		repo.ApplyPushed = true
+	}
+	return nil
+}
+
+// makeChange runs the script or applies the patch and stages the result, returning how many
+// files changed. The script's output ends up in repo.Exec.
+func (p *Processor) makeChange(ctx context.Context, repo *types.GitRepo) (int, error) {
+	if p.config.ApplyScript != "" {
+		cmd := exec.CommandContext(ctx, p.config.ApplyScript)
+		cmd.Dir = repo.Path
+		if err := runCaptured(cmd, repo); err != nil {
+			return 0, fmt.Errorf("script failed: %w", err)
+		}
+	} else if output, err := p.gitCommand(ctx, repo.Path, "apply", p.config.ApplyPatch).CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("patch does not apply: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	if output, err := p.gitCommand(ctx, repo.Path, "add", "--all").CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("failed to stage the change: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	output, err := p.gitCommand(ctx, repo.Path, "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the changed files: %w", err)
+	}
+	return len(parseLines(string(output))), nil
+}
+
+// commitChange commits the staged change and records the commit in repo.ApplyCommit
+func (p *Processor) commitChange(ctx context.Context, repo *types.GitRepo, message string) error {
+	if output, err := p.gitCommand(ctx, repo.Path, "commit", "--quiet", "--message", message).CombinedOutput(); err != nil {
+		return fmt.Errorf("commit failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	output, err := p.gitCommand(ctx, repo.Path, "rev-parse", "--short=8", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read the commit: %w", err)
+	}
+	repo.ApplyCommit = strings.TrimSpace(string(output))
+	return nil
+}
+
+// undoChange discards what an uncommitted change did to the repository, which was clean
+// before, and returns to the original branch, deleting the one created for the change
+func (p *Processor) undoChange(ctx context.Context, repo *types.GitRepo) error {
+	steps := [][]string{
+		{"reset", "--quiet", "--hard"},
+		{"clean", "--quiet", "--force", "-d"},
+	}
+	if p.config.ApplyBranch != "" {
+		steps = append(steps,
+			[]string{"checkout", "--quiet", "-"},
+			[]string{"branch", "--quiet", "-D", p.config.ApplyBranch})
+	}
+	for _, args := range steps {
+		if output, err := p.gitCommand(ctx, repo.Path, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to undo the change (git %s): %w (output: %s)", args[0], err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// checkPatch reports how many files the patch would change without applying it
+func (p *Processor) checkPatch(ctx context.Context, repo *types.GitRepo) (int, error) {
+	output, err := p.gitCommand(ctx, repo.Path, "apply", "--check", "--numstat", p.config.ApplyPatch).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("patch does not apply: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	return len(parseLines(string(output))), nil
+}
+
+// pushBranch pushes the new branch to the configured remote and makes it the branch's upstream
+func (p *Processor) pushBranch(ctx context.Context, repo *types.GitRepo, branch string) error {
+	gitRepo, err := openRepo(repo.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	return p.withRateLimitRetry(ctx, gitRepo, repo.Name, func() error {
+		output, err := p.gitCommand(ctx, repo.Path, "push", "--quiet", "--set-upstream", p.remoteName(), branch).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("push failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,146 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// writeScript writes an executable shell script for apply to run
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+
+	script := filepath.Join(t.TempDir(), "change.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestProcessor_ProcessRepo_ApplyScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	setGitIdentity(t)
+	upstream, clone := cloneTestRepo(t, cloneOptions{})
+
+	config := &types.Config{
+		Operation:     types.OperationApply,
+		Remote:        "origin",
+		ApplyScript:   writeScript(t, "echo bumped > ci.yml\necho 'bumped CI'\n"),
+		CommitMessage: "Bump CI in {{.Name}}",
+		ApplyBranch:   "ci-bump",
+		ApplyPush:     true,
+	}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if result.Error != nil {
+		t.Fatalf("Expected the change to be committed, got %v", result.Error)
+	}
+	if result.ApplyCommit == "" || result.ApplyFiles != 1 || !result.ApplyPushed || result.Branch != "ci-bump" {
+		t.Errorf("Expected 1 file committed to ci-bump and pushed, got commit %q, %d files, branch %s, pushed %v",
+			result.ApplyCommit, result.ApplyFiles, result.Branch, result.ApplyPushed)
+	}
+	if result.Exec == nil || strings.TrimSpace(result.Exec.Stdout) != "bumped CI" {
+		t.Errorf("Expected the script's output to be captured, got %+v", result.Exec)
+	}
+	if subject := runGit(t, clone, "log", "-1", "--format=%s"); subject != "Bump CI in clone" {
+		t.Errorf("Expected the rendered commit message, got %q", subject)
+	}
+	if pushed := runGit(t, upstream, "rev-parse", "--short=8", "refs/heads/ci-bump"); pushed != result.ApplyCommit {
+		t.Errorf("Expected ci-bump to be pushed at %s, got %s", result.ApplyCommit, pushed)
+	}
+
+	again := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if again.Error == nil || !strings.Contains(again.Error.Error(), "already exists (skipped)") {
+		t.Errorf("Expected an existing branch to be skipped, got %v", again.Error)
+	}
+}
+
+func TestProcessor_ProcessRepo_ApplyUndo(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	setGitIdentity(t)
+
+	tests := []struct {
+		name    string
+		script  string
+		wantErr bool
+	}{
+		{"no changes", "true\n", false},
+		{"failing script", "echo half > new.txt\necho changed > README.md\nexit 3\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, clone := cloneTestRepo(t, cloneOptions{})
+			head := runGit(t, clone, "rev-parse", "HEAD")
+
+			config := &types.Config{Operation: types.OperationApply, ApplyScript: writeScript(t, tt.script), CommitMessage: "Change", ApplyBranch: "change"}
+			result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+			if (result.Error != nil) != tt.wantErr {
+				t.Fatalf("ProcessRepo() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+			if result.ApplyCommit != "" {
+				t.Errorf("Expected nothing to be committed, got %s", result.ApplyCommit)
+			}
+			if status := runGit(t, clone, "status", "--porcelain"); status != "" {
+				t.Errorf("Expected the change to be undone, got status %q", status)
+			}
+			if branch := runGit(t, clone, "branch", "--show-current"); branch != "master" {
+				t.Errorf("Expected to be back on master, got %s", branch)
+			}
+			if branches := runGit(t, clone, "branch", "--list", "change"); branches != "" {
+				t.Errorf("Expected the change branch to be deleted, got %q", branches)
+			}
+			if now := runGit(t, clone, "rev-parse", "HEAD"); now != head {
+				t.Errorf("Expected HEAD to stay at %s, got %s", head, now)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessRepo_ApplyPatch(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+	_, clone := cloneTestRepo(t, cloneOptions{})
+
+	// The patch is made in another clone of the same upstream
+	source := filepath.Join(t.TempDir(), "source")
+	runGit(t, filepath.Dir(source), "clone", "--quiet", clone, source)
+	commitFile(t, source, "LICENSE", "MIT\n")
+	patch := filepath.Join(t.TempDir(), "license.patch")
+	if err := os.WriteFile(patch, []byte(runGit(t, source, "format-patch", "-1", "--stdout")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &types.Config{Operation: types.OperationApply, ApplyPatch: patch, CommitMessage: "Add a license", DryRun: true}
+	planned := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if planned.Error != nil || planned.ApplyFiles != 1 || planned.ApplyCommit != "" {
+		t.Fatalf("Expected the patch to be checked to change 1 file, got %d files, commit %q (%v)", planned.ApplyFiles, planned.ApplyCommit, planned.Error)
+	}
+	if _, err := os.Stat(filepath.Join(clone, "LICENSE")); !os.IsNotExist(err) {
+		t.Error("Expected a dry run to leave the repository alone")
+	}
+
+	config.DryRun = false
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if result.Error != nil || result.ApplyCommit == "" || result.Branch != "master" {
+		t.Fatalf("Expected the patch to be committed to master, got commit %q on %s (%v)", result.ApplyCommit, result.Branch, result.Error)
+	}
+	if subject := runGit(t, clone, "log", "-1", "--format=%s"); subject != "Add a license" {
+		t.Errorf("Expected the commit message, got %q", subject)
+	}
+
+	// A repository with uncommitted changes is left alone
+	if err := os.WriteFile(filepath.Join(clone, "notes.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dirty := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if dirty.Error == nil || !strings.Contains(dirty.Error.Error(), "uncommitted changes (skipped)") {
+		t.Errorf("Expected a dirty repository to be skipped, got %v", dirty.Error)
+	}
+}
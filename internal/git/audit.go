@@ -0,0 +1,91 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// auditFiles records which of the configured required files are missing from the repository.
+// Each entry may be a glob and may list alternatives separated by '|'; an entry is satisfied
+// when any alternative matches a file or directory relative to the repository root.
+func (p *Processor) auditFiles(repo *types.GitRepo) {
+	repo.MissingFiles = []string{}
+	for _, required := range p.config.RequiredFiles {
+		if !requiredFilePresent(repo.Path, required) {
+			repo.MissingFiles = append(repo.MissingFiles, required)
+		}
+	}
+}
+
+// auditEmail resolves the user.email git would use for new commits in the repository and
+// checks its domain against the allowed domains. The git CLI is used so that includeIf and
+// global configuration are honored exactly as they would be when committing.
+func (p *Processor) auditEmail(ctx context.Context, repo *types.GitRepo) {
+	cmd := p.gitCommand(ctx, repo.Path, "config", "--get", "user.email")
+
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		repo.Error = fmt.Errorf("failed to read user.email: %w", err)
+		return
+	}
+
+	repo.UserEmail = strings.TrimSpace(string(output))
+	repo.EmailIssue = emailIssue(repo.UserEmail, p.config.EmailDomains)
+}
+
+// emailIssue explains why an email is not allowed, returning "" when its domain is allowlisted.
+// A domain also allows its subdomains.
+func emailIssue(email string, domains []string) string {
+	if email == "" {
+		return "user.email is not set"
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return fmt.Sprintf("user.email %s has no domain", email)
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		allowed = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(allowed), "@"))
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("user.email %s is not in an allowed domain", email)
+}
+
+// requiredFilePresent reports whether any alternative of a required file entry exists
+func requiredFilePresent(root, required string) bool {
+	for _, alternative := range strings.Split(required, "|") {
+		alternative = strings.TrimSpace(alternative)
+		if alternative == "" {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(alternative)))
+		if err == nil && len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditIssue describes why a repository failed its audit, returning "" when it is compliant
+func AuditIssue(repo types.GitRepo) string {
+	var issues []string
+	if len(repo.MissingFiles) > 0 {
+		issues = append(issues, "missing: "+strings.Join(repo.MissingFiles, ", "))
+	}
+	if repo.EmailIssue != "" {
+		issues = append(issues, repo.EmailIssue)
+	}
+	return strings.Join(issues, "; ")
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_AuditFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := []string{"LICENSE.md", filepath.Join(".github", "CODEOWNERS")}
+	for _, file := range files {
+		path := filepath.Join(tmpDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	config := &types.Config{
+		Operation: types.OperationAuditFiles,
+		RequiredFiles: []string{
+			"LICENSE*",
+			"SECURITY.md",
+			"CODEOWNERS|.github/CODEOWNERS",
+			".github/workflows",
+		},
+	}
+
+	repo := types.GitRepo{Path: tmpDir, Name: "audited"}
+	NewProcessor(config).auditFiles(&repo)
+
+	expected := []string{"SECURITY.md", ".github/workflows"}
+	if !reflect.DeepEqual(repo.MissingFiles, expected) {
+		t.Errorf("Expected missing files %v, got %v", expected, repo.MissingFiles)
+	}
+}
+
+func TestEmailIssue(t *testing.T) {
+	domains := []string{"example.com", "@corp.example.org"}
+
+	tests := []struct {
+		name    string
+		email   string
+		allowed bool
+	}{
+		{"allowed domain", "dev@example.com", true},
+		{"allowed domain case insensitive", "Dev@EXAMPLE.com", true},
+		{"allowed subdomain", "dev@eu.example.com", true},
+		{"allowed domain with @ prefix", "dev@corp.example.org", true},
+		{"personal address", "dev@gmail.com", false},
+		{"lookalike domain", "dev@notexample.com", false},
+		{"unset", "", false},
+		{"no domain", "dev", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := emailIssue(tt.email, domains)
+			if tt.allowed && issue != "" {
+				t.Errorf("Expected %q to be allowed, got issue %q", tt.email, issue)
+			}
+			if !tt.allowed && issue == "" {
+				t.Errorf("Expected %q to be flagged", tt.email)
+			}
+		})
+	}
+}
+
+func TestAuditIssue(t *testing.T) {
+	if issue := AuditIssue(types.GitRepo{}); issue != "" {
+		t.Errorf("Expected no issue for compliant repo, got %q", issue)
+	}
+
+	repo := types.GitRepo{
+		MissingFiles: []string{"LICENSE*", "SECURITY.md"},
+		EmailIssue:   "user.email dev@gmail.com is not in an allowed domain",
+	}
+	expected := "missing: LICENSE*, SECURITY.md; user.email dev@gmail.com is not in an allowed domain"
+	if issue := AuditIssue(repo); issue != expected {
+		t.Errorf("Expected %q, got %q", expected, issue)
+	}
+}
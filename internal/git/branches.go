@@ -0,0 +1,144 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// pruneBranches deletes the local branches that are merged into the default branch or whose
+// upstream branch is gone from the remote, recording them with their tips in
+// repo.DeletedBranches. The current branch and the default branch itself are kept, and so are
+// branches whose upstream is gone but which hold commits no remote branch contains, unless
+// --delete-unpushed is set; those are recorded in repo.KeptBranches. In dry-run mode the
+// deletions are only planned.
+func (p *Processor) pruneBranches(ctx context.Context, repo *types.GitRepo) error {
+	base, err := p.defaultBranch(ctx, repo.Path)
+	if err != nil {
+		return err
+	}
+
+	merged, err := p.gitCommand(ctx, repo.Path, "for-each-ref", "--merged", base, "--format=%(refname:short)", "refs/heads").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list merged branches: %w", err)
+	}
+	tracking, err := p.gitCommand(ctx, repo.Path, "for-each-ref", "--format=%(refname:short) %(upstream:track)", "refs/heads").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	tips, err := p.gitCommand(ctx, repo.Path, "for-each-ref", "--format=%(refname:short) %(objectname:short)", "refs/heads").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list branch tips: %w", err)
+	}
+
+	mergedBranches := parseLines(string(merged))
+	keep := []string{repo.Branch, strings.TrimPrefix(base, p.remoteName()+"/")}
+	var branches, kept []string
+	for _, branch := range append(mergedBranches, goneBranches(string(tracking))...) {
+		if slices.Contains(keep, branch) || slices.Contains(branches, branch) || slices.Contains(kept, branch) {
+			continue
+		}
+		if !slices.Contains(mergedBranches, branch) && !p.config.DeleteUnpushed {
+			unpushed, err := p.unpushedCommits(ctx, repo.Path, branch)
+			if err != nil {
+				return err
+			}
+			if unpushed > 0 {
+				kept = append(kept, branch)
+				continue
+			}
+		}
+		branches = append(branches, branch)
+	}
+	slices.Sort(branches)
+	slices.Sort(kept)
+
+	if len(branches) > 0 && !p.config.DryRun {
+		// A branch whose upstream is gone may hold commits the default branch lacks, typically
+		// because it was squash-merged, so branches are force-deleted
+		args := append([]string{"branch", "--delete", "--force", "--"}, branches...)
+		if output, err := p.gitCommand(ctx, repo.Path, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete branches: %w (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	// The tips are recorded so a deleted branch can be restored with git branch <name> <tip>
+	tip := branchTips(string(tips))
+	repo.DeletedBranches = nil
+	for _, branch := range branches {
+		repo.DeletedBranches = append(repo.DeletedBranches, fmt.Sprintf("%s (was %s)", branch, tip[branch]))
+	}
+	repo.KeptBranches = kept
+	return nil
+}
+
+// unpushedCommits counts the commits on branch that no remote-tracking branch contains
+func (p *Processor) unpushedCommits(ctx context.Context, dir, branch string) (int, error) {
+	output, err := p.gitCommand(ctx, dir, "rev-list", "--count", "refs/heads/"+branch, "--not", "--remotes").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unpushed commits on %s: %w", branch, err)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse unpushed commit count %q: %w", strings.TrimSpace(string(output)), err)
+	}
+	return count, nil
+}
+
+// defaultBranch returns the branch merged branches are measured against: the remote's default
+// branch as remote/branch when the remote's HEAD is known, and otherwise a local main or master
+func (p *Processor) defaultBranch(ctx context.Context, dir string) (string, error) {
+	output, err := p.gitCommand(ctx, dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/"+p.remoteName()+"/HEAD").Output()
+	if err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		if p.gitCommand(ctx, dir, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil {
+			return branch, nil
+		}
+	}
+	return "", errors.New("no default branch found (skipped)")
+}
+
+// goneBranches extracts the branches whose upstream is gone from git for-each-ref output in
+// the format "%(refname:short) %(upstream:track)", whose lines read "feature [gone]"
+func goneBranches(output string) []string {
+	var branches []string
+	for _, line := range parseLines(output) {
+		if branch, ok := strings.CutSuffix(line, " [gone]"); ok {
+			branches = append(branches, branch)
+		}
+	}
+	return branches
+}
+
+// branchTips maps each branch to its abbreviated tip from git for-each-ref output in the format
+// "%(refname:short) %(objectname:short)"
+func branchTips(output string) map[string]string {
+	tips := make(map[string]string)
+	for _, line := range parseLines(output) {
+		if branch, tip, ok := strings.Cut(line, " "); ok {
+			tips[branch] = tip
+		}
+	}
+	return tips
+}
+
+// parseLines returns the non-empty lines of output
+func parseLines(output string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestGoneBranches(t *testing.T) {
+	t.Parallel()
+
+	output := "main \nfeature [gone]\nfix/typo [ahead 1]\nold [gone]\n"
+	if got, want := goneBranches(output), []string{"feature", "old"}; !slices.Equal(got, want) {
+		t.Errorf("goneBranches() = %v, want %v", got, want)
+	}
+}
+
+func TestProcessor_ProcessRepo_PruneBranches(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	initTestRepo(t, upstream)
+	runGit(t, upstream, "branch", "squashed")
+	path := filepath.Join(root, "clone")
+	runGit(t, root, "clone", "--quiet", upstream, path)
+
+	// merged has nothing the default branch lacks; squashed has unmerged work but its upstream
+	// is gone, as after a squash merge; wip has unmerged work and no upstream
+	runGit(t, path, "branch", "merged")
+	runGit(t, path, "checkout", "--quiet", "--track", "origin/squashed")
+	commitFile(t, path, "squashed.txt", "squashed\n")
+	runGit(t, path, "checkout", "--quiet", "-b", "wip", "master")
+	commitFile(t, path, "wip.txt", "wip\n")
+	runGit(t, upstream, "branch", "--delete", "squashed")
+	runGit(t, path, "remote", "prune", "origin")
+
+	mergedTip := runGit(t, path, "rev-parse", "--short", "merged")
+	squashedTip := runGit(t, path, "rev-parse", "--short", "squashed")
+
+	// squashed's commit is on no remote branch, so it is kept until --delete-unpushed
+	dryRun := &types.Config{Operation: types.OperationPruneBranches, Remote: "origin", DryRun: true}
+	planned := NewProcessor(dryRun).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+	want := []string{"merged (was " + mergedTip + ")"}
+	if planned.Error != nil || !slices.Equal(planned.DeletedBranches, want) {
+		t.Fatalf("Expected %v to be planned for deletion, got %v (%v)", want, planned.DeletedBranches, planned.Error)
+	}
+	if !slices.Equal(planned.KeptBranches, []string{"squashed"}) {
+		t.Errorf("Expected squashed to be kept for its unpushed commit, got %v", planned.KeptBranches)
+	}
+	if branches := runGit(t, path, "branch", "--list", "merged"); branches == "" {
+		t.Fatal("Expected the dry run to keep the branches")
+	}
+
+	config := &types.Config{Operation: types.OperationPruneBranches, Remote: "origin"}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+	if result.Error != nil || !slices.Equal(result.DeletedBranches, want) {
+		t.Fatalf("Expected %v to be deleted, got %v (%v)", want, result.DeletedBranches, result.Error)
+	}
+	if got := runGit(t, path, "for-each-ref", "--format=%(refname:short)", "refs/heads"); got != "master\nsquashed\nwip" {
+		t.Errorf("Expected master, squashed and the current branch wip to be kept, got %q", got)
+	}
+
+	config.DeleteUnpushed = true
+	result = NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+	want = []string{"squashed (was " + squashedTip + ")"}
+	if result.Error != nil || !slices.Equal(result.DeletedBranches, want) || len(result.KeptBranches) > 0 {
+		t.Fatalf("Expected %v to be deleted with --delete-unpushed, got %v and kept %v (%v)", want, result.DeletedBranches, result.KeptBranches, result.Error)
+	}
+	if got := runGit(t, path, "for-each-ref", "--format=%(refname:short)", "refs/heads"); got != "master\nwip" {
+		t.Errorf("Expected master and the current branch wip to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// ErrBudgetExhausted marks repositories that were not attempted because the time budget ran out
+var ErrBudgetExhausted = errors.New("not attempted: time budget exhausted (skipped)")
+
+// SortByStaleness orders repositories so that the ones fetched longest ago come first.
+// Repositories that have never been fetched sort before all others; ties keep scan order.
+func SortByStaleness(repos []types.GitRepo) {
+	fetchedAt := make(map[string]time.Time, len(repos))
+	for _, repo := range repos {
+		fetchedAt[repo.Path] = lastFetch(repo.Path)
+	}
+
+	slices.SortStableFunc(repos, func(a, b types.GitRepo) int {
+		return fetchedAt[a.Path].Compare(fetchedAt[b.Path])
+	})
+}
+
+// lastFetch returns when the repository was last fetched, or the zero time if never
+func lastFetch(repoPath string) time.Time {
+	info, err := os.Stat(filepath.Join(gitDir(repoPath), "FETCH_HEAD"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// NotAttempted returns the result for a repository skipped because the time budget ran out
+func NotAttempted(repo types.GitRepo) types.GitRepo {
+	repo.Error = ErrBudgetExhausted
+	return repo
+}
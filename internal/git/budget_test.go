@@ -0,0 +1,55 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestSortByStaleness(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	now := time.Now()
+	fetched := map[string]time.Time{
+		"recent": now.Add(-time.Hour),
+		"old":    now.Add(-72 * time.Hour),
+	}
+
+	var repos []types.GitRepo
+	for _, name := range []string{"recent", "never", "old"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Join(path, ".git"), 0755); err != nil {
+			t.Fatalf("Failed to create repo %s: %v", name, err)
+		}
+		if when, ok := fetched[name]; ok {
+			fetchHead := filepath.Join(path, ".git", "FETCH_HEAD")
+			if err := os.WriteFile(fetchHead, nil, 0644); err != nil {
+				t.Fatalf("Failed to write FETCH_HEAD: %v", err)
+			}
+			if err := os.Chtimes(fetchHead, when, when); err != nil {
+				t.Fatalf("Failed to set FETCH_HEAD time: %v", err)
+			}
+		}
+		repos = append(repos, types.GitRepo{Path: path, Name: name})
+	}
+
+	SortByStaleness(repos)
+
+	expected := []string{"never", "old", "recent"}
+	for i, name := range expected {
+		if repos[i].Name != name {
+			t.Errorf("Position %d: expected %s, got %s", i, name, repos[i].Name)
+		}
+	}
+}
+
+func TestNotAttempted(t *testing.T) {
+	repo := NotAttempted(types.GitRepo{Name: "late"})
+	if !errors.Is(repo.Error, ErrBudgetExhausted) {
+		t.Errorf("Expected ErrBudgetExhausted, got %v", repo.Error)
+	}
+}
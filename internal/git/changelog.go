@@ -0,0 +1,41 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// readCommits records the commits on the current branch since the configured date, merges
+// aside, for the changelog. The date is passed with a time of day because git reads a bare
+// date as that day at the current time.
+func (p *Processor) readCommits(ctx context.Context, repo *types.GitRepo) {
+	if repo.Empty {
+		return
+	}
+	since := "--since=" + p.config.Since + "T00:00:00"
+	output, err := p.gitCommand(ctx, repo.Path, "log", "--no-merges", since, "--format=%h%x1f%an%x1f%aI%x1f%s", "HEAD").Output()
+	if err != nil {
+		repo.Error = fmt.Errorf("failed to read commits: %w", err)
+		return
+	}
+	repo.Commits = parseCommits(string(output))
+}
+
+// parseCommits reads git log output with the hash, author, date and subject of each commit on a
+// line, separated by unit separators
+func parseCommits(output string) []types.Commit {
+	var commits []types.Commit
+	for _, line := range parseLines(output) {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, types.Commit{Hash: fields[0], Author: fields[1], Date: date, Subject: fields[3]})
+	}
+	return commits
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_ProcessRepo_Changelog(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	runGit(t, filepath.Dir(repoPath), "init", "--quiet", repoPath)
+	t.Setenv("GIT_AUTHOR_DATE", "2024-01-10T12:00:00")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-10T12:00:00")
+	commitFile(t, repoPath, "a.txt", "a\n")
+	t.Setenv("GIT_AUTHOR_DATE", "2024-02-01T09:00:00")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-02-01T09:00:00")
+	commitFile(t, repoPath, "b.txt", "b\n")
+
+	tests := []struct {
+		since string
+		want  []string
+	}{
+		{"2024-02-01", []string{"add b.txt"}},
+		{"2024-01-01", []string{"add b.txt", "add a.txt"}},
+		{time.Now().AddDate(0, 0, 1).Format(time.DateOnly), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			config := &types.Config{Operation: types.OperationChangelog, Since: tt.since}
+			result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "repo"})
+			if result.Error != nil {
+				t.Fatalf("ProcessRepo() error = %v", result.Error)
+			}
+			var subjects []string
+			for _, commit := range result.Commits {
+				subjects = append(subjects, commit.Subject)
+			}
+			if len(subjects) != len(tt.want) {
+				t.Fatalf("Expected commits %v, got %v", tt.want, subjects)
+			}
+			for i := range subjects {
+				if subjects[i] != tt.want[i] {
+					t.Errorf("Expected commits %v, got %v", tt.want, subjects)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCommits(t *testing.T) {
+	output := "1a2b3c4\x1fAda Lovelace\x1f2024-01-05T10:00:00+01:00\x1ffeat: add login\n" +
+		"malformed line\n" +
+		"5e6f7a8\x1fLinus\x1f2024-01-04T08:00:00Z\x1fFix \x1f in subject\n"
+
+	commits := parseCommits(output)
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %+v", commits)
+	}
+	if commits[0].Hash != "1a2b3c4" || commits[0].Author != "Ada Lovelace" || commits[0].Subject != "feat: add login" ||
+		commits[0].Date.Format(time.DateOnly) != "2024-01-05" {
+		t.Errorf("Unexpected first commit: %+v", commits[0])
+	}
+	if commits[1].Subject != "Fix \x1f in subject" {
+		t.Errorf("Expected the subject to keep everything after the third separator, got %q", commits[1].Subject)
+	}
+}
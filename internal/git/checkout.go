@@ -0,0 +1,130 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// checkoutBranch switches the repository to the configured branch, recording the switch in
+// repo.Checkout. A branch that only exists on the configured remote is created to track it
+// with --create and skipped otherwise, as are repositories with the branch nowhere. The
+// remote's branches are known as of the last fetch. In dry-run mode the switch is only planned.
+func (p *Processor) checkoutBranch(gitRepo *gogit.Repository, repo *types.GitRepo) error {
+	target := p.config.Branch
+	if repo.Branch == target {
+		return nil
+	}
+
+	// go-git moves HEAD before it finds out the worktree cannot be updated, so refuse up front
+	if !repo.Clean {
+		return errors.New("repository has uncommitted changes, not switching branches (skipped)")
+	}
+
+	remote := p.remoteName()
+	opts := &gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(target)}
+	checkout := repo.Branch + " -> " + target
+	if _, err := gitRepo.Reference(opts.Branch, true); err != nil {
+		remoteRef, err := gitRepo.Reference(plumbing.NewRemoteReferenceName(remote, target), true)
+		if err != nil {
+			return fmt.Errorf("no branch %s locally or on %s (skipped)", target, remote)
+		}
+		if !p.config.CreateBranch {
+			return fmt.Errorf("no local branch %s, use --create to track %s/%s (skipped)", target, remote, target)
+		}
+		opts.Create = true
+		opts.Hash = remoteRef.Hash()
+		checkout += fmt.Sprintf(" (new, tracking %s/%s)", remote, target)
+	}
+
+	if !p.config.DryRun {
+		worktree, err := gitRepo.Worktree()
+		if err != nil {
+			return fmt.Errorf("failed to get worktree: %w", err)
+		}
+		if err := worktree.Checkout(opts); err != nil {
+			return fmt.Errorf("checkout failed: %w", err)
+		}
+		if opts.Create {
+			if err := setTracking(gitRepo, target, remote); err != nil {
+				return fmt.Errorf("failed to set upstream: %w", err)
+			}
+		}
+		p.AnalyzeRepo(repo)
+	}
+
+	repo.Checkout = checkout
+	return nil
+}
+
+// setTracking points branch at the same-named branch of remote
+func setTracking(gitRepo *gogit.Repository, branch, remote string) error {
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: remote,
+		Merge:  plumbing.NewBranchReferenceName(branch),
+	}
+	return gitRepo.SetConfig(cfg)
+}
+
+// checkoutPin checks out the ref the lockfile pins the repository to (checkout --lock): the
+// pinned branch when it points at the ref, or else the ref's commit with a detached HEAD. The
+// move is recorded in repo.Checkout, e.g. "main at 1a2b3c4d -> detached at 9f8e7d6c (v1.4.0)";
+// repositories already there are left alone. In dry-run mode the checkout is only planned.
+func (p *Processor) checkoutPin(ctx context.Context, repo *types.GitRepo) error {
+	pin := repo.Pin
+	target, err := p.revParse(ctx, repo.Path, pin.Ref+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("ref %s not found, fetch first", pin.Ref)
+	}
+	head, err := p.revParse(ctx, repo.Path, "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	// The pinned branch only counts while it still points at the ref
+	branch := ""
+	if pin.Branch != "" {
+		if hash, err := p.revParse(ctx, repo.Path, "refs/heads/"+pin.Branch); err == nil && hash == target {
+			branch = pin.Branch
+		}
+	}
+	if head == target && (branch == "" || repo.Branch == branch) {
+		return nil
+	}
+	if !repo.Clean {
+		return fmt.Errorf("repository has uncommitted changes, not checking out %s (skipped)", pin.Ref)
+	}
+
+	to := "detached at " + shortHash(target)
+	args := []string{"switch", "--quiet", "--detach", target.String()}
+	if branch != "" {
+		to = branch + " at " + shortHash(target)
+		args = []string{"switch", "--quiet", branch}
+	}
+	if !strings.HasPrefix(target.String(), pin.Ref) && pin.Ref != branch {
+		to += " (" + pin.Ref + ")"
+	}
+
+	if !p.config.DryRun {
+		if output, err := p.gitCommand(ctx, repo.Path, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to check out %s: %w (output: %s)", pin.Ref, err, strings.TrimSpace(string(output)))
+		}
+	}
+	repo.Checkout = fmt.Sprintf("%s at %s -> %s", repo.Branch, shortHash(head), to)
+	if !p.config.DryRun {
+		p.AnalyzeRepo(repo)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func checkout(t *testing.T, path string, config *types.Config) types.GitRepo {
+	t.Helper()
+
+	config.Operation = types.OperationCheckout
+	return NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+}
+
+func TestProcessor_ProcessRepo_CheckoutLocalBranch(t *testing.T) {
+	path := t.TempDir()
+	gitRepo := initTestRepo(t, path)
+	head, err := gitRepo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gitRepo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("dev"), head.Hash())); err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun := checkout(t, path, &types.Config{Branch: "dev", DryRun: true})
+	if dryRun.Error != nil || dryRun.Checkout != "master -> dev" || dryRun.Branch != "master" {
+		t.Fatalf("Expected dry run to plan master -> dev without switching, got %q on %q (error %v)", dryRun.Checkout, dryRun.Branch, dryRun.Error)
+	}
+
+	switched := checkout(t, path, &types.Config{Branch: "dev"})
+	if switched.Error != nil || switched.Checkout != "master -> dev" || switched.Branch != "dev" {
+		t.Fatalf("Expected to switch master -> dev, got %q on %q (error %v)", switched.Checkout, switched.Branch, switched.Error)
+	}
+
+	again := checkout(t, path, &types.Config{Branch: "dev"})
+	if again.Error != nil || again.Checkout != "" {
+		t.Errorf("Expected nothing to do on the branch already, got %q (error %v)", again.Checkout, again.Error)
+	}
+}
+
+func TestProcessor_ProcessRepo_CheckoutRemoteBranch(t *testing.T) {
+	gitRepo, path, barePath := initPushableRepo(t)
+
+	// Publish a feature branch that only exists on the remote
+	other, err := gogit.PlainClone(t.TempDir(), false, &gogit.CloneOptions{URL: barePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitEmpty(t, other, "feature work")
+	if err := other.Push(&gogit.PushOptions{RefSpecs: []config.RefSpec{"refs/heads/master:refs/heads/feature"}}); err != nil {
+		t.Fatal(err)
+	}
+	fetchOrigin(t, gitRepo)
+
+	missing := checkout(t, path, &types.Config{Branch: "nowhere"})
+	if missing.Error == nil || !strings.Contains(missing.Error.Error(), "no branch nowhere locally or on origin (skipped)") {
+		t.Errorf("Expected a missing branch to be skipped, got %v", missing.Error)
+	}
+
+	refused := checkout(t, path, &types.Config{Branch: "feature"})
+	if refused.Error == nil || !strings.Contains(refused.Error.Error(), "--create") || !strings.Contains(refused.Error.Error(), "(skipped)") {
+		t.Fatalf("Expected a remote-only branch to be skipped without --create, got %v", refused.Error)
+	}
+
+	created := checkout(t, path, &types.Config{Branch: "feature", CreateBranch: true})
+	if created.Error != nil {
+		t.Fatalf("Expected to create feature, got %v", created.Error)
+	}
+	if created.Branch != "feature" || created.Upstream != "origin/feature" {
+		t.Errorf("Expected feature tracking origin/feature, got %q tracking %q", created.Branch, created.Upstream)
+	}
+	if !strings.HasSuffix(created.Checkout, "(new, tracking origin/feature)") {
+		t.Errorf("Expected the checkout to note the new branch, got %q", created.Checkout)
+	}
+	if created.LastCommitMsg != "feature work" {
+		t.Errorf("Expected the worktree at the remote branch, got commit %q", created.LastCommitMsg)
+	}
+}
+
+func TestProcessor_ProcessRepo_CheckoutDirty(t *testing.T) {
+	path := t.TempDir()
+	gitRepo := initTestRepo(t, path)
+	head, err := gitRepo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gitRepo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("dev"), head.Hash())); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := checkout(t, path, &types.Config{Branch: "dev"})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "uncommitted changes") || result.Branch != "master" {
+		t.Errorf("Expected a dirty repository to stay on master, got %q (error %v)", result.Branch, result.Error)
+	}
+}
+
+func TestLoadManifestLock(t *testing.T) {
+	manifest := writeManifest(t, `repos:
+  - url: git@github.com:acme/api.git
+    branch: main
+    ref: v1.4.0
+  - path: services/billing
+    ref: 9f8e7d6c
+  - url: git@github.com:acme/docs.git
+`)
+
+	repos, err := loadManifest(manifest, "/work", types.OperationCheckout)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected the 2 repositories with a ref, got %+v", repos)
+	}
+	if repos[0].Pin == nil || *repos[0].Pin != (types.Pin{Ref: "v1.4.0", Branch: "main"}) || repos[0].CloneBranch != "" {
+		t.Errorf("Expected api pinned to v1.4.0 on main, got %+v", repos[0])
+	}
+	if repos[1].Path != filepath.Join("/work", "services", "billing") || repos[1].Pin.Ref != "9f8e7d6c" {
+		t.Errorf("Expected billing, listed by path alone, pinned to 9f8e7d6c, got %+v", repos[1])
+	}
+}
+
+func TestProcessor_ProcessRepo_CheckoutPin(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	runGit(t, filepath.Dir(repoPath), "init", "--quiet", "--initial-branch=main", repoPath)
+	commitFile(t, repoPath, "a.txt", "a\n")
+	runGit(t, repoPath, "tag", "v1.0.0")
+	commitFile(t, repoPath, "b.txt", "b\n")
+
+	pinned := func(pin types.Pin, dryRun bool) types.GitRepo {
+		t.Helper()
+		config := &types.Config{Operation: types.OperationCheckout, DryRun: dryRun}
+		return NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "repo", Pin: &pin})
+	}
+
+	dryRun := pinned(types.Pin{Ref: "v1.0.0", Branch: "main"}, true)
+	if dryRun.Error != nil || !strings.HasSuffix(dryRun.Checkout, "(v1.0.0)") || dryRun.Branch != "main" {
+		t.Fatalf("Expected dry run to plan detaching at v1.0.0 without switching, got %q on %q (error %v)", dryRun.Checkout, dryRun.Branch, dryRun.Error)
+	}
+
+	// main has moved past the tag, so the tag is checked out detached
+	detached := pinned(types.Pin{Ref: "v1.0.0", Branch: "main"}, false)
+	if detached.Error != nil || !strings.HasPrefix(detached.Checkout, "main at ") || !strings.Contains(detached.Checkout, "-> detached at ") || detached.Branch != "detached" {
+		t.Fatalf("Expected to detach at v1.0.0, got %q on %q (error %v)", detached.Checkout, detached.Branch, detached.Error)
+	}
+	if again := pinned(types.Pin{Ref: "v1.0.0"}, false); again.Error != nil || again.Checkout != "" {
+		t.Errorf("Expected nothing to do at the ref already, got %q (error %v)", again.Checkout, again.Error)
+	}
+
+	// A pinned branch that points at the ref is checked out rather than a detached HEAD
+	output, err := exec.Command("git", "-C", repoPath, "rev-parse", "main").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := strings.TrimSpace(string(output))
+	onBranch := pinned(types.Pin{Ref: head, Branch: "main"}, false)
+	if onBranch.Error != nil || !strings.HasSuffix(onBranch.Checkout, "-> main at "+head[:8]) || onBranch.Branch != "main" {
+		t.Errorf("Expected to switch back to main, got %q on %q (error %v)", onBranch.Checkout, onBranch.Branch, onBranch.Error)
+	}
+
+	missing := pinned(types.Pin{Ref: "v9.9.9"}, false)
+	if missing.Error == nil || !strings.Contains(missing.Error.Error(), "fetch first") {
+		t.Errorf("Expected a missing ref to ask for a fetch, got %v", missing.Error)
+	}
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"path/filepath"
+)
+
+// ciSystems maps CI systems to the well-known configuration paths that identify them
+var ciSystems = []struct {
+	name     string
+	patterns []string
+}{
+	{"GitHub Actions", []string{".github/workflows/*.yml", ".github/workflows/*.yaml"}},
+	{"GitLab CI", []string{".gitlab-ci.yml"}},
+	{"CircleCI", []string{".circleci/config.yml", ".circleci/config.yaml"}},
+	{"Jenkins", []string{"Jenkinsfile"}},
+	{"Travis CI", []string{".travis.yml"}},
+	{"Azure Pipelines", []string{"azure-pipelines.yml", "azure-pipelines.yaml"}},
+	{"Bitbucket Pipelines", []string{"bitbucket-pipelines.yml"}},
+}
+
+// detectCISystems returns the CI systems configured in the repository, in a stable order
+func detectCISystems(root string) []string {
+	found := []string{}
+	for _, system := range ciSystems {
+		for _, pattern := range system.patterns {
+			matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+			if err == nil && len(matches) > 0 {
+				found = append(found, system.name)
+				break
+			}
+		}
+	}
+	return found
+}
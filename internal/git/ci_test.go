@@ -0,0 +1,57 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectCISystems(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{
+			name:     "no CI",
+			files:    []string{"README.md"},
+			expected: []string{},
+		},
+		{
+			name:     "github actions",
+			files:    []string{".github/workflows/ci.yaml"},
+			expected: []string{"GitHub Actions"},
+		},
+		{
+			name:     "empty workflows directory",
+			files:    []string{".github/workflows/README.md"},
+			expected: []string{},
+		},
+		{
+			name:     "multiple systems",
+			files:    []string{".gitlab-ci.yml", ".circleci/config.yml", ".github/workflows/release.yml"},
+			expected: []string{"GitHub Actions", "GitLab CI", "CircleCI"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for _, file := range tt.files {
+				path := filepath.Join(tmpDir, filepath.FromSlash(file))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create dir for %s: %v", file, err)
+				}
+				if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", file, err)
+				}
+			}
+
+			got := detectCISystems(tmpDir)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
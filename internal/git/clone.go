@@ -0,0 +1,158 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/viper"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// manifestEntry is one repository listed in a manifest
+type manifestEntry struct {
+	URL     string `mapstructure:"url"`
+	Path    string `mapstructure:"path"`    // Target directory below the root, defaults to the repository name
+	Branch  string `mapstructure:"branch"`  // Branch to check out, defaults to the remote's HEAD
+	Version string `mapstructure:"version"` // Minimum tag the checkout is expected to be on (versions)
+	Ref     string `mapstructure:"ref"`     // Commit or tag the repository is pinned to (checkout --lock)
+}
+
+// loadManifest reads the repositories of the clone or versions operation, or the lockfile of
+// checkout --lock, from manifestPath, a YAML (or JSON or TOML, by extension; any other
+// extension, such as .lock, is read as YAML) file with a "repos" list, and places each below
+// rootPath:
+//
+//	repos:
+//	  - url: git@github.com:acme/api.git
+//	    path: services/api
+//	    branch: main
+//	    version: v1.4.0
+//	    ref: 9f8e7d6c5b4a39281706f5e4d3c2b1a098765432
+//
+// Clone needs the url of every entry. Versions and checkout only need where each repository
+// is, its url or path, and leave out the entries without a version or ref.
+func loadManifest(manifestPath, rootPath string, operation types.OperationType) ([]types.GitRepo, error) {
+	v := viper.New()
+	v.SetConfigFile(manifestPath)
+	if !slices.Contains(viper.SupportedExts, strings.TrimPrefix(filepath.Ext(manifestPath), ".")) {
+		v.SetConfigType("yaml")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var entries []manifestEntry
+	if err := v.UnmarshalKey("repos", &entries); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", manifestPath, err)
+	}
+
+	repos := make([]types.GitRepo, 0, len(entries))
+	targets := make(map[string]string, len(entries))
+	for i, entry := range entries {
+		entry.URL = strings.TrimSpace(entry.URL)
+		entry.Version = strings.TrimSpace(entry.Version)
+		entry.Ref = strings.TrimSpace(entry.Ref)
+		if (operation == types.OperationVersions && entry.Version == "") || (operation == types.OperationCheckout && entry.Ref == "") {
+			continue
+		}
+		if entry.URL == "" && operation == types.OperationClone {
+			return nil, fmt.Errorf("manifest %s: entry %d has no url", manifestPath, i+1)
+		}
+		if entry.URL == "" && strings.TrimSpace(entry.Path) == "" {
+			return nil, fmt.Errorf("manifest %s: entry %d has neither url nor path", manifestPath, i+1)
+		}
+
+		target := filepath.Clean(filepath.FromSlash(strings.TrimSpace(entry.Path)))
+		if entry.Path == "" {
+			target = repoNameFromURL(entry.URL)
+		}
+		label := cmp.Or(redactURL(entry.URL), entry.Path)
+		if !filepath.IsLocal(target) {
+			return nil, fmt.Errorf("manifest %s: path %q of %s must be relative and stay below the root", manifestPath, entry.Path, label)
+		}
+		if other, ok := targets[target]; ok {
+			return nil, fmt.Errorf("manifest %s: %s and %s are both at %s", manifestPath, other, label, target)
+		}
+		targets[target] = label
+
+		repo := types.GitRepo{
+			Path:        filepath.Join(rootPath, target),
+			Name:        filepath.Base(target),
+			RemoteURL:   redactURL(entry.URL),
+			CloneURL:    entry.URL,
+			CloneBranch: strings.TrimSpace(entry.Branch),
+		}
+		switch operation {
+		case types.OperationVersions:
+			repo.Version = &types.TagCheck{Expected: entry.Version}
+		case types.OperationCheckout:
+			repo.Pin = &types.Pin{Ref: entry.Ref, Branch: repo.CloneBranch}
+			repo.CloneBranch = ""
+		}
+		repos = append(repos, repo)
+	}
+
+	disambiguateNames(repos)
+	return repos, nil
+}
+
+// repoNameFromURL derives a directory name from a clone URL the way git clone does, e.g.
+// "api" for both https://github.com/acme/api.git and git@github.com:acme/api.git
+func repoNameFromURL(rawURL string) string {
+	name := strings.TrimRight(rawURL, "/")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, ".git")
+}
+
+// cloneRepo clones a manifest entry into its target directory and analyzes the result.
+// Targets that already hold a repository are skipped, so a manifest can be re-run to pick up
+// new entries; anything else already there is an error. In dry-run mode nothing is cloned.
+func (p *Processor) cloneRepo(ctx context.Context, repo *types.GitRepo) {
+	if _, err := openRepo(repo.Path); err == nil {
+		repo.Error = errors.New("already cloned (skipped)")
+		return
+	}
+	if entries, err := os.ReadDir(repo.Path); err == nil && len(entries) > 0 {
+		repo.Error = fmt.Errorf("%s already exists and is not an empty directory", repo.Path)
+		return
+	}
+
+	if p.config.DryRun {
+		repo.Remote = p.remoteName()
+		repo.Branch = repo.CloneBranch
+		return
+	}
+
+	opts := &gogit.CloneOptions{
+		URL:        repo.CloneURL,
+		RemoteName: p.remoteName(),
+	}
+	if repo.CloneBranch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(repo.CloneBranch)
+	}
+
+	networkStart := time.Now()
+	err := p.withHostRateLimitRetry(ctx, urlHost(repo.CloneURL), repo.Name, func() error {
+		_, err := gogit.PlainCloneContext(ctx, repo.Path, false, opts)
+		return err
+	})
+	repo.Timings.Network = time.Since(networkStart)
+	if err != nil {
+		repo.Error = fmt.Errorf("clone failed: %w", err)
+		return
+	}
+
+	p.AnalyzeRepo(repo)
+}
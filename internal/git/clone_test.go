@@ -0,0 +1,134 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "repos.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRepoNameFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/acme/api.git": "api",
+		"https://github.com/acme/web/":    "web",
+		"git@github.com:acme/tools.git":   "tools",
+		"git@example.com:solo.git":        "solo",
+		"ssh://git@example.com/acme/cli":  "cli",
+		"/srv/git/mirror.git":             "mirror",
+	}
+	for url, want := range tests {
+		if got := repoNameFromURL(url); got != want {
+			t.Errorf("repoNameFromURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestLoadCloneManifest(t *testing.T) {
+	manifest := writeManifest(t, `repos:
+  - url: https://token@github.com/acme/api.git
+  - url: git@github.com:acme/web.git
+    path: apps/web
+    branch: develop
+`)
+
+	repos, err := loadManifest(manifest, "/work", types.OperationClone)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(repos))
+	}
+
+	api, web := repos[0], repos[1]
+	if api.Path != filepath.Join("/work", "api") || api.Name != "api" {
+		t.Errorf("Expected api at /work/api, got %q named %q", api.Path, api.Name)
+	}
+	if api.CloneURL != "https://token@github.com/acme/api.git" || api.RemoteURL != "https://github.com/acme/api.git" {
+		t.Errorf("Expected the clone URL kept and the shown URL redacted, got %q and %q", api.CloneURL, api.RemoteURL)
+	}
+	if web.Path != filepath.Join("/work", "apps", "web") || web.CloneBranch != "develop" {
+		t.Errorf("Expected web at /work/apps/web on develop, got %q on %q", web.Path, web.CloneBranch)
+	}
+}
+
+func TestLoadCloneManifestErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing url":    "repos:\n  - path: api\n",
+		"escaping path":  "repos:\n  - url: https://github.com/acme/api.git\n    path: ../api\n",
+		"absolute path":  "repos:\n  - url: https://github.com/acme/api.git\n    path: /srv/api\n",
+		"same target":    "repos:\n  - url: https://github.com/acme/api.git\n  - url: https://github.com/globex/api.git\n",
+		"invalid format": "repos: [\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadManifest(writeManifest(t, content), "/work", types.OperationClone); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if _, err := loadManifest(filepath.Join(t.TempDir(), "missing.yaml"), "/work", types.OperationClone); err == nil {
+		t.Error("Expected an error for a missing manifest")
+	}
+}
+
+func TestProcessor_ProcessRepo_Clone(t *testing.T) {
+	source := t.TempDir()
+	initTestRepo(t, source)
+
+	root := t.TempDir()
+	config := &types.Config{Operation: types.OperationClone, CloneManifest: writeManifest(t, "repos:\n  - url: "+source+"\n    path: team/copy\n")}
+	repos, err := NewScanner(config).FindRepos(t.Context(), root, nil)
+	if err != nil || len(repos) != 1 {
+		t.Fatalf("FindRepos() = %d repositories, error %v", len(repos), err)
+	}
+
+	dryRun := NewProcessor(&types.Config{Operation: types.OperationClone, DryRun: true}).ProcessRepo(t.Context(), repos[0])
+	if dryRun.Error != nil {
+		t.Fatalf("Expected dry run to succeed, got %v", dryRun.Error)
+	}
+	if _, err := os.Stat(repos[0].Path); !os.IsNotExist(err) {
+		t.Fatal("Expected dry run not to clone")
+	}
+
+	processor := NewProcessor(config)
+	cloned := processor.ProcessRepo(t.Context(), repos[0])
+	if cloned.Error != nil {
+		t.Fatalf("Expected clone to succeed, got %v", cloned.Error)
+	}
+	if cloned.Branch != "master" || cloned.Remote != "origin" || cloned.LastCommit == "" {
+		t.Errorf("Expected the clone to be analyzed, got branch %q, remote %q, commit %q", cloned.Branch, cloned.Remote, cloned.LastCommit)
+	}
+
+	again := processor.ProcessRepo(t.Context(), repos[0])
+	if again.Error == nil || !strings.Contains(again.Error.Error(), "(skipped)") {
+		t.Errorf("Expected an existing clone to be skipped, got %v", again.Error)
+	}
+}
+
+func TestProcessor_ProcessRepo_CloneIntoOccupiedDirectory(t *testing.T) {
+	source := t.TempDir()
+	initTestRepo(t, source)
+
+	target := t.TempDir()
+	if err := os.WriteFile(filepath.Join(target, "notes.txt"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewProcessor(&types.Config{Operation: types.OperationClone}).ProcessRepo(t.Context(), types.GitRepo{Path: target, Name: "copy", CloneURL: source})
+	if result.Error == nil || strings.Contains(result.Error.Error(), "skipped") {
+		t.Errorf("Expected cloning into a non-empty directory to fail, got %v", result.Error)
+	}
+}
@@ -0,0 +1,175 @@
+package git
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+const (
+	// dnsCacheTTL bounds how long a lookup is reused, so long runs still notice DNS changes
+	dnsCacheTTL = 5 * time.Minute
+
+	// dialTimeout is the total time allowed to connect to one of a host's addresses
+	dialTimeout = 30 * time.Second
+
+	// minAttemptTimeout keeps each address attempt from being squeezed to nothing
+	minAttemptTimeout = 2 * time.Second
+)
+
+// dnsCache remembers host lookups for the duration of a run. Concurrent lookups of the same
+// host share a single query, so hundreds of workers starting together resolve github.com once.
+type dnsCache struct {
+	resolver *net.Resolver
+	group    singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDNSCache() *dnsCache {
+	return &dnsCache{
+		resolver: net.DefaultResolver,
+		entries:  make(map[string]dnsEntry),
+	}
+}
+
+// lookup returns the addresses of host, resolving it only when no fresh entry is cached.
+// Failed lookups are not cached.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	result, err, _ := c.group.Do(host, func() (any, error) {
+		addrs, err := c.resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		c.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(dnsCacheTTL)}
+		c.mu.Unlock()
+		return addrs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]string), nil
+}
+
+// cachingDialer dials through the run's DNS cache, trying each resolved address of the
+// allowed IP family in turn
+type cachingDialer struct {
+	dialer net.Dialer
+	cache  *dnsCache
+	family types.IPFamily
+}
+
+func newCachingDialer(family types.IPFamily) *cachingDialer {
+	return &cachingDialer{
+		dialer: net.Dialer{KeepAlive: 30 * time.Second},
+		cache:  newDNSCache(),
+		family: family,
+	}
+}
+
+// DialContext connects to address, resolving its host through the cache. Like net.Dialer it
+// splits the remaining time across the addresses so one unreachable address cannot use it all.
+func (d *cachingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	network = restrictNetwork(network, d.family)
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := d.cache.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	addrs = filterFamily(addrs, d.family)
+
+	deadline := time.Now().Add(dialTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	var firstErr error
+	for i, addr := range addrs {
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout(deadline, len(addrs)-i))
+		conn, err := d.dialer.DialContext(attemptCtx, network, net.JoinHostPort(addr, port))
+		cancel()
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if firstErr == nil {
+		firstErr = &net.DNSError{Err: "no addresses found for ip-family " + string(d.family), Name: host, IsNotFound: true}
+	}
+	return nil, firstErr
+}
+
+// Dial connects to address like DialContext, for dialers of x/net/proxy
+func (d *cachingDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+// attemptTimeout shares the time left before deadline among the remaining addresses
+func attemptTimeout(deadline time.Time, remaining int) time.Duration {
+	timeout := time.Until(deadline) / time.Duration(remaining)
+	return max(timeout, minAttemptTimeout)
+}
+
+// restrictNetwork narrows a generic tcp/udp network to the requested IP family
+func restrictNetwork(network string, family types.IPFamily) string {
+	if network != "tcp" && network != "udp" {
+		return network
+	}
+	switch family {
+	case types.IPFamily4:
+		return network + "4"
+	case types.IPFamily6:
+		return network + "6"
+	default:
+		return network
+	}
+}
+
+// filterFamily keeps only addresses of the requested IP family
+func filterFamily(addrs []string, family types.IPFamily) []string {
+	if family != types.IPFamily4 && family != types.IPFamily6 {
+		return addrs
+	}
+
+	filtered := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			continue
+		}
+		if (ip.To4() != nil) == (family == types.IPFamily4) {
+			filtered = append(filtered, addr)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,177 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestDNSCache_ReusesFreshEntries(t *testing.T) {
+	cache := newDNSCache()
+	cache.entries["forge.example"] = dnsEntry{
+		addrs:   []string{"127.0.0.1"},
+		expires: time.Now().Add(time.Minute),
+	}
+
+	addrs, err := cache.lookup(context.Background(), "forge.example")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	if len(addrs) != 1 || addrs[0] != "127.0.0.1" {
+		t.Errorf("Expected cached address, got %v", addrs)
+	}
+}
+
+func TestDNSCache_LookupOnce(t *testing.T) {
+	var queries atomic.Int32
+	cache := newDNSCache()
+	cache.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			queries.Add(1)
+			return nil, &net.OpError{Op: "dial", Err: net.UnknownNetworkError("test")}
+		},
+	}
+
+	// localhost is answered from /etc/hosts without querying the DNS server
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.lookup(context.Background(), "localhost"); err != nil {
+				t.Errorf("lookup failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := cache.entries["localhost"]; !ok {
+		t.Fatal("Expected localhost to be cached")
+	}
+	if queries.Load() != 0 {
+		t.Errorf("Expected no DNS server queries, got %d", queries.Load())
+	}
+}
+
+func TestDNSCache_ExpiredEntry(t *testing.T) {
+	cache := newDNSCache()
+	cache.entries["localhost"] = dnsEntry{
+		addrs:   []string{"192.0.2.1"},
+		expires: time.Now().Add(-time.Second),
+	}
+
+	addrs, err := cache.lookup(context.Background(), "localhost")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	for _, addr := range addrs {
+		if addr == "192.0.2.1" {
+			t.Errorf("Expected expired entry to be refreshed, got %v", addrs)
+		}
+	}
+}
+
+func TestCachingDialer_DialContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	dialer := newCachingDialer(types.IPFamilyAuto)
+	// An unreachable address first: the dialer must fall through to the working one
+	dialer.cache.entries["forge.example"] = dnsEntry{
+		addrs:   []string{"127.0.0.2", "127.0.0.1"},
+		expires: time.Now().Add(time.Minute),
+	}
+
+	conn, err := dialer.DialContext(context.Background(), "tcp", net.JoinHostPort("forge.example", port))
+	if err != nil {
+		t.Fatalf("DialContext failed: %v", err)
+	}
+	conn.Close()
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	deadline := time.Now().Add(30 * time.Second)
+	if timeout := attemptTimeout(deadline, 3); timeout < 9*time.Second || timeout > 10*time.Second {
+		t.Errorf("Expected ~10s per attempt, got %v", timeout)
+	}
+	if timeout := attemptTimeout(time.Now(), 1); timeout != minAttemptTimeout {
+		t.Errorf("Expected minimum attempt timeout, got %v", timeout)
+	}
+}
+
+func TestCachingDialer_IPFamily(t *testing.T) {
+	dialer := newCachingDialer(types.IPFamily6)
+	dialer.cache.entries["forge.example"] = dnsEntry{
+		addrs:   []string{"127.0.0.1"},
+		expires: time.Now().Add(time.Minute),
+	}
+
+	_, err := dialer.DialContext(context.Background(), "tcp", "forge.example:443")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		t.Fatalf("Expected a DNS error for an IPv4-only host with ip-family 6, got %v", err)
+	}
+}
+
+func TestFilterFamily(t *testing.T) {
+	addrs := []string{"2001:db8::1", "192.0.2.1", "::ffff:192.0.2.2", "2001:db8::2"}
+
+	tests := []struct {
+		family   types.IPFamily
+		expected []string
+	}{
+		{types.IPFamilyAuto, addrs},
+		{types.IPFamily4, []string{"192.0.2.1", "::ffff:192.0.2.2"}},
+		{types.IPFamily6, []string{"2001:db8::1", "2001:db8::2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.family), func(t *testing.T) {
+			if got := filterFamily(addrs, tt.family); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRestrictNetwork(t *testing.T) {
+	tests := []struct {
+		network  string
+		family   types.IPFamily
+		expected string
+	}{
+		{"tcp", types.IPFamilyAuto, "tcp"},
+		{"tcp", types.IPFamily4, "tcp4"},
+		{"tcp", types.IPFamily6, "tcp6"},
+		{"tcp4", types.IPFamily6, "tcp4"},
+		{"unix", types.IPFamily4, "unix"},
+	}
+
+	for _, tt := range tests {
+		if got := restrictNetwork(tt.network, tt.family); got != tt.expected {
+			t.Errorf("restrictNetwork(%q, %q) = %q, want %q", tt.network, tt.family, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// diffArgs diffs tracked files against HEAD. Renames are off so that --numstat and the patch
+// list the same files in the same order, and paths are printed unquoted.
+var diffArgs = []string{"-c", "core.quotePath=false", "diff", "HEAD", "--no-color", "--no-ext-diff", "--no-renames"}
+
+// collectDiffs records the uncommitted changes to each tracked file for the scan export. The git
+// CLI is used because go-git cannot diff a worktree. Untracked files have no diff against HEAD
+// and are only listed among the modified files.
+func (p *Processor) collectDiffs(ctx context.Context, repo *types.GitRepo) {
+	numstat, err := p.gitCommand(ctx, repo.Path, append(diffArgs, "--numstat")...).Output()
+	if err != nil {
+		repo.Error = fmt.Errorf("failed to diff: %w", err)
+		return
+	}
+
+	diffs := parseNumstat(string(numstat))
+	if len(diffs) > 0 && p.config.DiffMaxBytes > 0 {
+		if err := p.readPatches(ctx, repo.Path, diffs); err != nil {
+			repo.Error = fmt.Errorf("failed to diff: %w", err)
+			return
+		}
+	}
+	repo.Diffs = diffs
+}
+
+// parseNumstat reads `git diff --numstat` output, where binary files have "-" for both counts
+func parseNumstat(output string) []types.FileDiff {
+	var diffs []types.FileDiff
+	for line := range strings.Lines(output) {
+		fields := strings.SplitN(strings.TrimRight(line, "\n"), "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		diff := types.FileDiff{Path: fields[2], Binary: fields[0] == "-" && fields[1] == "-"}
+		diff.Added, _ = strconv.Atoi(fields[0])
+		diff.Deleted, _ = strconv.Atoi(fields[1])
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// readPatches streams the unified diff into diffs, keeping at most DiffMaxBytes of each file's
+// patch so a huge generated file cannot blow up memory or the export
+func (p *Processor) readPatches(ctx context.Context, dir string, diffs []types.FileDiff) (err error) {
+	cmd := p.gitCommand(ctx, dir, diffArgs...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, cmd.Wait())
+	}()
+
+	reader := bufio.NewReader(stdout)
+	current := -1
+	var patch strings.Builder
+	for {
+		line, readErr := reader.ReadString('\n')
+		if strings.HasPrefix(line, "diff --git ") {
+			if current >= 0 && current < len(diffs) {
+				diffs[current].Patch = patch.String()
+			}
+			current++
+			patch.Reset()
+		}
+		if current >= 0 && current < len(diffs) && line != "" {
+			if patch.Len()+len(line) > p.config.DiffMaxBytes {
+				diffs[current].Truncated = true
+			} else if !diffs[current].Truncated {
+				patch.WriteString(line)
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			_, _ = io.Copy(io.Discard, stdout)
+			return readErr
+		}
+	}
+	if current >= 0 && current < len(diffs) {
+		diffs[current].Patch = patch.String()
+	}
+	return nil
+}
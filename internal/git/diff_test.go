@@ -0,0 +1,90 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestParseNumstat(t *testing.T) {
+	t.Parallel()
+
+	output := "3\t1\tREADME.md\n-\t-\tlogo.png\n0\t0\tdir/with space.sh\n"
+	want := []types.FileDiff{
+		{Path: "README.md", Added: 3, Deleted: 1},
+		{Path: "logo.png", Binary: true},
+		{Path: "dir/with space.sh"},
+	}
+	if got := parseNumstat(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNumstat() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCollectDiffs(t *testing.T) {
+	requireGit(t)
+
+	path := t.TempDir()
+	gitRepo := initTestRepo(t, path)
+	if err := os.WriteFile(filepath.Join(path, "notes.txt"), []byte(strings.Repeat("line\n", 100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("notes.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "untracked.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := types.GitRepo{Path: path, Name: "repo"}
+	p := NewProcessor(&types.Config{DiffMaxBytes: 200})
+	p.collectDiffs(context.Background(), &repo)
+	if repo.Error != nil {
+		t.Fatalf("collectDiffs() error = %v", repo.Error)
+	}
+
+	if len(repo.Diffs) != 2 {
+		t.Fatalf("Expected diffs for the two tracked files, got %+v", repo.Diffs)
+	}
+	readme, notes := repo.Diffs[0], repo.Diffs[1]
+	if readme.Path != "README.md" || readme.Added != 1 || readme.Deleted != 1 || readme.Truncated {
+		t.Errorf("Unexpected README diff: %+v", readme)
+	}
+	if !strings.Contains(readme.Patch, "-# test\n+# changed\n") {
+		t.Errorf("Expected README patch, got:\n%s", readme.Patch)
+	}
+	if notes.Path != "notes.txt" || notes.Added != 100 || !notes.Truncated || len(notes.Patch) > 200 {
+		t.Errorf("Expected staged notes.txt truncated to 200 bytes, got %+v", notes)
+	}
+	if !strings.HasPrefix(notes.Patch, "diff --git a/notes.txt b/notes.txt\n") || !strings.HasSuffix(notes.Patch, "\n") {
+		t.Errorf("Expected patch cut at a line boundary, got:\n%s", notes.Patch)
+	}
+}
+
+func TestCollectDiffsStatsOnly(t *testing.T) {
+	requireGit(t)
+
+	path := t.TempDir()
+	initTestRepo(t, path)
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := types.GitRepo{Path: path, Name: "repo"}
+	NewProcessor(&types.Config{}).collectDiffs(context.Background(), &repo)
+	want := []types.FileDiff{{Path: "README.md", Added: 1, Deleted: 1}}
+	if repo.Error != nil || !reflect.DeepEqual(repo.Diffs, want) {
+		t.Errorf("collectDiffs() = %+v (error %v), want %+v", repo.Diffs, repo.Error, want)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package git
+
+import "path/filepath"
+
+// dirID identifies a directory however it is reached: by its absolute path with every symlink
+// resolved, where there are no inodes to go by
+type dirID struct {
+	path string
+}
+
+// directoryID returns the identity of the directory at path, following symlinks
+func directoryID(path string) (dirID, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return dirID{}, err
+	}
+	abs, err := filepath.Abs(resolved)
+	return dirID{path: abs}, err
+}
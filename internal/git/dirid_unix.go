@@ -0,0 +1,27 @@
+//go:build unix
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// dirID identifies a directory however it is reached: by device and inode
+type dirID struct {
+	dev, ino uint64
+}
+
+// directoryID returns the identity of the directory at path, following symlinks
+func directoryID(path string) (dirID, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return dirID{}, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return dirID{}, fmt.Errorf("no inode for %s", path)
+	}
+	return dirID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// ErrLowDiskSpace marks a run or repository stopped because the disk is nearly full
+var ErrLowDiskSpace = errors.New("not enough free disk space")
+
+// downloads reports whether the run writes fetched objects, or whole clones, to disk
+func downloads(config *types.Config) bool {
+	switch config.Operation {
+	case types.OperationFetch, types.OperationPull, types.OperationSync, types.OperationClone:
+		return !config.DryRun
+	case types.OperationStatus:
+		return config.FetchFirst && !config.DryRun
+	default:
+		return false
+	}
+}
+
+// CheckFreeSpace fails, for the operations that download objects (fetch, pull, sync, clone and
+// status --fetch-first), when the filesystem holding path has less than --min-free-mb free. A
+// fetch or clone that runs out of space halfway leaves a broken pack or clone behind, so it is
+// better not to start one. Platforms that cannot tell how much space is free always pass.
+func CheckFreeSpace(config *types.Config, path string) error {
+	if config.MinFreeMB <= 0 || !downloads(config) {
+		return nil
+	}
+	free, ok := freeSpace(path)
+	if !ok || free >= uint64(config.MinFreeMB)<<20 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d MiB free on the filesystem of %s, below --min-free-mb %d; free up space or lower --min-free-mb",
+		ErrLowDiskSpace, free>>20, path, config.MinFreeMB)
+}
+
+// freeSpace returns the bytes available to git-herd on the filesystem holding path, or on that
+// of its closest existing parent, e.g. for a repository that is yet to be cloned
+func freeSpace(path string) (uint64, bool) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return 0, false
+	}
+	for {
+		if free, err := availableBytes(dir); err == nil {
+			return free, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return 0, false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd)
+
+package git
+
+import "errors"
+
+// availableBytes reports that there is no way to tell how much space is free
+func availableBytes(string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestCheckFreeSpace(t *testing.T) {
+	if _, ok := freeSpace(t.TempDir()); !ok {
+		t.Skipf("free space is not known on %s", runtime.GOOS)
+	}
+	// No disk has an exbibyte free
+	const huge = 1 << 40
+	notCloned := filepath.Join(t.TempDir(), "acme", "api")
+
+	tests := []struct {
+		name    string
+		config  types.Config
+		wantErr bool
+	}{
+		{"pull with room", types.Config{Operation: types.OperationPull, MinFreeMB: 1}, false},
+		{"pull without room", types.Config{Operation: types.OperationPull, MinFreeMB: huge}, true},
+		{"clone below a missing directory", types.Config{Operation: types.OperationClone, MinFreeMB: huge}, true},
+		{"status fetching first", types.Config{Operation: types.OperationStatus, FetchFirst: true, MinFreeMB: huge}, true},
+		{"status", types.Config{Operation: types.OperationStatus, MinFreeMB: huge}, false},
+		{"push uploads", types.Config{Operation: types.OperationPush, MinFreeMB: huge}, false},
+		{"dry run", types.Config{Operation: types.OperationFetch, DryRun: true, MinFreeMB: huge}, false},
+		{"disabled", types.Config{Operation: types.OperationFetch}, false},
+	}
+	for _, tt := range tests {
+		err := CheckFreeSpace(&tt.config, notCloned)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: CheckFreeSpace() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrLowDiskSpace) {
+			t.Errorf("%s: expected ErrLowDiskSpace, got %v", tt.name, err)
+		}
+	}
+}
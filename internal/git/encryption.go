@@ -0,0 +1,103 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// encryptionFilters maps the smudge/clean filters of transparent encryption tools, as named in
+// .gitattributes, to the tools that install them
+var encryptionFilters = map[string]string{
+	"git-crypt": "git-crypt",
+	"crypt":     "transcrypt",
+}
+
+// detectEncryption reports which transparent encryption tool, if any, the repository's
+// .gitattributes hand files to, and whether it is locked: without the tool's filter configured
+// locally (and, for git-crypt, its key) the encrypted files are checked out as ciphertext.
+func detectEncryption(repoPath string, gitRepo *gogit.Repository) (tool string, locked bool) {
+	filter := encryptionFilter(repoPath, gitRepo)
+	if filter == "" {
+		return "", false
+	}
+	tool = encryptionFilters[filter]
+
+	cfg, err := gitRepo.Config()
+	if err != nil || cfg.Raw.Section("filter").Subsection(filter).Option("smudge") == "" {
+		return tool, true
+	}
+	if filter == "git-crypt" {
+		keys, err := os.ReadDir(filepath.Join(commonDir(repoPath), "git-crypt", "keys"))
+		return tool, err != nil || len(keys) == 0
+	}
+	return tool, false
+}
+
+// encryptionFilter returns the first encryption filter assigned in any tracked .gitattributes
+// file or in .git/info/attributes
+func encryptionFilter(repoPath string, gitRepo *gogit.Repository) string {
+	for _, file := range attributesFiles(repoPath, gitRepo) {
+		if filter := attributesFilter(file, func(filter string) bool { return encryptionFilters[filter] != "" }); filter != "" {
+			return filter
+		}
+	}
+	return ""
+}
+
+// attributesFiles lists .git/info/attributes and every tracked .gitattributes file. Tracked
+// files are found through the index, so the worktree is not walked.
+func attributesFiles(repoPath string, gitRepo *gogit.Repository) []string {
+	files := []string{filepath.Join(commonDir(repoPath), "info", "attributes")}
+	if index, err := gitRepo.Storer.Index(); err == nil {
+		for _, entry := range index.Entries {
+			if path.Base(entry.Name) == ".gitattributes" {
+				files = append(files, filepath.Join(repoPath, filepath.FromSlash(entry.Name)))
+			}
+		}
+	}
+	return files
+}
+
+// attributesFilter returns the first filter assigned in a gitattributes file that wanted accepts
+func attributesFilter(file string, wanted func(filter string) bool) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// The first field is the pattern; the rest are attributes
+		for _, attr := range strings.Fields(line)[1:] {
+			if filter, ok := strings.CutPrefix(attr, "filter="); ok && wanted(filter) {
+				return filter
+			}
+		}
+	}
+	return ""
+}
+
+// checkUnlocked records the repository's encryption and refuses a repository whose encrypted
+// files are locked (--skip-locked)
+func (p *Processor) checkUnlocked(repo *types.GitRepo) error {
+	gitRepo, err := openRepo(repo.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	repo.Encryption, repo.Locked = detectEncryption(repo.Path, gitRepo)
+	if repo.Locked {
+		return fmt.Errorf("%s files are locked, not pulling without the key (skipped)", repo.Encryption)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// trackAttributes commits a .gitattributes file with content at rel in the repository
+func trackAttributes(t *testing.T, gitRepo *gogit.Repository, root, rel, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add(rel); err != nil {
+		t.Fatal(err)
+	}
+	commitEmpty(t, gitRepo, "add "+rel)
+}
+
+// setSmudge configures the smudge command of filter in the repository's local config
+func setSmudge(t *testing.T, gitRepo *gogit.Repository, filter string) {
+	t.Helper()
+
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Raw.Section("filter").Subsection(filter).SetOption("smudge", filter+" smudge")
+	if err := gitRepo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectEncryption_GitCrypt(t *testing.T) {
+	root := t.TempDir()
+	gitRepo := initTestRepo(t, root)
+
+	if tool, locked := detectEncryption(root, gitRepo); tool != "" || locked {
+		t.Fatalf("Expected no encryption, got %q (locked %v)", tool, locked)
+	}
+
+	trackAttributes(t, gitRepo, root, "secrets/.gitattributes", "# encrypted\n*.env filter=git-crypt diff=git-crypt\n")
+	if tool, locked := detectEncryption(root, gitRepo); tool != "git-crypt" || !locked {
+		t.Fatalf("Expected locked git-crypt, got %q (locked %v)", tool, locked)
+	}
+
+	// The filter alone is not enough: git-crypt also needs its key
+	setSmudge(t, gitRepo, "git-crypt")
+	if _, locked := detectEncryption(root, gitRepo); !locked {
+		t.Fatal("Expected git-crypt without a key to be locked")
+	}
+
+	keys := filepath.Join(root, ".git", "git-crypt", "keys")
+	if err := os.MkdirAll(keys, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(keys, "default"), []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if tool, locked := detectEncryption(root, gitRepo); tool != "git-crypt" || locked {
+		t.Errorf("Expected unlocked git-crypt, got %q (locked %v)", tool, locked)
+	}
+}
+
+func TestDetectEncryption_Transcrypt(t *testing.T) {
+	root := t.TempDir()
+	gitRepo := initTestRepo(t, root)
+	trackAttributes(t, gitRepo, root, ".gitattributes", "config/*.yml filter=crypt diff=crypt merge=crypt\n")
+
+	if tool, locked := detectEncryption(root, gitRepo); tool != "transcrypt" || !locked {
+		t.Fatalf("Expected locked transcrypt, got %q (locked %v)", tool, locked)
+	}
+
+	setSmudge(t, gitRepo, "crypt")
+	if tool, locked := detectEncryption(root, gitRepo); tool != "transcrypt" || locked {
+		t.Errorf("Expected unlocked transcrypt, got %q (locked %v)", tool, locked)
+	}
+}
+
+func TestDetectEncryption_IgnoresOtherFilters(t *testing.T) {
+	root := t.TempDir()
+	gitRepo := initTestRepo(t, root)
+	trackAttributes(t, gitRepo, root, ".gitattributes", "*.psd filter=lfs diff=lfs merge=lfs -text\n")
+
+	if tool, locked := detectEncryption(root, gitRepo); tool != "" || locked {
+		t.Errorf("Expected no encryption for LFS, got %q (locked %v)", tool, locked)
+	}
+}
+
+func TestProcessor_ProcessRepo_SkipLocked(t *testing.T) {
+	root := t.TempDir()
+	gitRepo := initTestRepo(t, root)
+	trackAttributes(t, gitRepo, root, ".gitattributes", "*.env filter=git-crypt diff=git-crypt\n")
+
+	result := NewProcessor(&types.Config{Operation: types.OperationPull, SkipLocked: true}).ProcessRepo(t.Context(), types.GitRepo{Path: root, Name: "repo"})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "git-crypt files are locked") || !strings.Contains(result.Error.Error(), "(skipped)") {
+		t.Errorf("Expected a locked repository to be skipped, got %v", result.Error)
+	}
+	if result.Encryption != "git-crypt" || !result.Locked {
+		t.Errorf("Expected the result to record locked git-crypt, got %q (locked %v)", result.Encryption, result.Locked)
+	}
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// maxExecOutput caps how much of each of a command's output streams is kept per repository, so
+// a chatty command across hundreds of repositories cannot exhaust memory
+const maxExecOutput = 64 * 1024
+
+// cappedBuffer keeps the first maxExecOutput bytes written to it and drops the rest
+type cappedBuffer struct {
+	data      []byte
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	keep := p
+	if room := maxExecOutput - len(b.data); len(keep) > room {
+		keep = keep[:max(room, 0)]
+		b.truncated = true
+	}
+	b.data = append(b.data, keep...)
+	return len(p), nil
+}
+
+// shellCommand runs command with the platform's shell, like mr and gita do
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// execCommand runs the configured shell command in the repository (exec), capturing its output
+// and exit code in repo.Exec. A non-zero exit fails the repository. In dry-run mode nothing runs.
+func (p *Processor) execCommand(ctx context.Context, repo *types.GitRepo) error {
+	if p.config.DryRun {
+		return nil
+	}
+
+	cmd := shellCommand(ctx, p.config.Exec)
+	cmd.Dir = repo.Path
+	return runCaptured(cmd, repo)
+}
+
+// runCaptured runs cmd, capturing its output and exit code in repo.Exec. A non-zero exit is
+// an error.
+func runCaptured(cmd *exec.Cmd, repo *types.GitRepo) error {
+	var stdout, stderr cappedBuffer
+	// The command works on the repository it runs in, not one git-herd was started from
+	cmd.Env = isolatedEnv(os.Environ())
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to run command: %w", err)
+	}
+
+	repo.Exec = &types.ExecResult{
+		Stdout:    string(stdout.data),
+		Stderr:    string(stderr.data),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+	if exitErr != nil {
+		repo.Exec.ExitCode = exitErr.ExitCode()
+		return fmt.Errorf("command exited with status %d", repo.Exec.ExitCode)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestCappedBuffer(t *testing.T) {
+	t.Parallel()
+
+	var b cappedBuffer
+	chunk := strings.Repeat("x", maxExecOutput-1)
+	if n, err := b.Write([]byte(chunk)); n != len(chunk) || err != nil {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	if n, err := b.Write([]byte("yz")); n != 2 || err != nil {
+		t.Fatalf("Write() past the cap = %d, %v, want everything reported written", n, err)
+	}
+	if len(b.data) != maxExecOutput || !b.truncated {
+		t.Errorf("Expected %d bytes kept and truncation noted, got %d (truncated %v)", maxExecOutput, len(b.data), b.truncated)
+	}
+}
+
+func TestProcessor_ProcessRepo_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands use sh syntax")
+	}
+	requireGit(t)
+	setGitIdentity(t)
+
+	path := filepath.Join(t.TempDir(), "repo")
+	initTestRepo(t, path)
+	// A dirty working tree is no reason to skip a command
+	if err := os.WriteFile(filepath.Join(path, "scratch.txt"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &types.Config{Operation: types.OperationExec, Remote: "origin", SkipDirty: true, Exec: "git rev-parse --show-toplevel; echo oops >&2"}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if result.Error != nil || result.Exec == nil {
+		t.Fatalf("Expected the command to run, got %v", result.Error)
+	}
+	if top, _ := filepath.EvalSymlinks(path); strings.TrimSpace(result.Exec.Stdout) != top {
+		t.Errorf("Expected the command to run in the repository, got stdout %q", result.Exec.Stdout)
+	}
+	if result.Exec.Stderr != "oops\n" || result.Exec.ExitCode != 0 {
+		t.Errorf("Expected stderr to be captured, got %q (exit %d)", result.Exec.Stderr, result.Exec.ExitCode)
+	}
+
+	config.Exec = "exit 3"
+	failed := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if failed.Error == nil || failed.Exec == nil || failed.Exec.ExitCode != 3 {
+		t.Fatalf("Expected exit status 3 to fail the repository, got %v (%+v)", failed.Error, failed.Exec)
+	}
+
+	config.DryRun = true
+	planned := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if planned.Error != nil || planned.Exec != nil {
+		t.Errorf("Expected the dry run not to run the command, got %v (%+v)", planned.Error, planned.Exec)
+	}
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// fdsPerWorker is about how many files a worker holds open while it processes a repository:
+// the index and packfiles go-git reads, and the pipes of a git subprocess
+const fdsPerWorker = 32
+
+// fdReserve is left over for everything besides the workers: the terminal, the log, report
+// and history files, and the directory walk
+const fdReserve = 64
+
+// FileLimit is the process's limit on open files (ulimit -n) and how many it has open
+type FileLimit struct {
+	Max  int
+	Open int
+}
+
+// Workers returns how many workers the limit leaves room for, at most want and at least 1
+func (l FileLimit) Workers(want int) int {
+	return max(1, min(want, (l.Max-l.Open-fdReserve)/fdsPerWorker))
+}
+
+// CapWorkers lowers --workers to what the open file limit leaves room for, so a high worker
+// count on a low limit does not end in "too many open files" halfway through a run. It returns
+// why it lowered them, or "" when it did not (or the platform has no such limit).
+func CapWorkers(config *types.Config) string {
+	limit, ok := openFileLimit()
+	if !ok {
+		return ""
+	}
+	workers := limit.Workers(config.Workers)
+	if workers >= config.Workers {
+		return ""
+	}
+	message := fmt.Sprintf("open file limit (ulimit -n) is %d with %d in use, running %d workers instead of %d; raise it, e.g. ulimit -n %d, to run them all",
+		limit.Max, limit.Open, workers, config.Workers, limit.Open+fdReserve+config.Workers*fdsPerWorker)
+	config.Workers = workers
+	return message
+}
+
+// tooManyFiles reports whether err comes from running out of file descriptors, in git-herd
+// itself or in a git subprocess
+func tooManyFiles(err error) bool {
+	return err != nil && (errors.Is(err, syscall.EMFILE) || strings.Contains(err.Error(), "too many open files"))
+}
+
+// explainFileLimit adds the open file limit, and what to do about it, to an error that comes
+// from running out of file descriptors
+func explainFileLimit(err error) error {
+	if !tooManyFiles(err) {
+		return err
+	}
+	if limit, ok := openFileLimit(); ok {
+		return fmt.Errorf("%w (open file limit is %d; raise it with ulimit -n or lower --workers)", err, limit.Max)
+	}
+	return fmt.Errorf("%w (lower --workers)", err)
+}
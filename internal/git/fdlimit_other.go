@@ -0,0 +1,8 @@
+//go:build !unix
+
+package git
+
+// openFileLimit reports that there is no limit on open files to go by
+func openFileLimit() (FileLimit, bool) {
+	return FileLimit{}, false
+}
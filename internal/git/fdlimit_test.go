@@ -0,0 +1,51 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestFileLimit_Workers(t *testing.T) {
+	tests := []struct {
+		limit FileLimit
+		want  int
+		got   int
+	}{
+		{FileLimit{Max: 256, Open: 10}, 20, 5},      // (256-10-64)/32
+		{FileLimit{Max: 1 << 20, Open: 10}, 20, 20}, // Plenty of room
+		{FileLimit{Max: 100, Open: 50}, 20, 1},      // No room still runs one worker
+		{FileLimit{Max: 1024, Open: 0}, 3, 3},       // Never more than asked for
+	}
+	for _, tt := range tests {
+		if got := tt.limit.Workers(tt.want); got != tt.got {
+			t.Errorf("%+v.Workers(%d) = %d, want %d", tt.limit, tt.want, got, tt.got)
+		}
+	}
+}
+
+func TestExplainFileLimit(t *testing.T) {
+	if err := explainFileLimit(nil); err != nil {
+		t.Errorf("explainFileLimit(nil) = %v", err)
+	}
+	other := errors.New("authentication required")
+	if err := explainFileLimit(other); err != other {
+		t.Errorf("unrelated error changed to %v", err)
+	}
+
+	for _, err := range []error{
+		&os.PathError{Op: "open", Path: "packfile", Err: syscall.EMFILE},
+		fmt.Errorf("git fetch failed: fatal: unable to create pipe: too many open files"),
+	} {
+		explained := explainFileLimit(err)
+		if !errors.Is(explained, err) {
+			t.Errorf("explainFileLimit(%v) lost the error", err)
+		}
+		if !strings.Contains(explained.Error(), "--workers") {
+			t.Errorf("explainFileLimit(%v) = %v, want guidance", err, explained)
+		}
+	}
+}
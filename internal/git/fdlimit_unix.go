@@ -0,0 +1,22 @@
+//go:build unix
+
+package git
+
+import (
+	"os"
+	"syscall"
+)
+
+// openFileLimit returns the soft limit on open files and how many are open, counted in /dev/fd
+func openFileLimit() (FileLimit, bool) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return FileLimit{}, false
+	}
+	// An unlimited soft limit is the largest value there is, which no worker count comes near
+	limit := FileLimit{Max: int(min(rlimit.Cur, 1<<31-1))}
+	if entries, err := os.ReadDir("/dev/fd"); err == nil {
+		limit.Open = len(entries)
+	}
+	return limit, true
+}
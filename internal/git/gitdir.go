@@ -0,0 +1,123 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// repoEnvVars point git at a repository other than the one in the working directory. They are
+// dropped from git CLI invocations, since a GIT_DIR exported for one split checkout would
+// otherwise send every repository's command to that checkout.
+var repoEnvVars = []string{
+	"GIT_DIR", "GIT_WORK_TREE", "GIT_COMMON_DIR", "GIT_INDEX_FILE",
+	"GIT_OBJECT_DIRECTORY", "GIT_ALTERNATE_OBJECT_DIRECTORIES",
+}
+
+// openRepo opens the repository whose worktree is at path. Besides a .git directory this
+// handles a .git file pointing elsewhere, as left by --separate-git-dir and git worktree add;
+// linked worktrees share objects and refs through the main repository's commondir.
+func openRepo(path string) (*gogit.Repository, error) {
+	return gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+}
+
+// isWorktreeRoot reports whether dir is the top of a repository's worktree: it has a .git
+// directory, or a .git file pointing at the git directory kept elsewhere
+func isWorktreeRoot(dir string) bool {
+	dotGit := filepath.Join(dir, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return true
+	}
+	data, err := os.ReadFile(dotGit)
+	return err == nil && strings.HasPrefix(string(data), "gitdir:")
+}
+
+// isBareRepo reports whether dir is a bare repository: a git directory with HEAD, objects and
+// refs, configured with core.bare, and no worktree around it. A .git directory is not one, nor
+// is the git directory a --separate-git-dir checkout keeps elsewhere.
+func isBareRepo(dir string) bool {
+	if filepath.Base(dir) == ".git" || isWorktreeRoot(dir) {
+		return false
+	}
+	head, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+	if err != nil || !strings.HasPrefix(string(head), "ref: refs/") && !plumbing.IsHash(strings.TrimSpace(string(head))) {
+		return false
+	}
+	for _, name := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config"))
+	if err != nil {
+		return false
+	}
+	cfg := config.NewConfig()
+	return cfg.Unmarshal(data) == nil && cfg.Core.IsBare
+}
+
+// gitDir returns the git directory of the worktree at repoPath: .git itself, or where a .git
+// file's "gitdir:" line points, resolved relative to the worktree. A bare repository is its own
+// git directory.
+func gitDir(repoPath string) string {
+	dotGit := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(dotGit)
+	if errors.Is(err, os.ErrNotExist) && isBareRepo(repoPath) {
+		return repoPath
+	}
+	if err != nil || info.IsDir() {
+		return dotGit
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return dotGit
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// commonDir returns the directory holding what the worktree at repoPath shares with other
+// worktrees of the same repository, such as hooks, info and the object store. It is the git
+// directory itself except for linked worktrees.
+func commonDir(repoPath string) string {
+	dir := gitDir(repoPath)
+	data, err := os.ReadFile(filepath.Join(dir, "commondir"))
+	if err != nil {
+		return dir
+	}
+	common := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+	return filepath.Clean(common)
+}
+
+// isolatedEnv returns env without the variables that redirect git to another repository
+func isolatedEnv(env []string) []string {
+	isolated := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if !slices.Contains(repoEnvVars, name) {
+			isolated = append(isolated, kv)
+		}
+	}
+	return isolated
+}
@@ -0,0 +1,184 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// separateGitDir moves the git directory of the repository at root to dir and leaves a .git
+// file pointing at it, as git init --separate-git-dir does
+func separateGitDir(t *testing.T, root, dir string) {
+	t.Helper()
+
+	if err := os.Rename(filepath.Join(root, ".git"), dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: "+dir+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitDir(t *testing.T) {
+	root := t.TempDir()
+	checkout := filepath.Join(root, "checkout")
+	initTestRepo(t, checkout)
+
+	if got := gitDir(checkout); got != filepath.Join(checkout, ".git") {
+		t.Errorf("Expected the .git directory, got %q", got)
+	}
+
+	separate := filepath.Join(root, "store", "checkout.git")
+	if err := os.MkdirAll(filepath.Dir(separate), 0755); err != nil {
+		t.Fatal(err)
+	}
+	separateGitDir(t, checkout, separate)
+	if got := gitDir(checkout); got != separate {
+		t.Errorf("Expected %q, got %q", separate, got)
+	}
+	if got := commonDir(checkout); got != separate {
+		t.Errorf("Expected the common dir to be the git dir, got %q", got)
+	}
+
+	// Relative pointers resolve against the worktree
+	if err := os.WriteFile(filepath.Join(checkout, ".git"), []byte("gitdir: ../store/checkout.git\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitDir(checkout); got != separate {
+		t.Errorf("Expected relative gitdir to resolve to %q, got %q", separate, got)
+	}
+}
+
+func TestCommonDir_LinkedWorktree(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "main")
+	initTestRepo(t, main)
+
+	linked := filepath.Join(root, "linked")
+	worktreeDir := filepath.Join(main, ".git", "worktrees", "linked")
+	if err := os.MkdirAll(worktreeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreeDir, "commondir"), []byte("../..\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(linked, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: "+worktreeDir+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := gitDir(linked); got != worktreeDir {
+		t.Errorf("Expected %q, got %q", worktreeDir, got)
+	}
+	if got, want := commonDir(linked), filepath.Join(main, ".git"); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestIsWorktreeRoot(t *testing.T) {
+	root := t.TempDir()
+
+	if isWorktreeRoot(root) {
+		t.Error("Expected a directory without .git not to be a worktree root")
+	}
+
+	// A stray .git file that is not a gitdir pointer is not a repository
+	if err := os.WriteFile(filepath.Join(root, ".git"), []byte("not a pointer\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if isWorktreeRoot(root) {
+		t.Error("Expected a .git file without gitdir: not to be a worktree root")
+	}
+
+	if err := os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: /elsewhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !isWorktreeRoot(root) {
+		t.Error("Expected a .git pointer file to be a worktree root")
+	}
+}
+
+func TestIsolatedEnv(t *testing.T) {
+	env := isolatedEnv([]string{"HOME=/root", "GIT_DIR=/other/.git", "GIT_WORK_TREE=/other", "GIT_SSH_COMMAND=ssh"})
+
+	want := []string{"HOME=/root", "GIT_SSH_COMMAND=ssh"}
+	if !slices.Equal(env, want) {
+		t.Errorf("Expected %v, got %v", want, env)
+	}
+}
+
+func TestAnalyzeRepo_SeparateGitDir(t *testing.T) {
+	root := t.TempDir()
+	checkout := filepath.Join(root, "checkout")
+	initTestRepo(t, checkout)
+	separate := filepath.Join(root, "checkout.git")
+	separateGitDir(t, checkout, separate)
+
+	repos, err := NewScanner(&types.Config{}).FindRepos(t.Context(), root, nil)
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Path != checkout {
+		t.Fatalf("Expected only the checkout, got %+v", repos)
+	}
+
+	repo := repos[0]
+	NewProcessor(&types.Config{}).AnalyzeRepo(&repo)
+	if repo.Error != nil {
+		t.Fatalf("Expected the split checkout to analyze, got %v", repo.Error)
+	}
+	if repo.GitDir != separate {
+		t.Errorf("Expected git dir %q, got %q", separate, repo.GitDir)
+	}
+	if !repo.Clean || repo.Branch == "" {
+		t.Errorf("Expected a clean checkout on a branch, got clean=%v branch=%q", repo.Clean, repo.Branch)
+	}
+}
+
+func TestBareRepo(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	initTestRepo(t, app)
+	mirror := filepath.Join(root, "mirrors", "app.git")
+	if _, err := gogit.PlainClone(mirror, true, &gogit.CloneOptions{URL: app}); err != nil {
+		t.Fatalf("Failed to clone bare: %v", err)
+	}
+
+	if !isBareRepo(mirror) || isBareRepo(app) || isBareRepo(filepath.Join(app, ".git")) {
+		t.Error("Expected only the bare clone to be a bare repository")
+	}
+	if got := gitDir(mirror); got != mirror {
+		t.Errorf("Expected a bare repository to be its own git directory, got %q", got)
+	}
+
+	repos, err := NewScanner(&types.Config{Recursive: true}).FindRepos(t.Context(), root, nil)
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Path != app || repos[0].Bare || repos[1].Path != mirror || !repos[1].Bare {
+		t.Fatalf("Expected the checkout and the bare mirror, got %+v", repos)
+	}
+
+	repo := repos[1]
+	NewProcessor(&types.Config{}).AnalyzeRepo(&repo)
+	if repo.Error != nil || !repo.Bare || !repo.Clean || repo.GitDir != "" || repo.Branch == "" {
+		t.Fatalf("Expected a clean bare repository on a branch, got %+v", repo)
+	}
+
+	result := NewProcessor(&types.Config{Operation: types.OperationFetch, Remote: "origin"}).ProcessRepo(t.Context(), repos[1])
+	if result.Error != nil {
+		t.Errorf("Expected the bare repository to fetch, got %v", result.Error)
+	}
+	result = NewProcessor(&types.Config{Operation: types.OperationPull, Remote: "origin"}).ProcessRepo(t.Context(), repos[1])
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "bare repository: pull needs a worktree (skipped)") {
+		t.Errorf("Expected pull to skip the bare repository, got %v", result.Error)
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// assignGroups puts each repository below root in a group: the first group declared under
+// groups, by name, with a pattern matching the repository or a directory above it below the
+// root, or else the top-level directory below the root it is in, so repositories under
+// ~/work/clientA/* are in "clientA" when ~/work is walked. Repositories right below the root
+// are in no group unless one is declared for them.
+func assignGroups(config *types.Config, root string, repos []types.GitRepo) {
+	names := slices.Sorted(maps.Keys(config.Groups))
+	for i := range repos {
+		repos[i].Group = groupOf(config, names, root, repos[i].Path)
+	}
+}
+
+// groupOf returns the group of the repository at dir, with the declared group names in order
+func groupOf(config *types.Config, names []string, root, dir string) string {
+	for _, name := range names {
+		for _, pattern := range config.Groups[name] {
+			if matchWithin(pattern, root, dir) {
+				return name
+			}
+		}
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	top, _, nested := strings.Cut(filepath.ToSlash(rel), "/")
+	if !nested {
+		return ""
+	}
+	return top
+}
+
+// matchWithin reports whether pattern, as for --exclude, matches the directory at dir or one
+// of the directories above it below the root
+func matchWithin(pattern, root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return matchDir(pattern, root, dir)
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i := len(parts); i > 0; i-- {
+		if matchDir(pattern, root, filepath.Join(append([]string{root}, parts[:i]...)...)) {
+			return true
+		}
+	}
+	return false
+}
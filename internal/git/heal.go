@@ -0,0 +1,101 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// healDetached puts a repository with a detached HEAD back on a branch (heal): a local branch
+// at the detached commit, or else the local branch that most recently had the commit, or else
+// the default branch. The default branch is only checked out when some branch or tag contains
+// the commit, so commits made on the detached HEAD are never left behind. What changed is
+// recorded in repo.Checkout as "detached at <commit> -> <branch> (<why>)" and repo.Healed is
+// set; repositories that are on a branch are left alone. In dry-run mode the checkout is only planned.
+func (p *Processor) healDetached(ctx context.Context, repo *types.GitRepo) error {
+	if repo.Branch != "detached" {
+		return nil
+	}
+	if !repo.Clean {
+		return errors.New("repository has uncommitted changes, not leaving the detached HEAD (skipped)")
+	}
+
+	head, err := p.revParse(ctx, repo.Path, "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	branch, why, err := p.healTarget(ctx, repo.Path)
+	if err != nil {
+		return err
+	}
+
+	if !p.config.DryRun {
+		// switch creates the default branch from the remote's when there is no local one yet
+		if output, err := p.gitCommand(ctx, repo.Path, "switch", "--quiet", branch).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to check out %s: %w (output: %s)", branch, err, strings.TrimSpace(string(output)))
+		}
+		p.AnalyzeRepo(repo)
+	}
+
+	repo.Checkout = fmt.Sprintf("detached at %s -> %s (%s)", shortHash(head), branch, why)
+	repo.Healed = true
+	return nil
+}
+
+// healTarget picks the branch healDetached checks out and says why
+func (p *Processor) healTarget(ctx context.Context, dir string) (string, string, error) {
+	// Without a default branch, only branches with the commit are candidates
+	base, baseErr := p.defaultBranch(ctx, dir)
+	base = strings.TrimPrefix(base, p.remoteName()+"/")
+
+	// Local branches at the commit, the default branch first
+	at, err := p.localBranches(ctx, dir, "--points-at", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	if len(at) > 0 {
+		if slices.Contains(at, base) {
+			return base, "branch at the commit", nil
+		}
+		return at[0], "branch at the commit", nil
+	}
+
+	// Local branches the commit is part of, most recently committed to first
+	containing, err := p.localBranches(ctx, dir, "--contains", "HEAD", "--sort=-committerdate")
+	if err != nil {
+		return "", "", err
+	}
+	if len(containing) > 0 {
+		if slices.Contains(containing, base) {
+			return base, "default branch, contains the commit", nil
+		}
+		return containing[0], "contains the commit", nil
+	}
+
+	// Checking out the default branch would orphan commits nothing else points to
+	output, err := p.gitCommand(ctx, dir, "for-each-ref", "--count=1", "--contains", "HEAD", "--format=%(refname)", "refs/remotes", "refs/tags").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to look for the detached commit: %w", err)
+	}
+	if len(parseLines(string(output))) == 0 {
+		return "", "", errors.New("detached HEAD has commits on no branch or tag, create a branch to keep them")
+	}
+	if baseErr != nil {
+		return "", "", baseErr
+	}
+	return base, "default branch", nil
+}
+
+// localBranches lists the local branches for-each-ref selects with args
+func (p *Processor) localBranches(ctx context.Context, dir string, args ...string) ([]string, error) {
+	args = append(append([]string{"for-each-ref", "--format=%(refname:short)"}, args...), "refs/heads")
+	output, err := p.gitCommand(ctx, dir, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return parseLines(string(output)), nil
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_ProcessRepo_Heal(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	tests := []struct {
+		name    string
+		detach  func(t *testing.T, dir string)
+		branch  string
+		why     string
+		wantErr string
+	}{
+		{
+			name: "branch at the commit",
+			detach: func(t *testing.T, dir string) {
+				runGit(t, dir, "branch", "feature")
+				runGit(t, dir, "switch", "--quiet", "--detach", "feature")
+			},
+			branch: "master",
+			why:    "branch at the commit",
+		},
+		{
+			name: "branch containing the commit",
+			detach: func(t *testing.T, dir string) {
+				runGit(t, dir, "switch", "--quiet", "--create", "feature")
+				commitFile(t, dir, "a.txt", "a\n")
+				runGit(t, dir, "switch", "--quiet", "--detach", "HEAD~1")
+				runGit(t, dir, "branch", "--quiet", "-D", "master")
+			},
+			branch: "feature",
+			why:    "contains the commit",
+		},
+		{
+			name: "default branch",
+			detach: func(t *testing.T, dir string) {
+				runGit(t, dir, "switch", "--quiet", "--detach")
+				commitFile(t, dir, "release.txt", "v1\n")
+				runGit(t, dir, "tag", "v1.0.0")
+			},
+			branch: "master",
+			why:    "default branch",
+		},
+		{
+			name: "commits on no branch",
+			detach: func(t *testing.T, dir string) {
+				runGit(t, dir, "switch", "--quiet", "--detach")
+				commitFile(t, dir, "wip.txt", "wip\n")
+			},
+			wantErr: "commits on no branch or tag",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "repo")
+			initTestRepo(t, dir)
+			tt.detach(t, dir)
+			head := runGit(t, dir, "rev-parse", "--short=8", "HEAD")
+
+			config := &types.Config{Operation: types.OperationHeal, Remote: "origin", DryRun: true}
+			planned := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: dir, Name: "repo"})
+			if tt.wantErr != "" {
+				if planned.Error == nil || !strings.Contains(planned.Error.Error(), tt.wantErr) {
+					t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, planned.Error)
+				}
+				return
+			}
+			if planned.Error != nil || !planned.Healed || planned.Branch != "detached" {
+				t.Fatalf("Expected the heal to be planned only, got healed %v on %s (%v)", planned.Healed, planned.Branch, planned.Error)
+			}
+
+			config.DryRun = false
+			result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: dir, Name: "repo"})
+			if result.Error != nil {
+				t.Fatalf("ProcessRepo() error = %v", result.Error)
+			}
+			want := "detached at " + head + " -> " + tt.branch + " (" + tt.why + ")"
+			if !result.Healed || result.Checkout != want || result.Branch != tt.branch {
+				t.Errorf("Expected %q, got %q on %s", want, result.Checkout, result.Branch)
+			}
+			if current := runGit(t, dir, "branch", "--show-current"); current != tt.branch {
+				t.Errorf("Expected to be on %s, got %q", tt.branch, current)
+			}
+
+			// A repository on a branch is left alone
+			again := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: dir, Name: "repo"})
+			if again.Error != nil || again.Healed {
+				t.Errorf("Expected nothing to heal, got healed %v (%v)", again.Healed, again.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// HerdIgnoreFile is the file that lists, in gitignore syntax, the directories discovery skips
+const HerdIgnoreFile = ".herdignore"
+
+// herdIgnore holds the .herdignore patterns that apply to a directory during a walk: those of
+// the files in it and above it. Patterns are relative to the directory of the file they come
+// from, and a deeper file's patterns win over a shallower one's, as with .gitignore. A
+// herdIgnore is never changed once made, so directories walked concurrently can share it.
+type herdIgnore struct {
+	root     string
+	patterns []gitignore.Pattern
+}
+
+// ignored reports whether the directory at path, below the root, is matched by the patterns
+func (h *herdIgnore) ignored(path string) bool {
+	if len(h.patterns) == 0 {
+		return false
+	}
+	parts := h.parts(path)
+	return len(parts) > 0 && gitignore.NewMatcher(h.patterns).Match(parts, true)
+}
+
+// load returns the patterns for the directory at path below this one: these, followed by those
+// of the .herdignore in it, if there is one
+func (h *herdIgnore) load(path string) (*herdIgnore, error) {
+	file, err := os.Open(filepath.Join(path, HerdIgnoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", HerdIgnoreFile, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	loaded := &herdIgnore{root: h.root, patterns: slices.Clip(h.patterns)}
+	domain := h.parts(path)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		loaded.patterns = append(loaded.patterns, gitignore.ParsePattern(line, domain))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(path, HerdIgnoreFile), err)
+	}
+	return loaded, nil
+}
+
+// parts splits path, relative to the root, into its elements; the root itself has none
+func (h *herdIgnore) parts(path string) []string {
+	rel, err := filepath.Rel(h.root, path)
+	if err != nil || rel == "." {
+		return nil
+	}
+	return strings.Split(filepath.ToSlash(rel), "/")
+}
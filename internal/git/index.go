@@ -0,0 +1,79 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/index"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// Indexed returns when the walk the last FindRepos took its repositories from was made, with
+// --cached, or the zero time when it walked
+func (s *Scanner) Indexed() time.Time {
+	return s.indexed
+}
+
+// indexSettings describes the settings that decide what a walk finds, so a walk is only reused
+// by runs that would have found the same repositories. .herdignore files are not part of it:
+// after changing one, --refresh.
+func (s *Scanner) indexSettings() string {
+	c := s.config
+	return fmt.Sprintf("recursive=%t max-depth=%d follow-symlinks=%t submodules=%t exclude=%q include=%q",
+		c.Recursive, c.MaxDepth, c.FollowSymlinks, c.Submodules, c.ExcludeDirs, c.IncludeDirs)
+}
+
+// readIndex returns the repositories the latest walk of the directory at rootPath found, with
+// those that have since been removed left out, or false when there is no such walk. Repositories
+// added since are only found by walking again.
+func (s *Scanner) readIndex(rootPath string) ([]types.GitRepo, bool) {
+	root, err := filepath.Abs(rootPath)
+	if err != nil || s.config.IndexFile == "" {
+		return nil, false
+	}
+	// An unreadable index is walked around rather than failing the run, and replaced after
+	idx, err := index.Load(s.config.IndexFile)
+	if err != nil {
+		return nil, false
+	}
+	entry, ok := idx.Lookup(root, s.indexSettings())
+	if !ok {
+		return nil, false
+	}
+
+	repos := make([]types.GitRepo, 0, len(entry.Repos))
+	for _, repo := range entry.Repos {
+		path := filepath.Join(rootPath, filepath.FromSlash(repo.Path))
+		if repo.Bare && !isBareRepo(path) || !repo.Bare && !isWorktreeRoot(path) {
+			continue
+		}
+		repos = append(repos, types.GitRepo{Path: path, Name: repo.Name, HasGit: true, Bare: repo.Bare, Nesting: repo.Nesting, HasSubmodules: repo.Submodules})
+	}
+	s.indexed = entry.Scanned
+	return repos, true
+}
+
+// writeIndex records the repositories a walk of the directory at rootPath found. The index only
+// speeds up later runs, so failing to write it does not fail this one.
+func (s *Scanner) writeIndex(rootPath string, repos []types.GitRepo) {
+	root, err := filepath.Abs(rootPath)
+	if err != nil || s.config.IndexFile == "" {
+		return
+	}
+	idx, err := index.Load(s.config.IndexFile)
+	if err != nil {
+		idx = index.New(s.config.IndexFile) // Replace an unreadable index
+	}
+
+	entry := index.Entry{Scanned: time.Now(), Settings: s.indexSettings(), Repos: make([]index.Repo, 0, len(repos))}
+	for _, repo := range repos {
+		rel, err := filepath.Rel(rootPath, repo.Path)
+		if err != nil {
+			return
+		}
+		entry.Repos = append(entry.Repos, index.Repo{Path: filepath.ToSlash(rel), Name: repo.Name, Bare: repo.Bare, Nesting: repo.Nesting, Submodules: repo.HasSubmodules})
+	}
+	idx.Put(root, entry)
+	_ = idx.Save()
+}
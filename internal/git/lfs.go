@@ -0,0 +1,74 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// lfsFilter is the smudge/clean filter Git LFS assigns in .gitattributes
+const lfsFilter = "lfs"
+
+// usesLFS reports whether the repository assigns the Git LFS filter to any files
+func usesLFS(repoPath string, gitRepo *gogit.Repository) bool {
+	for _, file := range attributesFiles(repoPath, gitRepo) {
+		if attributesFilter(file, func(filter string) bool { return filter == lfsFilter }) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchLFS downloads the repository's Git LFS objects after a fetch or pull (--lfs). go-git
+// does not run the LFS filters, so pulled files stay pointer files until git lfs pull replaces
+// them; after a fetch the objects are only downloaded. How much was downloaded is recorded in
+// repo.LFSKiB.
+func (p *Processor) fetchLFS(ctx context.Context, repo *types.GitRepo) error {
+	if !p.config.LFS {
+		return nil
+	}
+	gitRepo, err := openRepo(repo.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	if repo.LFS = usesLFS(repo.Path, gitRepo); !repo.LFS {
+		return nil
+	}
+
+	if err := p.gitCommand(ctx, repo.Path, "lfs", "version").Run(); err != nil {
+		return fmt.Errorf("repository uses Git LFS, but git-lfs is not installed")
+	}
+
+	command := "fetch"
+	if p.config.Operation == types.OperationPull {
+		command = "pull"
+	}
+	objects := filepath.Join(commonDir(repo.Path), "lfs", "objects")
+	before := dirSizeKiB(objects)
+	if output, err := p.gitCommand(ctx, repo.Path, "lfs", command, p.remoteName()).CombinedOutput(); err != nil {
+		return fmt.Errorf("git lfs %s failed: %w (output: %s)", command, err, strings.TrimSpace(string(output)))
+	}
+	repo.LFSKiB = max(dirSizeKiB(objects)-before, 0)
+	return nil
+}
+
+// dirSizeKiB returns the total size of the files under dir in KiB, 0 if it does not exist
+func dirSizeKiB(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && d.Type().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size / 1024
+}
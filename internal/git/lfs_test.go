@@ -0,0 +1,67 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestUsesLFS(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	gitRepo := initTestRepo(t, root)
+	if usesLFS(root, gitRepo) {
+		t.Error("Expected a repository without attributes not to use LFS")
+	}
+
+	trackAttributes(t, gitRepo, root, "assets/.gitattributes", "*.psd filter=lfs diff=lfs merge=lfs -text\n")
+	if !usesLFS(root, gitRepo) {
+		t.Error("Expected a tracked .gitattributes assigning filter=lfs to be detected")
+	}
+}
+
+func TestDirSizeKiB(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "ab", "cd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ab", "cd", "object"), make([]byte, 3*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := dirSizeKiB(dir); got != 3 {
+		t.Errorf("dirSizeKiB() = %d, want 3", got)
+	}
+	if got := dirSizeKiB(filepath.Join(dir, "missing")); got != 0 {
+		t.Errorf("dirSizeKiB() of a missing directory = %d, want 0", got)
+	}
+}
+
+func TestFetchLFS(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	gitRepo := initTestRepo(t, root)
+	config := &types.Config{Operation: types.OperationFetch, LFS: true}
+
+	repo := types.GitRepo{Path: root, Name: "plain"}
+	if err := NewProcessor(config).fetchLFS(t.Context(), &repo); err != nil || repo.LFS {
+		t.Fatalf("Expected a repository without LFS files to be left alone, got LFS %v (%v)", repo.LFS, err)
+	}
+
+	trackAttributes(t, gitRepo, root, ".gitattributes", "*.bin filter=lfs -text\n")
+	err := NewProcessor(config).fetchLFS(t.Context(), &repo)
+	if !repo.LFS {
+		t.Error("Expected the repository to be recognized as using LFS")
+	}
+	// Without git-lfs, the repository fails rather than silently keeping pointer files
+	if exec.Command("git", "lfs", "version").Run() != nil && (err == nil || !strings.Contains(err.Error(), "git-lfs is not installed")) {
+		t.Errorf("Expected a missing git-lfs to be reported, got %v", err)
+	}
+}
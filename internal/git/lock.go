@@ -0,0 +1,238 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// LockEntry is a repository as a lockfile records it: where it is, where it comes from, and
+// the commit it is on
+type LockEntry struct {
+	URL    string // Remote URL without credentials, "" without a remote
+	Path   string // Path below the root, with slashes
+	Branch string // Branch checked out, "" when HEAD is detached
+	Ref    string // Full hash of the commit HEAD is on
+	Dirty  bool   // Has uncommitted changes, which the lockfile does not capture
+}
+
+// Snapshot records the commit every repository below rootPath is on, for git-herd lock. The
+// repositories are found as for any run, with --exclude, --include and --max-depth, at most
+// --workers at a time; empty repositories have no commit to record and are left out.
+func Snapshot(ctx context.Context, config *types.Config, rootPath string) ([]LockEntry, error) {
+	// A snapshot is of what is on disk, so repositories are never taken from a manifest or the
+	// index
+	walk := *config
+	walk.Operation = types.OperationScan
+	walk.Cached = false
+	repos, err := NewScanner(&walk).FindRepos(ctx, rootPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repositories: %w", err)
+	}
+
+	p := NewProcessor(&walk)
+	entries := make([]LockEntry, len(repos))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(walk.Workers, 1))
+	for i, repo := range repos {
+		g.Go(func() error {
+			entry, err := p.lockEntry(ctx, rootPath, repo)
+			if err != nil {
+				return fmt.Errorf("%s: %w", repo.Name, err)
+			}
+			entries[i] = entry
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(entries, func(entry LockEntry) bool { return entry.Ref == "" }), nil
+}
+
+// lockEntry reads the state of one repository for Snapshot
+func (p *Processor) lockEntry(ctx context.Context, rootPath string, repo types.GitRepo) (LockEntry, error) {
+	p.AnalyzeRepo(&repo)
+	if repo.Error != nil {
+		return LockEntry{}, repo.Error
+	}
+	if repo.Empty {
+		return LockEntry{}, nil
+	}
+
+	head, err := p.revParse(ctx, repo.Path, "HEAD")
+	if err != nil {
+		return LockEntry{}, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	rel, err := filepath.Rel(rootPath, repo.Path)
+	if err != nil {
+		return LockEntry{}, err
+	}
+
+	entry := LockEntry{URL: repo.RemoteURL, Path: filepath.ToSlash(rel), Ref: head.String(), Dirty: !repo.Clean}
+	if repo.Branch != "detached" {
+		entry.Branch = repo.Branch
+	}
+	return entry, nil
+}
+
+// WriteLock writes entries as a lockfile, in the manifest format clone reads, so the same file
+// both clones a workspace and checks each repository out at its commit with checkout --lock.
+// Values are written as JSON strings, which YAML reads as double-quoted scalars.
+func WriteLock(w io.Writer, entries []LockEntry) error {
+	var b strings.Builder
+	b.WriteString("# git-herd lockfile: restore with git-herd checkout --lock <file>\n")
+	if len(entries) == 0 {
+		b.WriteString("repos: []\n")
+	} else {
+		b.WriteString("repos:\n")
+	}
+	for _, entry := range entries {
+		prefix := "  - "
+		if entry.URL != "" {
+			fmt.Fprintf(&b, "%surl: %s\n", prefix, yamlString(entry.URL))
+			prefix = "    "
+		}
+		fmt.Fprintf(&b, "%spath: %s\n", prefix, yamlString(entry.Path))
+		if entry.Branch != "" {
+			fmt.Fprintf(&b, "    branch: %s\n", yamlString(entry.Branch))
+		}
+		fmt.Fprintf(&b, "    ref: %s\n", entry.Ref)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReadLock reads the entries of a lockfile, or of a manifest with a ref per entry; entries
+// without a ref are left out
+func ReadLock(lockPath string) ([]LockEntry, error) {
+	v := viper.New()
+	v.SetConfigFile(lockPath)
+	if !slices.Contains(viper.SupportedExts, strings.TrimPrefix(filepath.Ext(lockPath), ".")) {
+		v.SetConfigType("yaml")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read lockfile: %w", err)
+	}
+	var manifest []manifestEntry
+	if err := v.UnmarshalKey("repos", &manifest); err != nil {
+		return nil, fmt.Errorf("parse lockfile %s: %w", lockPath, err)
+	}
+
+	var entries []LockEntry
+	for _, entry := range manifest {
+		ref := strings.TrimSpace(entry.Ref)
+		url := strings.TrimSpace(entry.URL)
+		if ref == "" {
+			continue
+		}
+		dir := path.Clean(filepath.ToSlash(strings.TrimSpace(entry.Path)))
+		if strings.TrimSpace(entry.Path) == "" {
+			dir = repoNameFromURL(url)
+		}
+		entries = append(entries, LockEntry{URL: url, Path: dir, Branch: strings.TrimSpace(entry.Branch), Ref: ref})
+	}
+	return entries, nil
+}
+
+// LockChange is how a repository changed since a lockfile was written, for git-herd lock diff.
+// Old is nil for a repository that is new since, New for one that is gone.
+type LockChange struct {
+	Path    string
+	Old     *LockEntry
+	New     *LockEntry
+	Ahead   int   // Commits at New that Old did not have
+	Behind  int   // Commits at Old that New no longer has, e.g. after a reset
+	Missing error // Why the commits could not be counted, e.g. the old commit is gone
+}
+
+// Changed reports whether the repository is not exactly where the lockfile left it
+func (c LockChange) Changed() bool {
+	return c.Old == nil || c.New == nil || c.Old.Ref != c.New.Ref || c.Old.Branch != c.New.Branch
+}
+
+// DiffLock compares the repositories below rootPath with the entries of a lockfile written
+// earlier, counting the commits each moved by. The changes are returned by path, repositories
+// in both first, then the ones new since, then the ones gone.
+func DiffLock(ctx context.Context, config *types.Config, rootPath string, old []LockEntry) ([]LockChange, error) {
+	current, err := Snapshot(ctx, config, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*LockEntry, len(current))
+	for i := range current {
+		byPath[current[i].Path] = &current[i]
+	}
+
+	p := NewProcessor(config)
+	var changes []LockChange
+	seen := make(map[string]bool, len(old))
+	for i := range old {
+		change := LockChange{Path: old[i].Path, Old: &old[i], New: byPath[old[i].Path]}
+		seen[change.Path] = true
+		if change.New != nil && change.New.Ref != change.Old.Ref {
+			dir := filepath.Join(rootPath, filepath.FromSlash(change.Path))
+			change.Ahead, change.Behind, change.Missing = p.countMoved(ctx, dir, change.Old.Ref, change.New.Ref)
+		}
+		changes = append(changes, change)
+	}
+	for i := range current {
+		if !seen[current[i].Path] {
+			changes = append(changes, LockChange{Path: current[i].Path, New: &current[i]})
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b LockChange) int {
+		return cmp.Compare(lockChangeOrder(a), lockChangeOrder(b))
+	})
+	return changes, nil
+}
+
+// lockChangeOrder sorts repositories in both snapshots before new ones, and new before gone
+func lockChangeOrder(change LockChange) int {
+	switch {
+	case change.New == nil:
+		return 2
+	case change.Old == nil:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// countMoved counts the commits between two commits of the repository at dir, each way
+func (p *Processor) countMoved(ctx context.Context, dir, from, to string) (int, int, error) {
+	if _, err := p.revParse(ctx, dir, from+"^{commit}"); err != nil {
+		return 0, 0, errors.New("the snapshot's commit is no longer in the repository")
+	}
+	output, err := p.gitCommand(ctx, dir, "rev-list", "--left-right", "--count", from+"..."+to).Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+	behind, ahead, ok := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	if !ok {
+		return 0, 0, errors.New("failed to count commits")
+	}
+	b, _ := strconv.Atoi(behind)
+	a, _ := strconv.Atoi(ahead)
+	return a, b, nil
+}
+
+// yamlString quotes s as a double-quoted YAML scalar
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
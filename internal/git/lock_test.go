@@ -0,0 +1,137 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestSnapshot(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+	root := t.TempDir()
+
+	api := initTestRepo(t, filepath.Join(root, "services", "api"))
+	if _, err := api.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://token@github.com/acme/api.git"}}); err != nil {
+		t.Fatal(err)
+	}
+	web := filepath.Join(root, "web")
+	initTestRepo(t, web)
+	runGit(t, web, "switch", "--quiet", "--detach", "HEAD")
+	if err := os.WriteFile(filepath.Join(web, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gogit.PlainInit(filepath.Join(root, "empty"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Snapshot(t.Context(), &types.Config{Workers: 2, Recursive: true, ExcludeDirs: []string{".git"}}, root)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected api and web, the empty repository left out, got %+v", entries)
+	}
+	head := runGit(t, filepath.Join(root, "services", "api"), "rev-parse", "HEAD")
+	if want := (LockEntry{URL: "https://github.com/acme/api.git", Path: "services/api", Branch: "master", Ref: head}); entries[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, entries[0])
+	}
+	if entries[1].Path != "web" || entries[1].Branch != "" || !entries[1].Dirty {
+		t.Errorf("Expected web detached with uncommitted changes, got %+v", entries[1])
+	}
+
+	// The lockfile reads back as the pins of checkout --lock
+	var b strings.Builder
+	if err := WriteLock(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	lockfile := filepath.Join(t.TempDir(), "herd.lock")
+	if err := os.WriteFile(lockfile, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repos, err := loadManifest(lockfile, root, types.OperationCheckout)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v\n%s", err, b.String())
+	}
+	if len(repos) != 2 || repos[0].Path != filepath.Join(root, "services", "api") || *repos[0].Pin != (types.Pin{Ref: head, Branch: "master"}) {
+		t.Errorf("Expected the lockfile to pin api to %s on master, got %+v\n%s", head, repos, b.String())
+	}
+}
+
+func TestWriteLock(t *testing.T) {
+	var b strings.Builder
+	if err := WriteLock(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(b.String(), "repos: []\n") {
+		t.Errorf("Expected an empty list without repositories, got:\n%s", b.String())
+	}
+
+	b.Reset()
+	entries := []LockEntry{{Path: "local tools", Ref: "9f8e7d6c5b4a39281706f5e4d3c2b1a098765432"}}
+	if err := WriteLock(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := "repos:\n  - path: \"local tools\"\n    ref: 9f8e7d6c5b4a39281706f5e4d3c2b1a098765432\n"
+	if !strings.HasSuffix(b.String(), want) {
+		t.Errorf("Expected a repository without a remote or branch listed by path, got:\n%s", b.String())
+	}
+}
+
+func TestDiffLock(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+	root := t.TempDir()
+	config := &types.Config{Workers: 2, Recursive: true, ExcludeDirs: []string{".git"}}
+
+	for _, name := range []string{"api", "web", "docs", "legacy"} {
+		dir := filepath.Join(root, name)
+		runGit(t, root, "init", "--quiet", "--initial-branch=main", dir)
+		commitFile(t, dir, "a.txt", "a\n")
+		commitFile(t, dir, "b.txt", "b\n")
+	}
+	old, err := Snapshot(t.Context(), config, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// api gains two commits, web moves to a new branch and drops one, legacy goes and tools comes
+	commitFile(t, filepath.Join(root, "api"), "c.txt", "c\n")
+	commitFile(t, filepath.Join(root, "api"), "d.txt", "d\n")
+	runGit(t, filepath.Join(root, "web"), "switch", "--quiet", "--create", "feature", "HEAD~1")
+	if err := os.RemoveAll(filepath.Join(root, "legacy")); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, root, "init", "--quiet", filepath.Join(root, "tools"))
+	commitFile(t, filepath.Join(root, "tools"), "a.txt", "a\n")
+
+	changes, err := DiffLock(t.Context(), config, root, old)
+	if err != nil {
+		t.Fatalf("DiffLock() error = %v", err)
+	}
+	got := make(map[string]LockChange)
+	var order []string
+	for _, change := range changes {
+		got[change.Path] = change
+		order = append(order, change.Path)
+	}
+	if want := []string{"api", "docs", "web", "tools", "legacy"}; !slices.Equal(order, want) {
+		t.Errorf("Expected the changes in order %v, got %v", want, order)
+	}
+	if api := got["api"]; !api.Changed() || api.Ahead != 2 || api.Behind != 0 {
+		t.Errorf("Expected api 2 commits ahead, got %+v", api)
+	}
+	if web := got["web"]; web.New.Branch != "feature" || web.Ahead != 0 || web.Behind != 1 {
+		t.Errorf("Expected web on feature with 1 commit dropped, got %+v", web)
+	}
+	if got["docs"].Changed() || got["tools"].Old != nil || got["legacy"].New != nil {
+		t.Errorf("Expected docs unchanged, tools new and legacy gone, got %+v", changes)
+	}
+}
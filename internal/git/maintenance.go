@@ -0,0 +1,123 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// maintainRepo prunes remote-tracking branches whose branch is gone from the configured remote,
+// then runs git gc --auto and, with --repack, a full repack. The pruned branches and the object
+// store before and after are recorded on repo. A repository without the remote is only
+// garbage-collected, and skipped with --prune-only. In dry-run mode only the pruning is planned.
+func (p *Processor) maintainRepo(ctx context.Context, repo *types.GitRepo) error {
+	if repo.Remote == p.remoteName() {
+		networkStart := time.Now()
+		err := p.pruneRemote(ctx, repo)
+		repo.Timings.Network = time.Since(networkStart)
+		if err != nil {
+			return err
+		}
+	} else if p.config.PruneOnly {
+		return fmt.Errorf("no remote named %q (skipped)", p.remoteName())
+	}
+
+	if p.config.PruneOnly || p.config.DryRun {
+		return nil
+	}
+
+	before, err := p.countObjects(ctx, repo.Path)
+	if err != nil {
+		return err
+	}
+	// A detached gc would still be running when the objects are counted again
+	if output, err := p.gitCommand(ctx, repo.Path, "-c", "gc.autoDetach=false", "gc", "--auto", "--quiet").CombinedOutput(); err != nil {
+		return fmt.Errorf("gc failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	if p.config.Repack {
+		if output, err := p.gitCommand(ctx, repo.Path, "repack", "-a", "-d", "--quiet").CombinedOutput(); err != nil {
+			return fmt.Errorf("repack failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+	}
+	after, err := p.countObjects(ctx, repo.Path)
+	if err != nil {
+		return err
+	}
+
+	repo.ObjectsBefore, repo.ObjectsAfter = before, after
+	return nil
+}
+
+// pruneRemote removes the remote-tracking branches of the configured remote whose branch no
+// longer exists there, recording them in repo.PrunedRefs
+func (p *Processor) pruneRemote(ctx context.Context, repo *types.GitRepo) error {
+	args := []string{"remote", "prune", p.remoteName()}
+	if p.config.DryRun {
+		args = []string{"remote", "prune", "--dry-run", p.remoteName()}
+	}
+
+	output, err := p.gitCommand(ctx, repo.Path, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("prune failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	repo.PrunedRefs = parsePruned(string(output))
+	return nil
+}
+
+// parsePruned extracts the refs from git remote prune output, whose lines read
+// " * [pruned] origin/feature" or " * [would prune] origin/feature"
+func parsePruned(output string) []string {
+	var refs []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "* [")
+		if !ok {
+			continue
+		}
+		if _, ref, ok := strings.Cut(line, "] "); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// countObjects reads the size of the repository's object store from git count-objects
+func (p *Processor) countObjects(ctx context.Context, dir string) (types.ObjectStats, error) {
+	output, err := p.gitCommand(ctx, dir, "count-objects", "-v").Output()
+	if err != nil {
+		return types.ObjectStats{}, fmt.Errorf("failed to count objects: %w", err)
+	}
+	return parseCountObjects(string(output))
+}
+
+// parseCountObjects parses the "key: value" lines of git count-objects -v
+func parseCountObjects(output string) (types.ObjectStats, error) {
+	var stats types.ObjectStats
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ": ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return types.ObjectStats{}, fmt.Errorf("unexpected count-objects output %q", scanner.Text())
+		}
+		switch key {
+		case "count":
+			stats.Loose = int(n)
+		case "in-pack":
+			stats.Packed = int(n)
+		case "packs":
+			stats.Packs = int(n)
+		case "size", "size-pack", "size-garbage":
+			stats.SizeKiB += n
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestParsePruned(t *testing.T) {
+	t.Parallel()
+
+	output := "Pruning origin\nURL: git@github.com:org/repo.git\n * [pruned] origin/feature\n * [would prune] origin/fix/typo\n"
+	if got, want := parsePruned(output), []string{"origin/feature", "origin/fix/typo"}; !slices.Equal(got, want) {
+		t.Errorf("parsePruned() = %v, want %v", got, want)
+	}
+	if got := parsePruned(""); len(got) != 0 {
+		t.Errorf("Expected nothing pruned, got %v", got)
+	}
+}
+
+func TestParseCountObjects(t *testing.T) {
+	t.Parallel()
+
+	output := "count: 12\nsize: 48\nin-pack: 300\npacks: 2\nsize-pack: 1024\nprune-packable: 0\ngarbage: 0\nsize-garbage: 4\n"
+	stats, err := parseCountObjects(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := types.ObjectStats{Loose: 12, Packed: 300, Packs: 2, SizeKiB: 1076}
+	if stats != want {
+		t.Errorf("parseCountObjects() = %+v, want %+v", stats, want)
+	}
+	if _, err := parseCountObjects("count: many\n"); err == nil {
+		t.Error("Expected an error for unexpected output")
+	}
+}
+
+func TestProcessor_ProcessRepo_Maintenance(t *testing.T) {
+	requireGit(t)
+
+	upstream := t.TempDir()
+	initTestRepo(t, upstream)
+
+	path := t.TempDir()
+	gitRepo := initTestRepo(t, path)
+	if _, err := gitRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{upstream}}); err != nil {
+		t.Fatal(err)
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := plumbing.NewRemoteReferenceName("origin", "gone")
+	if err := gitRepo.Storer.SetReference(plumbing.NewHashReference(stale, head.Hash())); err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun := &types.Config{Operation: types.OperationMaintenance, Remote: "origin", DryRun: true}
+	planned := NewProcessor(dryRun).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if planned.Error != nil || !slices.Equal(planned.PrunedRefs, []string{"origin/gone"}) {
+		t.Fatalf("Expected origin/gone to be planned for pruning, got %v (%v)", planned.PrunedRefs, planned.Error)
+	}
+	if _, err := gitRepo.Reference(stale, false); err != nil {
+		t.Fatalf("Expected the dry run to keep origin/gone, got %v", err)
+	}
+
+	config := &types.Config{Operation: types.OperationMaintenance, Remote: "origin", Repack: true}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if result.Error != nil {
+		t.Fatalf("Maintenance failed: %v", result.Error)
+	}
+	if !slices.Equal(result.PrunedRefs, []string{"origin/gone"}) {
+		t.Errorf("Expected origin/gone to be pruned, got %v", result.PrunedRefs)
+	}
+	if _, err := gitRepo.Reference(stale, false); err == nil {
+		t.Error("Expected origin/gone to be removed")
+	}
+	if result.ObjectsBefore.Objects() == 0 || result.ObjectsAfter.Packs != 1 || result.ObjectsAfter.Loose != 0 {
+		t.Errorf("Expected the repack to leave everything in one pack, got %+v -> %+v", result.ObjectsBefore, result.ObjectsAfter)
+	}
+}
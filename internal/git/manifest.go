@@ -0,0 +1,86 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// detectManifests looks for well-known dependency manifests at the repository root
+// and extracts the ecosystem and project name from each one it can parse
+func detectManifests(root string) []types.Manifest {
+	var manifests []types.Manifest
+
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		manifests = append(manifests, types.Manifest{
+			Ecosystem: "go",
+			File:      "go.mod",
+			Name:      goModulePath(data),
+		})
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		manifest := types.Manifest{Ecosystem: "node", File: "package.json"}
+		var pkg struct {
+			Name    string            `json:"name"`
+			Engines map[string]string `json:"engines"`
+		}
+		if err := json.Unmarshal(data, &pkg); err == nil {
+			manifest.Name = pkg.Name
+			manifest.Engines = pkg.Engines
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "pyproject.toml")); err == nil {
+		manifest := types.Manifest{Ecosystem: "python", File: "pyproject.toml"}
+		var project struct {
+			Project struct {
+				Name           string `toml:"name"`
+				RequiresPython string `toml:"requires-python"`
+			} `toml:"project"`
+			Tool struct {
+				Poetry struct {
+					Name string `toml:"name"`
+				} `toml:"poetry"`
+			} `toml:"tool"`
+		}
+		if err := toml.Unmarshal(data, &project); err == nil {
+			manifest.Name = project.Project.Name
+			if manifest.Name == "" {
+				manifest.Name = project.Tool.Poetry.Name
+			}
+			if project.Project.RequiresPython != "" {
+				manifest.Engines = map[string]string{"python": project.Project.RequiresPython}
+			}
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	return manifests
+}
+
+// goModulePath returns the module path declared in a go.mod file
+func goModulePath(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "module" {
+			continue
+		}
+		path := fields[1]
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		return path
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectManifests(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":         "// comment\nmodule github.com/example/service\n\ngo 1.25\n",
+		"package.json":   `{"name": "@example/web", "engines": {"node": ">=20"}}`,
+		"pyproject.toml": "[project]\nname = \"example-tools\"\nrequires-python = \">=3.11\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	manifests := detectManifests(tmpDir)
+	if len(manifests) != 3 {
+		t.Fatalf("Expected 3 manifests, got %d: %+v", len(manifests), manifests)
+	}
+
+	expected := []struct {
+		ecosystem string
+		name      string
+		engine    string
+		version   string
+	}{
+		{"go", "github.com/example/service", "", ""},
+		{"node", "@example/web", "node", ">=20"},
+		{"python", "example-tools", "python", ">=3.11"},
+	}
+
+	for i, want := range expected {
+		got := manifests[i]
+		if got.Ecosystem != want.ecosystem {
+			t.Errorf("Manifest %d: expected ecosystem %q, got %q", i, want.ecosystem, got.Ecosystem)
+		}
+		if got.Name != want.name {
+			t.Errorf("Manifest %d: expected name %q, got %q", i, want.name, got.Name)
+		}
+		if want.engine != "" && got.Engines[want.engine] != want.version {
+			t.Errorf("Manifest %d: expected engine %s %q, got %q", i, want.engine, want.version, got.Engines[want.engine])
+		}
+	}
+}
+
+func TestDetectManifests_PoetryAndInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "pyproject.toml"), []byte("[tool.poetry]\nname = \"legacy\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pyproject.toml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	manifests := detectManifests(tmpDir)
+	if len(manifests) != 2 {
+		t.Fatalf("Expected 2 manifests, got %d", len(manifests))
+	}
+
+	// Unparseable manifests still identify the ecosystem
+	if manifests[0].Ecosystem != "node" || manifests[0].Name != "" {
+		t.Errorf("Expected unnamed node manifest, got %+v", manifests[0])
+	}
+	if manifests[1].Name != "legacy" {
+		t.Errorf("Expected poetry project name 'legacy', got %q", manifests[1].Name)
+	}
+}
+
+func TestDetectManifests_None(t *testing.T) {
+	if manifests := detectManifests(t.TempDir()); len(manifests) != 0 {
+		t.Errorf("Expected no manifests, got %+v", manifests)
+	}
+}
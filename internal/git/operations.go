@@ -2,27 +2,67 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 
+	"github.com/entro314-labs/git-herd/internal/auditlog"
+	"github.com/entro314-labs/git-herd/internal/console"
+	"github.com/entro314-labs/git-herd/internal/history"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
 // Processor handles git operations on repositories
 type Processor struct {
-	config *types.Config
+	config  *types.Config
+	limiter *RateLimiter
+	history *history.Store
+	audit   *auditlog.Log     // Where mutating operations are recorded, nil without --audit-log
+	printer *console.Printer  // Verbose progress messages, shared with whoever prints the results
+	shared  *sharedRepos      // Network turns of worktrees sharing a repository
+	safe    *safeDirectories  // Entries of git's safe.directory
+	probed  *preflightResults // Repositories whose remote failed --preflight
+	started time.Time         // When the run started, to group its outcomes in the history
+	steps   []pipelineStep    // Steps of the pipeline, nil without one
+
+	// Pipeline and position, from 1, of the step a processor runs, recorded in the history
+	pipeline string
+	step     int
 }
 
+var _ types.RepoOperator = (*Processor)(nil)
+
 // NewProcessor creates a new git operations processor
 func NewProcessor(config *types.Config) *Processor {
-	return &Processor{
-		config: config,
+	installPooledTransport(config)
+
+	p := &Processor{
+		config:  config,
+		limiter: NewRateLimiter(),
+		history: loadHistory(config),
+		audit:   loadAuditLog(config),
+		printer: console.Stdout,
+		shared:  newSharedRepos(),
+		safe:    &safeDirectories{},
+		probed:  &preflightResults{},
+		started: time.Now(),
+	}
+	p.steps = p.newSteps()
+	return p
+}
+
+// SetPrinter sends the processor's verbose progress messages to printer, so they share one
+// synchronized output with the results
+func (p *Processor) SetPrinter(printer *console.Printer) {
+	p.printer = printer
+	for _, step := range p.steps {
+		step.printer = printer
 	}
 }
 
@@ -31,70 +71,155 @@ func (p *Processor) AnalyzeRepo(repo *types.GitRepo) {
 	start := time.Now()
 	defer func() {
 		repo.Duration = time.Since(start)
+		repo.Timings.Analyze += repo.Duration
 	}()
 
-	gitRepo, err := gogit.PlainOpen(repo.Path)
+	gitRepo, err := openRepo(repo.Path)
 	if err != nil {
 		repo.Error = fmt.Errorf("failed to open repository: %w", err)
 		return
 	}
+	if dir := gitDir(repo.Path); dir != filepath.Join(repo.Path, ".git") && dir != repo.Path {
+		repo.GitDir = dir
+	}
+	repo.Worktree = isLinkedWorktree(repo.Path)
 
-	// Get current branch
+	// Get current branch. A freshly initialized repository has an unborn HEAD that names a
+	// branch without any commits yet; such repositories are empty rather than broken.
 	head, err := gitRepo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		head, err = gitRepo.Storer.Reference(plumbing.HEAD)
+		repo.Empty = err == nil
+	}
 	if err != nil {
 		repo.Error = fmt.Errorf("failed to get HEAD: %w", err)
 		return
 	}
 
-	if head.Name().IsBranch() {
-		repo.Branch = head.Name().Short()
+	branch := head.Name()
+	if repo.Empty {
+		branch = head.Target()
+	}
+
+	if branch.IsBranch() {
+		repo.Branch = branch.Short()
+		if cfg, err := gitRepo.Config(); err == nil {
+			repo.Upstream = branchUpstream(cfg, repo.Branch)
+		}
 	} else {
 		repo.Branch = "detached"
 	}
 
 	// Get last commit information
-	commit, err := gitRepo.CommitObject(head.Hash())
-	if err == nil {
-		repo.LastCommit = head.Hash().String()[:8] // Short hash
-		repo.LastCommitMsg = strings.Split(commit.Message, "\n")[0] // First line only
+	if !repo.Empty {
+		commit, err := gitRepo.CommitObject(head.Hash())
+		if err == nil {
+			repo.LastCommit = head.Hash().String()[:8]                  // Short hash
+			repo.LastCommitMsg = strings.Split(commit.Message, "\n")[0] // First line only
+		}
 	}
 
-	// Check working tree status
+	// Check working tree status; a bare repository has none, so nothing in it can be changed
+	repo.ModifiedFiles = []string{}
+	repo.DirtySince = time.Time{}
 	worktree, err := gitRepo.Worktree()
-	if err != nil {
+	repo.Bare = errors.Is(err, gogit.ErrIsBareRepository)
+	if repo.Bare {
+		repo.Clean = true
+	} else if err != nil {
 		repo.Error = fmt.Errorf("failed to get worktree: %w", err)
 		return
-	}
-
-	status, err := worktree.Status()
-	if err != nil {
-		repo.Error = fmt.Errorf("failed to get status: %w", err)
-		return
-	}
+	} else {
+		status, err := worktree.Status()
+		if err != nil {
+			repo.Error = fmt.Errorf("failed to get status: %w", err)
+			return
+		}
 
-	repo.Clean = status.IsClean()
+		repo.Clean = status.IsClean()
 
-	// Collect modified files
-	repo.ModifiedFiles = []string{}
-	for file, fileStatus := range status {
-		if fileStatus.Worktree != gogit.Unmodified || fileStatus.Staging != gogit.Unmodified {
-			repo.ModifiedFiles = append(repo.ModifiedFiles, file)
+		// Collect modified files, noting how long the oldest change has been sitting there
+		for file, fileStatus := range status {
+			if fileStatus.Worktree != gogit.Unmodified || fileStatus.Staging != gogit.Unmodified {
+				repo.ModifiedFiles = append(repo.ModifiedFiles, file)
+				repo.DirtySince = oldest(repo.DirtySince, modTime(filepath.Join(repo.Path, file)))
+			}
 		}
 	}
 
 	// Get remote information
 	remotes, err := gitRepo.Remotes()
-	if err == nil && len(remotes) > 0 {
-		repo.Remote = remotes[0].Config().Name
+	if err == nil {
+		repo.Remotes = listRemotes(remotes, p.remoteName())
+		if len(repo.Remotes) > 0 {
+			repo.Remote = repo.Remotes[0].Name
+			repo.RemoteURL = repo.Remotes[0].URL
+		}
 	}
 }
 
-// ProcessRepo performs the git operation on a single repository
-func (p *Processor) ProcessRepo(ctx context.Context, repo types.GitRepo) types.GitRepo {
+// modTime returns the modification time of path, or the zero time if it no longer exists
+func modTime(path string) time.Time {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// oldest returns the earlier of two times, ignoring zero times
+func oldest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// ProcessRepo performs the git operation on a single repository. A panic while processing
+// (go-git occasionally panics on malformed repositories) is returned as a *PanicError on
+// that repository's result instead of crashing the run.
+//
+// Each outcome is recorded in the history store, which also tells whether the repository is
+// flaky, and with a per-repository timeout in effect the repository fails once it runs longer
+// than that. With a pipeline, its steps are run and recorded instead.
+func (p *Processor) ProcessRepo(ctx context.Context, repo types.GitRepo) (result types.GitRepo) {
+	if len(p.steps) > 0 {
+		return p.runPipeline(ctx, repo)
+	}
+
 	start := time.Now()
 	defer func() {
-		repo.Duration = time.Since(start)
+		result.Duration = time.Since(start)
+		p.recordHistory(result)
+		p.recordAudit(result)
+		result.Flaky = p.isFlaky(result.Path)
 	}()
+	defer recoverRepo(&result, repo)
+
+	ctx, timeout, cancel := p.withRepoTimeout(ctx, repo.Path)
+	defer cancel()
+
+	result = p.processRepo(ctx, repo)
+	if result.Error != nil && errors.Is(context.Cause(ctx), errRepoTimeout) {
+		result.Error = fmt.Errorf("timed out after %v: %w", timeout, result.Error)
+	}
+	result.Error = explainFileLimit(result.Error)
+	return result
+}
+
+// processRepo analyzes a repository and runs the configured operation on it
+func (p *Processor) processRepo(ctx context.Context, repo types.GitRepo) types.GitRepo {
+	// The disk may fill up during the run, so every repository checks again before downloading
+	if err := CheckFreeSpace(p.config, repo.Path); err != nil {
+		repo.Error = err
+		return repo
+	}
+
+	// There is nothing to analyze until the repository has been cloned
+	if p.config.Operation == types.OperationClone {
+		p.cloneRepo(ctx, &repo)
+		return repo
+	}
 
 	// Analyze repo first (moved from scanning phase for better performance)
 	p.AnalyzeRepo(&repo)
@@ -103,15 +228,57 @@ func (p *Processor) ProcessRepo(ctx context.Context, repo types.GitRepo) types.G
 		return repo
 	}
 
+	// go-git does not mind who owns a repository but the git CLI does, so one it would refuse is
+	// refused up front, whichever of them the operation would have used
+	if err := p.checkOwnership(ctx, &repo); err != nil {
+		repo.Error = err
+		return repo
+	}
+
+	// Protected repositories only ever get read-only operations
+	protected := p.isProtected(repo.Path)
+	if protected && p.config.Operation.IsMutating() {
+		repo.Error = fmt.Errorf("protected repository: %s not allowed (policy skipped)", p.config.Operation)
+		return repo
+	}
+	if err := p.checkSharedWorkspace(repo); err != nil {
+		repo.Error = err
+		return repo
+	}
+
+	// A bare repository is fetched, but there is no worktree to pull into or check out
+	if repo.Bare && !p.config.Operation.WorksOnBare() {
+		repo.Error = fmt.Errorf("bare repository: %s needs a worktree (skipped)", p.config.Operation)
+		return repo
+	}
+
+	// Fetch and pull need a commit to work from, and there is nothing to discard changes against
+	if repo.Empty && !p.config.Operation.IsAnalysis() && p.config.Operation != types.OperationExec &&
+		p.config.Operation != types.OperationSetURL {
+		repo.Error = errors.New("empty repository: no commits yet (skipped)")
+		return repo
+	}
+
+	// Pulling into a locked repository only adds ciphertext that shows up as noise
+	if p.config.SkipLocked && p.config.Operation == types.OperationPull {
+		if err := p.checkUnlocked(&repo); err != nil {
+			repo.Error = err
+			return repo
+		}
+	}
+
 	// Discard specific files if configured
-	if len(p.config.DiscardFiles) > 0 && !repo.Clean {
-		gitRepo, err := gogit.PlainOpen(repo.Path)
+	if len(p.config.DiscardFiles) > 0 && !repo.Clean && !protected {
+		gitRepo, err := openRepo(repo.Path)
 		if err != nil {
 			repo.Error = fmt.Errorf("failed to open repository for discard: %w", err)
 			return repo
 		}
 
-		if err := p.discardFiles(gitRepo, &repo); err != nil {
+		discardStart := time.Now()
+		err = p.discardFiles(ctx, gitRepo, &repo)
+		repo.Timings.Discard = time.Since(discardStart)
+		if err != nil {
 			repo.Error = fmt.Errorf("failed to discard files: %w", err)
 			return repo
 		}
@@ -120,44 +287,254 @@ func (p *Processor) ProcessRepo(ctx context.Context, repo types.GitRepo) types.G
 		p.AnalyzeRepo(&repo)
 	}
 
-	// Skip dirty repos if configured (but not for scan operation)
-	if p.config.SkipDirty && !repo.Clean && p.config.Operation != types.OperationScan {
+	// Skip dirty repos if configured (but not for analysis operations, maintenance, branch
+	// pruning and URL rewrites that leave the working tree alone, commands run with exec,
+	// version checks that only check out a tag into a clean tree, or when their changes are
+	// about to be stashed)
+	if p.config.SkipDirty && !repo.Clean && !p.config.Operation.IsAnalysis() &&
+		p.config.Operation != types.OperationMaintenance && p.config.Operation != types.OperationPruneBranches &&
+		p.config.Operation != types.OperationSetURL && p.config.Operation != types.OperationExec &&
+		p.config.Operation != types.OperationVersions && !p.stashesDirty() {
 		repo.Error = fmt.Errorf("repository has uncommitted changes (skipped)")
 		return repo
 	}
 
+	// Analysis operations only read the repository, so they run even in dry-run mode
+	if p.config.Operation.IsAnalysis() {
+		if p.config.Operation == types.OperationStatus {
+			if err := p.fetchBeforeStatus(ctx, &repo); err != nil {
+				repo.Error = err
+				return repo
+			}
+		}
+
+		analysisStart := time.Now()
+		p.runAnalysis(ctx, &repo)
+		repo.Timings.Analyze += time.Since(analysisStart)
+		return repo
+	}
+
+	// Checkout is local; the remote only matters for the branches it creates
+	if p.config.Operation == types.OperationCheckout && repo.Pin != nil {
+		if err := p.checkoutPin(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	}
+	if p.config.Operation == types.OperationCheckout {
+		gitRepo, err := openRepo(repo.Path)
+		if err != nil {
+			repo.Error = fmt.Errorf("failed to open repository: %w", err)
+			return repo
+		}
+		if err := p.checkoutBranch(gitRepo, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	}
+
+	// So are stashing, popping, branch pruning, rewriting remote URLs, running commands, healing
+	// detached HEADs and checking versions; maintenance only needs the remote for pruning, and
+	// apply for pushing its branch
+	switch p.config.Operation {
+	case types.OperationStash:
+		if err := p.stashChanges(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationStashPop:
+		if err := p.popStash(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationMaintenance:
+		if err := p.maintainRepo(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationPruneBranches:
+		if err := p.pruneBranches(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationSetURL:
+		if err := p.setRemoteURLs(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationExec:
+		if err := p.execCommand(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationApply:
+		if err := p.applyChange(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationHeal:
+		if err := p.healDetached(ctx, &repo); err != nil {
+			repo.Error = err
+		}
+		return repo
+	case types.OperationVersions:
+		if err := p.checkVersion(ctx, &repo, protected); err != nil {
+			repo.Error = err
+		}
+		return repo
+	}
+
+	// Preflight already found the remote unreachable, so no worker waits on it again
+	if err := p.probed.get(repo.Path); err != nil {
+		repo.Error = err
+		return repo
+	}
+
+	// Repositories without the configured remote have nothing to fetch from
+	if repo.Remote != p.remoteName() {
+		repo.Error = fmt.Errorf("no remote named %q (skipped)", p.remoteName())
+		return repo
+	}
+
+	// Branch config is local state, so protected repositories keep theirs
+	setUpstream := p.config.SetUpstream && !protected
+
 	if p.config.DryRun {
+		if p.config.Operation == types.OperationSync {
+			if err := p.syncDefaultBranch(ctx, &repo); err != nil {
+				repo.Error = err
+			}
+			return repo
+		}
+		if p.config.Operation == types.OperationPush {
+			gitRepo, err := openRepo(repo.Path)
+			if err != nil {
+				repo.Error = fmt.Errorf("failed to open repository: %w", err)
+				return repo
+			}
+			if err := p.pushRepo(ctx, gitRepo, &repo); err != nil {
+				repo.Error = err
+			}
+			return repo
+		}
+		if setUpstream {
+			if err := p.setMissingUpstream(&repo); err != nil {
+				repo.Error = fmt.Errorf("failed to set upstream: %w", err)
+			}
+		}
+		// Nothing is stashed, so there is nothing to restore either
+		if p.config.Operation == types.OperationPull && p.config.AutoStash && !repo.Clean {
+			repo.Stashed, repo.Unstashed = true, true
+		}
 		return repo
 	}
 
-	gitRepo, err := gogit.PlainOpen(repo.Path)
+	gitRepo, err := openRepo(repo.Path)
 	if err != nil {
 		repo.Error = fmt.Errorf("failed to open repository: %w", err)
 		return repo
 	}
 
+	// Worktrees of one repository take turns on the network, and the repository is fetched once
+	shared := sharedRepoKey(repo.Path)
+	unlock := p.shared.lock(shared)
+	defer unlock()
+
+	networkStart := time.Now()
 	switch p.config.Operation {
 	case types.OperationFetch:
-		err = p.fetchRepo(ctx, gitRepo)
+		if name, ok := p.shared.fetchedWith(shared); ok {
+			repo.FetchedWith = name
+			break
+		}
+		if err = p.fetchRepo(ctx, gitRepo, &repo); err == nil {
+			p.shared.markFetched(shared, repo.Name)
+		}
 	case types.OperationPull:
-		err = p.pullRepo(ctx, gitRepo)
-	case types.OperationScan:
-		// Scan operation - analysis already done in AnalyzeRepo
-		return repo
+		err = p.withAutostash(ctx, &repo, func() error {
+			return p.pullRepo(ctx, gitRepo, &repo)
+		})
+	case types.OperationPush:
+		err = p.pushRepo(ctx, gitRepo, &repo)
+	case types.OperationSync:
+		err = p.syncDefaultBranch(ctx, &repo)
 	}
+	if err == nil {
+		err = p.updateSubmodules(ctx, &repo)
+	}
+	if err == nil {
+		err = p.fetchLFS(ctx, &repo)
+	}
+	repo.Timings.Network = time.Since(networkStart)
 
 	if err != nil {
 		repo.Error = err
+		return repo
+	}
+
+	// After a fetch the remote-tracking branch to point the upstream at is known to be current
+	if setUpstream {
+		if err := p.setMissingUpstream(&repo); err != nil {
+			repo.Error = fmt.Errorf("failed to set upstream: %w", err)
+		}
 	}
 
 	return repo
 }
 
+// runAnalysis performs the read-only work of analysis operations
+func (p *Processor) runAnalysis(ctx context.Context, repo *types.GitRepo) {
+	switch p.config.Operation {
+	case types.OperationScan:
+		repo.CISystems = detectCISystems(repo.Path)
+		p.readDepth(ctx, repo)
+		if p.config.Manifests {
+			repo.Manifests = detectManifests(repo.Path)
+		}
+		gitRepo, err := openRepo(repo.Path)
+		if err != nil {
+			repo.Error = fmt.Errorf("failed to open repository: %w", err)
+			return
+		}
+		repo.Encryption, repo.Locked = detectEncryption(repo.Path, gitRepo)
+		if p.config.SecurityCheck {
+			repo.Findings = securityFindings(repo.Path, gitRepo)
+		}
+		if p.config.ExportDiffs && !repo.Clean && !repo.Empty {
+			p.collectDiffs(ctx, repo)
+		}
+	case types.OperationAuditFiles:
+		p.auditFiles(repo)
+	case types.OperationAuditEmail:
+		p.auditEmail(ctx, repo)
+	case types.OperationStatus:
+		p.readStatus(ctx, repo)
+	case types.OperationChangelog:
+		p.readCommits(ctx, repo)
+	case types.OperationReleases:
+		p.readRelease(ctx, repo)
+	}
+
+	if p.config.OwnersMonths > 0 && repo.Error == nil && !repo.Empty {
+		p.readOwners(ctx, repo)
+	}
+}
+
 // fetchRepo performs git fetch on a repository
-func (p *Processor) fetchRepo(ctx context.Context, repo *gogit.Repository) error {
-	err := repo.FetchContext(ctx, &gogit.FetchOptions{
-		RemoteName: "origin",
-		Progress:   nil, // We could add progress reporting here
+func (p *Processor) fetchRepo(ctx context.Context, gitRepo *gogit.Repository, repo *types.GitRepo) error {
+	if p.shapesHistory() {
+		if err := p.fetchDepth(ctx, gitRepo, repo); err != nil {
+			return fmt.Errorf("fetch failed: %w", err)
+		}
+		p.readDepth(ctx, repo)
+		return nil
+	}
+
+	err := p.withRateLimitRetry(ctx, gitRepo, repo.Name, func() error {
+		return gitRepo.FetchContext(ctx, &gogit.FetchOptions{
+			RemoteName: p.remoteName(),
+			Progress:   nil, // We could add progress reporting here
+		})
 	})
 
 	if err != nil && err != gogit.NoErrAlreadyUpToDate {
@@ -167,17 +544,36 @@ func (p *Processor) fetchRepo(ctx context.Context, repo *gogit.Repository) error
 	return nil
 }
 
-// pullRepo performs git pull on a repository
-func (p *Processor) pullRepo(ctx context.Context, repo *gogit.Repository) error {
-	worktree, err := repo.Worktree()
+// pullRepo performs git pull on a repository. go-git only fast-forwards, so a branch that has
+// diverged from the remote is merged or rebased with the git CLI when --pull-strategy asks for it.
+func (p *Processor) pullRepo(ctx context.Context, gitRepo *gogit.Repository, repo *types.GitRepo) error {
+	worktree, err := gitRepo.Worktree()
 	if err != nil {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	err = worktree.PullContext(ctx, &gogit.PullOptions{
-		RemoteName: "origin",
-		Progress:   nil,
-	})
+	if p.shapesHistory() {
+		before := p.trackingTip(ctx, repo)
+		if err := p.fetchDepth(ctx, gitRepo, repo); err != nil {
+			return fmt.Errorf("pull failed: %w", err)
+		}
+		defer p.readDepth(ctx, repo)
+		err = p.fastForward(ctx, repo, before)
+	} else {
+		err = p.withRateLimitRetry(ctx, gitRepo, repo.Name, func() error {
+			return worktree.PullContext(ctx, &gogit.PullOptions{
+				RemoteName: p.remoteName(),
+				Progress:   nil,
+			})
+		})
+	}
+
+	if errors.Is(err, gogit.ErrNonFastForwardUpdate) {
+		if p.config.PullStrategy == types.PullMerge || p.config.PullStrategy == types.PullRebase {
+			return p.pullDiverged(ctx, repo)
+		}
+		return errors.New("diverged from the remote, not pulling with --pull-strategy ff-only (skipped)")
+	}
 
 	if err != nil && err != gogit.NoErrAlreadyUpToDate {
 		return fmt.Errorf("pull failed: %w", err)
@@ -186,8 +582,36 @@ func (p *Processor) pullRepo(ctx context.Context, repo *gogit.Repository) error
 	return nil
 }
 
+// withRateLimitRetry runs a network operation against the configured remote, pausing every
+// operation on the same host and retrying while the forge reports rate limiting. name is the
+// repository the pauses are reported for.
+func (p *Processor) withRateLimitRetry(ctx context.Context, repo *gogit.Repository, name string, op func() error) error {
+	return p.withHostRateLimitRetry(ctx, remoteHost(repo, p.remoteName()), name, op)
+}
+
+// withHostRateLimitRetry is withRateLimitRetry for an operation against host, for clones that
+// have no repository to read the remote from yet
+func (p *Processor) withHostRateLimitRetry(ctx context.Context, host, name string, op func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := p.limiter.Wait(ctx, host); err != nil {
+			return err
+		}
+
+		err := op()
+		delay, limited := rateLimitDelay(err)
+		if !limited || attempt >= p.config.RateLimitRetries {
+			return err
+		}
+
+		p.limiter.Pause(host, delay)
+		if p.config.Verbose {
+			p.printer.Repof(name, "Rate limited by %s, pausing for %v", host, delay)
+		}
+	}
+}
+
 // discardFiles discards changes to specific files matching the configured patterns
-func (p *Processor) discardFiles(gitRepo *gogit.Repository, repo *types.GitRepo) error {
+func (p *Processor) discardFiles(ctx context.Context, gitRepo *gogit.Repository, repo *types.GitRepo) error {
 	worktree, err := gitRepo.Worktree()
 	if err != nil {
 		return fmt.Errorf("failed to get worktree: %w", err)
@@ -232,9 +656,7 @@ func (p *Processor) discardFiles(gitRepo *gogit.Repository, repo *types.GitRepo)
 	if len(discardedFiles) > 0 {
 		for _, file := range discardedFiles {
 			// Use git command to discard changes to specific file
-			cmd := exec.Command("git", "checkout", "HEAD", "--", file)
-			cmd.Dir = repo.Path
-			cmd.Env = os.Environ()
+			cmd := p.gitCommand(ctx, repo.Path, "checkout", "HEAD", "--", file)
 
 			if output, err := cmd.CombinedOutput(); err != nil {
 				return fmt.Errorf("failed to discard %s: %w (output: %s)", file, err, string(output))
@@ -242,7 +664,7 @@ func (p *Processor) discardFiles(gitRepo *gogit.Repository, repo *types.GitRepo)
 		}
 
 		if p.config.Verbose {
-			fmt.Printf("  Discarded changes in %s: %v\n", repo.Name, discardedFiles)
+			p.printer.Repof(repo.Name, "Discarded changes: %v", discardedFiles)
 		}
 	}
 
@@ -0,0 +1,167 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// initTestRepo creates a repository at path with a single committed README
+func initTestRepo(t *testing.T, path string) *gogit.Repository {
+	t.Helper()
+
+	gitRepo, err := gogit.PlainInit(path, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# test\n"), 0644); err != nil {
+		t.Fatalf("Failed to write README: %v", err)
+	}
+
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("README.md"); err != nil {
+		t.Fatalf("Failed to add README: %v", err)
+	}
+	if _, err := worktree.Commit("initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "git-herd", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	return gitRepo
+}
+
+// requireGit skips the test where the git CLI is missing
+func requireGit(t *testing.T) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git CLI not available")
+	}
+}
+
+// cloneOptions shapes the clone cloneTestRepo makes. Every file is committed on its own, with
+// its name without the extension as its content, e.g. "ours\n" for ours.txt.
+type cloneOptions struct {
+	Commits []string // Files committed to the upstream before it is cloned
+	Depth   int      // Clone only this many commits; 0 clones the complete history
+	Branch  string   // Branch the clone creates and checks out before committing Ahead
+	Ahead   []string // Files committed to the clone, putting it ahead of its upstream
+	Behind  []string // Files committed to the upstream after the clone, putting the clone behind
+}
+
+// cloneTestRepo clones a new repository, shaped by opts, below a new directory and returns the
+// paths of the upstream and the clone. It needs the git CLI and an identity to commit with.
+func cloneTestRepo(t *testing.T, opts cloneOptions) (upstream, clone string) {
+	t.Helper()
+
+	root := t.TempDir()
+	upstream = filepath.Join(root, "upstream")
+	initTestRepo(t, upstream)
+	for _, name := range opts.Commits {
+		commitFile(t, upstream, name, strings.TrimSuffix(name, filepath.Ext(name))+"\n")
+	}
+
+	clone = filepath.Join(root, "clone")
+	if opts.Depth > 0 {
+		// Local clones ignore --depth unless they go through a transport
+		runGit(t, root, "clone", "--quiet", "--depth", strconv.Itoa(opts.Depth), "file://"+upstream, clone)
+	} else {
+		runGit(t, root, "clone", "--quiet", upstream, clone)
+	}
+	if opts.Branch != "" {
+		runGit(t, clone, "checkout", "--quiet", "-b", opts.Branch)
+	}
+	for _, name := range opts.Ahead {
+		commitFile(t, clone, name, strings.TrimSuffix(name, filepath.Ext(name))+"\n")
+	}
+	for _, name := range opts.Behind {
+		commitFile(t, upstream, name, strings.TrimSuffix(name, filepath.Ext(name))+"\n")
+	}
+	return upstream, clone
+}
+
+func TestProcessor_ProcessRepo_Scan(t *testing.T) {
+	tmpDir := t.TempDir()
+	initTestRepo(t, tmpDir)
+
+	config := &types.Config{Operation: types.OperationScan, DryRun: true}
+	repo := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: tmpDir, Name: "scanned", HasGit: true})
+
+	if repo.Error != nil {
+		t.Fatalf("Expected scan to succeed, got %v", repo.Error)
+	}
+	if !repo.Clean {
+		t.Error("Expected freshly committed repository to be clean")
+	}
+	if repo.LastCommitMsg != "initial commit" {
+		t.Errorf("Expected last commit message 'initial commit', got %q", repo.LastCommitMsg)
+	}
+	if len(repo.CISystems) != 0 {
+		t.Errorf("Expected empty CI systems for scanned repo, got %v", repo.CISystems)
+	}
+	if repo.Timings.Analyze <= 0 || repo.Timings.Network != 0 {
+		t.Errorf("Expected only analysis time for a scan, got %+v", repo.Timings)
+	}
+	if !repo.DirtySince.IsZero() {
+		t.Errorf("Expected a clean repository to have no dirty time, got %v", repo.DirtySince)
+	}
+}
+
+func TestProcessor_AnalyzeRepo_DirtySince(t *testing.T) {
+	tmpDir := t.TempDir()
+	initTestRepo(t, tmpDir)
+
+	old := time.Now().Add(-42 * 24 * time.Hour).Truncate(time.Second)
+	if err := os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(tmpDir, "README.md"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := types.GitRepo{Path: tmpDir, Name: "dirty"}
+	NewProcessor(&types.Config{}).AnalyzeRepo(&repo)
+	if repo.Error != nil {
+		t.Fatalf("AnalyzeRepo() error = %v", repo.Error)
+	}
+	if !repo.DirtySince.Equal(old) {
+		t.Errorf("Expected DirtySince to be the oldest change %v, got %v", old, repo.DirtySince)
+	}
+}
+
+func TestProcessor_ProcessRepo_EmptyRepository(t *testing.T) {
+	tmpDir := t.TempDir()
+	if _, err := gogit.PlainInit(tmpDir, false); err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	scanned := NewProcessor(&types.Config{Operation: types.OperationScan}).ProcessRepo(t.Context(), types.GitRepo{Path: tmpDir, Name: "empty"})
+	if scanned.Error != nil {
+		t.Fatalf("Expected scan of an empty repository to succeed, got %v", scanned.Error)
+	}
+	if !scanned.Empty || scanned.Branch != "master" || scanned.LastCommit != "" {
+		t.Errorf("Expected empty repository on unborn master, got empty=%v branch=%q commit=%q", scanned.Empty, scanned.Branch, scanned.LastCommit)
+	}
+
+	fetched := NewProcessor(&types.Config{Operation: types.OperationFetch}).ProcessRepo(t.Context(), types.GitRepo{Path: tmpDir, Name: "empty"})
+	if fetched.Error == nil || fetched.Error.Error() != "empty repository: no commits yet (skipped)" {
+		t.Errorf("Expected fetch of an empty repository to be skipped, got %v", fetched.Error)
+	}
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// maxOwners is how many of a repository's top committers are reported
+const maxOwners = 3
+
+// readOwners records the repository's top committers over the last OwnersMonths months, the
+// people to ask about its failures. git shortlog applies .mailmap, so one person committing
+// under several names or addresses is counted once under their canonical identity.
+func (p *Processor) readOwners(ctx context.Context, repo *types.GitRepo) {
+	since := fmt.Sprintf("--since=%d.months.ago", p.config.OwnersMonths)
+	output, err := p.gitCommand(ctx, repo.Path, "shortlog", "--summary", "--numbered", "--email", "--no-merges", since, "HEAD").Output()
+	if err != nil {
+		repo.Error = fmt.Errorf("failed to read committers: %w", err)
+		return
+	}
+
+	repo.Owners = parseShortlog(string(output), maxOwners)
+}
+
+// parseShortlog reads `git shortlog -sne` output, "<count>\t<name> <email>" per line with the
+// most active committer first, keeping at most limit entries
+func parseShortlog(output string, limit int) []types.Owner {
+	var owners []types.Owner
+	for line := range strings.Lines(output) {
+		count, identity, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		commits, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			continue
+		}
+
+		owner := types.Owner{Name: identity, Commits: commits}
+		if start := strings.LastIndex(identity, " <"); start >= 0 && strings.HasSuffix(identity, ">") {
+			owner.Name = identity[:start]
+			owner.Email = identity[start+2 : len(identity)-1]
+		}
+		owners = append(owners, owner)
+		if len(owners) == limit {
+			break
+		}
+	}
+	return owners
+}
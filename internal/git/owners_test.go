@@ -0,0 +1,71 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestParseShortlog(t *testing.T) {
+	t.Parallel()
+
+	output := "    42\tAda Lovelace <ada@example.com>\n     7\tLinus <linus@example.org>\n     1\tbuild bot\n     1\tnobody <n@example.com>\n"
+	want := []types.Owner{
+		{Name: "Ada Lovelace", Email: "ada@example.com", Commits: 42},
+		{Name: "Linus", Email: "linus@example.org", Commits: 7},
+		{Name: "build bot", Commits: 1},
+	}
+	if got := parseShortlog(output, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseShortlog() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadOwnersHonorsMailmap(t *testing.T) {
+	requireGit(t)
+
+	path := t.TempDir()
+	gitRepo := initTestRepo(t, path)
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, ".mailmap"), []byte("Ada Lovelace <ada@example.com> <ada@old.example>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add(".mailmap"); err != nil {
+		t.Fatal(err)
+	}
+	authors := []object.Signature{
+		{Name: "Ada Lovelace", Email: "ada@example.com"},
+		{Name: "ada", Email: "ada@old.example"},
+		{Name: "ada", Email: "ada@old.example"},
+	}
+	for _, author := range authors {
+		author.When = time.Now()
+		if _, err := worktree.Commit("work", &gogit.CommitOptions{AllowEmptyCommits: true, Author: &author}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := types.GitRepo{Path: path, Name: "repo"}
+	NewProcessor(&types.Config{OwnersMonths: 6}).readOwners(t.Context(), &repo)
+	if repo.Error != nil {
+		t.Fatalf("readOwners() error = %v", repo.Error)
+	}
+
+	want := []types.Owner{
+		{Name: "Ada Lovelace", Email: "ada@example.com", Commits: 3},
+		{Name: "git-herd", Email: "test@example.com", Commits: 1},
+	}
+	if !reflect.DeepEqual(repo.Owners, want) {
+		t.Errorf("Owners = %+v, want %+v", repo.Owners, want)
+	}
+}
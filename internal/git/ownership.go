@@ -0,0 +1,143 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// ErrDubiousOwnership marks a repository owned by another user that git's safe.directory does
+// not trust. The git CLI refuses to work in such a repository while go-git does not care, so
+// without checking, it would fail or not depending on which of them an operation uses.
+var ErrDubiousOwnership = errors.New("dubious ownership")
+
+// checkOwnership fails a repository the git CLI would refuse as owned by another user, unless
+// safe.directory trusts it. With --add-safe-directory, it is added to the global safe.directory
+// instead, as git itself suggests; a dry run only tells, and skips the repository, since git
+// would still refuse it.
+func (p *Processor) checkOwnership(ctx context.Context, repo *types.GitRepo) error {
+	owner, foreign := foreignOwner(repo.Path, gitDir(repo.Path))
+	if !foreign {
+		return nil
+	}
+	dir, err := filepath.Abs(repo.Path)
+	if err != nil {
+		return err
+	}
+	if p.safe.trusts(ctx, p, dir) {
+		return nil
+	}
+	if !p.config.AddSafeDirectory {
+		return fmt.Errorf("%w: owned by uid %d, not the current user; trust it with --add-safe-directory or git config --global --add safe.directory %s (skipped)",
+			ErrDubiousOwnership, owner, dir)
+	}
+
+	if p.config.DryRun {
+		repo.SafeDirectory = true
+		return fmt.Errorf("owned by uid %d, would be added to safe.directory (skipped)", owner)
+	}
+	added, err := p.safe.trust(ctx, p, dir)
+	repo.SafeDirectory = added
+	return err
+}
+
+// configLockRetries is how many times adding to safe.directory is retried while another git
+// process holds the lock on the global config, waiting configLockDelay longer each time
+const (
+	configLockRetries = 5
+	configLockDelay   = 100 * time.Millisecond
+)
+
+// safeDirectories holds the safe.directory entries of the system and global git config, read
+// once per run
+type safeDirectories struct {
+	once sync.Once
+	mu   sync.Mutex
+	dirs []string
+}
+
+// trusts reports whether an entry covers the directory at dir: "*", the directory itself, or
+// a parent ending in "/*". An empty entry clears those before it, as in git.
+func (s *safeDirectories) trusts(ctx context.Context, p *Processor, dir string) bool {
+	s.once.Do(func() {
+		for _, scope := range []string{"--system", "--global"} {
+			// config exits with 1, printing nothing, when the key is not set
+			output, _ := p.gitCommand(ctx, "", "config", scope, "--get-all", "safe.directory").Output()
+			if len(output) == 0 {
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(string(output), "\n"), "\n") {
+				s.add(strings.TrimSpace(line))
+			}
+		}
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.covers(dir)
+}
+
+// trust adds dir to the global safe.directory, reporting whether it did: workers adding
+// repositories at the same time hold the mutex across the write, so they neither fail on each
+// other's lock on the global config nor add an entry twice, and an entry added meanwhile is
+// noticed. A lock held by another git process is waited out a few times before failing.
+func (s *safeDirectories) trust(ctx context.Context, p *Processor, dir string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.covers(dir) {
+		return false, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		output, err := p.gitCommand(ctx, "", "config", "--global", "--add", "safe.directory", dir).CombinedOutput()
+		if err == nil {
+			break
+		}
+		message := strings.TrimSpace(string(output))
+		if !strings.Contains(message, "could not lock config file") {
+			return false, fmt.Errorf("failed to add %s to safe.directory: %w (output: %s)", dir, err, message)
+		}
+		if attempt > configLockRetries {
+			return false, fmt.Errorf("failed to add %s to safe.directory: the global git config stayed locked by another process after %d attempts (output: %s)", dir, attempt, message)
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(time.Duration(attempt) * configLockDelay):
+		}
+	}
+	s.dirs = append(s.dirs, dir)
+	return true, nil
+}
+
+// covers reports whether an entry covers the directory at dir; the caller holds the mutex
+func (s *safeDirectories) covers(dir string) bool {
+	target := filepath.ToSlash(dir)
+	for _, entry := range s.dirs {
+		entry = filepath.ToSlash(entry)
+		if entry == "*" || filepath.Clean(entry) == filepath.Clean(target) {
+			return true
+		}
+		if parent, ok := strings.CutSuffix(entry, "/*"); ok && strings.HasPrefix(target, parent+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// add records an entry, where an empty one clears the entries before it
+func (s *safeDirectories) add(entry string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry == "" {
+		s.dirs = nil
+		return
+	}
+	s.dirs = append(s.dirs, ExpandHome(entry))
+}
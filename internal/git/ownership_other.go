@@ -0,0 +1,9 @@
+//go:build !unix
+
+package git
+
+// foreignOwner reports every path as owned by the current user, since ownership is not told by
+// user ID here
+func foreignOwner(...string) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,168 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// chownTree hands every file below dir to the user uid
+func chownTree(t *testing.T, dir string, uid int) {
+	t.Helper()
+
+	err := filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, -1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessor_ProcessRepo_DubiousOwnership(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("handing a repository to another user needs root")
+	}
+	requireGit(t)
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("SUDO_UID", "")
+
+	repoPath := filepath.Join(t.TempDir(), "shared")
+	initTestRepo(t, repoPath)
+	chownTree(t, repoPath, 12345)
+	dir, err := filepath.Abs(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := types.GitRepo{Path: repoPath, Name: "shared", HasGit: true}
+	result := NewProcessor(&types.Config{Operation: types.OperationStatus}).ProcessRepo(t.Context(), repo)
+	if !errors.Is(result.Error, ErrDubiousOwnership) || !strings.Contains(result.Error.Error(), "skipped") {
+		t.Fatalf("Expected a skip for dubious ownership, got %v", result.Error)
+	}
+
+	config := &types.Config{Operation: types.OperationStatus, AddSafeDirectory: true, DryRun: true}
+	result = NewProcessor(config).ProcessRepo(t.Context(), repo)
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "would be added") || !result.SafeDirectory {
+		t.Fatalf("Expected the dry run to report adding to safe.directory, got %v", result.Error)
+	}
+	if _, err := os.Stat(global); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the dry run to leave the global config alone, got %v", err)
+	}
+
+	config.DryRun = false
+	result = NewProcessor(config).ProcessRepo(t.Context(), repo)
+	if result.Error != nil || !result.SafeDirectory {
+		t.Fatalf("Expected the repository to be added to safe.directory, got %v", result.Error)
+	}
+	if got := runGit(t, "", "config", "--global", "--get-all", "safe.directory"); got != dir {
+		t.Errorf("safe.directory = %q, want %q", got, dir)
+	}
+
+	// Once trusted, the repository is neither skipped nor added again
+	result = NewProcessor(&types.Config{Operation: types.OperationStatus}).ProcessRepo(t.Context(), repo)
+	if result.Error != nil || result.SafeDirectory {
+		t.Errorf("Expected the trusted repository to be processed as is, got %v", result.Error)
+	}
+}
+
+func TestSafeDirectories_TrustConcurrently(t *testing.T) {
+	requireGit(t)
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	// Every worker adds its own repository, and all of them the shared one, at the same time
+	p := NewProcessor(&types.Config{Operation: types.OperationStatus})
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := range 8 {
+		wg.Go(func() {
+			for _, dir := range []string{fmt.Sprintf("/srv/repos/%d", i), "/srv/repos/shared"} {
+				if _, err := p.safe.trust(t.Context(), p, dir); err != nil {
+					errs <- err
+				}
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("trust() error = %v", err)
+	}
+
+	entries := strings.Split(runGit(t, "", "config", "--global", "--get-all", "safe.directory"), "\n")
+	if len(entries) != 9 || !slices.Contains(entries, "/srv/repos/shared") || !slices.Contains(entries, "/srv/repos/7") {
+		t.Errorf("Expected each repository in safe.directory once, got %q", entries)
+	}
+}
+
+func TestSafeDirectories_TrustLockedConfig(t *testing.T) {
+	requireGit(t)
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	// Another git process holds the lock for a while, then releases it
+	if err := os.WriteFile(global+".lock", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(150*time.Millisecond, func() { os.Remove(global + ".lock") })
+	p := NewProcessor(&types.Config{Operation: types.OperationStatus})
+	if added, err := p.safe.trust(t.Context(), p, "/srv/repos/api"); err != nil || !added {
+		t.Fatalf("Expected the entry to be added once the lock is released, got %v (%v)", added, err)
+	}
+
+	// A lock that is never released fails the repository instead of losing the entry
+	if err := os.WriteFile(global+".lock", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(global + ".lock")
+	if _, err := p.safe.trust(t.Context(), p, "/srv/repos/web"); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("Expected a lasting lock to be reported, got %v", err)
+	}
+}
+
+func TestSafeDirectories_Trusts(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    bool
+	}{
+		{"none", nil, false},
+		{"exact", []string{"/srv/repos/api"}, true},
+		{"trailing slash", []string{"/srv/repos/api/"}, true},
+		{"other", []string{"/srv/repos/web"}, false},
+		{"everything", []string{"*"}, true},
+		{"parent", []string{"/srv/repos/*"}, true},
+		{"sibling prefix", []string{"/srv/rep/*"}, false},
+		{"reset", []string{"*", ""}, false},
+		{"after reset", []string{"", "/srv/repos/api"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &safeDirectories{}
+			s.once.Do(func() {}) // Keep the git config of the machine out of it
+			for _, entry := range tt.entries {
+				s.add(entry)
+			}
+			if got := s.trusts(t.Context(), nil, filepath.FromSlash("/srv/repos/api")); got != tt.want {
+				t.Errorf("trusts(%q) = %v, want %v", tt.entries, got, tt.want)
+			}
+		})
+	}
+}
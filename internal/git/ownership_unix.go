@@ -0,0 +1,31 @@
+//go:build unix
+
+package git
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// foreignOwner returns the owner of the first of paths that the current user does not own, as
+// git tells: running as root through sudo, the files of the user who ran sudo count as owned too
+func foreignOwner(paths ...string) (int, bool) {
+	uid := os.Geteuid()
+	sudoUID := -1
+	if uid == 0 {
+		if id, err := strconv.Atoi(os.Getenv("SUDO_UID")); err == nil {
+			sudoUID = id
+		}
+	}
+	for _, path := range paths {
+		var stat syscall.Stat_t
+		if err := syscall.Stat(path, &stat); err != nil {
+			continue
+		}
+		if owner := int(stat.Uid); owner != uid && owner != sudoUID {
+			return owner, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,27 @@
+package git
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// PanicError reports a panic recovered while processing a single repository
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("internal error: panic: %v", e.Value)
+}
+
+// recoverRepo converts a panic in the calling goroutine into an internal error result for repo,
+// so one malformed repository cannot take down the whole run. It must be called via defer.
+func recoverRepo(result *types.GitRepo, repo types.GitRepo) {
+	if r := recover(); r != nil {
+		*result = repo
+		result.Error = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestRecoverRepo(t *testing.T) {
+	repo := types.GitRepo{Name: "broken", Path: "/test/broken"}
+
+	result := func() (result types.GitRepo) {
+		defer recoverRepo(&result, repo)
+		panic("index out of range")
+	}()
+
+	if result.Name != "broken" || result.Path != "/test/broken" {
+		t.Errorf("Expected result to identify the repository, got %+v", result)
+	}
+
+	var panicErr *PanicError
+	if !errors.As(result.Error, &panicErr) {
+		t.Fatalf("Expected a PanicError, got %v", result.Error)
+	}
+	if panicErr.Value != "index out of range" {
+		t.Errorf("Expected panic value to be kept, got %v", panicErr.Value)
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestRecoverRepo") {
+		t.Error("Expected the stack to include the panicking caller")
+	}
+	if msg := result.Error.Error(); msg != "internal error: panic: index out of range" {
+		t.Errorf("Unexpected error message %q", msg)
+	}
+	if strings.Contains(result.Error.Error(), "skipped") {
+		t.Error("Panics must count as failures, not skips")
+	}
+}
+
+func TestRecoverRepoNoPanic(t *testing.T) {
+	repo := types.GitRepo{Name: "healthy"}
+
+	result := func() (result types.GitRepo) {
+		defer recoverRepo(&result, repo)
+		return types.GitRepo{Name: "healthy", Branch: "main"}
+	}()
+
+	if result.Error != nil || result.Branch != "main" {
+		t.Errorf("Expected result to pass through untouched, got %+v", result)
+	}
+}
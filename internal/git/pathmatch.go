@@ -0,0 +1,97 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// matchDir reports whether the directory at dir, below the scan root, matches an --exclude or
+// --include pattern. A pattern without a slash matches the directory's name, e.g. "vendor" or
+// "tmp-*", so "vendor" does not catch "my-vendor-tools". A pattern with a slash matches the
+// path from the root, with "**" standing for any number of segments, e.g. "**/build" or
+// "clients/*/legacy"; an absolute (or ~) pattern matches the absolute path instead.
+func matchDir(pattern, root, dir string) bool {
+	pattern = filepath.ToSlash(strings.TrimSuffix(strings.TrimSpace(pattern), "/"))
+	if pattern == "" {
+		return false
+	}
+
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, filepath.Base(dir))
+		return matched
+	}
+
+	target := dir
+	if strings.HasPrefix(pattern, "/") || strings.HasPrefix(pattern, "~/") {
+		pattern = filepath.ToSlash(ExpandHome(pattern))
+		if abs, err := filepath.Abs(dir); err == nil {
+			target = abs
+		}
+	} else if rel, err := filepath.Rel(root, dir); err == nil {
+		target = rel
+	}
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(strings.Trim(filepath.ToSlash(target), "/"), "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a "**" segment matches
+// zero or more path segments and any other is a path.Match pattern for exactly one
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// included reports whether the repository at dir is selected by --include: it, or a directory
+// above it below the root, matches one of the patterns. Without patterns every repository is.
+func (s *Scanner) included(root, dir string) bool {
+	if len(s.config.IncludeDirs) == 0 {
+		return true
+	}
+	if rel, err := filepath.Rel(root, dir); err != nil || rel == "." {
+		return false
+	}
+	return slices.ContainsFunc(s.config.IncludeDirs, func(pattern string) bool {
+		return matchWithin(pattern, root, dir)
+	})
+}
+
+// filterRepos keeps the repositories whose name or path matches --filter. A glob matches like
+// an --exclude pattern, e.g. "api-*" or "clients/**", or the name, e.g. "acme/api"; a regular
+// expression between slashes matches anywhere in the name or the path from the root.
+func filterRepos(config *types.Config, root string, repos []types.GitRepo) ([]types.GitRepo, error) {
+	re, err := config.FilterRegexp()
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return slices.DeleteFunc(repos, func(repo types.GitRepo) bool {
+		if re == nil {
+			matched, _ := path.Match(filepath.ToSlash(config.Filter), repo.Name)
+			return !matched && !matchDir(config.Filter, root, repo.Path)
+		}
+		rel, err := filepath.Rel(root, repo.Path)
+		if err != nil {
+			rel = repo.Path
+		}
+		return !re.MatchString(repo.Name) && !re.MatchString(filepath.ToSlash(rel))
+	}), nil
+}
@@ -0,0 +1,183 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/internal/policy"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// pipelineStep is a step of the pipeline: the processor running its operation, and the
+// condition a repository must meet for it to run
+type pipelineStep struct {
+	*Processor
+	condition *policy.Condition // nil when the step always runs
+	invalid   error             // Why the condition does not compile, which fails the step
+}
+
+// newSteps creates a processor for each step of the configured pipeline, sharing the rate
+// limiter, history, audit log and the other run-wide state of p
+func (p *Processor) newSteps() []pipelineStep {
+	var steps []pipelineStep
+	for i, s := range p.config.PipelineSteps() {
+		config := *p.config
+		config.Pipeline, config.Operation = "", s.Operation
+		step := pipelineStep{Processor: &Processor{
+			config:   &config,
+			limiter:  p.limiter,
+			history:  p.history,
+			audit:    p.audit,
+			printer:  p.printer,
+			shared:   p.shared,
+			safe:     p.safe,
+			probed:   p.probed,
+			started:  p.started,
+			pipeline: p.config.Pipeline,
+			step:     i + 1,
+		}}
+		if s.Condition != "" {
+			step.condition, step.invalid = policy.Compile(s.Condition)
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// conditionMet reports whether a repository meets the condition of the step as it is now, after
+// the steps before: it is analyzed and its status read again. HEAD moved since before, the
+// commit it was on before the previous step, counts as changed.
+func (s pipelineStep) conditionMet(ctx context.Context, repo types.GitRepo, before string) (bool, error) {
+	if s.invalid != nil {
+		return false, s.invalid
+	}
+	repo.Error = nil
+	s.AnalyzeRepo(&repo)
+	if repo.Error == nil {
+		s.readStatus(ctx, &repo)
+	}
+	if repo.Error != nil {
+		return false, repo.Error
+	}
+	return s.condition.Eval(policy.RepoVars(repo, before != "" && headHash(repo.Path) != before)), nil
+}
+
+// headHash returns the commit HEAD is on, "" when it cannot be read, e.g. without commits
+func headHash(repoPath string) string {
+	gitRepo, err := openRepo(repoPath)
+	if err != nil {
+		return ""
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		return ""
+	}
+	return head.Hash().String()
+}
+
+// resumeFrom returns, with --resume-pipeline, the step to pick the pipeline up at on a
+// repository and when the run it resumes started: the first step the last run of the pipeline on
+// the repository did not get through, because it failed, skipped the repository, e.g. a pull into
+// a dirty tree, or never ran as the run was cut short. When that run got through every step,
+// without one, or when the pipeline's steps have changed since, the pipeline starts over.
+func (p *Processor) resumeFrom(repoPath string) (int, time.Time) {
+	if !p.config.ResumePipeline || p.history == nil {
+		return 0, time.Time{}
+	}
+	records := p.history.Records(repoPath)
+	var last time.Time
+	for _, record := range records {
+		if record.Pipeline == p.config.Pipeline && record.Run.After(last) {
+			last = record.Run
+		}
+	}
+	if last.IsZero() {
+		return 0, last
+	}
+
+	steps := make(map[int]history.Record)
+	for _, record := range records {
+		if record.Pipeline != p.config.Pipeline || !record.Run.Equal(last) {
+			continue
+		}
+		if record.Step < 1 || record.Step > len(p.steps) || p.steps[record.Step-1].config.Operation != record.Operation {
+			return 0, time.Time{}
+		}
+		steps[record.Step] = record
+	}
+	for i := range p.steps {
+		if record, ok := steps[i+1]; !ok || !stepDone(record) {
+			return i, last
+		}
+	}
+	return 0, time.Time{}
+}
+
+// stepDone reports whether a step of a pipeline run left nothing to resume: it succeeded, was
+// passed over as done in an earlier run, or its condition was not met
+func stepDone(record history.Record) bool {
+	return !record.Failed() || strings.HasPrefix(record.Error, "done in the run of ") ||
+		strings.HasPrefix(record.Error, "condition ") && strings.HasSuffix(record.Error, " not met (skipped)")
+}
+
+// runPipeline runs the steps of the pipeline on a repository one after the other, each picking
+// up where the one before left the repository, so what every step did ends up in the result.
+// Each step is recorded in the history as its own operation. A step that fails stops the
+// pipeline; one that skips the repository, e.g. a pull into a dirty tree, does not, and neither
+// does one whose condition the repository does not meet. The result fails with the step that
+// failed, or skips when every step skipped. With --resume-pipeline, the steps the last run got
+// through are passed over as skipped. Steps passed over or whose condition is not met are
+// recorded as skipped, so a later resume can tell them from steps that never ran.
+func (p *Processor) runPipeline(ctx context.Context, repo types.GitRepo) (result types.GitRepo) {
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	result = repo
+	ran := false
+	before := "" // HEAD before the last step that ran
+	from, last := p.resumeFrom(repo.Path)
+	for i, step := range p.steps {
+		if i < from {
+			done := fmt.Errorf("done in the run of %s (skipped)", last.Local().Format(time.DateTime))
+			result.Steps = append(result.Steps, types.Step{Operation: step.config.Operation, Error: done})
+			result.Error = fmt.Errorf("%s: %w", step.config.Operation, done)
+			step.recordHistory(types.GitRepo{Path: result.Path, Error: done})
+			continue
+		}
+		if step.condition != nil || step.invalid != nil {
+			met, err := step.conditionMet(ctx, result, before)
+			if err != nil {
+				result.Steps = append(result.Steps, types.Step{Operation: step.config.Operation, Error: err})
+				result.Error = fmt.Errorf("%s: condition: %w", step.config.Operation, err)
+				return result
+			}
+			if !met {
+				skipped := fmt.Errorf("condition %s not met (skipped)", step.condition)
+				result.Steps = append(result.Steps, types.Step{Operation: step.config.Operation, Error: skipped})
+				result.Error = fmt.Errorf("%s: %w", step.config.Operation, skipped)
+				step.recordHistory(types.GitRepo{Path: result.Path, Error: skipped})
+				continue
+			}
+		}
+
+		before = headHash(result.Path)
+		result.Error = nil
+		result = step.ProcessRepo(ctx, result)
+		result.Steps = append(result.Steps, types.Step{Operation: step.config.Operation, Error: result.Error, Duration: result.Duration})
+		if result.Error == nil {
+			ran = true
+			continue
+		}
+		result.Error = fmt.Errorf("%s: %w", step.config.Operation, result.Error)
+		if !strings.Contains(result.Error.Error(), "skipped") {
+			return result
+		}
+	}
+	if ran {
+		result.Error = nil
+	}
+	return result
+}
@@ -0,0 +1,224 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_ProcessRepo_Pipeline(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	newPipeline := func(steps ...string) *Processor {
+		return NewProcessor(&types.Config{
+			Operation:    types.OperationStatus,
+			Remote:       "origin",
+			PullStrategy: types.PullFastForward,
+			Pipeline:     "morning",
+			Pipelines:    map[string][]string{"morning": steps},
+		})
+	}
+	operations := func(result types.GitRepo) string {
+		var ops []string
+		for _, step := range result.Steps {
+			ops = append(ops, string(step.Operation))
+		}
+		return strings.Join(ops, " ")
+	}
+
+	t.Run("every step runs", func(t *testing.T) {
+		_, path := cloneTestRepo(t, divergedClone)
+		result := newPipeline("fetch", "status").ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+		if result.Error != nil {
+			t.Fatalf("Expected the pipeline to succeed, got %v", result.Error)
+		}
+		if got := operations(result); got != "fetch status" {
+			t.Errorf("Expected the steps fetch and status, got %q", got)
+		}
+		// Status sees what the fetch before it brought in
+		if result.Ahead != 1 || result.Behind != 1 {
+			t.Errorf("Expected ahead 1, behind 1 after the fetch, got ahead %d, behind %d", result.Ahead, result.Behind)
+		}
+	})
+
+	t.Run("skipped step", func(t *testing.T) {
+		_, path := cloneTestRepo(t, divergedClone)
+		result := newPipeline("fetch", "pull", "status").ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+		if result.Error != nil {
+			t.Fatalf("Expected a skipped pull to leave the pipeline going, got %v", result.Error)
+		}
+		if got := operations(result); got != "fetch pull status" {
+			t.Fatalf("Expected the steps fetch, pull and status, got %q", got)
+		}
+		if err := result.Steps[1].Error; err == nil || !strings.Contains(err.Error(), "skipped") {
+			t.Errorf("Expected the diverged pull to be skipped, got %v", err)
+		}
+	})
+
+	t.Run("conditional steps", func(t *testing.T) {
+		upstream, path := cloneTestRepo(t, cloneOptions{})
+		steps := []string{"fetch", "pull if behind > 0 && clean", "status if changed"}
+
+		// Up to date, so there is nothing to pull and nothing changes
+		result := newPipeline(steps...).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+		if result.Error != nil {
+			t.Fatalf("Expected unmet conditions to leave the pipeline going, got %v", result.Error)
+		}
+		for _, step := range result.Steps[1:] {
+			if step.Error == nil || !strings.Contains(step.Error.Error(), "not met (skipped)") {
+				t.Errorf("Expected %s to be skipped on its condition, got %v", step.Operation, step.Error)
+			}
+		}
+
+		commitFile(t, upstream, "new.txt", "new\n")
+		result = newPipeline(steps...).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+		if result.Error != nil {
+			t.Fatalf("Expected the pipeline to succeed, got %v", result.Error)
+		}
+		for _, step := range result.Steps {
+			if step.Error != nil {
+				t.Errorf("Expected %s to run once the clone is behind, got %v", step.Operation, step.Error)
+			}
+		}
+		if got, want := runGit(t, path, "rev-parse", "HEAD"), runGit(t, upstream, "rev-parse", "HEAD"); got != want {
+			t.Errorf("Expected the pull to bring the clone to %s, got %s", want, got)
+		}
+
+		// Nothing ran when every condition fails, so the repository is skipped
+		result = newPipeline("pull if behind > 0").ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+		if result.Error == nil || !strings.Contains(result.Error.Error(), "skipped") {
+			t.Errorf("Expected the repository to be skipped, got %v", result.Error)
+		}
+	})
+
+	t.Run("post-hook", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("test commands use sh syntax")
+		}
+		hook := func(path string) types.GitRepo {
+			t.Helper()
+			p := NewProcessor(&types.Config{
+				Operation:    types.OperationExec,
+				Remote:       "origin",
+				PullStrategy: types.PullFastForward,
+				Exec:         "echo ran >> hook.log",
+				Pipeline:     "deploy",
+				Pipelines:    map[string][]string{"deploy": {"pull", "exec if changed"}},
+			})
+			return p.ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+		}
+
+		// Nothing to pull leaves HEAD where it was, so the hook does not run
+		_, current := cloneTestRepo(t, cloneOptions{})
+		result := hook(current)
+		if err := result.Steps[1].Error; err == nil || !strings.Contains(err.Error(), "not met") {
+			t.Errorf("Expected the hook to be skipped without a change, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(current, "hook.log")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected the hook not to run, got %v", err)
+		}
+
+		// Pulling a new commit moves HEAD, so it does
+		_, behind := cloneTestRepo(t, cloneOptions{Behind: []string{"news.txt"}})
+		result = hook(behind)
+		if result.Error != nil || result.Steps[1].Error != nil {
+			t.Fatalf("Expected the hook to run after the pull, got %v", result.Error)
+		}
+		if data, err := os.ReadFile(filepath.Join(behind, "hook.log")); err != nil || string(data) != "ran\n" {
+			t.Errorf("Expected the hook to run once, got %q (%v)", data, err)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		_, path := cloneTestRepo(t, divergedClone)
+		historyFile := filepath.Join(t.TempDir(), "history.json")
+		run := func(resume bool, steps ...string) types.GitRepo {
+			t.Helper()
+			p := NewProcessor(&types.Config{
+				Operation:      types.OperationFetch,
+				Remote:         "origin",
+				PullStrategy:   types.PullFastForward,
+				HistoryFile:    historyFile,
+				Pipeline:       "morning",
+				Pipelines:      map[string][]string{"morning": steps},
+				ResumePipeline: resume,
+			})
+			result := p.ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+			if err := p.SaveHistory(); err != nil {
+				t.Fatalf("SaveHistory failed: %v", err)
+			}
+			return result
+		}
+
+		url := runGit(t, path, "remote", "get-url", "origin")
+		runGit(t, path, "remote", "set-url", "origin", filepath.Join(t.TempDir(), "gone"))
+		if result := run(false, "status", "fetch"); result.Error == nil {
+			t.Fatal("Expected the fetch from a missing remote to fail")
+		}
+		runGit(t, path, "remote", "set-url", "origin", url)
+
+		// The status went through, so the pipeline resumes at the fetch that failed
+		result := run(true, "status", "fetch")
+		if result.Error != nil {
+			t.Fatalf("Expected the resumed pipeline to succeed, got %v", result.Error)
+		}
+		if err := result.Steps[0].Error; err == nil || !strings.Contains(err.Error(), "done in the run of") {
+			t.Errorf("Expected the status to be passed over, got %v", err)
+		}
+		if err := result.Steps[1].Error; err != nil {
+			t.Errorf("Expected the fetch to run again, got %v", err)
+		}
+
+		// Every step went through, so there is nothing left to resume and the pipeline starts
+		// over, on every later run too
+		for range 2 {
+			result := run(true, "status", "fetch")
+			if result.Error != nil || result.Steps[0].Error != nil || result.Steps[1].Error != nil {
+				t.Errorf("Expected a repository the pipeline went through to be processed again, got %v", result.Steps)
+			}
+		}
+
+		// A step whose condition was not met counts as gone through, not as never run
+		if result := run(false, "status", "fetch if dirty"); result.Error != nil || result.Steps[1].Error == nil {
+			t.Fatalf("Expected the fetch of the clean clone to be passed over, got %v", result.Steps)
+		}
+		if result := run(true, "status", "fetch if dirty"); result.Steps[0].Error != nil {
+			t.Errorf("Expected the pipeline to start over, got %v", result.Steps)
+		}
+
+		// Changed steps start over
+		if result := run(true, "fetch", "status"); result.Error != nil || operations(result) != "fetch status" {
+			t.Errorf("Expected a changed pipeline to run every step, got %q, %v", operations(result), result.Error)
+		}
+
+		// The pull skips the diverged branch, so the pipeline resumes at the pull, not past it
+		if result := run(false, "fetch", "pull"); result.Error != nil || result.Steps[1].Error == nil {
+			t.Fatalf("Expected the pull of the diverged branch to be skipped, got %v", result.Steps)
+		}
+		result = run(true, "fetch", "pull")
+		if err := result.Steps[0].Error; err == nil || !strings.Contains(err.Error(), "done in the run of") {
+			t.Errorf("Expected the fetch to be passed over, got %v", err)
+		}
+		if err := result.Steps[1].Error; err == nil || strings.Contains(err.Error(), "done in the run of") {
+			t.Errorf("Expected the skipped pull to run again, got %v", err)
+		}
+	})
+
+	t.Run("failed step", func(t *testing.T) {
+		_, path := cloneTestRepo(t, divergedClone)
+		runGit(t, path, "remote", "set-url", "origin", filepath.Join(t.TempDir(), "gone"))
+		result := newPipeline("fetch", "status").ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+		if result.Error == nil || !strings.HasPrefix(result.Error.Error(), "fetch: ") {
+			t.Fatalf("Expected the pipeline to fail at fetch, got %v", result.Error)
+		}
+		if got := operations(result); got != "fetch" {
+			t.Errorf("Expected the failed fetch to stop the pipeline, got the steps %q", got)
+		}
+	})
+}
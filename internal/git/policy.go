@@ -0,0 +1,57 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isProtected reports whether a repository path matches the configured protected list.
+// Entries may be directories (protecting everything below them), globs matched against the
+// absolute repository path, or bare name globs matched against the repository's base name.
+func (p *Processor) isProtected(repoPath string) bool {
+	absRepo, err := filepath.Abs(repoPath)
+	if err != nil {
+		absRepo = filepath.Clean(repoPath)
+	}
+
+	for _, entry := range p.config.Protected {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.ContainsRune(entry, '/') && !strings.ContainsRune(entry, filepath.Separator) {
+			if matched, err := filepath.Match(entry, filepath.Base(absRepo)); err == nil && matched {
+				return true
+			}
+			continue
+		}
+
+		pattern := ExpandHome(entry)
+		if absPattern, err := filepath.Abs(pattern); err == nil {
+			pattern = absPattern
+		}
+
+		if matched, err := filepath.Match(pattern, absRepo); err == nil && matched {
+			return true
+		}
+		if absRepo == pattern || strings.HasPrefix(absRepo, pattern+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExpandHome replaces a leading ~ with the current user's home directory, as a shell would
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_IsProtected(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		protected []string
+		repoPath  string
+		expected  bool
+	}{
+		{"no protected entries", nil, filepath.Join(tmpDir, "infra", "prod"), false},
+		{"directory entry protects children", []string{filepath.Join(tmpDir, "infra")}, filepath.Join(tmpDir, "infra", "prod"), true},
+		{"directory entry matches itself", []string{filepath.Join(tmpDir, "infra")}, filepath.Join(tmpDir, "infra"), true},
+		{"directory entry does not match siblings", []string{filepath.Join(tmpDir, "infra")}, filepath.Join(tmpDir, "infra-toys"), false},
+		{"path glob", []string{filepath.Join(tmpDir, "*", "prod-*")}, filepath.Join(tmpDir, "infra", "prod-db"), true},
+		{"name glob", []string{"prod-*"}, filepath.Join(tmpDir, "infra", "prod-db"), true},
+		{"name glob mismatch", []string{"prod-*"}, filepath.Join(tmpDir, "infra", "staging-db"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewProcessor(&types.Config{Protected: tt.protected})
+			if got := processor.isProtected(tt.repoPath); got != tt.expected {
+				t.Errorf("isProtected(%q) = %v, want %v", tt.repoPath, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory: %v", err)
+	}
+
+	if got := ExpandHome("~/infra"); got != filepath.Join(home, "infra") {
+		t.Errorf("Expected ~/infra to expand under %s, got %s", home, got)
+	}
+	if got := ExpandHome("/srv/infra"); got != "/srv/infra" {
+		t.Errorf("Expected absolute path to be unchanged, got %s", got)
+	}
+}
+
+func TestProcessor_ProcessRepo_ProtectedPolicySkip(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "prod")
+	initTestRepo(t, repoPath)
+
+	// Maintenance prunes refs and rewrites packs, so it is no more allowed than a pull
+	for _, operation := range []types.OperationType{types.OperationPull, types.OperationMaintenance} {
+		config := &types.Config{
+			Operation: operation,
+			Protected: []string{"prod"},
+		}
+
+		repo := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "prod", HasGit: true})
+		if repo.Error == nil {
+			t.Fatalf("Expected protected repository to be policy skipped for %s", operation)
+		}
+		if !strings.Contains(repo.Error.Error(), "policy skipped") {
+			t.Errorf("Expected policy skip error for %s, got %v", operation, repo.Error)
+		}
+	}
+}
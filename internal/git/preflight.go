@@ -0,0 +1,102 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// ErrPreflight marks repositories whose remote did not answer git ls-remote before the run
+var ErrPreflight = errors.New("preflight failed")
+
+// preflightResults holds the errors of the repositories that failed preflight, by path
+type preflightResults struct {
+	mu     sync.Mutex
+	failed map[string]error
+}
+
+func (r *preflightResults) add(path string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed == nil {
+		r.failed = make(map[string]error)
+	}
+	r.failed[path] = err
+}
+
+func (r *preflightResults) get(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failed[path]
+}
+
+// Preflight checks with git ls-remote, --preflight-timeout at most per repository, that the
+// configured remote of every repository answers, so unreachable remotes and broken credentials
+// are found before any worker waits on them for the full timeout. The repositories that failed
+// are returned with their error, in the order of repos, and are failed straight away when
+// processed. Repositories without the remote are left to processing to skip.
+func (p *Processor) Preflight(ctx context.Context, repos []types.GitRepo) []types.GitRepo {
+	errs := make([]error, len(repos))
+	g := new(errgroup.Group)
+	g.SetLimit(p.config.Workers)
+	for i, repo := range repos {
+		g.Go(func() error {
+			errs[i] = p.checkRemote(ctx, repo.Path)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var failed []types.GitRepo
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		p.probed.add(repos[i].Path, err)
+		repo := repos[i]
+		repo.Error = err
+		failed = append(failed, repo)
+	}
+	return failed
+}
+
+// checkRemote runs git ls-remote against the repository's configured remote
+func (p *Processor) checkRemote(ctx context.Context, path string) error {
+	remote := p.remoteName()
+	if p.gitCommand(ctx, path, "config", "--get", "remote."+remote+".url").Run() != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.PreflightTimeout)
+	defer cancel()
+	cmd := p.gitCommand(ctx, path, "ls-remote", "--quiet", remote, "HEAD")
+	// A credential prompt would wait for the full timeout, and means the credentials are broken
+	cmd.Env = append(cmd.Env, "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	output, err := cmd.CombinedOutput()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: remote %s did not answer within %v", ErrPreflight, remote, p.config.PreflightTimeout)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	default:
+		return fmt.Errorf("%w: remote %s: %s", ErrPreflight, remote, lastLine(string(output), err))
+	}
+}
+
+// lastLine returns the last non-empty line of a git command's output, which is where git puts
+// the reason it failed, or err when it printed nothing
+func lastLine(output string, err error) string {
+	lines := parseLines(output)
+	if len(lines) == 0 {
+		return err.Error()
+	}
+	return strings.TrimPrefix(lines[len(lines)-1], "fatal: ")
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_Preflight(t *testing.T) {
+	requireGit(t)
+
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	initTestRepo(t, upstream)
+	reachable := filepath.Join(root, "reachable")
+	runGit(t, root, "clone", "--quiet", upstream, reachable)
+	unreachable := filepath.Join(root, "unreachable")
+	initTestRepo(t, unreachable)
+	runGit(t, unreachable, "remote", "add", "origin", filepath.Join(root, "missing.git"))
+	noRemote := filepath.Join(root, "no-remote")
+	initTestRepo(t, noRemote)
+
+	config := &types.Config{Operation: types.OperationFetch, Remote: "origin", Workers: 2, Preflight: true, PreflightTimeout: 10 * time.Second}
+	processor := NewProcessor(config)
+	repos := []types.GitRepo{
+		{Path: reachable, Name: "reachable"},
+		{Path: unreachable, Name: "unreachable"},
+		{Path: noRemote, Name: "no-remote"},
+	}
+
+	failed := processor.Preflight(t.Context(), repos)
+	if len(failed) != 1 || failed[0].Name != "unreachable" || !errors.Is(failed[0].Error, ErrPreflight) {
+		t.Fatalf("Expected only the unreachable remote to fail preflight, got %+v", failed)
+	}
+	if !strings.Contains(failed[0].Error.Error(), "remote origin: ") {
+		t.Errorf("Expected git's reason in the error, got %v", failed[0].Error)
+	}
+
+	if result := processor.ProcessRepo(t.Context(), repos[1]); !errors.Is(result.Error, ErrPreflight) {
+		t.Errorf("Expected the repository to fail with its preflight error, got %v", result.Error)
+	}
+	if result := processor.ProcessRepo(t.Context(), repos[0]); result.Error != nil {
+		t.Errorf("Expected the reachable repository to be fetched, got %v", result.Error)
+	}
+	if result := processor.ProcessRepo(t.Context(), repos[2]); result.Error == nil || !strings.Contains(result.Error.Error(), "(skipped)") {
+		t.Errorf("Expected the repository without a remote to be skipped as usual, got %v", result.Error)
+	}
+}
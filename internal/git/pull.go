@@ -0,0 +1,46 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// pullDiverged pulls a branch that has diverged from the remote with the git CLI, which unlike
+// go-git can merge and rebase, recording the strategy in repo.PulledWith. A merge or rebase that
+// stops on conflicts is aborted, leaving the branch where it was.
+func (p *Processor) pullDiverged(ctx context.Context, repo *types.GitRepo) error {
+	if repo.Branch == "" || repo.Branch == "detached" {
+		return errors.New("detached HEAD: no branch to merge or rebase (skipped)")
+	}
+
+	args := []string{"pull", "--no-rebase", "--no-edit", p.remoteName()}
+	abort := []string{"merge", "--abort"}
+	if p.config.PullStrategy == types.PullRebase {
+		args = []string{"pull", "--rebase", p.remoteName()}
+		abort = []string{"rebase", "--abort"}
+	}
+	args = append(args, pullBranch(repo, p.remoteName()))
+
+	output, err := p.gitCommand(ctx, repo.Path, args...).CombinedOutput()
+	if err != nil {
+		// Without a merge or rebase in progress there is nothing to abort, and git says so
+		_ = p.gitCommand(context.WithoutCancel(ctx), repo.Path, abort...).Run()
+		return fmt.Errorf("pull --%s failed: %w (output: %s)", p.config.PullStrategy, err, strings.TrimSpace(string(output)))
+	}
+
+	repo.PulledWith = string(p.config.PullStrategy)
+	return nil
+}
+
+// pullBranch returns the remote branch to integrate: the upstream when it is on remote, and
+// otherwise the remote's branch of the same name
+func pullBranch(repo *types.GitRepo, remote string) string {
+	if branch, ok := strings.CutPrefix(repo.Upstream, remote+"/"); ok {
+		return branch
+	}
+	return repo.Branch
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// runGit runs a git CLI command in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commitFile writes and commits a file with the git CLI
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", name)
+	runGit(t, dir, "commit", "--quiet", "--message", "add "+name)
+}
+
+// divergedClone has a different file committed to the clone and its upstream, so the clone's
+// branch has diverged from its upstream
+var divergedClone = cloneOptions{Ahead: []string{"ours.txt"}, Behind: []string{"theirs.txt"}}
+
+func TestProcessor_ProcessRepo_PullStrategy(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	tests := []struct {
+		strategy types.PullStrategy
+		parents  int // Parents of HEAD after the pull
+	}{
+		{types.PullMerge, 2},
+		{types.PullRebase, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			_, path := cloneTestRepo(t, divergedClone)
+
+			config := &types.Config{Operation: types.OperationPull, Remote: "origin", PullStrategy: tt.strategy}
+			result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+			if result.Error != nil {
+				t.Fatalf("Expected the diverged branch to be pulled, got %v", result.Error)
+			}
+			if result.PulledWith != string(tt.strategy) {
+				t.Errorf("Expected PulledWith %q, got %q", tt.strategy, result.PulledWith)
+			}
+			for _, file := range []string{"ours.txt", "theirs.txt"} {
+				if _, err := os.Stat(filepath.Join(path, file)); err != nil {
+					t.Errorf("Expected %s after the pull: %v", file, err)
+				}
+			}
+			if parents := strings.Fields(runGit(t, path, "log", "-1", "--format=%p")); len(parents) != tt.parents {
+				t.Errorf("Expected HEAD to have %d parents, got %v", tt.parents, parents)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessRepo_PullFastForwardOnly(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	_, path := cloneTestRepo(t, divergedClone)
+	head := runGit(t, path, "rev-parse", "HEAD")
+
+	config := &types.Config{Operation: types.OperationPull, Remote: "origin", PullStrategy: types.PullFastForward}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "clone"})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "skipped") {
+		t.Errorf("Expected the diverged branch to be skipped, got %v", result.Error)
+	}
+	if got := runGit(t, path, "rev-parse", "HEAD"); got != head {
+		t.Errorf("Expected HEAD to stay at %s, got %s", head, got)
+	}
+}
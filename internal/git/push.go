@@ -0,0 +1,123 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// pushRepo pushes the current branch to its upstream on the configured remote when it is ahead,
+// recording what was pushed in repo.Pushed. A branch whose upstream has commits it lacks has
+// diverged and is refused unless --force-with-lease is given; the forced push then only goes
+// through if the remote branch is still where it was when last fetched. In dry-run mode the
+// push is only planned.
+func (p *Processor) pushRepo(ctx context.Context, gitRepo *gogit.Repository, repo *types.GitRepo) error {
+	if repo.Branch == "" || repo.Branch == "detached" {
+		return errors.New("detached HEAD: no branch to push (skipped)")
+	}
+
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	branch, ok := cfg.Branches[repo.Branch]
+	if !ok || branch.Remote == "" || branch.Merge == "" {
+		return errors.New("no upstream to push to (skipped)")
+	}
+	if branch.Remote != p.remoteName() {
+		return fmt.Errorf("upstream %s is not on remote %q (skipped)", repo.Upstream, p.remoteName())
+	}
+
+	head, err := gitRepo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	upstream, err := gitRepo.Reference(plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short()), true)
+	if err != nil {
+		return fmt.Errorf("upstream %s has not been fetched (skipped)", repo.Upstream)
+	}
+	if head.Hash() == upstream.Hash() {
+		return nil
+	}
+
+	kind, err := p.classifyPush(gitRepo, head.Hash(), upstream.Hash(), repo)
+	if err != nil || kind == pushNothing {
+		return err
+	}
+
+	opts := &gogit.PushOptions{
+		RemoteName: branch.Remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(head.Name().String() + ":" + branch.Merge.String())},
+	}
+	pushed := fmt.Sprintf("%s..%s %s -> %s", shortHash(upstream.Hash()), shortHash(head.Hash()), repo.Branch, branch.Merge.Short())
+	if kind == pushForce {
+		opts.ForceWithLease = &gogit.ForceWithLease{RefName: branch.Merge, Hash: upstream.Hash()}
+		pushed = fmt.Sprintf("%s...%s %s -> %s (forced update)", shortHash(upstream.Hash()), shortHash(head.Hash()), repo.Branch, branch.Merge.Short())
+	}
+
+	if !p.config.DryRun {
+		err = p.withRateLimitRetry(ctx, gitRepo, repo.Name, func() error {
+			return gitRepo.PushContext(ctx, opts)
+		})
+		if err != nil && err != gogit.NoErrAlreadyUpToDate {
+			return fmt.Errorf("push failed: %w", err)
+		}
+	}
+
+	repo.Pushed = pushed
+	return nil
+}
+
+// pushKind is what pushing the current branch to its upstream would take
+type pushKind int
+
+const (
+	pushNothing     pushKind = iota // The branch is only behind its upstream
+	pushFastForward                 // The upstream is an ancestor of the branch
+	pushForce                       // The two have diverged and --force-with-lease allows overwriting
+)
+
+// classifyPush compares the current branch with its upstream. Diverged branches are refused
+// without --force-with-lease.
+func (p *Processor) classifyPush(gitRepo *gogit.Repository, head, upstream plumbing.Hash, repo *types.GitRepo) (pushKind, error) {
+	headCommit, err := gitRepo.CommitObject(head)
+	if err != nil {
+		return pushNothing, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	upstreamCommit, err := gitRepo.CommitObject(upstream)
+	if err != nil {
+		return pushNothing, fmt.Errorf("failed to read upstream commit: %w", err)
+	}
+
+	fastForward, err := upstreamCommit.IsAncestor(headCommit)
+	if err != nil {
+		return pushNothing, fmt.Errorf("failed to compare with upstream: %w", err)
+	}
+	if fastForward {
+		return pushFastForward, nil
+	}
+
+	behind, err := headCommit.IsAncestor(upstreamCommit)
+	if err != nil {
+		return pushNothing, fmt.Errorf("failed to compare with upstream: %w", err)
+	}
+	if behind {
+		return pushNothing, nil
+	}
+
+	if !p.config.ForceWithLease {
+		return pushNothing, fmt.Errorf("diverged from %s, not pushing without --force-with-lease (skipped)", repo.Upstream)
+	}
+	return pushForce, nil
+}
+
+// shortHash abbreviates a commit hash the way reports show commits
+func shortHash(hash plumbing.Hash) string {
+	return hash.String()[:8]
+}
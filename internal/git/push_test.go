@@ -0,0 +1,153 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// commitEmpty adds an empty commit to the repository's current branch
+func commitEmpty(t *testing.T, gitRepo *gogit.Repository, msg string) plumbing.Hash {
+	t.Helper()
+
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit(msg, &gogit.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "git-herd", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// initPushableRepo creates a bare remote and a repository whose current branch tracks the
+// remote's copy of it, returning the repository, its path and the remote's path
+func initPushableRepo(t *testing.T) (*gogit.Repository, string, string) {
+	t.Helper()
+
+	barePath := t.TempDir()
+	if _, err := gogit.PlainInit(barePath, true); err != nil {
+		t.Fatal(err)
+	}
+
+	path := t.TempDir()
+	gitRepo := initTestRepo(t, path)
+	if _, err := gitRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{barePath}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gitRepo.Push(&gogit.PushOptions{RemoteName: "origin"}); err != nil {
+		t.Fatal(err)
+	}
+	fetchOrigin(t, gitRepo)
+
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Branches["master"] = &config.Branch{Name: "master", Remote: "origin", Merge: plumbing.NewBranchReferenceName("master")}
+	if err := gitRepo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return gitRepo, path, barePath
+}
+
+func fetchOrigin(t *testing.T, gitRepo *gogit.Repository) {
+	t.Helper()
+
+	if err := gitRepo.Fetch(&gogit.FetchOptions{RemoteName: "origin"}); err != nil && err != gogit.NoErrAlreadyUpToDate {
+		t.Fatal(err)
+	}
+}
+
+// remoteHead returns where master points in the repository at path
+func remoteHead(t *testing.T, path string) plumbing.Hash {
+	t.Helper()
+
+	gitRepo, err := gogit.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := gitRepo.Reference(plumbing.NewBranchReferenceName("master"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref.Hash()
+}
+
+func TestProcessor_ProcessRepo_Push(t *testing.T) {
+	gitRepo, path, barePath := initPushableRepo(t)
+	before := remoteHead(t, barePath)
+	head := commitEmpty(t, gitRepo, "local work")
+
+	dryRun := NewProcessor(&types.Config{Operation: types.OperationPush, DryRun: true}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if dryRun.Error != nil || dryRun.Pushed == "" {
+		t.Fatalf("Expected dry run to plan a push, got %q (error %v)", dryRun.Pushed, dryRun.Error)
+	}
+	if remoteHead(t, barePath) != before {
+		t.Fatal("Expected dry run not to push")
+	}
+
+	processor := NewProcessor(&types.Config{Operation: types.OperationPush})
+	pushed := processor.ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if pushed.Error != nil {
+		t.Fatalf("Expected push to succeed, got %v", pushed.Error)
+	}
+	if want := shortHash(before) + ".." + shortHash(head) + " master -> master"; pushed.Pushed != want {
+		t.Errorf("Expected push summary %q, got %q", want, pushed.Pushed)
+	}
+	if remoteHead(t, barePath) != head {
+		t.Error("Expected the remote branch to point at the pushed commit")
+	}
+
+	again := processor.ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if again.Error != nil || again.Pushed != "" {
+		t.Errorf("Expected nothing to push once up to date, got %q (error %v)", again.Pushed, again.Error)
+	}
+}
+
+func TestProcessor_ProcessRepo_PushDiverged(t *testing.T) {
+	gitRepo, path, barePath := initPushableRepo(t)
+
+	// Someone else pushes to the remote while the local branch gets a commit of its own
+	other, err := gogit.PlainClone(t.TempDir(), false, &gogit.CloneOptions{URL: barePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitEmpty(t, other, "their work")
+	if err := other.Push(&gogit.PushOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	theirs := remoteHead(t, barePath)
+	head := commitEmpty(t, gitRepo, "our work")
+	fetchOrigin(t, gitRepo)
+
+	refused := NewProcessor(&types.Config{Operation: types.OperationPush}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if refused.Error == nil || !strings.Contains(refused.Error.Error(), "--force-with-lease (skipped)") {
+		t.Fatalf("Expected diverged branch to be skipped, got %v", refused.Error)
+	}
+	if remoteHead(t, barePath) != theirs {
+		t.Fatal("Expected the remote branch to be left alone")
+	}
+
+	forced := NewProcessor(&types.Config{Operation: types.OperationPush, ForceWithLease: true}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if forced.Error != nil {
+		t.Fatalf("Expected forced push to succeed, got %v", forced.Error)
+	}
+	if !strings.HasSuffix(forced.Pushed, "(forced update)") {
+		t.Errorf("Expected a forced update, got %q", forced.Pushed)
+	}
+	if remoteHead(t, barePath) != head {
+		t.Error("Expected the remote branch to point at the local commit")
+	}
+}
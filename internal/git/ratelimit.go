@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// defaultRateLimitPause is used when a forge rate-limits us without a usable Retry-After
+const defaultRateLimitPause = time.Minute
+
+// RateLimiter pauses network operations per host after a forge reports rate limiting,
+// so the remaining repositories on that host wait instead of failing
+type RateLimiter struct {
+	mu     sync.Mutex
+	paused map[string]time.Time
+}
+
+// NewRateLimiter creates an empty per-host rate limiter
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{paused: make(map[string]time.Time)}
+}
+
+// Pause stops operations against host for at least d
+func (r *RateLimiter) Pause(host string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(r.paused[host]) {
+		r.paused[host] = until
+	}
+}
+
+// Wait blocks until host is no longer paused or ctx is done
+func (r *RateLimiter) Wait(ctx context.Context, host string) error {
+	for {
+		r.mu.Lock()
+		until := r.paused[host]
+		r.mu.Unlock()
+
+		remaining := time.Until(until)
+		if remaining <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimitDelay reports whether err is a forge rate-limit response and how long to back off.
+// HTTP 429 responses honor Retry-After; GitHub's secondary rate limits arrive as 403s whose
+// body mentions the rate limit and carry no usable headers.
+func rateLimitDelay(err error) (time.Duration, bool) {
+	var unexpected *plumbing.UnexpectedError
+	if errors.As(err, &unexpected) {
+		var httpErr *githttp.Err
+		if errors.As(unexpected.Err, &httpErr) && httpErr.Response != nil &&
+			httpErr.Response.StatusCode == http.StatusTooManyRequests {
+			return retryAfter(httpErr.Response.Header.Get("Retry-After")), true
+		}
+	}
+
+	if errors.Is(err, transport.ErrAuthorizationFailed) && strings.Contains(strings.ToLower(err.Error()), "rate limit") {
+		return defaultRateLimitPause, true
+	}
+
+	return 0, false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return defaultRateLimitPause
+}
+
+// remoteHost returns the host of the named remote's first URL, or "" if it cannot be determined
+func remoteHost(repo *gogit.Repository, remoteName string) string {
+	remote, err := repo.Remote(remoteName)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return urlHost(remote.Config().URLs[0])
+}
+
+// urlHost returns the host of a remote URL, or "" if it has none (e.g. a local path)
+func urlHost(rawURL string) string {
+	endpoint, err := transport.NewEndpoint(rawURL)
+	if err != nil {
+		return ""
+	}
+	return endpoint.Host
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func tooManyRequests(retryAfter string) error {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	response := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     header,
+		Request:    &http.Request{URL: &url.URL{Scheme: "https", Host: "github.com"}},
+	}
+	return fmt.Errorf("fetch failed: %w", plumbing.NewUnexpectedError(&githttp.Err{Response: response}))
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantLimited bool
+		wantDelay   time.Duration
+	}{
+		{"nil error", nil, false, 0},
+		{"unrelated error", errors.New("network unreachable"), false, 0},
+		{"429 with seconds", tooManyRequests("30"), true, 30 * time.Second},
+		{"429 without header", tooManyRequests(""), true, defaultRateLimitPause},
+		{
+			"secondary rate limit",
+			fmt.Errorf("%w: You have exceeded a secondary rate limit", transport.ErrAuthorizationFailed),
+			true,
+			defaultRateLimitPause,
+		},
+		{
+			"plain authorization failure",
+			fmt.Errorf("%w: permission denied", transport.ErrAuthorizationFailed),
+			false,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, limited := rateLimitDelay(tt.err)
+			if limited != tt.wantLimited {
+				t.Fatalf("Expected limited=%v, got %v", tt.wantLimited, limited)
+			}
+			if delay != tt.wantDelay {
+				t.Errorf("Expected delay %v, got %v", tt.wantDelay, delay)
+			}
+		})
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	when := time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat)
+	delay := retryAfter(when)
+	if delay < time.Minute || delay > 2*time.Minute {
+		t.Errorf("Expected delay of about 2m, got %v", delay)
+	}
+}
+
+func TestRateLimiter_PauseAndWait(t *testing.T) {
+	limiter := NewRateLimiter()
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background(), "github.com"); err != nil {
+		t.Fatalf("Wait on unpaused host failed: %v", err)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Error("Expected unpaused host to return immediately")
+	}
+
+	limiter.Pause("github.com", 50*time.Millisecond)
+	// A shorter pause must not cut an existing one short
+	limiter.Pause("github.com", time.Millisecond)
+
+	start = time.Now()
+	if err := limiter.Wait(context.Background(), "github.com"); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected to wait for the pause, waited %v", elapsed)
+	}
+
+	// Other hosts are unaffected
+	limiter.Pause("github.com", time.Hour)
+	if err := limiter.Wait(context.Background(), "gitlab.com"); err != nil {
+		t.Fatalf("Wait on other host failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx, "github.com"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context cancellation, got %v", err)
+	}
+}
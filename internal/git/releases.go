@@ -0,0 +1,56 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// readRelease records the latest tag reachable from HEAD, when it was made, and the commits on
+// the current branch since, for the releases inventory. Without a tag, the whole history is
+// unreleased. The repository is flagged as overdue when its oldest unreleased commit is older
+// than --unreleased-days.
+func (p *Processor) readRelease(ctx context.Context, repo *types.GitRepo) {
+	if repo.Empty {
+		return
+	}
+
+	release := &types.Release{}
+	// describe fails when no tag can describe HEAD, which leaves the tag empty
+	if output, err := p.gitCommand(ctx, repo.Path, "describe", "--tags", "--abbrev=0", "HEAD").Output(); err == nil {
+		release.Tag = strings.TrimSpace(string(output))
+	}
+
+	unreleased := "HEAD"
+	if release.Tag != "" {
+		output, err := p.gitCommand(ctx, repo.Path, "for-each-ref", "--format=%(creatordate:iso-strict)", "refs/tags/"+release.Tag).Output()
+		if err != nil {
+			repo.Error = fmt.Errorf("failed to read tag %s: %w", release.Tag, err)
+			return
+		}
+		release.Date, _ = time.Parse(time.RFC3339, strings.TrimSpace(string(output)))
+		unreleased = "refs/tags/" + release.Tag + "..HEAD"
+	}
+
+	output, err := p.gitCommand(ctx, repo.Path, "log", "--format=%cI", unreleased).Output()
+	if err != nil {
+		repo.Error = fmt.Errorf("failed to read unreleased commits: %w", err)
+		return
+	}
+	dates := parseLines(string(output))
+	release.Unreleased = len(dates)
+	if len(dates) > 0 {
+		// log lists the newest first, but commit dates need not be in order
+		for _, line := range dates {
+			date, err := time.Parse(time.RFC3339, line)
+			if err == nil && (release.Oldest.IsZero() || date.Before(release.Oldest)) {
+				release.Oldest = date
+			}
+		}
+		release.Overdue = time.Since(release.Oldest) > time.Duration(p.config.UnreleasedDays)*24*time.Hour
+	}
+	repo.Release = release
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_ProcessRepo_Releases(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	runGit(t, filepath.Dir(repoPath), "init", "--quiet", repoPath)
+	t.Setenv("GIT_AUTHOR_DATE", "2024-01-10T12:00:00Z")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-10T12:00:00Z")
+	commitFile(t, repoPath, "a.txt", "a\n")
+
+	process := func(days int) types.GitRepo {
+		t.Helper()
+		config := &types.Config{Operation: types.OperationReleases, UnreleasedDays: days}
+		result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "repo"})
+		if result.Error != nil {
+			t.Fatalf("ProcessRepo() error = %v", result.Error)
+		}
+		if result.Release == nil {
+			t.Fatal("Expected the release to be read")
+		}
+		return result
+	}
+
+	// Never tagged: the whole history is unreleased
+	if release := process(30).Release; release.Tag != "" || release.Unreleased != 1 || !release.Overdue {
+		t.Errorf("Expected one overdue commit without a tag, got %+v", release)
+	}
+
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-15T08:00:00Z")
+	runGit(t, repoPath, "tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	if release := process(30).Release; release.Tag != "v1.0.0" || release.Unreleased != 0 || release.Overdue {
+		t.Errorf("Expected v1.0.0 with nothing unreleased, got %+v", release)
+	}
+
+	t.Setenv("GIT_AUTHOR_DATE", "2024-02-01T09:00:00Z")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-02-01T09:00:00Z")
+	commitFile(t, repoPath, "b.txt", "b\n")
+	commitFile(t, repoPath, "c.txt", "c\n")
+
+	release := process(30).Release
+	if release.Tag != "v1.0.0" || !release.Date.Equal(time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected v1.0.0 tagged on 2024-01-15, got %s on %v", release.Tag, release.Date)
+	}
+	if release.Unreleased != 2 || !release.Oldest.Equal(time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)) || !release.Overdue {
+		t.Errorf("Expected 2 overdue commits since 2024-02-01, got %+v", release)
+	}
+
+	// Within the threshold, unreleased work is not overdue
+	days := int(time.Since(release.Oldest).Hours()/24) + 1
+	if release := process(days).Release; release.Overdue {
+		t.Errorf("Expected nothing overdue within %d days, got %+v", days, release)
+	}
+}
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"cmp"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// defaultRemote is the remote fetch, pull and push use unless configured otherwise
+const defaultRemote = "origin"
+
+// remoteName returns the remote that fetch, pull and push use
+func (p *Processor) remoteName() string {
+	return cmp.Or(p.config.Remote, defaultRemote)
+}
+
+// listRemotes describes a repository's remotes, preferred first and the rest by name
+func listRemotes(remotes []*gogit.Remote, preferred string) []types.Remote {
+	listed := make([]types.Remote, 0, len(remotes))
+	for _, remote := range remotes {
+		config := remote.Config()
+		entry := types.Remote{Name: config.Name}
+		if len(config.URLs) > 0 {
+			entry.URL = redactURL(config.URLs[0])
+		}
+		listed = append(listed, entry)
+	}
+
+	rank := func(r types.Remote) int {
+		if r.Name == preferred {
+			return 0
+		}
+		return 1
+	}
+	slices.SortFunc(listed, func(a, b types.Remote) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), strings.Compare(a.Name, b.Name))
+	})
+	return listed
+}
+
+// redactURL removes credentials from a remote URL before it is shown or written to a report.
+// HTTP(S) URLs lose their user info entirely, since the "user" is often an access token;
+// other URLs keep the user (git@ for SSH) but lose any password. scp-style addresses such as
+// git@github.com:org/repo.git carry no password and are returned unchanged.
+func redactURL(rawURL string) string {
+	if !strings.Contains(rawURL, "://") {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+
+	switch {
+	case u.Scheme == "http" || u.Scheme == "https":
+		u.User = nil
+	default:
+		if _, hasPassword := u.User.Password(); !hasPassword {
+			return rawURL
+		}
+		u.User = url.User(u.User.Username())
+	}
+	return u.String()
+}
+
+// excludeRemotes drops the repositories whose remote matches an --exclude-remote pattern. The
+// remote URL is read from the repository's config file alone, so this is cheap enough to run
+// right after discovery; repositories without a readable remote are kept.
+func excludeRemotes(config *types.Config, repos []types.GitRepo) []types.GitRepo {
+	preferred := cmp.Or(config.Remote, defaultRemote)
+	return slices.DeleteFunc(repos, func(repo types.GitRepo) bool {
+		remote := remotePath(configuredRemoteURL(repo.Path, preferred))
+		return remote != "" && slices.ContainsFunc(config.ExcludeRemotes, func(pattern string) bool {
+			return matchRemote(pattern, remote)
+		})
+	})
+}
+
+// configuredRemoteURL returns the URL of the preferred remote of the repository at repoPath,
+// or else of its first remote by name, as its config file lists them; "" when it has none
+func configuredRemoteURL(repoPath, preferred string) string {
+	data, err := os.ReadFile(filepath.Join(commonDir(repoPath), "config"))
+	if err != nil {
+		return ""
+	}
+	cfg := config.NewConfig()
+	if cfg.Unmarshal(data) != nil || len(cfg.Remotes) == 0 {
+		return ""
+	}
+
+	remote, ok := cfg.Remotes[preferred]
+	if !ok {
+		remote = cfg.Remotes[slices.Sorted(maps.Keys(cfg.Remotes))[0]]
+	}
+	if len(remote.URLs) == 0 {
+		return ""
+	}
+	return remote.URLs[0]
+}
+
+// remotePath returns a remote URL as host and path, e.g. "github.com/acme/api" for both
+// git@github.com:acme/api.git and https://token@github.com/acme/api, so one pattern matches
+// every way of writing it; "" for a URL without a host, such as a local path
+func remotePath(rawURL string) string {
+	endpoint, err := transport.NewEndpoint(rawURL)
+	if err != nil || endpoint.Host == "" {
+		return ""
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(endpoint.Path, "/"), ".git")
+	return strings.ToLower(endpoint.Host) + "/" + repoPath
+}
+
+// matchRemote reports whether an --exclude-remote pattern matches a remote as remotePath
+// gives it. The pattern matches the host and path segment by segment, with "*" within one and
+// "**" across any number, like --exclude; a pattern matching only the leading segments matches
+// everything below them, so "github.com/legacy-org" covers the whole organization.
+func matchRemote(pattern, remote string) bool {
+	pattern = strings.TrimSuffix(strings.Trim(strings.TrimSpace(pattern), "/"), ".git")
+	if pattern == "" {
+		return false
+	}
+	parts := strings.Split(strings.ToLower(pattern), "/")
+	segments := strings.Split(remote, "/")
+	for i := len(segments); i > 0; i-- {
+		if matchSegments(parts, segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
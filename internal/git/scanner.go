@@ -2,87 +2,480 @@ package git
 
 import (
 	"context"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
+// maxScanDirs is how many directories a walk reads before giving up without --force-root. A
+// walk of a whole disk gets there within seconds; a workspace does not come near it.
+const maxScanDirs = 200_000
+
 // Scanner handles discovering git repositories in a directory tree
 type Scanner struct {
-	config *types.Config
+	config     *types.Config
+	maxDirs    int       // Directories a walk may read, 0 for no limit
+	indexed    time.Time // When the walk the last FindRepos took from the index was made
+	discovered int       // Repositories the last FindRepos found before --group and --filter
 }
 
+var _ types.RepoFinder = (*Scanner)(nil)
+
 // NewScanner creates a new git repository scanner
 func NewScanner(config *types.Config) *Scanner {
-	return &Scanner{
+	s := &Scanner{
 		config: config,
 	}
+	if !config.ForceRoot {
+		s.maxDirs = maxScanDirs
+	}
+	return s
+}
+
+// CheckRoot refuses, without --force-root, to walk the filesystem root, or the home directory
+// itself when neither a config file (configured) nor --max-depth narrows the walk, since a
+// `git-herd pull /` typed by accident would otherwise touch every repository on the disk.
+// Runs that take their repositories from a manifest do not walk and always pass.
+func (s *Scanner) CheckRoot(rootPath string, configured bool) error {
+	if s.config.ForceRoot || s.manifest() != "" {
+		return nil
+	}
+	dir, err := filepath.Abs(rootPath)
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	if dir == filepath.VolumeName(dir)+string(filepath.Separator) {
+		return fmt.Errorf("refusing to walk the filesystem root %s, pass --force-root to do it anyway", dir)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || configured || s.config.MaxDepth > 0 {
+		return nil
+	}
+	if resolved, err := filepath.EvalSymlinks(home); err == nil {
+		home = resolved
+	}
+	if dir == home {
+		return fmt.Errorf("refusing to walk all of the home directory %s without a config file; narrow the path, set --max-depth, or pass --force-root", dir)
+	}
+	return nil
 }
 
-// FindRepos discovers all git repositories in the given directory
+// FindRepos discovers all git repositories in the given directory, skipping the directories
+// excluded by --exclude or matched by a .herdignore file, and, with --include, keeping only the
+// repositories it selects. --max-depth stops the walk that many levels below the directory, and
+// up to --workers directories are read at a time, with the repositories returned in the same
+// order however many. With --follow-symlinks, symlinked directories are walked too, after the
+// rest, and no directory twice. Each walk is recorded in the --index-file, and --cached takes
+// the repositories from there instead of walking. A directory that is itself a repository is a
+// one-repository run: it is returned alone, without looking for others inside. Bare
+// repositories, such as mirrors, are found too, and never walked into. The clone and
+// versions operations instead return the repositories listed in the manifest, and checkout
+// --lock those in the lockfile, placed below the directory. Each repository is then put in its
+// group, --group keeps only the repositories in it, and --filter only those whose name or path
+// matches it. Repositories inside others are marked with their nesting, and --nested skips them
+// or keeps only them.
 func (s *Scanner) FindRepos(ctx context.Context, rootPath string, onProgress func(int)) ([]types.GitRepo, error) {
-	var repos []types.GitRepo
-	var mu sync.Mutex
-	var foundCount int
+	repos, err := s.discover(ctx, rootPath, onProgress)
+	s.discovered = len(repos)
+	if err != nil {
+		return repos, err
+	}
+	if s.manifest() == "" {
+		repos = selectNested(s.config.Nested, repos)
+	}
+	assignGroups(s.config, rootPath, repos)
+	if s.config.Group != "" {
+		repos = slices.DeleteFunc(repos, func(repo types.GitRepo) bool { return repo.Group != s.config.Group })
+	}
+	if len(s.config.ExcludeRemotes) > 0 {
+		repos = excludeRemotes(s.config, repos)
+	}
+	if s.config.Filter == "" {
+		return repos, nil
+	}
+	return filterRepos(s.config, rootPath, repos)
+}
+
+// selectNested keeps the repositories the --nested mode processes
+func selectNested(mode types.NestedMode, repos []types.GitRepo) []types.GitRepo {
+	switch mode {
+	case types.NestedSkip:
+		return slices.DeleteFunc(repos, func(repo types.GitRepo) bool { return repo.Nesting > 0 })
+	case types.NestedOnly:
+		return slices.DeleteFunc(repos, func(repo types.GitRepo) bool { return repo.Nesting == 0 })
+	default:
+		return repos
+	}
+}
+
+// Discovered returns how many repositories the last FindRepos found before --nested, --group,
+// --exclude-remote and --filter
+func (s *Scanner) Discovered() int {
+	return s.discovered
+}
+
+// discover finds the repositories for FindRepos, before --filter
+func (s *Scanner) discover(ctx context.Context, rootPath string, onProgress func(int)) ([]types.GitRepo, error) {
+	if manifest := s.manifest(); manifest != "" {
+		repos, err := loadManifest(manifest, rootPath, s.config.Operation)
+		if err == nil && onProgress != nil {
+			onProgress(len(repos))
+		}
+		return repos, err
+	}
+
+	if isWorktreeRoot(rootPath) || isBareRepo(rootPath) {
+		name := filepath.Base(rootPath)
+		if abs, err := filepath.Abs(rootPath); err == nil {
+			name = filepath.Base(abs)
+		}
+		if onProgress != nil {
+			onProgress(1)
+		}
+		bare := isBareRepo(rootPath)
+		return []types.GitRepo{{Path: rootPath, Name: name, HasGit: true, Bare: bare, HasSubmodules: !bare && hasSubmodules(rootPath)}}, nil
+	}
+
+	s.indexed = time.Time{}
+	if s.config.Cached && !s.config.Refresh {
+		if repos, ok := s.readIndex(rootPath); ok {
+			if onProgress != nil {
+				onProgress(len(repos))
+			}
+			return repos, nil
+		}
+	}
+
+	stat := os.Lstat
+	if s.config.FollowSymlinks {
+		stat = os.Stat
+	}
+	info, err := stat(rootPath)
+	if err != nil || !info.IsDir() {
+		return nil, err
+	}
+
+	// The first error stops the directories still being read
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	w := &walker{
+		scanner:    s,
+		root:       rootPath,
+		slots:      make(chan struct{}, max(s.config.Workers, 1)-1),
+		cancel:     cancel,
+		onProgress: onProgress,
+		visited:    make(map[dirID]bool),
+	}
+
+	// .herdignore files, at the root and below, prune whole trees before they are walked.
+	// Symlinked directories are walked one at a time after the tree they are found in, so which
+	// path a directory reachable through several is found at does not depend on timing.
+	found := w.visit(ctx, rootPath, walkState{ignore: &herdIgnore{root: rootPath}})
+	repos, links := found.repos, found.links
+	for len(links) > 0 && found.err == nil {
+		found = w.visit(ctx, links[0].path, links[0].state)
+		repos = append(repos, found.repos...)
+		links = append(links[1:], found.links...)
+	}
+
+	disambiguateNames(repos)
+	if found.err == nil {
+		s.writeIndex(rootPath, repos)
+	}
+	return repos, found.err
+}
+
+// walker walks the directory tree for FindRepos, reading up to --workers directories at a time.
+// The repositories below each directory are collected in the order of its entries, so they come
+// out in the order of a sequential walk however the reads interleave.
+type walker struct {
+	scanner    *Scanner
+	root       string
+	slots      chan struct{} // One for each goroutine reading directories besides the caller's
+	cancel     context.CancelCauseFunc
+	onProgress func(int)
+
+	mu      sync.Mutex
+	found   int
+	dirs    int            // Directories read so far
+	visited map[dirID]bool // Directories walked, with --follow-symlinks
+}
+
+// walkState is what a directory inherits from the directories above it
+type walkState struct {
+	ignore    *herdIgnore
+	enclosing string // Innermost repository the directory is in, "" when in none
+	nesting   int    // Repositories the directory is in
+}
+
+// walkLink is a symlinked directory found during a walk, to be walked after it
+type walkLink struct {
+	path  string
+	state walkState
+}
+
+// walkResult is what walking a directory found: the repositories and the symlinked directories
+// at and below it, in walk order, or the error that stopped it
+type walkResult struct {
+	repos []types.GitRepo
+	links []walkLink
+	err   error
+}
+
+// visit walks the directory at path
+func (w *walker) visit(ctx context.Context, path string, state walkState) walkResult {
+	if ctx.Err() != nil {
+		return walkResult{err: context.Cause(ctx)}
+	}
+	s := w.scanner
+
+	// Check if we should exclude this directory
+	for _, exclude := range s.config.ExcludeDirs {
+		if path != w.root && matchDir(exclude, w.root, path) {
+			return walkResult{}
+		}
+	}
+	if state.ignore.ignored(path) {
+		return walkResult{}
+	}
+	if s.config.FollowSymlinks && !w.claim(path) {
+		return walkResult{}
+	}
+	if !w.count() {
+		return walkResult{err: fmt.Errorf("stopped after reading %d directories without finding the end of %s; narrow the path, set --max-depth or --exclude, or pass --force-root", s.maxDirs, w.root)}
+	}
 
-	err := filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
+	// With --max-depth, directories at the limit are checked but not walked
+	atLimit := s.config.MaxDepth > 0 && depthBelow(w.root, path) >= s.config.MaxDepth
+	if !atLimit {
+		ignore, err := state.ignore.load(path)
 		if err != nil {
-			return err
+			return walkResult{err: err}
+		}
+		state.ignore = ignore
+	}
+
+	// Check if this is a git repository
+	if isWorktreeRoot(path) {
+		// With --submodules, submodules are updated along with the repository containing them
+		if s.config.Submodules && state.enclosing != "" && isSubmoduleOf(path, state.enclosing) {
+			return walkResult{}
 		}
 
-		// Check for context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+		// Repositories --include does not select are passed over, but the ones inside them
+		// may still be selected
+		if !s.included(w.root, path) {
+			if !s.config.Recursive || atLimit {
+				return walkResult{}
+			}
+			state.nesting++
+			return w.children(ctx, path, state)
+		}
+
+		// Don't analyze repo here - defer to processing phase for better performance
+		repo := types.GitRepo{
+			Path:          path,
+			Name:          filepath.Base(path),
+			HasGit:        true,
+			Nesting:       state.nesting,
+			HasSubmodules: hasSubmodules(path),
+		}
+		w.progress()
+
+		// Skip subdirectories if not recursive
+		if !s.config.Recursive || atLimit {
+			return walkResult{repos: []types.GitRepo{repo}}
 		}
 
-		// Skip if not a directory
-		if !d.IsDir() {
-			return nil
+		// Walking the worktree is charged to the repository's scan timing, so a huge or slow
+		// (e.g. NFS) worktree shows up on its own result
+		start := time.Now()
+		state.enclosing = path
+		state.nesting++
+		nested := w.children(ctx, path, state)
+		repo.Timings.Scan = time.Since(start)
+		nested.repos = append([]types.GitRepo{repo}, nested.repos...)
+		return nested
+	}
+
+	// A bare repository has no worktree to hold other repositories, only its git directory
+	if isBareRepo(path) {
+		if !s.included(w.root, path) {
+			return walkResult{}
 		}
+		w.progress()
+		return walkResult{repos: []types.GitRepo{{Path: path, Name: filepath.Base(path), HasGit: true, Bare: true, Nesting: state.nesting}}}
+	}
 
-		// Check if we should exclude this directory
-		for _, exclude := range s.config.ExcludeDirs {
-			if strings.Contains(path, exclude) {
-				if d.IsDir() {
-					return filepath.SkipDir
+	if atLimit {
+		return walkResult{}
+	}
+	return w.children(ctx, path, state)
+}
+
+// children visits the subdirectories of the directory at path, each on a goroutine of its own
+// while a slot is free and on the caller's otherwise, and returns what they found in order.
+// With --follow-symlinks, symlinks to directories are returned to be walked later.
+func (w *walker) children(ctx context.Context, path string, state walkState) walkResult {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return walkResult{err: err}
+	}
+
+	found := make([]walkResult, len(entries))
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		if entry.Type()&fs.ModeSymlink != 0 && w.scanner.config.FollowSymlinks {
+			if info, err := os.Stat(child); err == nil && info.IsDir() {
+				found[i].links = []walkLink{{path: child, state: state}}
+			}
+			continue
+		}
+		if !entry.IsDir() {
+			continue
+		}
+		select {
+		case w.slots <- struct{}{}:
+			wg.Go(func() {
+				defer func() { <-w.slots }()
+				if found[i] = w.visit(ctx, child, state); found[i].err != nil {
+					w.cancel(found[i].err)
 				}
-				return nil
+			})
+		default:
+			if found[i] = w.visit(ctx, child, state); found[i].err != nil {
+				w.cancel(found[i].err)
 			}
 		}
+	}
+	wg.Wait()
 
-		// Check if this is a git repository
-		gitPath := filepath.Join(path, ".git")
-		if _, err := os.Stat(gitPath); err == nil {
-			repo := types.GitRepo{
-				Path:   path,
-				Name:   filepath.Base(path),
-				HasGit: true,
-			}
+	if err := context.Cause(ctx); err != nil {
+		return walkResult{err: err}
+	}
+	var result walkResult
+	for _, child := range found {
+		result.repos = append(result.repos, child.repos...)
+		result.links = append(result.links, child.links...)
+	}
+	return result
+}
 
-			// Don't analyze repo here - defer to processing phase for better performance
-			mu.Lock()
-			repos = append(repos, repo)
-			foundCount++
-			currentCount := foundCount
-			mu.Unlock()
+// claim marks the directory at path as walked, reporting false when it already was, e.g.
+// through a symlink, or is unreadable
+func (w *walker) claim(path string) bool {
+	id, err := directoryID(path)
+	if err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.visited[id] {
+		return false
+	}
+	w.visited[id] = true
+	return true
+}
 
-			if onProgress != nil {
-				onProgress(currentCount)
-			}
+// count counts a directory read, reporting false once there are more than the scanner allows
+func (w *walker) count() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dirs++
+	return w.scanner.maxDirs == 0 || w.dirs <= w.scanner.maxDirs
+}
 
-			// Skip subdirectories if not recursive
-			if !s.config.Recursive {
-				return filepath.SkipDir
-			}
+// progress counts a repository found and reports the count so far
+func (w *walker) progress() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.found++
+	if w.onProgress != nil {
+		w.onProgress(w.found)
+	}
+}
+
+// manifest returns the manifest or lockfile that lists the repositories to work on, or "" when
+// they are found by walking the directory
+func (s *Scanner) manifest() string {
+	switch {
+	case s.config.Operation == types.OperationClone || s.config.Operation == types.OperationVersions:
+		return s.config.CloneManifest
+	case s.config.Operation == types.OperationCheckout:
+		return s.config.Lock
+	default:
+		return ""
+	}
+}
+
+// depthBelow counts the directory levels from root down to path, 0 for the root itself
+func depthBelow(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// withinDir reports whether path is dir or lies beneath it
+func withinDir(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// disambiguateNames gives repositories that share a directory name the shortest path suffixes
+// that tell them apart, e.g. "acme/api" and "globex/api", so every display and report can use
+// Name alone
+func disambiguateNames(repos []types.GitRepo) {
+	parts := make([][]string, len(repos))
+	depth := make([]int, len(repos))
+	for i, repo := range repos {
+		parts[i] = strings.Split(filepath.ToSlash(filepath.Clean(repo.Path)), "/")
+		depth[i] = 1
+	}
+
+	name := func(i int) string {
+		d := min(depth[i], len(parts[i]))
+		return strings.Join(parts[i][len(parts[i])-d:], "/")
+	}
+
+	for {
+		groups := make(map[string][]int)
+		for i := range repos {
+			groups[name(i)] = append(groups[name(i)], i)
 		}
 
-		return nil
-	})
+		extended := false
+		for _, members := range groups {
+			if len(members) < 2 {
+				continue
+			}
+			for _, i := range members {
+				if depth[i] < len(parts[i]) {
+					depth[i]++
+					extended = true
+				}
+			}
+		}
+		if !extended {
+			break
+		}
+	}
 
-	return repos, err
+	for i := range repos {
+		if depth[i] > 1 {
+			repos[i].Name = name(i)
+		}
+	}
 }
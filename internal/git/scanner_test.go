@@ -2,8 +2,12 @@ package git
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/entro314-labs/git-herd/pkg/types"
@@ -136,3 +140,583 @@ func TestScanner_ExcludeDirectories(t *testing.T) {
 		t.Errorf("Expected to find 'project', got %s", repos[0].Name)
 	}
 }
+
+func TestScanner_FindRepos_ScanTimings(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"big/.git", "big/src/pkg", "big/docs", "other/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &types.Config{Recursive: true}
+	repos, err := NewScanner(config).FindRepos(t.Context(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected 2 repos, got %d", len(repos))
+	}
+	if repos[0].Name != "big" || repos[0].Timings.Scan <= 0 {
+		t.Errorf("Expected walking big's worktree to be charged to it, got %+v", repos[0].Timings)
+	}
+}
+
+func TestWithinDir(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/src/repo", "/src/repo", true},
+		{"/src/repo/pkg", "/src/repo", true},
+		{"/src/repo-two", "/src/repo", false},
+		{"/src", "/src/repo", false},
+	}
+
+	for _, tt := range tests {
+		if got := withinDir(filepath.FromSlash(tt.path), filepath.FromSlash(tt.dir)); got != tt.want {
+			t.Errorf("withinDir(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestScanner_FindRepos_RootIsRepository(t *testing.T) {
+	// The root's own path contains an excluded directory name, which used to hide it entirely
+	root := filepath.Join(t.TempDir(), "vendor", "tool")
+	for _, dir := range []string{".git", "nested/.git"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &types.Config{Recursive: true, ExcludeDirs: []string{".git", "vendor"}}
+	var progress int
+	repos, err := NewScanner(config).FindRepos(t.Context(), root, func(count int) { progress = count })
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+
+	if len(repos) != 1 || repos[0].Path != root || repos[0].Name != "tool" || !repos[0].HasGit {
+		t.Errorf("Expected only the root repository, got %+v", repos)
+	}
+	if progress != 1 {
+		t.Errorf("Expected progress to report 1 repository, got %d", progress)
+	}
+}
+
+func TestDisambiguateNames(t *testing.T) {
+	repos := []types.GitRepo{
+		{Path: "/work/clients/acme/api", Name: "api"},
+		{Path: "/work/clients/globex/api", Name: "api"},
+		{Path: "/work/teams/a/tools/cli", Name: "cli"},
+		{Path: "/work/teams/b/tools/cli", Name: "cli"},
+		{Path: "/work/web", Name: "web"},
+	}
+	for i := range repos {
+		repos[i].Path = filepath.FromSlash(repos[i].Path)
+	}
+
+	disambiguateNames(repos)
+
+	expected := []string{"acme/api", "globex/api", "a/tools/cli", "b/tools/cli", "web"}
+	for i, want := range expected {
+		if repos[i].Name != want {
+			t.Errorf("repos[%d].Name = %q, want %q", i, repos[i].Name, want)
+		}
+	}
+}
+
+func TestScanner_FindRepos_DuplicateNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"acme/api/.git", "globex/api/.git", "acme/web/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos, err := NewScanner(&types.Config{Recursive: true, ExcludeDirs: []string{".git"}}).FindRepos(t.Context(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.Name)
+	}
+	if strings.Join(names, ",") != "acme/api,web,globex/api" {
+		t.Errorf("Expected disambiguated names, got %v", names)
+	}
+}
+
+func TestScanner_FindRepos_HerdIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{
+		"archive/old/.git", "api/.git", "api-deprecated/.git",
+		"team/web/.git", "team/legacy/.git", "team/keep-deprecated/.git", "other/legacy/.git",
+	} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		HerdIgnoreFile:                        "# retired trees\narchive/**\n*-deprecated\n",
+		filepath.Join("team", HerdIgnoreFile): "legacy\n!keep-deprecated\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos, err := NewScanner(&types.Config{Recursive: true, ExcludeDirs: []string{".git"}}).FindRepos(t.Context(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.Name)
+	}
+	// team/legacy is ignored by team's .herdignore, other/legacy is outside its directory
+	if strings.Join(names, ",") != "api,legacy,keep-deprecated,web" {
+		t.Errorf("Expected .herdignore'd trees to be skipped, got %v", names)
+	}
+}
+
+func TestMatchDir(t *testing.T) {
+	root := filepath.Join("/work", "projects")
+	tests := []struct {
+		pattern string
+		dir     string
+		want    bool
+	}{
+		{"vendor", "vendor", true},
+		{"vendor", "tools/vendor", true},
+		{"vendor", "my-vendor-tools", false},
+		{"tmp-*", "a/tmp-cache", true},
+		{"**/build", "build", true},
+		{"**/build", "web/app/build", true},
+		{"**/build", "web/build-tools", false},
+		{"clients/*/legacy", "clients/acme/legacy", true},
+		{"clients/*/legacy", "old/clients/acme/legacy", false},
+		{"clients/**", "clients/acme/api", true},
+		{"/work/projects/archive", "archive", true},
+		{"/work/projects/archive", "other/archive", false},
+	}
+	for _, tt := range tests {
+		if got := matchDir(tt.pattern, root, filepath.Join(root, tt.dir)); got != tt.want {
+			t.Errorf("matchDir(%q, %q) = %v, want %v", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestScanner_FindRepos_ExcludeInclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{
+		"my-vendor-tools/.git", "vendor/dep/.git", "web/build/.git",
+		"clients/acme/.git", "clients/acme/plugins/.git", "clients/globex/.git",
+	} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	find := func(exclude, include []string) string {
+		t.Helper()
+		config := &types.Config{Recursive: true, ExcludeDirs: append([]string{".git"}, exclude...), IncludeDirs: include}
+		repos, err := NewScanner(config).FindRepos(t.Context(), tmpDir, nil)
+		if err != nil {
+			t.Fatalf("FindRepos failed: %v", err)
+		}
+		var names []string
+		for _, repo := range repos {
+			names = append(names, repo.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := find([]string{"vendor", "**/build"}, nil); got != "acme,plugins,globex,my-vendor-tools" {
+		t.Errorf("Expected vendor and build to be excluded by segment, got %s", got)
+	}
+	if got := find(nil, []string{"clients/*"}); got != "acme,plugins,globex" {
+		t.Errorf("Expected only the clients to be included, got %s", got)
+	}
+	if got := find([]string{"acme"}, []string{"clients", "build"}); got != "globex,build" {
+		t.Errorf("Expected the exclusion to win over the allowlist, got %s", got)
+	}
+}
+
+func TestScanner_FindRepos_MaxDepth(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"api/.git", "api/plugins/.git", "clients/acme/.git", "clients/acme/deep/tools/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	find := func(maxDepth int, recursive bool) string {
+		t.Helper()
+		config := &types.Config{Recursive: recursive, ExcludeDirs: []string{".git"}, MaxDepth: maxDepth}
+		repos, err := NewScanner(config).FindRepos(t.Context(), tmpDir, nil)
+		if err != nil {
+			t.Fatalf("FindRepos failed: %v", err)
+		}
+		var names []string
+		for _, repo := range repos {
+			names = append(names, repo.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := find(0, true); got != "api,plugins,acme,tools" {
+		t.Errorf("Expected every repository without a limit, got %s", got)
+	}
+	if got := find(1, true); got != "api" {
+		t.Errorf("Expected only the repositories one level down, got %s", got)
+	}
+	if got := find(2, true); got != "api,plugins,acme" {
+		t.Errorf("Expected the repositories two levels down, got %s", got)
+	}
+	if got := find(2, false); got != "api,acme" {
+		t.Errorf("Expected repositories not to be walked without --recursive, got %s", got)
+	}
+}
+
+func TestScanner_FindRepos_Cached(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"api/.git", "web/.git", "tools/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &types.Config{Recursive: true, ExcludeDirs: []string{".git"}, IndexFile: filepath.Join(t.TempDir(), "index.json")}
+	find := func() string {
+		t.Helper()
+		repos, err := NewScanner(config).FindRepos(t.Context(), tmpDir, nil)
+		if err != nil {
+			t.Fatalf("FindRepos failed: %v", err)
+		}
+		var names []string
+		for _, repo := range repos {
+			names = append(names, repo.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := find(); got != "api,tools,web" {
+		t.Fatalf("Expected the walk to find every repository, got %s", got)
+	}
+
+	// A repository added after the walk is not in the index, one removed is left out
+	if err := os.MkdirAll(filepath.Join(tmpDir, "docs", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(tmpDir, "web")); err != nil {
+		t.Fatal(err)
+	}
+	config.Cached = true
+	scanner := NewScanner(config)
+	repos, err := scanner.FindRepos(t.Context(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Name != "api" || repos[1].Name != "tools" || scanner.Indexed().IsZero() {
+		t.Errorf("Expected api and tools from the index, got %+v (indexed %v)", repos, scanner.Indexed())
+	}
+
+	// Other settings may find other repositories, so they walk
+	config.ExcludeDirs = []string{".git", "tools"}
+	if got := find(); got != "api,docs" {
+		t.Errorf("Expected a walk with other settings, got %s", got)
+	}
+	config.ExcludeDirs = []string{".git"}
+
+	config.Refresh = true
+	if got := find(); got != "api,docs,tools" {
+		t.Errorf("Expected --refresh to walk again, got %s", got)
+	}
+	config.Refresh = false
+	if got := find(); got != "api,docs,tools" {
+		t.Errorf("Expected the refreshed index, got %s", got)
+	}
+}
+
+func TestScanner_FindRepos_Filter(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"api-gateway/.git", "api-users/.git", "web/.git", "clients/acme/api/.git", "clients/globex/web/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"", "api-gateway,api-users,clients/acme/api,clients/globex/web,web"},
+		{"api-*", "api-gateway,api-users"},
+		{"web", "clients/globex/web,web"},
+		{"clients/**", "clients/acme/api,clients/globex/web"},
+		{"clients/*/api", "clients/acme/api"},
+		{"globex/web", "clients/globex/web"}, // The name, told apart from the other web
+		{"/^api-(users|orders)$/", "api-users"},
+		{"/^clients/.*/web/", "clients/globex/web"},
+		{"nothing-*", ""},
+	}
+	for _, tt := range tests {
+		config := &types.Config{Recursive: true, ExcludeDirs: []string{".git"}, Filter: tt.filter}
+		scanner := NewScanner(config)
+		repos, err := scanner.FindRepos(t.Context(), tmpDir, nil)
+		if err != nil {
+			t.Fatalf("FindRepos(--filter %q) failed: %v", tt.filter, err)
+		}
+		var paths []string
+		for _, repo := range repos {
+			rel, _ := filepath.Rel(tmpDir, repo.Path)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		if got := strings.Join(paths, ","); got != tt.want {
+			t.Errorf("--filter %q: got %s, want %s", tt.filter, got, tt.want)
+		}
+		if scanner.Discovered() != 5 {
+			t.Errorf("--filter %q: expected 5 repositories discovered before filtering, got %d", tt.filter, scanner.Discovered())
+		}
+	}
+}
+
+func TestScanner_FindRepos_Groups(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"tools/.git", "clientA/api/.git", "clientA/web/.git", "clientB/api/.git", "legacy/clientA-old/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		groups map[string][]string
+		group  string
+		want   string
+	}{
+		{"top-level directories", nil, "", "clientA/api=clientA,clientA/web=clientA,clientB/api=clientB,legacy/clientA-old=legacy,tools="},
+		{"declared", map[string][]string{"clientA": {"clientA-*"}, "internal": {"tools", "legacy"}}, "",
+			"clientA/api=clientA,clientA/web=clientA,clientB/api=clientB,legacy/clientA-old=clientA,tools=internal"},
+		{"selected", nil, "clientA", "clientA/api=clientA,clientA/web=clientA"},
+		{"selected declared", map[string][]string{"clientA": {"clientA-*"}}, "clientA", "clientA/api=clientA,clientA/web=clientA,legacy/clientA-old=clientA"},
+	}
+	for _, tt := range tests {
+		config := &types.Config{Recursive: true, ExcludeDirs: []string{".git"}, Groups: tt.groups, Group: tt.group}
+		scanner := NewScanner(config)
+		repos, err := scanner.FindRepos(t.Context(), tmpDir, nil)
+		if err != nil {
+			t.Fatalf("%s: FindRepos failed: %v", tt.name, err)
+		}
+		var got []string
+		for _, repo := range repos {
+			rel, _ := filepath.Rel(tmpDir, repo.Path)
+			got = append(got, filepath.ToSlash(rel)+"="+repo.Group)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, strings.Join(got, ","), tt.want)
+		}
+		if scanner.Discovered() != 5 {
+			t.Errorf("%s: expected 5 repositories discovered before selecting a group, got %d", tt.name, scanner.Discovered())
+		}
+	}
+}
+
+func TestScanner_FindRepos_Workers(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, org := range []string{"acme", "globex", "initech"} {
+		for _, repo := range []string{"api", "web", "tools", "infra"} {
+			for _, dir := range []string{".git", "src/nested/.git", "docs"} {
+				if err := os.MkdirAll(filepath.Join(tmpDir, org, repo, dir), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+
+	find := func(workers int) []string {
+		t.Helper()
+		var counts []int
+		config := &types.Config{Recursive: true, Workers: workers, ExcludeDirs: []string{".git"}}
+		repos, err := NewScanner(config).FindRepos(t.Context(), tmpDir, func(count int) { counts = append(counts, count) })
+		if err != nil {
+			t.Fatalf("FindRepos failed: %v", err)
+		}
+		if len(counts) != len(repos) || counts[len(counts)-1] != len(repos) {
+			t.Errorf("Expected progress to count up to %d, got %v", len(repos), counts)
+		}
+		var paths []string
+		for _, repo := range repos {
+			paths = append(paths, repo.Path)
+		}
+		return paths
+	}
+
+	sequential := find(1)
+	if len(sequential) != 24 {
+		t.Fatalf("Expected 24 repositories, got %d", len(sequential))
+	}
+	for range 5 {
+		if concurrent := find(8); !slices.Equal(concurrent, sequential) {
+			t.Fatalf("Expected the same order with 8 workers as with 1, got\n%v\nwant\n%v", concurrent, sequential)
+		}
+	}
+}
+
+func TestScanner_FindRepos_Nested(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"app/.git", "app/vendor/lib/.git", "app/vendor/lib/testdata/fixture/.git", "tools/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		mode types.NestedMode
+		want string
+	}{
+		{types.NestedInclude, "app=0,app/vendor/lib=1,app/vendor/lib/testdata/fixture=2,tools=0"},
+		{types.NestedSkip, "app=0,tools=0"},
+		{types.NestedOnly, "app/vendor/lib=1,app/vendor/lib/testdata/fixture=2"},
+	}
+	for _, tt := range tests {
+		config := &types.Config{Recursive: true, Workers: 1, ExcludeDirs: []string{".git"}, Nested: tt.mode}
+		scanner := NewScanner(config)
+		repos, err := scanner.FindRepos(t.Context(), tmpDir, nil)
+		if err != nil {
+			t.Fatalf("%s: FindRepos failed: %v", tt.mode, err)
+		}
+		var got []string
+		for _, repo := range repos {
+			rel, _ := filepath.Rel(tmpDir, repo.Path)
+			got = append(got, fmt.Sprintf("%s=%d", filepath.ToSlash(rel), repo.Nesting))
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.mode, strings.Join(got, ","), tt.want)
+		}
+		if scanner.Discovered() != 4 {
+			t.Errorf("%s: expected 4 repositories discovered before --nested, got %d", tt.mode, scanner.Discovered())
+		}
+	}
+
+	// Repositories --include passes over still count as enclosing the ones inside them
+	config := &types.Config{Recursive: true, Workers: 1, ExcludeDirs: []string{".git"}, IncludeDirs: []string{"fixture"}, Nested: types.NestedOnly}
+	repos, err := NewScanner(config).FindRepos(t.Context(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Name != "fixture" || repos[0].Nesting != 2 {
+		t.Errorf("Expected only fixture, nested twice, got %d repositories", len(repos))
+	}
+}
+
+func TestScanner_FindRepos_Canceled(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "api", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	config := &types.Config{Recursive: true, Workers: 4}
+	if _, err := NewScanner(config).FindRepos(ctx, tmpDir, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a canceled walk to fail with context.Canceled, got %v", err)
+	}
+}
+
+func TestScanner_FindRepos_FollowSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	outside := t.TempDir()
+	for _, dir := range []string{filepath.Join(tmpDir, "api", ".git"), filepath.Join(outside, "shared", ".git")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{
+		"linked":   filepath.Join(outside, "shared"), // A repository outside the tree
+		"api-link": filepath.Join(tmpDir, "api"),     // A second way to the same repository
+		"loop":     tmpDir,                           // A cycle back to the root
+	} {
+		if err := os.Symlink(target, filepath.Join(tmpDir, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	find := func(follow bool) string {
+		t.Helper()
+		config := &types.Config{Recursive: true, Workers: 4, ExcludeDirs: []string{".git"}, FollowSymlinks: follow}
+		repos, err := NewScanner(config).FindRepos(t.Context(), tmpDir, nil)
+		if err != nil {
+			t.Fatalf("FindRepos failed: %v", err)
+		}
+		var paths []string
+		for _, repo := range repos {
+			rel, _ := filepath.Rel(tmpDir, repo.Path)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return strings.Join(paths, ",")
+	}
+
+	if got := find(false); got != "api" {
+		t.Errorf("Expected symlinks not to be followed by default, got %s", got)
+	}
+	if got := find(true); got != "api,linked" {
+		t.Errorf("Expected every repository once, at its path in the tree, got %s", got)
+	}
+}
+
+func TestScanner_CheckRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	root := filepath.VolumeName(home) + string(filepath.Separator)
+
+	tests := []struct {
+		name       string
+		path       string
+		config     types.Config
+		configured bool
+		wantErr    bool
+	}{
+		{"filesystem root", root, types.Config{}, true, true},
+		{"filesystem root with force-root", root, types.Config{ForceRoot: true}, false, false},
+		{"home without a config file", home, types.Config{}, false, true},
+		{"home with a config file", home, types.Config{}, true, false},
+		{"home with max-depth", home, types.Config{MaxDepth: 2}, false, false},
+		{"below home", filepath.Join(home, "Projects"), types.Config{}, false, false},
+		{"filesystem root with a manifest", root, types.Config{Operation: types.OperationClone, CloneManifest: "repos.yaml"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewScanner(&tt.config).CheckRoot(tt.path, tt.configured)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckRoot(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScanner_FindRepos_MaxDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"a/.git", "b/c", "d/e/f"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scanner := NewScanner(&types.Config{Recursive: true, ExcludeDirs: []string{".git"}})
+	scanner.maxDirs = 4
+	if _, err := scanner.FindRepos(t.Context(), tmpDir, nil); err == nil || !strings.Contains(err.Error(), "--force-root") {
+		t.Errorf("Expected the walk to stop past 4 directories, got %v", err)
+	}
+
+	scanner = NewScanner(&types.Config{Recursive: true, ExcludeDirs: []string{".git"}, ForceRoot: true})
+	if repos, err := scanner.FindRepos(t.Context(), tmpDir, nil); err != nil || len(repos) != 1 {
+		t.Errorf("Expected --force-root to lift the limit, got %d repositories (error %v)", len(repos), err)
+	}
+}
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+// securityFindings lists repository-local settings that are common persistence vectors in
+// cloned repositories: executable hooks, fsmonitor commands, hooksPath redirection, and
+// credential helper overrides
+func securityFindings(repoPath string, gitRepo *gogit.Repository) []string {
+	findings := executableHooks(filepath.Join(commonDir(repoPath), "hooks"))
+
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		return append(findings, fmt.Sprintf("unable to read local config: %v", err))
+	}
+
+	core := cfg.Raw.Section("core")
+	if fsmonitor := core.Option("fsmonitor"); fsmonitor != "" && !isGitBool(fsmonitor) {
+		findings = append(findings, fmt.Sprintf("core.fsmonitor runs %q", fsmonitor))
+	}
+	if hooksPath := core.Option("hooksPath"); hooksPath != "" {
+		findings = append(findings, fmt.Sprintf("core.hooksPath set to %q", hooksPath))
+	}
+
+	credential := cfg.Raw.Section("credential")
+	for _, helper := range credential.OptionAll("helper") {
+		findings = append(findings, fmt.Sprintf("credential.helper overridden: %q", helper))
+	}
+	for _, subsection := range credential.Subsections {
+		for _, helper := range subsection.OptionAll("helper") {
+			findings = append(findings, fmt.Sprintf("credential.%s.helper overridden: %q", subsection.Name, helper))
+		}
+	}
+
+	return findings
+}
+
+// executableHooks returns findings for executable hook scripts, ignoring git's *.sample files
+func executableHooks(hooksDir string) []string {
+	entries, err := os.ReadDir(hooksDir)
+	if err != nil {
+		return nil
+	}
+
+	var hooks []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".sample") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.Mode().Perm()&0o111 != 0 {
+			hooks = append(hooks, entry.Name())
+		}
+	}
+	slices.Sort(hooks)
+
+	findings := make([]string, 0, len(hooks))
+	for _, hook := range hooks {
+		findings = append(findings, fmt.Sprintf("executable hook: %s", hook))
+	}
+	return findings
+}
+
+// isGitBool reports whether a config value is one of git's boolean spellings
+func isGitBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "1", "0":
+		return true
+	default:
+		return false
+	}
+}
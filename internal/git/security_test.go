@@ -0,0 +1,79 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+func TestSecurityFindings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gitRepo, err := gogit.PlainInit(tmpDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	hooksDir := filepath.Join(tmpDir, ".git", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatalf("Failed to create hooks dir: %v", err)
+	}
+	hooks := map[string]os.FileMode{
+		"post-checkout":     0755,
+		"pre-commit.sample": 0755,
+		"pre-push":          0644,
+	}
+	for name, mode := range hooks {
+		if err := os.WriteFile(filepath.Join(hooksDir, name), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatalf("Failed to write hook %s: %v", name, err)
+		}
+	}
+
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	cfg.Raw.Section("core").SetOption("fsmonitor", "/tmp/monitor.sh")
+	cfg.Raw.Section("core").SetOption("hooksPath", "/tmp/hooks")
+	cfg.Raw.Section("credential").Subsection("https://example.com").SetOption("helper", "!/tmp/steal.sh")
+	if err := gitRepo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	got := securityFindings(tmpDir, gitRepo)
+	expected := []string{
+		"executable hook: post-checkout",
+		`core.fsmonitor runs "/tmp/monitor.sh"`,
+		`core.hooksPath set to "/tmp/hooks"`,
+		`credential.https://example.com.helper overridden: "!/tmp/steal.sh"`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected findings %v, got %v", expected, got)
+	}
+}
+
+func TestSecurityFindings_CleanRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gitRepo, err := gogit.PlainInit(tmpDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	// The builtin fsmonitor daemon is not an anomaly
+	cfg.Raw.Section("core").SetOption("fsmonitor", "true")
+	if err := gitRepo.SetConfig(cfg); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if got := securityFindings(tmpDir, gitRepo); len(got) != 0 {
+		t.Errorf("Expected no findings, got %v", got)
+	}
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// Select returns the repositories --only, --on-branch and --remote-host pick, in the order of
+// repos. Each repository is analyzed for it, --workers at a time, and its ahead and behind
+// counts read when --only asks for them; nothing is fetched, so they are as of the last fetch.
+// Repositories that cannot be analyzed are kept, so that processing reports what is wrong with
+// them instead of leaving them out unnoticed.
+func (p *Processor) Select(ctx context.Context, repos []types.GitRepo) []types.GitRepo {
+	keep := make([]bool, len(repos))
+	g := new(errgroup.Group)
+	g.SetLimit(max(p.config.Workers, 1))
+	for i, repo := range repos {
+		g.Go(func() error {
+			p.AnalyzeRepo(&repo)
+			if repo.Error == nil && (slices.Contains(p.config.Only, string(types.StateAhead)) || slices.Contains(p.config.Only, string(types.StateBehind))) {
+				p.readStatus(ctx, &repo)
+			}
+			keep[i] = repo.Error != nil || p.selected(repo)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var selected []types.GitRepo
+	for i, repo := range repos {
+		if keep[i] {
+			selected = append(selected, repo)
+		}
+	}
+	return selected
+}
+
+// selected reports whether an analyzed repository is in every state --only lists, on a branch
+// --on-branch matches, and has its remote on one of the --remote-host hosts
+func (p *Processor) selected(repo types.GitRepo) bool {
+	for _, state := range p.config.Only {
+		if !inState(repo, types.RepoState(state)) {
+			return false
+		}
+	}
+	if p.config.OnBranch != "" {
+		if matched, _ := path.Match(p.config.OnBranch, repo.Branch); !matched || repo.Branch == "detached" {
+			return false
+		}
+	}
+	if len(p.config.RemoteHosts) > 0 {
+		host := urlHost(repo.RemoteURL)
+		if host == "" || !slices.ContainsFunc(p.config.RemoteHosts, func(h string) bool { return strings.EqualFold(h, host) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// inState reports whether an analyzed repository is in the state
+func inState(repo types.GitRepo, state types.RepoState) bool {
+	switch state {
+	case types.StateDirty:
+		return !repo.Clean
+	case types.StateClean:
+		return repo.Clean
+	case types.StateAhead:
+		return repo.Ahead > 0
+	case types.StateBehind:
+		return repo.Behind > 0
+	case types.StateDetached:
+		return repo.Branch == "detached"
+	case types.StateNoUpstream:
+		return repo.Branch != "detached" && repo.Upstream == ""
+	default:
+		return false
+	}
+}
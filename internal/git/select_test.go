@@ -0,0 +1,69 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_Select(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	initTestRepo(t, api)
+	runGit(t, api, "remote", "add", "origin", "git@github.com:acme/api.git")
+	if err := os.WriteFile(filepath.Join(api, "notes.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	web := filepath.Join(root, "web")
+	initTestRepo(t, web)
+	runGit(t, web, "remote", "add", "origin", "https://gitlab.com/acme/web.git")
+	runGit(t, web, "checkout", "--quiet", "-b", "release/1.0")
+
+	detached := filepath.Join(root, "detached")
+	initTestRepo(t, detached)
+	runGit(t, detached, "checkout", "--quiet", "--detach")
+
+	// Ahead by its own commit and, once fetched, behind by the upstream's
+	_, diverged := cloneTestRepo(t, divergedClone)
+	runGit(t, diverged, "fetch", "--quiet")
+
+	repos := []types.GitRepo{
+		{Path: api, Name: "api"},
+		{Path: web, Name: "web"},
+		{Path: detached, Name: "detached"},
+		{Path: diverged, Name: "diverged"},
+		{Path: filepath.Join(root, "gone"), Name: "gone"}, // Fails analysis, so is always kept
+	}
+
+	tests := []struct {
+		name   string
+		config types.Config
+		want   string
+	}{
+		{"dirty", types.Config{Only: []string{"dirty"}}, "api,gone"},
+		{"clean and behind", types.Config{Only: []string{"clean", "behind"}}, "diverged,gone"},
+		{"ahead", types.Config{Only: []string{"ahead"}}, "diverged,gone"},
+		{"detached", types.Config{Only: []string{"detached"}}, "detached,gone"},
+		{"no upstream", types.Config{Only: []string{"no-upstream"}}, "api,web,gone"},
+		{"on branch glob", types.Config{OnBranch: "release/*"}, "web,gone"},
+		{"remote host", types.Config{RemoteHosts: []string{"GitHub.com", "bitbucket.org"}}, "api,gone"},
+		{"all of them", types.Config{Only: []string{"dirty"}, RemoteHosts: []string{"gitlab.com"}}, "gone"},
+	}
+	for _, tt := range tests {
+		tt.config.Workers = 2
+		var names []string
+		for _, repo := range NewProcessor(&tt.config).Select(t.Context(), repos) {
+			names = append(names, repo.Name)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version, e.g. from the tag "v1.4.0-rc.1+build.5"
+type semver struct {
+	major, minor, patch int
+	prerelease          []string // Dot-separated identifiers after "-", empty for a release
+}
+
+// parseSemver parses a tag as a semantic version, with or without a leading "v". Missing minor
+// and patch numbers count as 0, so "v2" and "v2.1" are versions too; build metadata is ignored.
+func parseSemver(tag string) (semver, bool) {
+	s := strings.TrimPrefix(tag, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return semver{}, false
+		}
+		numbers[i] = n
+	}
+
+	v := semver{major: numbers[0], minor: numbers[1], patch: numbers[2]}
+	if hasPre {
+		if pre == "" {
+			return semver{}, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// compare orders versions by semver precedence: a pre-release comes before its release, and
+// pre-release identifiers compare numerically when both are numbers
+func (v semver) compare(other semver) int {
+	if c := cmp.Or(cmp.Compare(v.major, other.major), cmp.Compare(v.minor, other.minor), cmp.Compare(v.patch, other.patch)); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := range min(len(v.prerelease), len(other.prerelease)) {
+		a, b := v.prerelease[i], other.prerelease[i]
+		an, aErr := strconv.Atoi(a)
+		bn, bErr := strconv.Atoi(b)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1 // Numeric identifiers come before alphanumeric ones
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a, b)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(v.prerelease), len(other.prerelease))
+}
@@ -0,0 +1,45 @@
+package git
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	for _, tag := range []string{"v1.4.0", "1.4.0", "v2", "v2.1", "v1.0.0-rc.1", "v1.0.0+build.5"} {
+		if _, ok := parseSemver(tag); !ok {
+			t.Errorf("parseSemver(%q) failed", tag)
+		}
+	}
+	for _, tag := range []string{"release-2024", "v1.2.3.4", "v01.2.3", "v1.0.0-", "latest", ""} {
+		if _, ok := parseSemver(tag); ok {
+			t.Errorf("parseSemver(%q) should fail", tag)
+		}
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// In ascending order of precedence, per semver.org
+	ordered := []string{
+		"v1.0.0-alpha", "v1.0.0-alpha.1", "v1.0.0-alpha.beta", "v1.0.0-beta", "v1.0.0-beta.2",
+		"v1.0.0-beta.11", "v1.0.0-rc.1", "v1.0.0", "v1.2", "v1.10.0", "v2",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := parseSemver(ordered[i])
+			b, _ := parseSemver(ordered[j])
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := a.compare(b); got != want {
+				t.Errorf("compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	a, _ := parseSemver("1.4.0")
+	b, _ := parseSemver("v1.4.0+build.7")
+	if a.compare(b) != 0 {
+		t.Error("Expected the v prefix and build metadata not to matter")
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// setRemoteURLs replaces --url-match with --url-replace in every fetch and push URL of the
+// repository's remotes (set-url), recording each rewrite in repo.URLRewrites. Repositories
+// without a matching URL are left alone. In dry-run mode the rewrites are only planned.
+func (p *Processor) setRemoteURLs(ctx context.Context, repo *types.GitRepo) error {
+	// Only the repository's own config: remotes are not configured globally. git config exits
+	// with status 1 when nothing matches, i.e. without remotes.
+	output, err := p.gitCommand(ctx, repo.Path, "config", "--local", "--get-regexp", `^remote\..*\.(url|pushurl)$`).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return fmt.Errorf("failed to read remote URLs: %w", err)
+	}
+
+	for _, line := range parseLines(string(output)) {
+		key, from, found := strings.Cut(line, " ")
+		if !found || !strings.Contains(from, p.config.URLMatch) {
+			continue
+		}
+		to := strings.ReplaceAll(from, p.config.URLMatch, p.config.URLReplace)
+		if to == from {
+			continue
+		}
+
+		if !p.config.DryRun {
+			// A remote may have several URLs, so only this one is replaced
+			if output, err := p.gitCommand(ctx, repo.Path, "config", "--local", "--fixed-value", "--replace-all", key, to, from).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to set %s: %w (output: %s)", key, err, strings.TrimSpace(string(output)))
+			}
+		}
+
+		name, setting, _ := cutLast(strings.TrimPrefix(key, "remote."), ".")
+		repo.URLRewrites = append(repo.URLRewrites, types.Rewrite{
+			Remote: name,
+			Push:   setting == "pushurl",
+			From:   redactURL(from),
+			To:     redactURL(to),
+		})
+	}
+	return nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_ProcessRepo_SetURL(t *testing.T) {
+	requireGit(t)
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	initTestRepo(t, repoPath)
+	runGit(t, repoPath, "remote", "add", "origin", "git@old-host:acme/api.git")
+	runGit(t, repoPath, "config", "--add", "remote.origin.pushurl", "https://old-host/acme/api.git")
+	runGit(t, repoPath, "config", "--add", "remote.origin.pushurl", "git@old-host:acme/api.git")
+	runGit(t, repoPath, "remote", "add", "fork.mirror", "git@old-host:me/api.git")
+	runGit(t, repoPath, "remote", "add", "upstream", "git@elsewhere:acme/api.git")
+
+	config := &types.Config{Operation: types.OperationSetURL, URLMatch: "git@old-host:", URLReplace: "git@new-host:", DryRun: true}
+	want := []types.Rewrite{
+		{Remote: "origin", From: "git@old-host:acme/api.git", To: "git@new-host:acme/api.git"},
+		{Remote: "origin", Push: true, From: "git@old-host:acme/api.git", To: "git@new-host:acme/api.git"},
+		{Remote: "fork.mirror", From: "git@old-host:me/api.git", To: "git@new-host:me/api.git"},
+	}
+
+	planned := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "repo"})
+	if planned.Error != nil || !slices.Equal(planned.URLRewrites, want) {
+		t.Fatalf("Expected rewrites %+v to be planned, got %+v (%v)", want, planned.URLRewrites, planned.Error)
+	}
+	if url := runGit(t, repoPath, "remote", "get-url", "origin"); url != "git@old-host:acme/api.git" {
+		t.Errorf("Expected a dry run to leave origin alone, got %s", url)
+	}
+
+	config.DryRun = false
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "repo"})
+	if result.Error != nil || !slices.Equal(result.URLRewrites, want) {
+		t.Fatalf("Expected rewrites %+v, got %+v (%v)", want, result.URLRewrites, result.Error)
+	}
+	if url := runGit(t, repoPath, "remote", "get-url", "fork.mirror"); url != "git@new-host:me/api.git" {
+		t.Errorf("Expected fork.mirror to be rewritten, got %s", url)
+	}
+	if urls := runGit(t, repoPath, "config", "--get-all", "remote.origin.pushurl"); urls != "https://old-host/acme/api.git\ngit@new-host:acme/api.git" {
+		t.Errorf("Expected only the matching push URL to be rewritten, got %q", urls)
+	}
+	if url := runGit(t, repoPath, "remote", "get-url", "upstream"); url != "git@elsewhere:acme/api.git" {
+		t.Errorf("Expected upstream to be left alone, got %s", url)
+	}
+
+	again := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "repo"})
+	if again.Error != nil || len(again.URLRewrites) != 0 {
+		t.Errorf("Expected nothing left to rewrite, got %+v (%v)", again.URLRewrites, again.Error)
+	}
+}
+
+func TestProcessor_ProcessRepo_SetURLWithoutRemotes(t *testing.T) {
+	requireGit(t)
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	initTestRepo(t, repoPath)
+
+	config := &types.Config{Operation: types.OperationSetURL, URLMatch: "git@old-host:", URLReplace: "git@new-host:"}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: repoPath, Name: "repo"})
+	if result.Error != nil || len(result.URLRewrites) != 0 {
+		t.Errorf("Expected nothing to rewrite, got %+v (%v)", result.URLRewrites, result.Error)
+	}
+}
@@ -0,0 +1,130 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// isShallow reports whether the repository is a shallow clone, which git records by listing
+// the commits whose parents were cut off in the shallow file
+func isShallow(repoPath string) bool {
+	_, err := os.Stat(filepath.Join(commonDir(repoPath), "shallow"))
+	return err == nil
+}
+
+// readDepth records whether the repository is shallow and, if so, how many commits of the
+// current branch's history it has
+func (p *Processor) readDepth(ctx context.Context, repo *types.GitRepo) {
+	repo.Shallow, repo.Depth = isShallow(repo.Path), 0
+	if !repo.Shallow || repo.Empty {
+		return
+	}
+	output, err := p.gitCommand(ctx, repo.Path, "rev-list", "--count", "HEAD").Output()
+	if err != nil {
+		return
+	}
+	repo.Depth, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// fetchDepth fetches the configured remote with --depth or --unshallow. go-git cannot deepen
+// or unshallow an existing clone, so this uses the git CLI. Unshallowing a complete clone is
+// an error for git, so such repositories are fetched as usual.
+func (p *Processor) fetchDepth(ctx context.Context, gitRepo *gogit.Repository, repo *types.GitRepo) error {
+	args := []string{"fetch", "--quiet"}
+	switch {
+	case p.config.Depth > 0:
+		args = append(args, "--depth", strconv.Itoa(p.config.Depth))
+	case isShallow(repo.Path):
+		args = append(args, "--unshallow")
+	}
+	args = append(args, p.remoteName())
+
+	return p.withRateLimitRetry(ctx, gitRepo, repo.Name, func() error {
+		if output, err := p.gitCommand(ctx, repo.Path, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%w (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	})
+}
+
+// pullTarget returns the remote-tracking branch a pull of the current branch moves it to
+func (p *Processor) pullTarget(repo *types.GitRepo) string {
+	return p.remoteName() + "/" + pullBranch(repo, p.remoteName())
+}
+
+// trackingTip returns the commit the pull target points at, or "" when there is none yet
+func (p *Processor) trackingTip(ctx context.Context, repo *types.GitRepo) string {
+	output, err := p.gitCommand(ctx, repo.Path, "rev-parse", "--verify", "--quiet", p.pullTarget(repo)+"^{commit}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// isAncestor reports whether commit is an ancestor of, or the same as, descendant
+func (p *Processor) isAncestor(ctx context.Context, repoPath, commit, descendant string) (bool, error) {
+	// is-ancestor exits with status 1 when commit is not an ancestor of descendant
+	err := p.gitCommand(ctx, repoPath, "merge-base", "--is-ancestor", commit, descendant).Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to compare %s with %s: %w", commit, descendant, err)
+	}
+	return true, nil
+}
+
+// fastForward brings the current branch up to what fetchDepth fetched, with the git CLI. The
+// history of a shallow clone stops at its graft, so git cannot always see that HEAD leads to
+// the fetched tip: a branch whose HEAD was already part of what the remote had before the
+// fetch (before, the tip of the pull target then) has no commits of its own, and is moved to
+// the fetched tip too. Any other branch has diverged, and is reported the way go-git reports
+// it, as gogit.ErrNonFastForwardUpdate.
+func (p *Processor) fastForward(ctx context.Context, repo *types.GitRepo, before string) error {
+	if repo.Branch == "" || repo.Branch == "detached" {
+		return errors.New("detached HEAD: no branch to pull (skipped)")
+	}
+	target := p.pullTarget(repo)
+
+	ancestor, err := p.isAncestor(ctx, repo.Path, "HEAD", target)
+	if err != nil {
+		return err
+	}
+	if ancestor {
+		if output, err := p.gitCommand(ctx, repo.Path, "merge", "--quiet", "--ff-only", target).CombinedOutput(); err != nil {
+			return fmt.Errorf("fast-forward to %s failed: %w (output: %s)", target, err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	pushed := false
+	if before != "" {
+		if pushed, err = p.isAncestor(ctx, repo.Path, "HEAD", before); err != nil {
+			return err
+		}
+	}
+	if !pushed {
+		return gogit.ErrNonFastForwardUpdate
+	}
+	// --keep carries uncommitted changes over, and refuses to touch files they would be lost in
+	if output, err := p.gitCommand(ctx, repo.Path, "reset", "--quiet", "--keep", target).CombinedOutput(); err != nil {
+		return fmt.Errorf("fast-forward to %s failed: %w (output: %s)", target, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// shapesHistory reports whether fetch and pull change how much history clones have
+func (p *Processor) shapesHistory() bool {
+	return p.config.Depth > 0 || p.config.Unshallow
+}
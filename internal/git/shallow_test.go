@@ -0,0 +1,104 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// shallowClone clones a repository with five commits at depth 1
+var shallowClone = cloneOptions{Commits: []string{"a.txt", "b.txt", "c.txt", "d.txt"}, Depth: 1}
+
+func TestProcessor_ProcessRepo_ScanDepth(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+	upstream, clone := cloneTestRepo(t, shallowClone)
+
+	config := &types.Config{Operation: types.OperationScan}
+	if result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"}); !result.Shallow || result.Depth != 1 {
+		t.Errorf("Expected the clone to be recorded as shallow at depth 1, got %v at %d (%v)", result.Shallow, result.Depth, result.Error)
+	}
+	if result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: upstream, Name: "upstream"}); result.Shallow || result.Depth != 0 {
+		t.Errorf("Expected a complete clone not to be recorded as shallow, got %v at %d", result.Shallow, result.Depth)
+	}
+}
+
+func TestProcessor_ProcessRepo_FetchDepth(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+	_, clone := cloneTestRepo(t, shallowClone)
+
+	config := &types.Config{Operation: types.OperationFetch, Remote: "origin", Depth: 3}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if result.Error != nil {
+		t.Fatalf("Expected the fetch to deepen the clone, got %v", result.Error)
+	}
+	if count := runGit(t, clone, "rev-list", "--count", "origin/master"); count != "3" {
+		t.Errorf("Expected origin/master to have 3 commits, got %s", count)
+	}
+	if !result.Shallow || result.Depth != 3 {
+		t.Errorf("Expected depth 3 to be recorded, got %v at %d", result.Shallow, result.Depth)
+	}
+}
+
+func TestProcessor_ProcessRepo_PullDepth(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	t.Run("depth", func(t *testing.T) {
+		upstream, clone := cloneTestRepo(t, shallowClone)
+		commitFile(t, upstream, "e.txt", "e\n")
+
+		config := &types.Config{Operation: types.OperationPull, Remote: "origin", Depth: 1}
+		result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+		if result.Error != nil {
+			t.Fatalf("Expected the shallow clone to be pulled, got %v", result.Error)
+		}
+		if head, want := runGit(t, clone, "rev-parse", "HEAD"), runGit(t, upstream, "rev-parse", "HEAD"); head != want {
+			t.Errorf("Expected HEAD to be %s, got %s", want, head)
+		}
+		if !result.Shallow {
+			t.Error("Expected the clone to stay shallow")
+		}
+	})
+
+	t.Run("diverged", func(t *testing.T) {
+		upstream, clone := cloneTestRepo(t, shallowClone)
+		commitFile(t, upstream, "e.txt", "e\n")
+		commitFile(t, clone, "ours.txt", "ours\n")
+		head := runGit(t, clone, "rev-parse", "HEAD")
+
+		config := &types.Config{Operation: types.OperationPull, Remote: "origin", Depth: 1}
+		result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+		if result.Error == nil || !strings.Contains(result.Error.Error(), "diverged") {
+			t.Errorf("Expected a clone with commits of its own to be reported diverged, got %v", result.Error)
+		}
+		if got := runGit(t, clone, "rev-parse", "HEAD"); got != head {
+			t.Errorf("Expected HEAD to stay at %s, got %s", head, got)
+		}
+	})
+
+	t.Run("unshallow", func(t *testing.T) {
+		upstream, clone := cloneTestRepo(t, shallowClone)
+		commitFile(t, upstream, "e.txt", "e\n")
+
+		config := &types.Config{Operation: types.OperationPull, Remote: "origin", Unshallow: true}
+		result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+		if result.Error != nil {
+			t.Fatalf("Expected the shallow clone to be unshallowed, got %v", result.Error)
+		}
+		if count := runGit(t, clone, "rev-list", "--count", "HEAD"); count != "6" {
+			t.Errorf("Expected the complete history of 6 commits, got %s", count)
+		}
+		if result.Shallow || result.Depth != 0 {
+			t.Errorf("Expected the clone to no longer be shallow, got %v at %d", result.Shallow, result.Depth)
+		}
+
+		// Unshallowing a complete clone is a plain fetch
+		again := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+		if again.Error != nil {
+			t.Errorf("Expected a complete clone to be pulled as usual, got %v", again.Error)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// stashMessage marks the stashes git-herd creates, so stash pop never restores one a person made
+const stashMessage = "git-herd stash"
+
+// stashesDirty reports whether the operation stashes uncommitted changes instead of skipping
+// dirty repositories: the stash operation itself, and pull with --autostash
+func (p *Processor) stashesDirty() bool {
+	return p.config.Operation == types.OperationStash ||
+		(p.config.Operation == types.OperationPull && p.config.AutoStash)
+}
+
+// stashChanges stashes the repository's uncommitted changes, untracked files included, and
+// records that in repo.Stashed. A clean repository has nothing to stash. In dry-run mode the
+// stash is only planned.
+func (p *Processor) stashChanges(ctx context.Context, repo *types.GitRepo) error {
+	if repo.Clean {
+		return nil
+	}
+
+	if !p.config.DryRun {
+		cmd := p.gitCommand(ctx, repo.Path, "stash", "push", "--include-untracked", "--message", stashMessage)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("stash failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+		// go-git can see changes git itself does not, such as file mode differences on some filesystems
+		if strings.Contains(string(output), "No local changes to save") {
+			return nil
+		}
+	}
+
+	repo.Stashed = true
+	return nil
+}
+
+// popStash restores the most recent stash if git-herd created it, recording that in
+// repo.Unstashed. A repository whose latest stash is someone else's, or that has none, is
+// skipped. In dry-run mode the pop is only planned.
+func (p *Processor) popStash(ctx context.Context, repo *types.GitRepo) error {
+	output, err := p.gitCommand(ctx, repo.Path, "stash", "list", "--max-count=1", "--format=%gs").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list stashes: %w", err)
+	}
+	// Stash subjects read "On <branch>: <message>"
+	if !strings.HasSuffix(strings.TrimSpace(string(output)), ": "+stashMessage) {
+		return errors.New("no git-herd stash to pop (skipped)")
+	}
+
+	if !p.config.DryRun {
+		cmd := p.gitCommand(ctx, repo.Path, "stash", "pop")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("stash pop failed, changes kept in stash@{0}: %w (output: %s)", err, strings.TrimSpace(string(output)))
+		}
+		p.AnalyzeRepo(repo)
+	}
+
+	repo.Unstashed = true
+	return nil
+}
+
+// withAutostash runs op, a pull, with the repository's uncommitted changes stashed away when
+// --autostash is given. The changes are restored afterwards whether or not op succeeded.
+func (p *Processor) withAutostash(ctx context.Context, repo *types.GitRepo, op func() error) error {
+	if !p.config.AutoStash || repo.Clean {
+		return op()
+	}
+
+	if err := p.stashChanges(ctx, repo); err != nil {
+		return err
+	}
+	opErr := op()
+	if !repo.Stashed {
+		return opErr
+	}
+	if err := p.popStash(ctx, repo); err != nil {
+		return errors.Join(opErr, fmt.Errorf("failed to restore stashed changes: %w", err))
+	}
+	return opErr
+}
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// setGitIdentity gives git CLI commands in the test a committer, which stashing needs
+func setGitIdentity(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(name+"_NAME", "git-herd")
+		t.Setenv(name+"_EMAIL", "test@example.com")
+	}
+}
+
+// stashList returns the subjects of the repository's stashes, newest first
+func stashList(t *testing.T, path string) []string {
+	t.Helper()
+
+	cmd := exec.Command("git", "stash", "list", "--format=%gs")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git stash list failed: %v", err)
+	}
+	if len(output) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+}
+
+func TestProcessor_ProcessRepo_StashAndPop(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	path := t.TempDir()
+	initTestRepo(t, path)
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "notes.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stashed := NewProcessor(&types.Config{Operation: types.OperationStash, SkipDirty: true}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if stashed.Error != nil || !stashed.Stashed {
+		t.Fatalf("Expected the changes to be stashed, got %+v", stashed)
+	}
+	if _, err := os.Stat(filepath.Join(path, "notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected untracked files to be stashed too, got %v", err)
+	}
+	if stashes := stashList(t, path); len(stashes) != 1 {
+		t.Fatalf("Expected one stash, got %v", stashes)
+	}
+
+	// A clean repository has nothing to stash
+	again := NewProcessor(&types.Config{Operation: types.OperationStash}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if again.Error != nil || again.Stashed {
+		t.Errorf("Expected nothing to stash, got %+v", again)
+	}
+
+	popped := NewProcessor(&types.Config{Operation: types.OperationStashPop}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if popped.Error != nil || !popped.Unstashed {
+		t.Fatalf("Expected the stash to be popped, got %+v", popped)
+	}
+	if popped.Clean || len(popped.ModifiedFiles) != 2 {
+		t.Errorf("Expected both changes to be back, got %v", popped.ModifiedFiles)
+	}
+	if stashes := stashList(t, path); len(stashes) != 0 {
+		t.Errorf("Expected no stashes left, got %v", stashes)
+	}
+}
+
+func TestProcessor_ProcessRepo_StashPopLeavesOwnStashes(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	path := t.TempDir()
+	initTestRepo(t, path)
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stash := exec.Command("git", "stash", "push", "--message", "work in progress")
+	stash.Dir = path
+	if output, err := stash.CombinedOutput(); err != nil {
+		t.Fatalf("git stash failed: %v\n%s", err, output)
+	}
+
+	result := NewProcessor(&types.Config{Operation: types.OperationStashPop}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "skipped") {
+		t.Errorf("Expected a stash made by hand to be skipped, got %v", result.Error)
+	}
+	if stashes := stashList(t, path); len(stashes) != 1 {
+		t.Errorf("Expected the stash to be kept, got %v", stashes)
+	}
+}
+
+func TestProcessor_ProcessRepo_PullAutostashDryRun(t *testing.T) {
+	path, repo := initTrackedRepo(t)
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &types.Config{Operation: types.OperationPull, SkipDirty: true, AutoStash: true, DryRun: true, Remote: "origin"}
+	result := NewProcessor(config).ProcessRepo(t.Context(), repo)
+	if result.Error != nil {
+		t.Fatalf("Expected a dirty repository to be pulled with autostash, got %v", result.Error)
+	}
+	if !result.Stashed || !result.Unstashed {
+		t.Errorf("Expected the dry run to plan stashing and restoring, got stashed=%v unstashed=%v", result.Stashed, result.Unstashed)
+	}
+}
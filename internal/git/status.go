@@ -0,0 +1,109 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// fetchBeforeStatus fetches the configured remote so that the status that follows compares
+// against current remote-tracking branches (--fetch-first). Repositories without the remote or
+// without commits are left as they are, as is everything in dry-run mode.
+func (p *Processor) fetchBeforeStatus(ctx context.Context, repo *types.GitRepo) error {
+	if !p.config.FetchFirst || p.config.DryRun || repo.Empty || repo.Remote != p.remoteName() {
+		return nil
+	}
+
+	gitRepo, err := openRepo(repo.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	networkStart := time.Now()
+	err = p.fetchRepo(ctx, gitRepo, repo)
+	repo.Timings.Network += time.Since(networkStart)
+	return err
+}
+
+// readStatus records how far the current branch is ahead of and behind its upstream and how
+// many stash entries the repository has. The git CLI counts both, since go-git neither walks
+// commit ranges efficiently nor reads reflogs, where stashes live.
+func (p *Processor) readStatus(ctx context.Context, repo *types.GitRepo) {
+	if repo.Empty {
+		return
+	}
+
+	ref := p.upstreamRef(repo)
+	repo.UpstreamGone = repo.Upstream != "" && ref == ""
+	if ref != "" {
+		output, err := p.gitCommand(ctx, repo.Path, "rev-list", "--left-right", "--count", "HEAD..."+ref.String()).Output()
+		if err != nil {
+			repo.Error = fmt.Errorf("failed to compare with upstream: %w", err)
+			return
+		}
+		repo.Ahead, repo.Behind, err = parseLeftRight(string(output))
+		if err != nil {
+			repo.Error = fmt.Errorf("failed to compare with upstream: %w", err)
+			return
+		}
+	}
+
+	output, err := p.gitCommand(ctx, repo.Path, "stash", "list", "--format=%gd").Output()
+	if err != nil {
+		repo.Error = fmt.Errorf("failed to list stashes: %w", err)
+		return
+	}
+	repo.Stashes = bytes.Count(output, []byte("\n"))
+}
+
+// upstreamRef returns the reference the current branch's upstream is stored in, or "" when the
+// branch has no upstream or the upstream's branch is gone
+func (p *Processor) upstreamRef(repo *types.GitRepo) plumbing.ReferenceName {
+	if repo.Upstream == "" {
+		return ""
+	}
+
+	gitRepo, err := openRepo(repo.Path)
+	if err != nil {
+		return ""
+	}
+	cfg, err := gitRepo.Config()
+	if err != nil {
+		return ""
+	}
+	branch, ok := cfg.Branches[repo.Branch]
+	if !ok {
+		return ""
+	}
+
+	ref := plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short())
+	if branch.Remote == "." {
+		ref = branch.Merge
+	}
+	if _, err := gitRepo.Reference(ref, false); err != nil {
+		return ""
+	}
+	return ref
+}
+
+// parseLeftRight reads `git rev-list --left-right --count` output, "<ahead>\t<behind>"
+func parseLeftRight(output string) (ahead, behind int, err error) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", output)
+	}
+	if ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, err
+	}
+	if behind, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
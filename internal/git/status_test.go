@@ -0,0 +1,96 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestParseLeftRight(t *testing.T) {
+	t.Parallel()
+
+	ahead, behind, err := parseLeftRight("2\t5\n")
+	if err != nil || ahead != 2 || behind != 5 {
+		t.Errorf("parseLeftRight() = %d, %d, %v; want 2, 5, nil", ahead, behind, err)
+	}
+	if _, _, err := parseLeftRight("fatal\n"); err == nil {
+		t.Error("Expected an error for unexpected output")
+	}
+}
+
+func TestProcessor_ProcessRepo_Status(t *testing.T) {
+	requireGit(t)
+
+	path, repo := initTrackedRepo(t)
+	if err := NewProcessor(&types.Config{}).setMissingUpstream(&repo); err != nil {
+		t.Fatal(err)
+	}
+
+	gitRepo, err := gogit.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := gitRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Commit("local work", &gogit.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "git-herd", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("# stashed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stash := exec.Command("git", "-c", "user.name=git-herd", "-c", "user.email=test@example.com", "stash")
+	stash.Dir = path
+	if output, err := stash.CombinedOutput(); err != nil {
+		t.Fatalf("git stash failed: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(path, "notes.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &types.Config{Operation: types.OperationStatus, FetchFirst: true, DryRun: true}
+	status := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if status.Error != nil {
+		t.Fatalf("Expected status to succeed, got %v", status.Error)
+	}
+	if status.Ahead != 1 || status.Behind != 0 || status.Stashes != 1 || status.Clean || status.UpstreamGone {
+		t.Errorf("Expected ahead 1, behind 0, 1 stash and a dirty worktree, got %+v", status)
+	}
+	if status.Timings.Network != 0 {
+		t.Errorf("Expected no fetch in dry-run mode, got network time %v", status.Timings.Network)
+	}
+}
+
+func TestProcessor_ProcessRepo_StatusUpstreamGone(t *testing.T) {
+	requireGit(t)
+
+	path, repo := initTrackedRepo(t)
+	if err := NewProcessor(&types.Config{}).setMissingUpstream(&repo); err != nil {
+		t.Fatal(err)
+	}
+	gitRepo, err := gogit.PlainOpen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gitRepo.Storer.RemoveReference(plumbing.NewRemoteReferenceName("origin", repo.Branch)); err != nil {
+		t.Fatal(err)
+	}
+
+	status := NewProcessor(&types.Config{Operation: types.OperationStatus}).ProcessRepo(t.Context(), types.GitRepo{Path: path, Name: "repo"})
+	if status.Error != nil || !status.UpstreamGone || status.Ahead != 0 || status.Stashes != 0 {
+		t.Errorf("Expected a gone upstream without error, got %+v", status)
+	}
+}
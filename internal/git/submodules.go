@@ -0,0 +1,61 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// hasSubmodules reports whether the repository at dir declares submodules in .gitmodules
+func hasSubmodules(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ".gitmodules"))
+	return err == nil && !info.IsDir()
+}
+
+// isSubmoduleOf reports whether the worktree at dir is a submodule of the repository at parent,
+// i.e. its git directory lives in the parent's modules directory
+func isSubmoduleOf(dir, parent string) bool {
+	return withinDir(gitDir(dir), filepath.Join(commonDir(parent), "modules"))
+}
+
+// updateSubmodules brings the repository's submodules along after a fetch or pull
+// (--submodules), recursively: after a fetch each initialized submodule fetches too, and after
+// a pull every submodule is initialized and checked out at the commit the repository records.
+// The submodules updated are counted in repo.Submodules.
+func (p *Processor) updateSubmodules(ctx context.Context, repo *types.GitRepo) error {
+	if !p.config.Submodules || !repo.HasSubmodules {
+		return nil
+	}
+
+	var steps [][]string
+	if p.config.Operation == types.OperationPull {
+		// URLs changed in .gitmodules only take effect once synced
+		steps = [][]string{
+			{"submodule", "sync", "--quiet", "--recursive"},
+			{"submodule", "update", "--init", "--recursive"},
+		}
+	} else {
+		steps = [][]string{{"submodule", "foreach", "--quiet", "--recursive", "git fetch --quiet"}}
+	}
+	for _, args := range steps {
+		if output, err := p.gitCommand(ctx, repo.Path, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("submodule %s failed: %w (output: %s)", args[1], err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	output, err := p.gitCommand(ctx, repo.Path, "submodule", "status", "--recursive").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list submodules: %w", err)
+	}
+	// Uninitialized submodules, listed with a leading "-", were not updated
+	for _, line := range parseLines(string(output)) {
+		if !strings.HasPrefix(line, "-") {
+			repo.Submodules++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// initSubmoduleClone creates a repository with a submodule and clones it without initializing
+// the submodule, returning the clone
+func initSubmoduleClone(t *testing.T) string {
+	t.Helper()
+
+	// Submodules are cloned over the file transport, which git disallows by default
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	root := t.TempDir()
+	lib := filepath.Join(root, "lib")
+	initTestRepo(t, lib)
+	parent := filepath.Join(root, "parent")
+	initTestRepo(t, parent)
+	runGit(t, parent, "submodule", "add", "--quiet", lib, "lib")
+	runGit(t, parent, "commit", "--quiet", "--message", "add lib")
+
+	clone := filepath.Join(root, "clone")
+	runGit(t, root, "clone", "--quiet", parent, clone)
+	return clone
+}
+
+func TestProcessor_ProcessRepo_PullSubmodules(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+	clone := initSubmoduleClone(t)
+
+	repo := types.GitRepo{Path: clone, Name: "clone", HasSubmodules: hasSubmodules(clone)}
+	if !repo.HasSubmodules {
+		t.Fatal("Expected .gitmodules to be detected")
+	}
+
+	config := &types.Config{Operation: types.OperationPull, Remote: "origin", Submodules: true}
+	result := NewProcessor(config).ProcessRepo(t.Context(), repo)
+	if result.Error != nil {
+		t.Fatalf("Pull with submodules failed: %v", result.Error)
+	}
+	if result.Submodules != 1 {
+		t.Errorf("Expected 1 submodule updated, got %d", result.Submodules)
+	}
+	if !isWorktreeRoot(filepath.Join(clone, "lib")) {
+		t.Error("Expected the submodule to be checked out")
+	}
+
+	fetch := &types.Config{Operation: types.OperationFetch, Remote: "origin", Submodules: true}
+	if result := NewProcessor(fetch).ProcessRepo(t.Context(), repo); result.Error != nil || result.Submodules != 1 {
+		t.Errorf("Expected the submodule to be fetched, got %d (%v)", result.Submodules, result.Error)
+	}
+}
+
+func TestScanner_FindRepos_Submodules(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+	clone := initSubmoduleClone(t)
+	runGit(t, clone, "submodule", "update", "--quiet", "--init")
+
+	// Without --submodules the checked-out submodule is a repository of its own
+	repos, err := NewScanner(&types.Config{Recursive: true}).FindRepos(t.Context(), filepath.Dir(clone), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found int
+	for _, repo := range repos {
+		if repo.Path == clone && !repo.HasSubmodules {
+			t.Error("Expected HasSubmodules to be set")
+		}
+		if withinDir(repo.Path, clone) {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("Expected the clone and its submodule, got %+v", repos)
+	}
+
+	repos, err = NewScanner(&types.Config{Recursive: true, Submodules: true}).FindRepos(t.Context(), filepath.Dir(clone), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range repos {
+		if repo.Path == filepath.Join(clone, "lib") {
+			t.Errorf("Expected the submodule to be left to its repository with --submodules, got %+v", repos)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// syncDefaultBranch brings the default branch up to date with the remote without leaving the
+// branch the repository is on (sync): it checks out the default branch, pulls it with the
+// configured --pull-strategy, and checks the original branch out again. When the pull fails,
+// any merge or rebase is aborted and the original branch is restored before the error is
+// returned. The branch and what the pull moved it by are recorded in repo.SyncBranch and
+// repo.SyncUpdate. In dry-run mode the sync is only planned.
+func (p *Processor) syncDefaultBranch(ctx context.Context, repo *types.GitRepo) error {
+	original := repo.Branch
+	if original == "" || original == "detached" {
+		return errors.New("detached HEAD: no branch to return to after syncing (skipped)")
+	}
+
+	base, err := p.defaultBranch(ctx, repo.Path)
+	if err != nil {
+		return err
+	}
+	branch := strings.TrimPrefix(base, p.remoteName()+"/")
+	if p.gitCommand(ctx, repo.Path, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run() != nil {
+		return fmt.Errorf("no local branch %s to keep up to date (skipped)", branch)
+	}
+	repo.SyncBranch = branch
+
+	if p.config.DryRun {
+		return nil
+	}
+
+	if branch != original {
+		if output, err := p.gitCommand(ctx, repo.Path, "switch", "--quiet", branch).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to check out %s: %w (output: %s)", branch, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	before, _ := p.revParse(ctx, repo.Path, "HEAD")
+	pullErr := p.pullCurrent(ctx, repo.Path, branch)
+	after, _ := p.revParse(ctx, repo.Path, "HEAD")
+	if pullErr == nil && before != after {
+		repo.SyncUpdate = shortHash(before) + ".." + shortHash(after)
+	}
+
+	if branch != original {
+		// Restoring the branch the repository was on matters even when the run is cancelled
+		restore := context.WithoutCancel(ctx)
+		if output, err := p.gitCommand(restore, repo.Path, "switch", "--quiet", original).CombinedOutput(); err != nil {
+			return errors.Join(pullErr, fmt.Errorf("failed to return to %s, left on %s: %w (output: %s)",
+				original, branch, err, strings.TrimSpace(string(output))))
+		}
+	}
+	if pullErr != nil {
+		return fmt.Errorf("failed to sync %s: %w", branch, pullErr)
+	}
+	return nil
+}
+
+// pullCurrent pulls branch from the configured remote into the checked-out branch with the
+// configured --pull-strategy, aborting a merge or rebase that stops on conflicts
+func (p *Processor) pullCurrent(ctx context.Context, dir, branch string) error {
+	args := []string{"pull", "--quiet", "--ff-only", p.remoteName(), branch}
+	var abort []string
+	switch p.config.PullStrategy {
+	case types.PullMerge:
+		args = []string{"pull", "--quiet", "--no-rebase", "--no-edit", p.remoteName(), branch}
+		abort = []string{"merge", "--abort"}
+	case types.PullRebase:
+		args = []string{"pull", "--quiet", "--rebase", p.remoteName(), branch}
+		abort = []string{"rebase", "--abort"}
+	}
+
+	output, err := p.gitCommand(ctx, dir, args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if abort != nil {
+		// Without a merge or rebase in progress there is nothing to abort, and git says so
+		_ = p.gitCommand(context.WithoutCancel(ctx), dir, abort...).Run()
+	}
+	return fmt.Errorf("pull --%s failed: %w (output: %s)", p.config.PullStrategy, err, strings.TrimSpace(string(output)))
+}
+
+// revParse resolves rev in the repository at dir to a commit hash
+func (p *Processor) revParse(ctx context.Context, dir, rev string) (plumbing.Hash, error) {
+	output, err := p.gitCommand(ctx, dir, "rev-parse", "--verify", "--quiet", rev).Output()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return plumbing.NewHash(strings.TrimSpace(string(output))), nil
+}
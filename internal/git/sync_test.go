@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// syncClone has a feature branch checked out in the clone while its upstream's master moved on
+var syncClone = cloneOptions{Branch: "feature", Ahead: []string{"feature.txt"}, Behind: []string{"news.txt"}}
+
+func TestProcessor_ProcessRepo_Sync(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	upstream, clone := cloneTestRepo(t, syncClone)
+	config := &types.Config{Operation: types.OperationSync, Remote: "origin", PullStrategy: types.PullFastForward}
+
+	config.DryRun = true
+	planned := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if planned.Error != nil || planned.SyncBranch != "master" || planned.SyncUpdate != "" {
+		t.Fatalf("Expected a sync of master to be planned, got %q %q (%v)", planned.SyncBranch, planned.SyncUpdate, planned.Error)
+	}
+
+	config.DryRun = false
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if result.Error != nil || result.SyncBranch != "master" || result.SyncUpdate == "" {
+		t.Fatalf("Expected master to be updated, got %q %q (%v)", result.SyncBranch, result.SyncUpdate, result.Error)
+	}
+	if got, want := runGit(t, clone, "rev-parse", "master"), runGit(t, upstream, "rev-parse", "HEAD"); got != want {
+		t.Errorf("Expected master at the upstream's %s, got %s", want, got)
+	}
+	if branch := runGit(t, clone, "branch", "--show-current"); branch != "feature" {
+		t.Errorf("Expected to be back on feature, got %s", branch)
+	}
+	if _, err := os.Stat(filepath.Join(clone, "feature.txt")); err != nil {
+		t.Errorf("Expected the feature branch's work to be checked out again: %v", err)
+	}
+}
+
+func TestProcessor_ProcessRepo_SyncRollback(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	// master diverged from the upstream, so a fast-forward-only pull fails
+	_, clone := cloneTestRepo(t, syncClone)
+	runGit(t, clone, "checkout", "--quiet", "master")
+	commitFile(t, clone, "local.txt", "local\n")
+	runGit(t, clone, "checkout", "--quiet", "feature")
+	before := runGit(t, clone, "rev-parse", "master")
+
+	config := &types.Config{Operation: types.OperationSync, Remote: "origin", PullStrategy: types.PullFastForward}
+	result := NewProcessor(config).ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "failed to sync master") {
+		t.Fatalf("Expected the sync to fail, got %v", result.Error)
+	}
+	if branch := runGit(t, clone, "branch", "--show-current"); branch != "feature" {
+		t.Errorf("Expected the failed sync to return to feature, got %s", branch)
+	}
+	if after := runGit(t, clone, "rev-parse", "master"); after != before {
+		t.Errorf("Expected master to stay at %s, got %s", before, after)
+	}
+}
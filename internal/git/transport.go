@@ -0,0 +1,149 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/net/proxy"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// sshControlPersist is how long an idle SSH master connection stays open for reuse
+const sshControlPersist = "60s"
+
+// installTransportOnce guards registration of the pooled transport, which is process-wide in go-git
+var installTransportOnce sync.Once
+
+// installPooledTransport replaces go-git's default HTTP(S) transport with a single shared client
+// whose pool keeps an idle keep-alive connection per worker and host, so hundreds of fetches
+// against the same forge reuse connections instead of repeating TCP and TLS handshakes. Both
+// it and go-git's native SSH transport dial through one run-wide DNS cache restricted to the
+// configured IP family.
+func installPooledTransport(config *types.Config) {
+	installTransportOnce.Do(func() {
+		dialer := newCachingDialer(config.IPFamily)
+		client := githttp.NewClient(&http.Client{Transport: newPooledTransport(config.Workers, dialer)})
+		gitclient.InstallProtocol("https", client)
+		gitclient.InstallProtocol("http", client)
+		installSSHDialer(dialer)
+		gitclient.InstallProtocol("ssh", sshTransport{gitssh.DefaultClient})
+	})
+}
+
+// newPooledTransport clones net/http's default transport, sized so no worker has to
+// close its connection to a host because the idle pool is full, and dialing with dialer
+func newPooledTransport(workers int, dialer *cachingDialer) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.MaxIdleConnsPerHost = max(workers, 2)
+	transport.MaxIdleConns = max(transport.MaxIdleConns, workers)
+	transport.IdleConnTimeout = 90 * time.Second
+	return transport
+}
+
+// sshDialScheme is the proxy scheme through which go-git's SSH transport reaches the run's dialer
+const sshDialScheme = "git-herd-dial"
+
+// installSSHDialer registers dialer as the one behind sshDialScheme. go-git dials SSH remotes
+// with x/net/proxy, whose dialers for a proxy URL are looked up by scheme.
+func installSSHDialer(dialer *cachingDialer) {
+	proxy.RegisterDialerType(sshDialScheme, func(*url.URL, proxy.Dialer) (proxy.Dialer, error) {
+		return dialer, nil
+	})
+}
+
+// sshTransport is go-git's SSH transport dialing through the dialer installSSHDialer
+// registered, by giving each endpoint without a proxy of its own a proxy URL of sshDialScheme
+type sshTransport struct {
+	transport.Transport
+}
+
+func (t sshTransport) NewUploadPackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.UploadPackSession, error) {
+	return t.Transport.NewUploadPackSession(viaRunDialer(ep), auth)
+}
+
+func (t sshTransport) NewReceivePackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.ReceivePackSession, error) {
+	return t.Transport.NewReceivePackSession(viaRunDialer(ep), auth)
+}
+
+// viaRunDialer returns a copy of ep dialed through the run's dialer, or ep itself when it
+// names a proxy
+func viaRunDialer(ep *transport.Endpoint) *transport.Endpoint {
+	if ep.Proxy.URL != "" {
+		return ep
+	}
+	dialed := *ep
+	dialed.Proxy = transport.ProxyOptions{URL: sshDialScheme + "://run"}
+	return &dialed
+}
+
+// gitCommand builds a git CLI invocation in dir. With SSH multiplexing enabled, ssh shares one
+// master connection per host across invocations; go-git's native SSH transport used for
+// fetch and pull opens its own connection per operation and is not affected.
+func (p *Processor) gitCommand(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = isolatedEnv(os.Environ())
+	if env := sshCommandEnv(p.config); env != "" {
+		cmd.Env = append(cmd.Env, env)
+	}
+	return cmd
+}
+
+// sshCommandEnv returns a GIT_SSH_COMMAND setting applying the configured connection sharing
+// and IP family, or "" when there is nothing to apply or the user configured their own ssh command
+func sshCommandEnv(config *types.Config) string {
+	if os.Getenv("GIT_SSH_COMMAND") != "" || os.Getenv("GIT_SSH") != "" {
+		return ""
+	}
+
+	var options []string
+	switch config.IPFamily {
+	case types.IPFamily4:
+		options = append(options, "-4")
+	case types.IPFamily6:
+		options = append(options, "-6")
+	}
+	if config.SSHMultiplex {
+		if dir := sshControlDir(); dir != "" {
+			options = append(options,
+				"-o ControlMaster=auto",
+				fmt.Sprintf("-o 'ControlPath=%s'", filepath.Join(dir, "%C")),
+				"-o ControlPersist="+sshControlPersist)
+		}
+	}
+
+	if len(options) == 0 {
+		return ""
+	}
+	return "GIT_SSH_COMMAND=ssh " + strings.Join(options, " ")
+}
+
+// sshControlDir returns the directory holding ControlMaster sockets, or "" when the platform
+// lacks ControlMaster support or the directory cannot be created
+var sshControlDir = sync.OnceValue(func() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+
+	// Socket paths are limited to ~104 bytes, so keep the directory short and let %C hash the rest
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("git-herd-ssh-%d", os.Getuid()))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return ""
+	}
+	return dir
+})
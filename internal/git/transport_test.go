@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestNewPooledTransport(t *testing.T) {
+	transport := newPooledTransport(20, newCachingDialer(types.IPFamilyAuto))
+	if transport.MaxIdleConnsPerHost != 20 {
+		t.Errorf("Expected 20 idle connections per host, got %d", transport.MaxIdleConnsPerHost)
+	}
+	if transport.MaxIdleConns < 20 {
+		t.Errorf("Expected total idle pool to fit every worker, got %d", transport.MaxIdleConns)
+	}
+
+	if small := newPooledTransport(1, newCachingDialer(types.IPFamilyAuto)); small.MaxIdleConnsPerHost != 2 {
+		t.Errorf("Expected at least 2 idle connections per host, got %d", small.MaxIdleConnsPerHost)
+	}
+}
+
+func TestProcessor_GitCommandSSHMultiplex(t *testing.T) {
+	t.Setenv("GIT_SSH_COMMAND", "")
+	t.Setenv("GIT_SSH", "")
+
+	hasControlMaster := func(env []string) bool {
+		return slices.ContainsFunc(env, func(v string) bool {
+			return strings.HasPrefix(v, "GIT_SSH_COMMAND=") && strings.Contains(v, "ControlMaster=auto")
+		})
+	}
+
+	disabled := NewProcessor(&types.Config{SSHMultiplex: false})
+	cmd := disabled.gitCommand(context.Background(), t.TempDir(), "status")
+	if hasControlMaster(cmd.Env) {
+		t.Error("Expected no ControlMaster settings when multiplexing is disabled")
+	}
+
+	if sshControlDir() == "" {
+		t.Skip("SSH multiplexing is unavailable in this environment")
+	}
+
+	enabled := NewProcessor(&types.Config{SSHMultiplex: true})
+	cmd = enabled.gitCommand(context.Background(), t.TempDir(), "status")
+	if !hasControlMaster(cmd.Env) {
+		t.Error("Expected ControlMaster settings when multiplexing is enabled")
+	}
+}
+
+func TestSSHCommandEnv(t *testing.T) {
+	t.Setenv("GIT_SSH_COMMAND", "")
+	t.Setenv("GIT_SSH", "")
+
+	if env := sshCommandEnv(&types.Config{IPFamily: types.IPFamilyAuto}); env != "" {
+		t.Errorf("Expected no ssh command without options, got %q", env)
+	}
+
+	env := sshCommandEnv(&types.Config{IPFamily: types.IPFamily4})
+	if env != "GIT_SSH_COMMAND=ssh -4" {
+		t.Errorf("Expected IPv4-only ssh command, got %q", env)
+	}
+
+	t.Setenv("GIT_SSH_COMMAND", "ssh -i ~/.ssh/deploy")
+	if env := sshCommandEnv(&types.Config{IPFamily: types.IPFamily6, SSHMultiplex: true}); env != "" {
+		t.Errorf("Expected user GIT_SSH_COMMAND to be respected, got %q", env)
+	}
+}
+
+func TestSSHTransportDialsThroughRunDialer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	accepted := make(chan struct{}, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- struct{}{}
+		conn.Close()
+	}()
+
+	// forge.invalid only resolves through the dialer's cache, so connecting proves the dialer was used
+	dialer := newCachingDialer(types.IPFamilyAuto)
+	dialer.cache.entries["forge.invalid"] = dnsEntry{addrs: []string{"127.0.0.1"}, expires: time.Now().Add(time.Minute)}
+	installSSHDialer(dialer)
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	ep, err := transport.NewEndpoint("ssh://git@forge.invalid:" + port + "/team/api.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := &gitssh.Password{User: "git", HostKeyCallbackHelper: gitssh.HostKeyCallbackHelper{HostKeyCallback: ssh.InsecureIgnoreHostKey()}}
+	if _, err := (sshTransport{gitssh.DefaultClient}).NewUploadPackSession(ep, auth); err == nil {
+		t.Fatal("Expected the handshake with a server that hangs up to fail")
+	}
+	select {
+	case <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the SSH transport to connect through the run's dialer")
+	}
+	if ep.Proxy.URL != "" {
+		t.Error("Expected the caller's endpoint to be left unchanged")
+	}
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// branchUpstream returns the upstream configured for branch as "remote/branch", or "" if it has none
+func branchUpstream(cfg *config.Config, branch string) string {
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return ""
+	}
+	if b.Remote == "." {
+		// Tracking another local branch
+		return b.Merge.Short()
+	}
+	return b.Remote + "/" + b.Merge.Short()
+}
+
+// setMissingUpstream points the current branch at the same-named branch of the configured
+// remote, as `git branch --set-upstream-to` would. Branches that already have an upstream,
+// detached HEADs, and branches the remote doesn't have are left alone. In dry-run mode the
+// upstream is only reported.
+func (p *Processor) setMissingUpstream(repo *types.GitRepo) error {
+	if repo.Upstream != "" || repo.Branch == "" || repo.Branch == "detached" {
+		return nil
+	}
+
+	gitRepo, err := openRepo(repo.Path)
+	if err != nil {
+		return err
+	}
+
+	remote := p.remoteName()
+	if _, err := gitRepo.Reference(plumbing.NewRemoteReferenceName(remote, repo.Branch), false); err != nil {
+		return nil
+	}
+
+	if !p.config.DryRun {
+		cfg, err := gitRepo.Config()
+		if err != nil {
+			return err
+		}
+
+		branch, ok := cfg.Branches[repo.Branch]
+		if !ok {
+			branch = &config.Branch{Name: repo.Branch}
+			cfg.Branches[repo.Branch] = branch
+		}
+		branch.Remote = remote
+		branch.Merge = plumbing.NewBranchReferenceName(repo.Branch)
+
+		if err := gitRepo.SetConfig(cfg); err != nil {
+			return fmt.Errorf("write config: %w", err)
+		}
+	}
+
+	repo.Upstream = remote + "/" + repo.Branch
+	repo.UpstreamSet = true
+	return nil
+}
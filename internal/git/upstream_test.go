@@ -0,0 +1,108 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestBranchUpstream(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.NewConfig()
+	cfg.Branches["main"] = &config.Branch{Name: "main", Remote: "origin", Merge: "refs/heads/main"}
+	cfg.Branches["feature"] = &config.Branch{Name: "feature", Remote: ".", Merge: "refs/heads/main"}
+	cfg.Branches["loose"] = &config.Branch{Name: "loose", Rebase: "true"}
+
+	tests := map[string]string{
+		"main":    "origin/main",
+		"feature": "main",
+		"loose":   "",
+		"missing": "",
+	}
+	for branch, want := range tests {
+		if got := branchUpstream(cfg, branch); got != want {
+			t.Errorf("branchUpstream(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+// initTrackedRepo creates a test repository whose origin has a branch matching the current one
+func initTrackedRepo(t *testing.T) (string, types.GitRepo) {
+	t.Helper()
+
+	path := t.TempDir()
+	gitRepo := initTestRepo(t, path)
+	if _, err := gitRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/org/repo.git"}}); err != nil {
+		t.Fatal(err)
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracking := plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", head.Name().Short()), head.Hash())
+	if err := gitRepo.Storer.SetReference(tracking); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := types.GitRepo{Path: path, Name: "repo"}
+	NewProcessor(&types.Config{}).AnalyzeRepo(&repo)
+	if repo.Error != nil || repo.Upstream != "" {
+		t.Fatalf("Expected a branch without upstream, got %+v", repo)
+	}
+	return path, repo
+}
+
+func TestSetMissingUpstream(t *testing.T) {
+	path, repo := initTrackedRepo(t)
+
+	if err := NewProcessor(&types.Config{SetUpstream: true}).setMissingUpstream(&repo); err != nil {
+		t.Fatalf("setMissingUpstream() error = %v", err)
+	}
+	want := "origin/" + repo.Branch
+	if repo.Upstream != want || !repo.UpstreamSet {
+		t.Errorf("Expected upstream %s to be set, got %q (set=%v)", want, repo.Upstream, repo.UpstreamSet)
+	}
+
+	reanalyzed := types.GitRepo{Path: path}
+	NewProcessor(&types.Config{}).AnalyzeRepo(&reanalyzed)
+	if reanalyzed.Upstream != want {
+		t.Errorf("Expected upstream %s in the repository config, got %q", want, reanalyzed.Upstream)
+	}
+}
+
+func TestSetMissingUpstreamDryRun(t *testing.T) {
+	path, repo := initTrackedRepo(t)
+
+	if err := NewProcessor(&types.Config{SetUpstream: true, DryRun: true}).setMissingUpstream(&repo); err != nil {
+		t.Fatalf("setMissingUpstream() error = %v", err)
+	}
+	if !repo.UpstreamSet {
+		t.Error("Expected dry run to report the upstream it would set")
+	}
+
+	reanalyzed := types.GitRepo{Path: path}
+	NewProcessor(&types.Config{}).AnalyzeRepo(&reanalyzed)
+	if reanalyzed.Upstream != "" {
+		t.Errorf("Expected dry run to leave the config alone, got upstream %q", reanalyzed.Upstream)
+	}
+}
+
+func TestSetMissingUpstreamWithoutRemoteBranch(t *testing.T) {
+	path := t.TempDir()
+	initTestRepo(t, path)
+
+	repo := types.GitRepo{Path: path}
+	p := NewProcessor(&types.Config{SetUpstream: true})
+	p.AnalyzeRepo(&repo)
+
+	if err := p.setMissingUpstream(&repo); err != nil {
+		t.Fatalf("setMissingUpstream() error = %v", err)
+	}
+	if repo.Upstream != "" || repo.UpstreamSet {
+		t.Errorf("Expected no upstream without a remote branch to track, got %q", repo.Upstream)
+	}
+}
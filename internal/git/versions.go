@@ -0,0 +1,87 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// checkVersion compares the highest semver tag a repository's HEAD contains with the minimum
+// version its manifest entry expects (versions), recording exactly where HEAD is with git
+// describe. With --checkout-tag a repository that is behind has the expected tag checked out,
+// detached; the tag must already be there, as nothing is fetched. In dry-run mode the checkout
+// is only planned.
+func (p *Processor) checkVersion(ctx context.Context, repo *types.GitRepo, protected bool) error {
+	check := repo.Version
+	if check == nil {
+		return errors.New("no expected version in the manifest (skipped)")
+	}
+	expected, ok := parseSemver(check.Expected)
+	if !ok {
+		return fmt.Errorf("expected version %s is not a semantic version", check.Expected)
+	}
+
+	output, err := p.gitCommand(ctx, repo.Path, "tag", "--merged", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+	check.Current = ""
+	var current semver
+	for _, tag := range parseLines(string(output)) {
+		if v, ok := parseSemver(tag); ok && (check.Current == "" || v.compare(current) > 0) {
+			check.Current, current = tag, v
+		}
+	}
+	// describe fails when no tag can describe HEAD
+	if output, err := p.gitCommand(ctx, repo.Path, "describe", "--tags", "HEAD").Output(); err == nil {
+		check.Describe = strings.TrimSpace(string(output))
+	}
+	check.Behind = check.Current == "" || current.compare(expected) < 0
+
+	if !check.Behind || !p.config.CheckoutTag {
+		return nil
+	}
+	if protected {
+		return fmt.Errorf("protected repository: checking out %s not allowed (policy skipped)", check.Expected)
+	}
+	if !repo.Clean {
+		return fmt.Errorf("repository has uncommitted changes, not checking out %s (skipped)", check.Expected)
+	}
+	tag, err := p.findTag(ctx, repo.Path, check.Expected, expected)
+	if err != nil {
+		return err
+	}
+
+	if !p.config.DryRun {
+		if output, err := p.gitCommand(ctx, repo.Path, "switch", "--detach", "--quiet", "refs/tags/"+tag).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to check out %s: %w (output: %s)", tag, err, strings.TrimSpace(string(output)))
+		}
+		p.AnalyzeRepo(repo)
+	}
+	check.CheckedOut = tag
+	return nil
+}
+
+// findTag returns the tag named name, or else a tag of the same version, e.g. "v1.4.0" for
+// "1.4.0"
+func (p *Processor) findTag(ctx context.Context, dir, name string, version semver) (string, error) {
+	output, err := p.gitCommand(ctx, dir, "tag", "--list").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	tags := parseLines(string(output))
+	for _, tag := range tags {
+		if tag == name {
+			return tag, nil
+		}
+	}
+	for _, tag := range tags {
+		if v, ok := parseSemver(tag); ok && v.compare(version) == 0 {
+			return tag, nil
+		}
+	}
+	return "", fmt.Errorf("tag %s not found, fetch tags first", name)
+}
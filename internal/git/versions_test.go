@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestLoadManifestVersions(t *testing.T) {
+	manifest := writeManifest(t, `repos:
+  - url: git@github.com:acme/api.git
+    version: v1.4.0
+  - path: services/billing
+    version: 2.0.0
+  - url: git@github.com:acme/docs.git
+`)
+
+	repos, err := loadManifest(manifest, "/work", types.OperationVersions)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected the 2 repositories with a version, got %+v", repos)
+	}
+	if repos[0].Path != filepath.Join("/work", "api") || repos[0].Version == nil || repos[0].Version.Expected != "v1.4.0" {
+		t.Errorf("Expected api to expect v1.4.0, got %+v", repos[0])
+	}
+	if repos[1].Path != filepath.Join("/work", "services", "billing") || repos[1].Version.Expected != "2.0.0" {
+		t.Errorf("Expected billing, listed by path alone, to expect 2.0.0, got %+v", repos[1])
+	}
+}
+
+func TestProcessor_ProcessRepo_Versions(t *testing.T) {
+	requireGit(t)
+	setGitIdentity(t)
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	runGit(t, filepath.Dir(repoPath), "init", "--quiet", repoPath)
+	commitFile(t, repoPath, "a.txt", "a\n")
+	runGit(t, repoPath, "tag", "v1.3.0")
+	commitFile(t, repoPath, "b.txt", "b\n")
+	runGit(t, repoPath, "tag", "v1.4.0")
+	runGit(t, repoPath, "switch", "--quiet", "--detach", "v1.3.0")
+	commitFile(t, repoPath, "c.txt", "c\n")
+
+	process := func(expected string, checkout, dryRun bool) types.GitRepo {
+		t.Helper()
+		config := &types.Config{Operation: types.OperationVersions, CheckoutTag: checkout, DryRun: dryRun}
+		repo := types.GitRepo{Path: repoPath, Name: "repo", Version: &types.TagCheck{Expected: expected}}
+		return NewProcessor(config).ProcessRepo(t.Context(), repo)
+	}
+	head := func() string {
+		t.Helper()
+		output, err := exec.Command("git", "-C", repoPath, "describe", "--tags").Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	result := process("v1.2.0", false, false)
+	if result.Error != nil || result.Version.Behind || result.Version.Current != "v1.3.0" {
+		t.Errorf("Expected v1.3.0 to satisfy v1.2.0, got %+v (%v)", result.Version, result.Error)
+	}
+	if !strings.HasPrefix(result.Version.Describe, "v1.3.0-1-g") {
+		t.Errorf("Expected describe to place HEAD one commit past v1.3.0, got %q", result.Version.Describe)
+	}
+
+	result = process("1.4.0", true, true)
+	if result.Error != nil || !result.Version.Behind || result.Version.CheckedOut != "v1.4.0" {
+		t.Errorf("Expected a planned checkout of v1.4.0, got %+v (%v)", result.Version, result.Error)
+	}
+	if got := head(); !strings.HasPrefix(got, "v1.3.0-1-g") {
+		t.Errorf("Expected dry run to leave HEAD alone, got %s", got)
+	}
+
+	result = process("1.4.0", true, false)
+	if result.Error != nil || result.Version.CheckedOut != "v1.4.0" || result.Branch != "detached" {
+		t.Errorf("Expected v1.4.0 checked out detached, got %+v on %s (%v)", result.Version, result.Branch, result.Error)
+	}
+	if got := head(); got != "v1.4.0" {
+		t.Errorf("Expected HEAD at v1.4.0, got %s", got)
+	}
+
+	if result := process("v2.0.0", true, false); result.Error == nil || !strings.Contains(result.Error.Error(), "fetch tags first") {
+		t.Errorf("Expected a missing tag to fail, got %v", result.Error)
+	}
+	if result := process("latest", false, false); result.Error == nil {
+		t.Error("Expected a version that is not semver to fail")
+	}
+}
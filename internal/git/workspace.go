@@ -0,0 +1,96 @@
+package git
+
+import (
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"strconv"
+
+	"github.com/entro314-labs/git-herd/internal/auditlog"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// loadAuditLog returns the --audit-log to record mutating operations in, nil without one
+func loadAuditLog(config *types.Config) *auditlog.Log {
+	if config.AuditLog == "" {
+		return nil
+	}
+	return auditlog.New(ExpandHome(config.AuditLog))
+}
+
+// recordAudit appends the outcome of a mutating operation on a repository to the audit log,
+// with the user who ran it and, when someone else, the user who owns the repository. Dry runs
+// change nothing and are not recorded.
+func (p *Processor) recordAudit(repo types.GitRepo) {
+	if p.audit == nil || p.config.DryRun || !p.config.Operation.IsMutating() {
+		return
+	}
+
+	entry := auditlog.Entry{Operation: p.config.Operation, Repo: repo.Path}
+	if abs, err := filepath.Abs(repo.Path); err == nil {
+		entry.Repo = abs
+	}
+	if owner, foreign := foreignOwner(repo.Path, gitDir(repo.Path)); foreign {
+		entry.Owner = ownerName(owner)
+	}
+	if repo.Error != nil {
+		entry.Error = repo.Error.Error()
+	}
+	// The first error is reported once the run is over, not for every repository
+	_ = p.audit.Append(entry)
+}
+
+// AuditErr returns the first error writing the audit log during the run, nil if there was none
+func (p *Processor) AuditErr() error {
+	if p.audit == nil {
+		return nil
+	}
+	return p.audit.Err()
+}
+
+// checkSharedWorkspace refuses, with --shared-workspace, to write to a repository another user
+// owns unless --allow-owner names them, so a job run as root or as a service account cannot
+// change, or take ownership of files in, a developer's checkout
+func (p *Processor) checkSharedWorkspace(repo types.GitRepo) error {
+	if !p.config.SharedWorkspace || !writesRepository(p.config) {
+		return nil
+	}
+	owner, foreign := foreignOwner(repo.Path, gitDir(repo.Path))
+	if !foreign {
+		return nil
+	}
+	name := ownerName(owner)
+	for _, allowed := range p.config.AllowOwners {
+		if allowed == name || allowed == strconv.Itoa(owner) {
+			return nil
+		}
+	}
+	return fmt.Errorf("owned by %s: %s not allowed in a shared workspace without --allow-owner %s (policy skipped)",
+		name, p.config.Operation, name)
+}
+
+// writesRepository reports whether the run writes to the repositories it processes. Everything
+// but analysis does: even a fetch writes objects and remote-tracking refs, and so do LFS,
+// submodules and --set-upstream along with it. Status writes with --fetch-first, and versions
+// with --checkout-tag. --discard-files rewrites the working tree before any operation.
+func writesRepository(config *types.Config) bool {
+	if len(config.DiscardFiles) > 0 {
+		return true
+	}
+	switch config.Operation {
+	case types.OperationStatus:
+		return config.FetchFirst
+	case types.OperationVersions:
+		return config.CheckoutTag
+	default:
+		return !config.Operation.IsAnalysis()
+	}
+}
+
+// ownerName returns the name of the user with ID uid, or the ID itself for an unknown user
+func ownerName(uid int) string {
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(uid)
+}
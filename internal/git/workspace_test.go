@@ -0,0 +1,89 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/internal/auditlog"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_ProcessRepo_SharedWorkspace(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("handing a repository to another user needs root")
+	}
+	requireGit(t)
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(global, []byte("[safe]\n\tdirectory = *\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("SUDO_UID", "")
+
+	repoPath := filepath.Join(t.TempDir(), "checkout")
+	initTestRepo(t, repoPath)
+	chownTree(t, repoPath, 12345)
+	repo := types.GitRepo{Path: repoPath, Name: "checkout", HasGit: true}
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+
+	config := &types.Config{Operation: types.OperationPull, Remote: "origin", SharedWorkspace: true, AuditLog: auditLog}
+	result := NewProcessor(config).ProcessRepo(t.Context(), repo)
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "not allowed in a shared workspace") ||
+		!strings.Contains(result.Error.Error(), "policy skipped") {
+		t.Fatalf("Expected the other user's checkout to be policy skipped, got %v", result.Error)
+	}
+
+	// A fetch writes objects and refs the owner would no longer own, so it is refused too
+	config = &types.Config{Operation: types.OperationFetch, Remote: "origin", SharedWorkspace: true}
+	if result := NewProcessor(config).ProcessRepo(t.Context(), repo); result.Error == nil || !strings.Contains(result.Error.Error(), "policy skipped") {
+		t.Errorf("Expected a fetch in a shared workspace to be policy skipped, got %v", result.Error)
+	}
+
+	// So is discarding changes to files ahead of a status, which only reads otherwise
+	config = &types.Config{Operation: types.OperationStatus, Remote: "origin", SharedWorkspace: true, DiscardFiles: []string{"README.md"}}
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := NewProcessor(config).ProcessRepo(t.Context(), repo); result.Error == nil || !strings.Contains(result.Error.Error(), "policy skipped") {
+		t.Errorf("Expected a status discarding files in a shared workspace to be policy skipped, got %v", result.Error)
+	}
+	if data, err := os.ReadFile(filepath.Join(repoPath, "README.md")); err != nil || string(data) != "# changed\n" {
+		t.Errorf("Expected the other user's changes to be left alone, got %q (%v)", data, err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading is still allowed, and the owner can be allowed to be changed
+	config = &types.Config{Operation: types.OperationScan, SharedWorkspace: true, AuditLog: auditLog}
+	if result := NewProcessor(config).ProcessRepo(t.Context(), repo); result.Error != nil {
+		t.Errorf("Expected a scan in a shared workspace to succeed, got %v", result.Error)
+	}
+	config = &types.Config{Operation: types.OperationPull, Remote: "origin", SharedWorkspace: true, AllowOwners: []string{"12345"}, AuditLog: auditLog}
+	result = NewProcessor(config).ProcessRepo(t.Context(), repo)
+	if result.Error != nil && strings.Contains(result.Error.Error(), "shared workspace") {
+		t.Errorf("Expected --allow-owner to allow the pull, got %v", result.Error)
+	}
+
+	// Both pulls are in the audit log, with the owner; the scan changed nothing
+	data, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit log entries, got %d:\n%s", len(lines), data)
+	}
+	var entry auditlog.Entry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Operation != types.OperationPull || entry.Owner != ownerName(12345) || entry.User == "" || !strings.Contains(entry.Error, "policy skipped") {
+		t.Errorf("Unexpected audit log entry %+v", entry)
+	}
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// isLinkedWorktree reports whether the worktree at dir was added with git worktree add, sharing
+// its repository with the main worktree
+func isLinkedWorktree(dir string) bool {
+	_, err := os.Stat(filepath.Join(gitDir(dir), "commondir"))
+	return err == nil
+}
+
+// sharedRepoKey identifies the repository behind the worktree at dir, the same for the main
+// worktree and every linked one
+func sharedRepoKey(dir string) string {
+	common := commonDir(dir)
+	if abs, err := filepath.Abs(common); err == nil {
+		return abs
+	}
+	return common
+}
+
+// sharedRepos coordinates the network operations of worktrees that share one repository. They
+// share its refs and objects, so their fetches would race on the same lock files and fetch the
+// same thing twice.
+type sharedRepos struct {
+	mu      sync.Mutex
+	locks   map[string]*sync.Mutex
+	fetched map[string]string // Name of the worktree each repository was fetched through
+}
+
+func newSharedRepos() *sharedRepos {
+	return &sharedRepos{
+		locks:   make(map[string]*sync.Mutex),
+		fetched: make(map[string]string),
+	}
+}
+
+// lock takes the repository's turn for network operations, returning the function that gives
+// it up again
+func (s *sharedRepos) lock(key string) func() {
+	s.mu.Lock()
+	lock, ok := s.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[key] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// fetchedWith returns the worktree the repository has already been fetched through in this run
+func (s *sharedRepos) fetchedWith(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name, ok := s.fetched[key]
+	return name, ok
+}
+
+// markFetched records that the repository was fetched through the worktree called name
+func (s *sharedRepos) markFetched(key, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetched[key] = name
+}
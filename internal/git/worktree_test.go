@@ -0,0 +1,41 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestProcessor_ProcessRepo_FetchOncePerRepository(t *testing.T) {
+	requireGit(t)
+
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	initTestRepo(t, upstream)
+	clone := filepath.Join(root, "clone")
+	runGit(t, root, "clone", "--quiet", upstream, clone)
+	linked := filepath.Join(root, "linked")
+	runGit(t, clone, "worktree", "add", "--quiet", "-b", "feature", linked)
+
+	if isLinkedWorktree(clone) || !isLinkedWorktree(linked) {
+		t.Fatalf("Expected only %s to be a linked worktree", linked)
+	}
+	if sharedRepoKey(clone) != sharedRepoKey(linked) {
+		t.Fatalf("Expected both worktrees to share a repository, got %s and %s", sharedRepoKey(clone), sharedRepoKey(linked))
+	}
+
+	processor := NewProcessor(&types.Config{Operation: types.OperationFetch, Remote: "origin"})
+	first := processor.ProcessRepo(t.Context(), types.GitRepo{Path: clone, Name: "clone"})
+	if first.Error != nil || first.FetchedWith != "" || first.Worktree {
+		t.Fatalf("Expected the main worktree to be fetched, got %+v", first)
+	}
+
+	second := processor.ProcessRepo(t.Context(), types.GitRepo{Path: linked, Name: "linked"})
+	if second.Error != nil {
+		t.Fatalf("Expected the linked worktree to succeed, got %v", second.Error)
+	}
+	if !second.Worktree || second.FetchedWith != "clone" {
+		t.Errorf("Expected the linked worktree to share the fetch of clone, got worktree=%v fetched with %q", second.Worktree, second.FetchedWith)
+	}
+}
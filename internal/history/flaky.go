@@ -0,0 +1,50 @@
+package history
+
+import "github.com/entro314-labs/git-herd/pkg/types"
+
+const (
+	// flakyWindow is how many of a repository's latest runs are checked for flakiness
+	flakyWindow = 10
+
+	// flakyFlips is how often the outcome must change between success and failure within the
+	// window, e.g. ok, failed, ok, failed
+	flakyFlips = 3
+)
+
+// Flaky reports whether the operation keeps alternating between success and failure across the
+// repository's recent records, oldest first. Skipped runs say nothing either way and are left out.
+// A repository that started failing and stays broken is not flaky.
+func Flaky(records []Record, operation types.OperationType) bool {
+	var failed []bool
+	for _, record := range records {
+		if record.Operation == operation && !record.Skipped() {
+			failed = append(failed, record.Failed())
+		}
+	}
+	failed = failed[max(len(failed)-flakyWindow, 0):]
+
+	flips := 0
+	for i := 1; i < len(failed); i++ {
+		if failed[i] != failed[i-1] {
+			flips++
+		}
+	}
+	return flips >= flakyFlips
+}
+
+// FailureStreak returns how many of the repository's latest runs of the operation failed in a
+// row, from records oldest first. Skipped runs neither break nor extend the streak.
+func FailureStreak(records []Record, operation types.OperationType) int {
+	streak := 0
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		if record.Operation != operation || record.Skipped() {
+			continue
+		}
+		if !record.Failed() {
+			break
+		}
+		streak++
+	}
+	return streak
+}
@@ -0,0 +1,68 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestFlaky(t *testing.T) {
+	t.Parallel()
+
+	ok := Record{Operation: types.OperationFetch}
+	failed := Record{Operation: types.OperationFetch, Error: "connection reset"}
+	skipped := Record{Operation: types.OperationFetch, Error: "dirty working tree (skipped)"}
+	pulled := Record{Operation: types.OperationPull, Error: "diverged"}
+
+	tests := []struct {
+		name    string
+		records []Record
+		want    bool
+	}{
+		{"no history", nil, false},
+		{"always ok", []Record{ok, ok, ok, ok}, false},
+		{"broken and staying broken", []Record{ok, ok, failed, failed, failed}, false},
+		{"alternating", []Record{ok, failed, ok, failed}, true},
+		{"skips left out", []Record{ok, failed, skipped, failed, ok, failed}, true},
+		{"other operations left out", []Record{ok, pulled, ok, failed, ok}, false},
+		{"alternation outside the window", []Record{ok, failed, ok, failed, ok, ok, ok, ok, ok, ok, ok, ok, ok, ok}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Flaky(tt.records, types.OperationFetch); got != tt.want {
+				t.Errorf("Flaky() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailureStreak(t *testing.T) {
+	t.Parallel()
+
+	ok := Record{Operation: types.OperationFetch}
+	failed := Record{Operation: types.OperationFetch, Error: "authentication required"}
+	skipped := Record{Operation: types.OperationFetch, Error: "dirty working tree (skipped)"}
+	pulled := Record{Operation: types.OperationPull}
+
+	tests := []struct {
+		name    string
+		records []Record
+		want    int
+	}{
+		{"no history", nil, 0},
+		{"last run ok", []Record{failed, failed, ok}, 0},
+		{"failing since the last success", []Record{failed, ok, failed, failed}, 2},
+		{"skips and other operations left out", []Record{ok, failed, skipped, pulled, failed}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := FailureStreak(tt.records, types.OperationFetch); got != tt.want {
+				t.Errorf("FailureStreak() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
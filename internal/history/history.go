@@ -0,0 +1,132 @@
+// Package history persists per-repository outcomes across runs so later runs can learn from them
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// maxRecords bounds how many outcomes are kept per repository
+const maxRecords = 20
+
+// Record is the outcome of one operation on one repository
+type Record struct {
+	Run       time.Time           `json:"run,omitzero"` // When the run that recorded it started
+	Time      time.Time           `json:"time"`
+	Operation types.OperationType `json:"operation"`
+	Duration  time.Duration       `json:"duration"`
+	Error     string              `json:"error,omitempty"`
+	Labels    map[string]string   `json:"labels,omitempty"`   // Labels of the run, e.g. the machine it ran on
+	Pipeline  string              `json:"pipeline,omitempty"` // Pipeline the operation ran as a step of, "" for none
+	Step      int                 `json:"step,omitzero"`      // Position of the step in Pipeline, from 1
+}
+
+// Failed reports whether the operation failed
+func (r Record) Failed() bool {
+	return r.Error != ""
+}
+
+// Store holds recent outcomes keyed by repository path. It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu    sync.RWMutex
+	repos map[string][]Record
+	dirty bool
+}
+
+// DefaultPath returns the history file location under the user's cache directory
+func DefaultPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "git-herd", "history.json")
+}
+
+// Load reads the history file at path; a missing file yields an empty store
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, repos: make(map[string][]Record)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.repos); err != nil {
+		return nil, fmt.Errorf("parse history %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Add appends an outcome for a repository, dropping its oldest beyond maxRecords
+func (s *Store) Add(repoPath string, record Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := append(s.repos[repoPath], record)
+	if len(records) > maxRecords {
+		records = records[len(records)-maxRecords:]
+	}
+	s.repos[repoPath] = records
+	s.dirty = true
+}
+
+// Records returns a copy of a repository's outcomes, oldest first
+func (s *Store) Records(repoPath string) []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.repos[repoPath])
+}
+
+// Save writes the store back to its file if anything was added since the last save. The file
+// is replaced atomically so a crash mid-write cannot corrupt earlier history.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.repos)
+	if err != nil {
+		return fmt.Errorf("encode history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".history-*.json")
+	if err != nil {
+		return fmt.Errorf("create history file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("replace history: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Parallel()
+
+	store, err := Load(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if records := store.Records("/repos/a"); len(records) != 0 {
+		t.Errorf("Records() = %v, want none", records)
+	}
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of a corrupt file succeeded, want error")
+	}
+}
+
+func TestStoreSaveRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "history.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := Record{
+		Time:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Operation: types.OperationPull,
+		Duration:  1500 * time.Millisecond,
+		Error:     "network unreachable",
+	}
+	store.Add("/repos/a", record)
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	records := reloaded.Records("/repos/a")
+	if len(records) != 1 || !records[0].Time.Equal(record.Time) || records[0].Duration != record.Duration ||
+		records[0].Operation != record.Operation || !records[0].Failed() {
+		t.Errorf("Records() = %+v, want [%+v]", records, record)
+	}
+}
+
+func TestStoreSaveSkipsUnchanged(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Save() with no records wrote %s", path)
+	}
+}
+
+func TestStoreAddCapsRecords(t *testing.T) {
+	t.Parallel()
+
+	store, err := Load(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range maxRecords + 5 {
+		store.Add("/repos/a", Record{Duration: time.Duration(i)})
+	}
+
+	records := store.Records("/repos/a")
+	if len(records) != maxRecords {
+		t.Fatalf("len(Records()) = %d, want %d", len(records), maxRecords)
+	}
+	if records[0].Duration != 5 {
+		t.Errorf("oldest record = %v, want the 6th added", records[0].Duration)
+	}
+}
@@ -0,0 +1,121 @@
+package history
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// Failure categories, from the most to the least specific
+const (
+	CategoryTimeout  = "timeout"
+	CategoryAuth     = "auth"
+	CategoryNetwork  = "network"
+	CategoryDiverged = "diverged"
+	CategoryOther    = "other"
+)
+
+// categoryMarkers are lowercase fragments of error messages that identify each category
+var categoryMarkers = []struct {
+	category string
+	markers  []string
+}{
+	{CategoryTimeout, []string{"timed out", "deadline exceeded", "timeout"}},
+	{CategoryAuth, []string{"authentication", "authorization", "permission denied", "403", "401", "credentials"}},
+	{CategoryNetwork, []string{"could not resolve", "no such host", "connection refused", "connection reset", "network", "dial tcp", "i/o timeout", "unreachable"}},
+	{CategoryDiverged, []string{"diverged", "non-fast-forward", "conflict"}},
+}
+
+// FailureCategory sorts a failure's error message into a broad category
+func FailureCategory(message string) string {
+	message = strings.ToLower(message)
+	for _, c := range categoryMarkers {
+		for _, marker := range c.markers {
+			if strings.Contains(message, marker) {
+				return c.category
+			}
+		}
+	}
+	return CategoryOther
+}
+
+// Skipped reports whether the repository was skipped rather than failed
+func (r Record) Skipped() bool {
+	return strings.Contains(r.Error, "skipped")
+}
+
+// Run aggregates the records one run left across repositories
+type Run struct {
+	Start      time.Time
+	Operation  types.OperationType
+	Successful int
+	Failed     int
+	Skipped    int
+	Duration   time.Duration  // From the first repository started to the last finished
+	Failures   map[string]int // Failed repositories by FailureCategory
+}
+
+// SuccessRate returns the share of the repositories that succeeded or failed that succeeded, in
+// percent; 100 when every repository was skipped
+func (r Run) SuccessRate() float64 {
+	attempted := r.Successful + r.Failed
+	if attempted == 0 {
+		return 100
+	}
+	return float64(r.Successful) * 100 / float64(attempted)
+}
+
+// Runs groups the stored records into runs, oldest first. Records carry the start of their run;
+// records written before that was recorded are grouped by the minute they finished in.
+func (s *Store) Runs() []Run {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type runKey struct {
+		start     time.Time
+		operation types.OperationType
+	}
+	runs := make(map[runKey]*Run)
+	finished := make(map[runKey]time.Time)
+
+	for _, records := range s.repos {
+		for _, record := range records {
+			key := runKey{record.Run, record.Operation}
+			if record.Run.IsZero() {
+				key.start = record.Time.Truncate(time.Minute)
+			}
+			run, ok := runs[key]
+			if !ok {
+				run = &Run{Start: record.Time.Add(-record.Duration), Operation: record.Operation, Failures: make(map[string]int)}
+				runs[key] = run
+			}
+
+			switch {
+			case record.Skipped():
+				run.Skipped++
+			case record.Failed():
+				run.Failed++
+				run.Failures[FailureCategory(record.Error)]++
+			default:
+				run.Successful++
+			}
+			if started := record.Time.Add(-record.Duration); started.Before(run.Start) {
+				run.Start = started
+			}
+			if record.Time.After(finished[key]) {
+				finished[key] = record.Time
+			}
+		}
+	}
+
+	result := make([]Run, 0, len(runs))
+	for key, run := range runs {
+		run.Duration = finished[key].Sub(run.Start)
+		result = append(result, *run)
+	}
+	slices.SortFunc(result, func(a, b Run) int { return cmp.Compare(a.Start.UnixNano(), b.Start.UnixNano()) })
+	return result
+}
@@ -0,0 +1,56 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestFailureCategory(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"timed out after 30s: fetch failed: context deadline exceeded":   CategoryTimeout,
+		"fetch failed: authentication required":                          CategoryAuth,
+		"fetch failed: dial tcp: lookup github.com: no such host":        CategoryNetwork,
+		"diverged from the remote, not pulling with --pull-strategy ...": CategoryDiverged,
+		"failed to open repository: repository does not exist":           CategoryOther,
+	}
+	for message, want := range tests {
+		if got := FailureCategory(message); got != want {
+			t.Errorf("FailureCategory(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+func TestStoreRuns(t *testing.T) {
+	t.Parallel()
+
+	first := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	store := &Store{repos: map[string][]Record{
+		"/repos/a": {
+			{Run: first, Time: first.Add(10 * time.Second), Operation: types.OperationFetch, Duration: 10 * time.Second},
+			{Run: second, Time: second.Add(4 * time.Second), Operation: types.OperationFetch, Duration: 4 * time.Second},
+		},
+		"/repos/b": {
+			{Run: first, Time: first.Add(20 * time.Second), Operation: types.OperationFetch, Duration: 5 * time.Second, Error: "fetch failed: authentication required"},
+			{Run: second, Time: second.Add(3 * time.Second), Operation: types.OperationFetch, Duration: 3 * time.Second, Error: "repository has uncommitted changes (skipped)"},
+		},
+	}}
+
+	runs := store.Runs()
+	if len(runs) != 2 {
+		t.Fatalf("Runs() = %+v, want 2 runs", runs)
+	}
+	if got := runs[0]; got.Successful != 1 || got.Failed != 1 || got.Failures[CategoryAuth] != 1 || got.Duration != 20*time.Second {
+		t.Errorf("First run = %+v, want 1 successful, 1 auth failure over 20s", got)
+	}
+	if got := runs[1]; got.Successful != 1 || got.Skipped != 1 || got.SuccessRate() != 100 {
+		t.Errorf("Second run = %+v, want 1 successful and 1 skipped", got)
+	}
+	if runs[0].SuccessRate() != 50 {
+		t.Errorf("SuccessRate() = %v, want 50", runs[0].SuccessRate())
+	}
+}
@@ -0,0 +1,114 @@
+// Package index persists the repositories discovery found below each directory, so later runs
+// can skip walking the filesystem
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Repo is a repository discovery found, as the index records it
+type Repo struct {
+	Path       string `json:"path"` // Path below the root, with slashes
+	Name       string `json:"name"`
+	Bare       bool   `json:"bare,omitempty"`
+	Nesting    int    `json:"nesting,omitempty"` // Repositories it is inside
+	Submodules bool   `json:"submodules,omitempty"`
+}
+
+// Entry is what one walk of a directory found
+type Entry struct {
+	Scanned  time.Time `json:"scanned"`
+	Settings string    `json:"settings"` // The discovery settings of the walk, e.g. --exclude
+	Repos    []Repo    `json:"repos"`
+}
+
+// Index holds the latest walk of each directory, keyed by absolute path
+type Index struct {
+	path    string
+	entries map[string]Entry
+}
+
+// DefaultPath returns the index file location under the user's cache directory
+func DefaultPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "git-herd", "index.json")
+}
+
+// New returns an empty index to be saved at path
+func New(path string) *Index {
+	return &Index{path: path, entries: make(map[string]Entry)}
+}
+
+// Load reads the index file at path; a missing file yields an empty index
+func Load(path string) (*Index, error) {
+	index := New(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index.entries); err != nil {
+		return nil, fmt.Errorf("parse index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+// Lookup returns the latest walk of the directory at root, as long as it was made with the same
+// settings
+func (i *Index) Lookup(root, settings string) (Entry, bool) {
+	entry, ok := i.entries[root]
+	if !ok || entry.Settings != settings {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Put records a walk of the directory at root, replacing the one before
+func (i *Index) Put(root string, entry Entry) {
+	i.entries[root] = entry
+}
+
+// Save writes the index back to its file. The file is replaced atomically so two runs saving at
+// once leave one of their indexes rather than a mix of both.
+func (i *Index) Save() error {
+	data, err := json.Marshal(i.entries)
+	if err != nil {
+		return fmt.Errorf("encode index: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(i.path), 0o755); err != nil {
+		return fmt.Errorf("create index directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(i.path), ".index-*.json")
+	if err != nil {
+		return fmt.Errorf("create index file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), i.path); err != nil {
+		return fmt.Errorf("replace index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Parallel()
+
+	index, err := Load(filepath.Join(t.TempDir(), "index.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := index.Lookup("/src", ""); ok {
+		t.Error("Lookup() found an entry in an empty index")
+	}
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of a corrupt file succeeded, want error")
+	}
+}
+
+func TestIndexSaveRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "index.json")
+	index, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := Entry{
+		Scanned:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Settings: "recursive=true",
+		Repos:    []Repo{{Path: "api", Name: "api"}, {Path: "libs/core", Name: "core", Submodules: true}},
+	}
+	index.Put("/src", entry)
+	if err := index.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got, ok := reloaded.Lookup("/src", "recursive=true")
+	if !ok {
+		t.Fatal("Lookup() found nothing after a save")
+	}
+	if !got.Scanned.Equal(entry.Scanned) || len(got.Repos) != 2 || got.Repos[1] != entry.Repos[1] {
+		t.Errorf("Lookup() = %+v, want %+v", got, entry)
+	}
+
+	// A walk with other settings, e.g. another --exclude, may have found other repositories
+	if _, ok := reloaded.Lookup("/src", "recursive=false"); ok {
+		t.Error("Lookup() matched an entry made with other settings")
+	}
+}
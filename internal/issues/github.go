@@ -0,0 +1,223 @@
+// Package issues files GitHub issues for repositories that keep failing, so unattended runs
+// report their persistent failures somewhere people look
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+const (
+	// Label marks the issues git-herd files, so it finds them again to update
+	Label = "git-herd"
+
+	// defaultAPI is GitHub's REST API; GITHUB_API_URL points elsewhere, e.g. at GitHub Enterprise
+	defaultAPI = "https://api.github.com"
+
+	// historyRows is how many of the latest runs an issue's error history shows
+	historyRows = 10
+)
+
+// Failure is a repository that failed the operation in Streak consecutive runs
+type Failure struct {
+	Name      string
+	Path      string
+	Operation types.OperationType
+	Streak    int
+	Records   []history.Record // The repository's history, oldest first
+	Labels    []string         // Labels of the run that filed it as key=value pairs
+}
+
+// Title identifies the failure's issue; it stays the same across runs so the issue is found
+// again and updated instead of filed twice
+func (f Failure) Title() string {
+	return fmt.Sprintf("git-herd: %s fails to %s", f.Path, f.Operation)
+}
+
+// Body describes the failure with its latest runs of the operation, newest first
+func (f Failure) Body() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "`%s` (%s) has failed to %s in the last %d runs of git-herd.\n\n", f.Path, f.Name, f.Operation, f.Streak)
+	b.WriteString("| Finished | Duration | Outcome |\n|---|---|---|\n")
+
+	rows := 0
+	for i := len(f.Records) - 1; i >= 0 && rows < historyRows; i-- {
+		record := f.Records[i]
+		if record.Operation != f.Operation {
+			continue
+		}
+		outcome := "ok"
+		if record.Failed() {
+			outcome = strings.ReplaceAll(strings.ReplaceAll(record.Error, "|", `\|`), "\n", " ")
+		}
+		fmt.Fprintf(&b, "| %s | %v | %s |\n", record.Time.Format("2006-01-02 15:04"), record.Duration.Truncate(time.Millisecond), outcome)
+		rows++
+	}
+
+	updatedBy := "git-herd"
+	if len(f.Labels) > 0 {
+		updatedBy += " (" + strings.Join(f.Labels, ", ") + ")"
+	}
+	fmt.Fprintf(&b, "\nUpdated by %s at %s. Close this issue once the repository is fixed; should it fail again, a new issue is opened.\n",
+		updatedBy, time.Now().Format(time.RFC1123))
+	return b.String()
+}
+
+// Filer opens or updates the issue for a failure, returning its URL and whether it was newly
+// opened
+type Filer interface {
+	File(ctx context.Context, failure Failure) (url string, opened bool, err error)
+}
+
+// Tracker files issues in one GitHub repository through the REST API
+type Tracker struct {
+	client *http.Client
+	api    string
+	repo   string // owner/name
+	token  string
+}
+
+// NewTracker creates a Tracker filing issues in repo, given as owner/name. The token comes from
+// GITHUB_TOKEN or GH_TOKEN and the API from GITHUB_API_URL, as in GitHub Actions.
+func NewTracker(repo string) (*Tracker, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("filing issues in %s requires a token in GITHUB_TOKEN or GH_TOKEN", repo)
+	}
+
+	api := os.Getenv("GITHUB_API_URL")
+	if api == "" {
+		api = defaultAPI
+	}
+	return &Tracker{
+		client: &http.Client{Timeout: 30 * time.Second},
+		api:    strings.TrimSuffix(api, "/"),
+		repo:   repo,
+		token:  token,
+	}, nil
+}
+
+// issue is the part of a GitHub issue the tracker uses
+type issue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+}
+
+// File opens an issue for the failure, or updates the body of the open one filed for it before.
+// It returns the issue's URL and whether it was newly opened.
+func (t *Tracker) File(ctx context.Context, failure Failure) (url string, opened bool, err error) {
+	existing, err := t.findOpen(ctx, failure.Title())
+	if err != nil {
+		return "", false, err
+	}
+
+	if existing != nil {
+		var updated issue
+		path := fmt.Sprintf("/repos/%s/issues/%d", t.repo, existing.Number)
+		if err := t.do(ctx, http.MethodPatch, path, map[string]any{"body": failure.Body()}, &updated); err != nil {
+			return "", false, fmt.Errorf("failed to update issue #%d: %w", existing.Number, err)
+		}
+		return updated.HTMLURL, false, nil
+	}
+
+	var created issue
+	if err := t.do(ctx, http.MethodPost, "/repos/"+t.repo+"/issues", newIssueRequest(failure), &created); err != nil {
+		return "", false, fmt.Errorf("failed to open issue: %w", err)
+	}
+	return created.HTMLURL, true, nil
+}
+
+// findOpen returns the open git-herd issue titled title, or nil if there is none
+func (t *Tracker) findOpen(ctx context.Context, title string) (*issue, error) {
+	for page := 1; ; page++ {
+		var open []issue
+		path := fmt.Sprintf("/repos/%s/issues?state=open&labels=%s&per_page=100&page=%d", t.repo, Label, page)
+		if err := t.do(ctx, http.MethodGet, path, nil, &open); err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, candidate := range open {
+			if candidate.Title == title {
+				return &candidate, nil
+			}
+		}
+		if len(open) < 100 {
+			return nil, nil
+		}
+	}
+}
+
+// do sends an API request with body encoded as JSON, if any, and decodes the response into out
+func (t *Tracker) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.api+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// newIssueRequest is the API request body opening an issue for the failure
+func newIssueRequest(failure Failure) map[string]any {
+	return map[string]any{"title": failure.Title(), "body": failure.Body(), "labels": []string{Label}}
+}
+
+// Preview writes the request that would open each issue instead of sending it, to iterate on
+// issues without filing them (--notify-dry-run)
+type Preview struct {
+	repo string
+	out  io.Writer
+}
+
+// NewPreview creates a Preview of the issues that would be filed in repo, written to out
+func NewPreview(repo string, out io.Writer) *Preview {
+	return &Preview{repo: repo, out: out}
+}
+
+// File writes the request opening the failure's issue; nothing is sent and no URL is returned
+func (p *Preview) File(ctx context.Context, failure Failure) (string, bool, error) {
+	data, err := json.MarshalIndent(newIssueRequest(failure), "", "  ")
+	if err != nil {
+		return "", false, err
+	}
+	_, err = fmt.Fprintf(p.out, "POST /repos/%s/issues (not sent with --notify-dry-run; an open issue with this title is updated instead)\n%s\n", p.repo, data)
+	return "", false, err
+}
@@ -0,0 +1,137 @@
+package issues
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// fakeGitHub serves the issue endpoints the tracker uses from memory
+type fakeGitHub struct {
+	mu     sync.Mutex
+	issues []map[string]any
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var request map[string]any
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/ops/issues":
+		_ = json.NewEncoder(w).Encode(f.issues)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/ops/issues":
+		number := len(f.issues) + 1
+		created := map[string]any{"number": number, "title": request["title"], "body": request["body"], "html_url": "https://github.com/acme/ops/issues/1"}
+		f.issues = append(f.issues, created)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(created)
+	case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/ops/issues/1":
+		f.issues[0]["body"] = request["body"]
+		_ = json.NewEncoder(w).Encode(f.issues[0])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestTrackerFile(t *testing.T) {
+	github := &fakeGitHub{}
+	server := httptest.NewServer(github)
+	defer server.Close()
+
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("GITHUB_API_URL", server.URL)
+	tracker, err := NewTracker("acme/ops")
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+
+	run := time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)
+	failure := Failure{
+		Name:      "api",
+		Path:      "/srv/repos/api",
+		Operation: types.OperationFetch,
+		Streak:    2,
+		Records: []history.Record{
+			{Time: run, Operation: types.OperationFetch, Duration: time.Second},
+			{Time: run.Add(24 * time.Hour), Operation: types.OperationFetch, Error: "authentication required | denied"},
+			{Time: run.Add(48 * time.Hour), Operation: types.OperationFetch, Error: "authentication required"},
+		},
+	}
+
+	url, opened, err := tracker.File(t.Context(), failure)
+	if err != nil || !opened || url != "https://github.com/acme/ops/issues/1" {
+		t.Fatalf("File() = %q, %v, %v, want a new issue", url, opened, err)
+	}
+
+	failure.Streak = 3
+	if _, opened, err := tracker.File(t.Context(), failure); err != nil || opened {
+		t.Fatalf("File() again = %v, %v, want the open issue updated", opened, err)
+	}
+	if len(github.issues) != 1 {
+		t.Fatalf("Expected one issue, got %d", len(github.issues))
+	}
+
+	body := github.issues[0]["body"].(string)
+	for _, want := range []string{
+		"failed to fetch in the last 3 runs",
+		"| 2026-10-16 02:00 | 0s | authentication required |",
+		`authentication required \| denied`,
+		"| 2026-10-14 02:00 | 1s | ok |",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the issue body to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestNewTrackerRequiresToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	if _, err := NewTracker("acme/ops"); err == nil {
+		t.Error("Expected NewTracker() without a token to fail")
+	}
+}
+
+func TestPreviewFile(t *testing.T) {
+	var out strings.Builder
+	failure := Failure{Name: "api", Path: "/repos/api", Operation: types.OperationPull, Streak: 3}
+	url, opened, err := NewPreview("acme/ops", &out).File(t.Context(), failure)
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if url != "" || opened {
+		t.Errorf("Expected a preview to file nothing, got %q, opened %v", url, opened)
+	}
+
+	request, found := strings.CutPrefix(out.String(), "POST /repos/acme/ops/issues")
+	if !found {
+		t.Fatalf("Expected the request line first, got:\n%s", out.String())
+	}
+	var body struct {
+		Title  string
+		Labels []string
+	}
+	if err := json.Unmarshal([]byte(request[strings.Index(request, "\n"):]), &body); err != nil {
+		t.Fatalf("Expected the JSON request body, got %v:\n%s", err, request)
+	}
+	if body.Title != failure.Title() || len(body.Labels) != 1 || body.Labels[0] != Label {
+		t.Errorf("Unexpected request body %+v", body)
+	}
+}
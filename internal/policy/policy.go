@@ -0,0 +1,203 @@
+// Package policy evaluates conditions on the state of a repository, such as
+// "behind > 0 && clean", written as Go expressions over a fixed set of variables
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Condition is a compiled condition, checked to be a valid boolean expression over the known
+// variables
+type Condition struct {
+	source string
+	root   ast.Expr
+}
+
+// Compile parses and checks a condition. It takes identifiers of the variables, true and false,
+// integer and string literals, comparisons (== != < <= > >=), !, && and ||, and parentheses.
+func Compile(source string) (*Condition, error) {
+	source = strings.TrimSpace(source)
+	root, err := parser.ParseExpr(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", source, err)
+	}
+	k, err := check(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", source, err)
+	}
+	if k != kindBool {
+		return nil, fmt.Errorf("invalid condition %q: is %s, not true or false", source, k)
+	}
+	return &Condition{source: source, root: root}, nil
+}
+
+// String returns the condition as written
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Eval reports whether the condition holds for vars
+func (c *Condition) Eval(vars Vars) bool {
+	return eval(c.root, vars).(bool)
+}
+
+// kind is the type of a value in a condition
+type kind int
+
+const (
+	kindBool kind = iota
+	kindInt
+	kindString
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindBool:
+		return "a boolean"
+	case kindInt:
+		return "a number"
+	default:
+		return "a string"
+	}
+}
+
+// check returns the kind of an expression, or why it is not a valid one
+func check(node ast.Expr) (kind, error) {
+	switch node := node.(type) {
+	case *ast.ParenExpr:
+		return check(node.X)
+	case *ast.Ident:
+		if node.Name == "true" || node.Name == "false" {
+			return kindBool, nil
+		}
+		k, ok := variables[node.Name]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %s (known are %s)", node.Name, strings.Join(Variables(), ", "))
+		}
+		return k, nil
+	case *ast.BasicLit:
+		switch node.Kind {
+		case token.INT:
+			if _, err := strconv.Atoi(node.Value); err != nil {
+				return 0, fmt.Errorf("invalid number %s", node.Value)
+			}
+			return kindInt, nil
+		case token.STRING:
+			if _, err := strconv.Unquote(node.Value); err != nil {
+				return 0, fmt.Errorf("invalid string %s", node.Value)
+			}
+			return kindString, nil
+		}
+	case *ast.UnaryExpr:
+		x, err := check(node.X)
+		if err != nil {
+			return 0, err
+		}
+		if node.Op == token.NOT && x == kindBool {
+			return kindBool, nil
+		}
+		if node.Op == token.SUB && x == kindInt {
+			return kindInt, nil
+		}
+	case *ast.BinaryExpr:
+		x, err := check(node.X)
+		if err != nil {
+			return 0, err
+		}
+		y, err := check(node.Y)
+		if err != nil {
+			return 0, err
+		}
+		switch node.Op {
+		case token.LAND, token.LOR:
+			if x == kindBool && y == kindBool {
+				return kindBool, nil
+			}
+			return 0, fmt.Errorf("%s needs true or false on both sides", node.Op)
+		case token.EQL, token.NEQ:
+			if x == y {
+				return kindBool, nil
+			}
+			return 0, fmt.Errorf("cannot compare %s with %s", x, y)
+		case token.LSS, token.LEQ, token.GTR, token.GEQ:
+			if x == y && x != kindBool {
+				return kindBool, nil
+			}
+			return 0, fmt.Errorf("%s needs two numbers or two strings", node.Op)
+		}
+	}
+	return 0, fmt.Errorf("unsupported expression %T", node)
+}
+
+// eval evaluates an expression check accepted
+func eval(node ast.Expr, vars Vars) any {
+	switch node := node.(type) {
+	case *ast.ParenExpr:
+		return eval(node.X, vars)
+	case *ast.Ident:
+		switch node.Name {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+		return vars.get(node.Name)
+	case *ast.BasicLit:
+		if node.Kind == token.INT {
+			n, _ := strconv.Atoi(node.Value)
+			return n
+		}
+		s, _ := strconv.Unquote(node.Value)
+		return s
+	case *ast.UnaryExpr:
+		if node.Op == token.NOT {
+			return !eval(node.X, vars).(bool)
+		}
+		return -eval(node.X, vars).(int)
+	case *ast.BinaryExpr:
+		switch node.Op {
+		case token.LAND:
+			return eval(node.X, vars).(bool) && eval(node.Y, vars).(bool)
+		case token.LOR:
+			return eval(node.X, vars).(bool) || eval(node.Y, vars).(bool)
+		}
+		x, y := eval(node.X, vars), eval(node.Y, vars)
+		switch node.Op {
+		case token.EQL:
+			return x == y
+		case token.NEQ:
+			return x != y
+		}
+		if x, ok := x.(int); ok {
+			return compare(node.Op, x, y.(int))
+		}
+		return compare(node.Op, x.(string), y.(string))
+	}
+	panic(fmt.Sprintf("policy: unchecked expression %T", node))
+}
+
+// compare applies an ordering operator
+func compare[T int | string](op token.Token, x, y T) bool {
+	switch op {
+	case token.LSS:
+		return x < y
+	case token.LEQ:
+		return x <= y
+	case token.GTR:
+		return x > y
+	default:
+		return x >= y
+	}
+}
+
+// Variables returns the names of the variables conditions can use, sorted
+func Variables() []string {
+	return slices.Sorted(maps.Keys(variables))
+}
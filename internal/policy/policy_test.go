@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestCondition_Eval(t *testing.T) {
+	t.Parallel()
+
+	repo := types.GitRepo{Name: "api", Branch: "main", Upstream: "origin/main", Behind: 2, Clean: true}
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{"behind > 0 && clean", true},
+		{"behind > 0 && dirty", false},
+		{"ahead > 0 || behind >= 2", true},
+		{"!(clean)", false},
+		{`branch == "main" && name != "web"`, true},
+		{`branch < "release"`, true},
+		{"no_upstream || detached", false},
+		{"changed", true},
+		{"ahead > -1 && true", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			t.Parallel()
+
+			condition, err := Compile(tt.condition)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got := condition.Eval(RepoVars(repo, true)); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		condition string
+		want      string
+	}{
+		{"behind >", "invalid condition"},
+		{"behnd > 0", "unknown variable behnd"},
+		{"behind", "is a number, not true or false"},
+		{`behind == "2"`, "cannot compare a number with a string"},
+		{"clean && 1", "needs true or false on both sides"},
+		{"clean < dirty", "needs two numbers or two strings"},
+		{"len(branch) > 0", "unsupported expression"},
+		{"behind - 1 > 0", "unsupported expression"},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.condition); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Compile(%q) error = %v, want it to contain %q", tt.condition, err, tt.want)
+		}
+	}
+}
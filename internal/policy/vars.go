@@ -0,0 +1,68 @@
+package policy
+
+import "github.com/entro314-labs/git-herd/pkg/types"
+
+// variables are the variables conditions can use, with their kinds
+var variables = map[string]kind{
+	"ahead":         kindInt,    // Commits on the current branch its upstream lacks
+	"behind":        kindInt,    // Commits in the upstream the current branch lacks
+	"stashes":       kindInt,    // Stash entries
+	"modified":      kindInt,    // Files with uncommitted changes
+	"nesting":       kindInt,    // Repositories the repository is inside
+	"clean":         kindBool,   // No uncommitted changes
+	"dirty":         kindBool,   // Uncommitted changes
+	"detached":      kindBool,   // HEAD is not on a branch
+	"empty":         kindBool,   // No commits yet
+	"bare":          kindBool,   // No worktree
+	"shallow":       kindBool,   // Shallow clone
+	"no_upstream":   kindBool,   // The current branch tracks nothing
+	"upstream_gone": kindBool,   // The upstream's branch is gone from the remote
+	"changed":       kindBool,   // The step before moved HEAD, e.g. a pull that brought in commits
+	"name":          kindString, // Name of the repository
+	"branch":        kindString, // Current branch, "detached" when HEAD is detached
+	"remote":        kindString, // Remote operations use
+	"group":         kindString, // Group the repository is in, "" for none
+}
+
+// Vars are the values of the variables for one repository
+type Vars map[string]any
+
+// RepoVars returns the variables of an analyzed repository whose status has been read, with
+// changed telling whether the step before moved HEAD
+func RepoVars(repo types.GitRepo, changed bool) Vars {
+	return Vars{
+		"ahead":         repo.Ahead,
+		"behind":        repo.Behind,
+		"stashes":       repo.Stashes,
+		"modified":      len(repo.ModifiedFiles),
+		"nesting":       repo.Nesting,
+		"clean":         repo.Clean,
+		"dirty":         !repo.Clean,
+		"detached":      repo.Branch == "detached",
+		"empty":         repo.Empty,
+		"bare":          repo.Bare,
+		"shallow":       repo.Shallow,
+		"no_upstream":   repo.Upstream == "" && repo.Branch != "detached",
+		"upstream_gone": repo.UpstreamGone,
+		"changed":       changed,
+		"name":          repo.Name,
+		"branch":        repo.Branch,
+		"remote":        repo.Remote,
+		"group":         repo.Group,
+	}
+}
+
+// get returns the value of a variable, or the zero value of its kind when vars lack it
+func (v Vars) get(name string) any {
+	if value, ok := v[name]; ok {
+		return value
+	}
+	switch variables[name] {
+	case kindBool:
+		return false
+	case kindInt:
+		return 0
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"unicode/utf8"
+)
+
+// Badge colors, as used by shields.io
+const (
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+	badgeGrey   = "#9f9f9f"
+)
+
+// Badge is a shields.io-style status badge for a run, written to --badge
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// NewBadge summarizes a run that found found repositories, tallied tally and ended with err as
+// a badge reading e.g. "repos | 35/36 ok". The ratio leaves skipped repositories out; it is
+// green when nothing failed, yellow from 90% and red below.
+func NewBadge(tally *Tally, found int, err error) Badge {
+	badge := Badge{Label: "repos"}
+	attempted := tally.Successful + tally.Failed
+
+	switch {
+	case runStatus(tally, found, err) == StatusError:
+		badge.Message, badge.Color = "error", badgeRed
+	case tally.Total == 0:
+		badge.Message, badge.Color = "none found", badgeGrey
+	default:
+		badge.Message = fmt.Sprintf("%d/%d ok", tally.Successful, attempted)
+		switch {
+		case tally.Failed == 0:
+			badge.Color = badgeGreen
+		case tally.Successful*10 >= attempted*9:
+			badge.Color = badgeYellow
+		default:
+			badge.Color = badgeRed
+		}
+	}
+	return badge
+}
+
+// textWidth estimates how wide text renders in the badge's 11px Verdana, in pixels
+func textWidth(text string) int {
+	return utf8.RuneCountInString(text)*7 + 10
+}
+
+// SVG renders the badge in the flat shields.io style
+func (b Badge) SVG() string {
+	labelWidth, messageWidth := textWidth(b.Label), textWidth(b.Message)
+	width := labelWidth + messageWidth
+	label, message := html.EscapeString(b.Label), html.EscapeString(b.Message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+  <title>%[4]s: %[5]s</title>
+  <linearGradient id="s" x2="0" y2="100%%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="%[1]d" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[2]d" height="20" fill="#555"/>
+    <rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text>
+    <text x="%[7]d" y="14">%[4]s</text>
+    <text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text>
+    <text x="%[8]d" y="14">%[5]s</text>
+  </g>
+</svg>
+`, width, labelWidth, messageWidth, label, message, b.Color, labelWidth/2, labelWidth+messageWidth/2)
+}
+
+// WriteBadge writes badge as SVG to path
+func WriteBadge(path string, badge Badge) error {
+	if err := os.WriteFile(path, []byte(badge.SVG()), 0o644); err != nil {
+		return fmt.Errorf("failed to write badge: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewBadge(t *testing.T) {
+	tests := []struct {
+		name    string
+		tally   Tally
+		found   int
+		err     error
+		message string
+		color   string
+	}{
+		{"all ok", Tally{Total: 4, Successful: 3, Skipped: 1}, 4, nil, "3/3 ok", badgeGreen},
+		{"few failures", Tally{Total: 20, Successful: 19, Failed: 1}, 20, errors.New("1 repositories failed"), "19/20 ok", badgeYellow},
+		{"many failures", Tally{Total: 4, Successful: 2, Failed: 2}, 4, errors.New("2 repositories failed"), "2/4 ok", badgeRed},
+		{"nothing found", Tally{}, 0, nil, "none found", badgeGrey},
+		{"scan failed", Tally{}, 0, errors.New("failed to find repositories"), "error", badgeRed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badge := NewBadge(&tt.tally, tt.found, tt.err)
+			if badge.Message != tt.message || badge.Color != tt.color {
+				t.Errorf("NewBadge() = %q in %s, want %q in %s", badge.Message, badge.Color, tt.message, tt.color)
+			}
+		})
+	}
+}
+
+func TestWriteBadge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "badge.svg")
+	if err := WriteBadge(path, Badge{Label: "repos", Message: "35/36 ok", Color: badgeYellow}); err != nil {
+		t.Fatalf("WriteBadge() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svg struct {
+		XMLName xml.Name `xml:"svg"`
+		Title   string   `xml:"title"`
+	}
+	if err := xml.Unmarshal(data, &svg); err != nil {
+		t.Fatalf("Badge is not valid SVG: %v\n%s", err, data)
+	}
+	if svg.Title != "repos: 35/36 ok" {
+		t.Errorf("Expected the title to read the badge, got %q", svg.Title)
+	}
+	if !strings.Contains(string(data), badgeYellow) {
+		t.Errorf("Expected the badge to be %s, got\n%s", badgeYellow, data)
+	}
+}
@@ -0,0 +1,159 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// commitType is a conventional-commit type and the title of its changelog section
+type commitType struct {
+	kind, title string
+}
+
+// conventionalTypes are the conventional-commit types the changelog groups by, in the order
+// their sections appear; commits of any other type, or of none, go under "Other"
+var conventionalTypes = []commitType{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "CI"},
+	{"style", "Style"},
+	{"chore", "Chores"},
+}
+
+// conventionalSubject matches "type(scope)!: description"
+var conventionalSubject = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+// ChangelogWriter collects the commits of every repository and writes them as one markdown
+// document, a section per repository that has any, for a "what changed in our stack" summary
+type ChangelogWriter struct {
+	path         string
+	since        string
+	conventional bool
+	repos        []types.GitRepo
+	quiet        []string // Repositories without commits since the date
+	failed       []string // Repositories whose commits could not be read
+}
+
+// NewChangelogWriter creates a writer for the changelog at path; nothing is written until Close
+func NewChangelogWriter(path, since string, conventional bool) *ChangelogWriter {
+	return &ChangelogWriter{path: path, since: since, conventional: conventional}
+}
+
+// Add keeps the result's commits
+func (w *ChangelogWriter) Add(result types.GitRepo) {
+	switch {
+	case result.Error != nil:
+		w.failed = append(w.failed, result.Name)
+	case len(result.Commits) == 0:
+		w.quiet = append(w.quiet, result.Name)
+	default:
+		w.repos = append(w.repos, types.GitRepo{Name: result.Name, Path: result.Path, Branch: result.Branch, Commits: result.Commits})
+	}
+}
+
+// Close writes the changelog, repositories in name order
+func (w *ChangelogWriter) Close() error {
+	slices.SortFunc(w.repos, func(a, b types.GitRepo) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Path, b.Path))
+	})
+	slices.Sort(w.quiet)
+	slices.Sort(w.failed)
+
+	commits := 0
+	for _, repo := range w.repos {
+		commits += len(repo.Commits)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Changelog since %s\n\n", w.since)
+	fmt.Fprintf(&b, "Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "%s across %s.\n", plural(commits, "commit", "commits"), plural(len(w.repos), "repository", "repositories"))
+
+	for _, repo := range w.repos {
+		fmt.Fprintf(&b, "\n## %s\n\n", repo.Name)
+		fmt.Fprintf(&b, "`%s` on `%s`, %s\n", repo.Path, repo.Branch, plural(len(repo.Commits), "commit", "commits"))
+		if w.conventional {
+			writeGrouped(&b, repo.Commits)
+		} else {
+			b.WriteString("\n")
+			for _, commit := range repo.Commits {
+				b.WriteString(commitLine(commit.Subject, commit))
+			}
+		}
+	}
+
+	if len(w.quiet) > 0 {
+		fmt.Fprintf(&b, "\n---\n\nNo commits since %s: %s\n", w.since, strings.Join(w.quiet, ", "))
+	}
+	if len(w.failed) > 0 {
+		fmt.Fprintf(&b, "\nCould not be read: %s\n", strings.Join(w.failed, ", "))
+	}
+
+	if err := os.WriteFile(w.path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return nil
+}
+
+// writeGrouped writes a repository's commits in a section per conventional-commit type,
+// breaking changes flagged, with the scope in bold ahead of the description
+func writeGrouped(b *strings.Builder, commits []types.Commit) {
+	groups := make(map[string][]string)
+	for _, commit := range commits {
+		kind, line := "", commitLine(commit.Subject, commit)
+		match := conventionalSubject.FindStringSubmatch(commit.Subject)
+		if match != nil && slices.ContainsFunc(conventionalTypes, func(t commitType) bool { return t.kind == strings.ToLower(match[1]) }) {
+			kind = strings.ToLower(match[1])
+			description := match[4]
+			if match[2] != "" {
+				description = "**" + match[2] + ":** " + description
+			}
+			if match[3] != "" {
+				description = "⚠️ BREAKING: " + description
+			}
+			line = commitLine(description, commit)
+		}
+		groups[kind] = append(groups[kind], line)
+	}
+
+	for _, section := range slices.Concat(conventionalTypes, []commitType{{"", "Other"}}) {
+		if len(groups[section.kind]) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n### %s\n\n", section.title)
+		for _, line := range groups[section.kind] {
+			b.WriteString(line)
+		}
+	}
+}
+
+// commitLine formats one changelog entry, e.g. "- Fix login (`1a2b3c4d`, Ada, 2024-01-05)"
+func commitLine(text string, commit types.Commit) string {
+	return fmt.Sprintf("- %s (`%s`, %s, %s)\n", text, commit.Hash, commit.Author, commit.Date.Format(time.DateOnly))
+}
+
+// ChangelogLabel describes what the changelog collected for a result, e.g. "12 commits"
+func ChangelogLabel(result types.GitRepo) string {
+	if len(result.Commits) == 0 {
+		return "no commits"
+	}
+	return plural(len(result.Commits), "commit", "commits")
+}
+
+// ChangelogSummary summarizes a run's changelog, e.g. "120 commits across 8 repositories"
+func ChangelogSummary(commits, repos int) string {
+	return plural(commits, "commit", "commits") + " across " + plural(repos, "repository", "repositories")
+}
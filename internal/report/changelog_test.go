@@ -0,0 +1,111 @@
+package report
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func changelogResults() []types.GitRepo {
+	date := time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)
+	return []types.GitRepo{
+		{Name: "web", Path: "/repos/web", Branch: "main", Commits: []types.Commit{
+			{Hash: "1a2b3c4", Author: "Ada", Date: date, Subject: "feat(auth): add login"},
+			{Hash: "5e6f7a8", Author: "Linus", Date: date, Subject: "fix!: drop the v1 API"},
+			{Hash: "9b0c1d2", Author: "Ada", Date: date, Subject: "Update README"},
+			{Hash: "3e4f5a6", Author: "Ada", Date: date, Subject: "wip: half done"},
+		}},
+		{Name: "api", Path: "/repos/api", Branch: "main", Commits: []types.Commit{
+			{Hash: "7b8c9d0", Author: "Grace", Date: date, Subject: "Bump Go"},
+		}},
+		{Name: "docs", Path: "/repos/docs"},
+		{Name: "broken", Path: "/repos/broken", Error: errors.New("failed to read commits")},
+	}
+}
+
+func TestChangelogWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changelog.md")
+	w := NewChangelogWriter(path, "2024-01-01", false)
+	for _, result := range changelogResults() {
+		w.Add(result)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	for _, want := range []string{
+		"# Changelog since 2024-01-01\n",
+		"5 commits across 2 repositories.\n",
+		"- feat(auth): add login (`1a2b3c4`, Ada, 2024-01-05)\n",
+		"No commits since 2024-01-01: docs\n",
+		"Could not be read: broken\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected the changelog to contain %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Index(content, "## api") > strings.Index(content, "## web") {
+		t.Errorf("Expected repositories in name order, got:\n%s", content)
+	}
+	if strings.Contains(content, "### ") {
+		t.Errorf("Expected no grouping without conventional, got:\n%s", content)
+	}
+}
+
+func TestChangelogWriterConventional(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changelog.md")
+	w := NewChangelogWriter(path, "2024-01-01", true)
+	for _, result := range changelogResults() {
+		w.Add(result)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	web := content[strings.Index(content, "## web"):]
+	for _, want := range []string{
+		"### Features\n\n- **auth:** add login (`1a2b3c4`, Ada, 2024-01-05)\n",
+		"### Bug Fixes\n\n- ⚠️ BREAKING: drop the v1 API (`5e6f7a8`, Linus, 2024-01-05)\n",
+		"### Other\n\n- Update README (`9b0c1d2`, Ada, 2024-01-05)\n- wip: half done (`3e4f5a6`, Ada, 2024-01-05)\n",
+	} {
+		if !strings.Contains(web, want) {
+			t.Errorf("Expected the web section to contain %q, got:\n%s", want, web)
+		}
+	}
+	if strings.Index(web, "### Features") > strings.Index(web, "### Bug Fixes") {
+		t.Errorf("Expected features ahead of fixes, got:\n%s", web)
+	}
+}
+
+func TestChangelogLabel(t *testing.T) {
+	results := changelogResults()
+	if got := ChangelogLabel(results[0]); got != "4 commits" {
+		t.Errorf("ChangelogLabel() = %q, want %q", got, "4 commits")
+	}
+	if got := ChangelogLabel(results[2]); got != "no commits" {
+		t.Errorf("ChangelogLabel() = %q, want %q", got, "no commits")
+	}
+
+	var tally Tally
+	for _, result := range results {
+		tally.Add(result)
+	}
+	if got, want := ChangelogSummary(tally.Commits, tally.Changed), "5 commits across 2 repositories"; got != want {
+		t.Errorf("ChangelogSummary() = %q, want %q", got, want)
+	}
+}
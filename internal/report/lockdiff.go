@@ -0,0 +1,73 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/internal/git"
+)
+
+// WriteLockDiff writes one line for every repository that changed since the lockfile, e.g.
+// "services/api: main, 12 new commits (1a2b3c4d -> 9f8e7d6c)", and a summary line
+func WriteLockDiff(w io.Writer, changes []git.LockChange) error {
+	var b strings.Builder
+	moved, commits := 0, 0
+	for _, change := range changes {
+		if !change.Changed() {
+			continue
+		}
+		moved++
+		commits += change.Ahead
+		fmt.Fprintf(&b, "%s: %s\n", change.Path, describeLockChange(change))
+	}
+
+	switch {
+	case moved == 0:
+		fmt.Fprintf(&b, "✅ Nothing changed in %s since the snapshot\n", plural(len(changes), "repository", "repositories"))
+	default:
+		fmt.Fprintf(&b, "📊 %d of %d repositories changed since the snapshot, %s\n", moved, len(changes), plural(commits, "new commit", "new commits"))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describeLockChange says what happened to one repository since the lockfile
+func describeLockChange(change git.LockChange) string {
+	branch := func(entry *git.LockEntry) string { return cmp.Or(entry.Branch, "detached") }
+	switch {
+	case change.Old == nil:
+		return fmt.Sprintf("new since the snapshot (%s at %s)", branch(change.New), shortRef(change.New.Ref))
+	case change.New == nil:
+		return fmt.Sprintf("gone (was %s at %s)", branch(change.Old), shortRef(change.Old.Ref))
+	}
+
+	parts := []string{branch(change.New)}
+	if branch(change.Old) != branch(change.New) {
+		parts[0] = branch(change.Old) + " -> " + branch(change.New)
+	}
+	if change.Old.Ref != change.New.Ref {
+		switch {
+		case change.Missing != nil:
+			parts = append(parts, change.Missing.Error())
+		default:
+			if change.Ahead > 0 {
+				parts = append(parts, plural(change.Ahead, "new commit", "new commits"))
+			}
+			if change.Behind > 0 {
+				parts = append(parts, plural(change.Behind, "commit", "commits")+" dropped")
+			}
+		}
+		parts[len(parts)-1] += fmt.Sprintf(" (%s -> %s)", shortRef(change.Old.Ref), shortRef(change.New.Ref))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortRef abbreviates a full commit hash the way results show commits; tags are kept whole
+func shortRef(ref string) string {
+	if len(ref) == 40 && strings.Trim(ref, "0123456789abcdef") == "" {
+		return ref[:8]
+	}
+	return ref
+}
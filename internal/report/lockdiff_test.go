@@ -0,0 +1,47 @@
+package report
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/internal/git"
+)
+
+func TestWriteLockDiff(t *testing.T) {
+	const (
+		old = "1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d"
+		now = "9f8e7d6c5b4a39281706f5e4d3c2b1a098765432"
+	)
+	changes := []git.LockChange{
+		{Path: "api", Old: &git.LockEntry{Branch: "main", Ref: old}, New: &git.LockEntry{Branch: "main", Ref: now}, Ahead: 12},
+		{Path: "docs", Old: &git.LockEntry{Branch: "main", Ref: old}, New: &git.LockEntry{Branch: "main", Ref: old}},
+		{Path: "web", Old: &git.LockEntry{Branch: "main", Ref: old}, New: &git.LockEntry{Branch: "feature", Ref: now}, Ahead: 1, Behind: 2},
+		{Path: "infra", Old: &git.LockEntry{Ref: old}, New: &git.LockEntry{Branch: "main", Ref: now}, Missing: errors.New("the snapshot's commit is no longer in the repository")},
+		{Path: "tools", New: &git.LockEntry{Branch: "main", Ref: now}},
+		{Path: "legacy", Old: &git.LockEntry{Ref: "v1.0.0"}},
+	}
+
+	var b strings.Builder
+	if err := WriteLockDiff(&b, changes); err != nil {
+		t.Fatal(err)
+	}
+	want := `api: main, 12 new commits (1a2b3c4d -> 9f8e7d6c)
+web: main -> feature, 1 new commit, 2 commits dropped (1a2b3c4d -> 9f8e7d6c)
+infra: detached -> main, the snapshot's commit is no longer in the repository (1a2b3c4d -> 9f8e7d6c)
+tools: new since the snapshot (main at 9f8e7d6c)
+legacy: gone (was detached at v1.0.0)
+📊 5 of 6 repositories changed since the snapshot, 13 new commits
+`
+	if b.String() != want {
+		t.Errorf("WriteLockDiff() =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := WriteLockDiff(&b, changes[1:2]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "✅ Nothing changed in 1 repository since the snapshot\n"; got != want {
+		t.Errorf("WriteLockDiff() without changes = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,355 @@
+package report
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// MarkdownWriter streams the --export-scan markdown file, one section per repository as results
+// arrive, with the run-wide summary appended at the end. Like Writer, each section reaches the
+// file as soon as it is added. On Close a rollup table per top-level directory under the scanned
+// root is inserted ahead of the repository sections, for readers who only care about their slice.
+type MarkdownWriter struct {
+	file   *os.File
+	out    *bufio.Writer
+	err    error
+	root   string
+	header string
+	rollup map[string]*rollupRow
+}
+
+// rollupRow totals the repositories under one top-level directory
+type rollupRow struct {
+	repos, successful, failed, skipped int
+	duration                           time.Duration
+}
+
+// NewMarkdownWriter creates the export file at filePath and writes its header. rootPath is the
+// scanned directory that repositories are grouped under in the rollup.
+func NewMarkdownWriter(filePath, rootPath string) (*MarkdownWriter, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	header := fmt.Sprintf("# Git Repository Scan Report\n\nGenerated: %s\n\n---\n\n", time.Now().Format("2006-01-02 15:04:05"))
+	w := &MarkdownWriter{
+		file:   file,
+		out:    bufio.NewWriter(file),
+		root:   rootPath,
+		header: header,
+		rollup: make(map[string]*rollupRow),
+	}
+	w.fprintf("%s", header)
+	w.flush()
+	return w, nil
+}
+
+// fprintf writes to the export, remembering the first error so callers can check once at Close
+func (w *MarkdownWriter) fprintf(format string, a ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.out, format, a...)
+}
+
+// flush hands buffered output to the operating system so it survives the process dying
+func (w *MarkdownWriter) flush() {
+	if w.err != nil {
+		return
+	}
+	w.err = w.out.Flush()
+}
+
+// Add writes one repository's section
+func (w *MarkdownWriter) Add(repo types.GitRepo) {
+	w.addToRollup(repo)
+
+	w.fprintf("## %s\n\n", repo.Name)
+	w.fprintf("**Path:** `%s`\n\n", repo.Path)
+
+	if repo.GitDir != "" {
+		w.fprintf("**Git Dir:** `%s`\n\n", repo.GitDir)
+	}
+
+	if repo.Worktree {
+		w.fprintf("**Worktree:** linked\n\n")
+	}
+
+	if repo.Group != "" {
+		w.fprintf("**Group:** %s\n\n", repo.Group)
+	}
+
+	if repo.Bare {
+		w.fprintf("**Bare:** yes\n\n")
+	}
+
+	if repo.HasSubmodules {
+		w.fprintf("**Submodules:** yes\n\n")
+	}
+
+	if shallow := ShallowLabel(repo); shallow != "" {
+		w.fprintf("**Shallow:** %s\n\n", shallow)
+	}
+
+	if repo.Branch != "" {
+		w.fprintf("**Branch:** %s\n\n", repo.Branch)
+	}
+
+	if repo.Branch != "" && repo.Branch != "detached" {
+		w.fprintf("**Upstream:** %s\n\n", UpstreamLabel(repo))
+	}
+
+	if repo.Remote != "" {
+		w.fprintf("**Remote:** %s\n\n", repo.Remote)
+	}
+
+	if repo.RemoteURL != "" {
+		w.fprintf("**Remote URL:** %s\n\n", repo.RemoteURL)
+	}
+
+	if others := otherRemotes(repo); others != "" {
+		w.fprintf("**Other Remotes:** %s\n\n", others)
+	}
+
+	ciSystems := "none"
+	if len(repo.CISystems) > 0 {
+		ciSystems = strings.Join(repo.CISystems, ", ")
+	}
+	w.fprintf("**CI:** %s\n\n", ciSystems)
+
+	for _, manifest := range repo.Manifests {
+		w.fprintf("**Ecosystem:** %s\n\n", formatManifest(manifest))
+	}
+
+	if encryption := EncryptionLabel(repo); encryption != "" {
+		w.fprintf("**Encryption:** %s\n\n", encryption)
+	}
+
+	if owners := OwnersLabel(repo); owners != "" {
+		w.fprintf("**Owners:** %s\n\n", owners)
+	}
+
+	if repo.LastCommit != "" {
+		w.fprintf("**Last Commit:** `%s`\n\n", repo.LastCommit)
+		if repo.LastCommitMsg != "" {
+			w.fprintf("**Commit Message:** %s\n\n", repo.LastCommitMsg)
+		}
+	}
+
+	if len(repo.Findings) > 0 {
+		w.fprintf("**Security Findings:**\n\n")
+		for _, finding := range repo.Findings {
+			w.fprintf("- %s\n", finding)
+		}
+		w.fprintf("\n")
+	}
+
+	if repo.Empty {
+		w.fprintf("**Status:** Empty (no commits yet)\n\n")
+	}
+
+	if age := DirtyAge(repo, time.Now()); age != "" {
+		w.fprintf("**Dirty For:** %s (oldest change %s)\n\n", age, repo.DirtySince.Format("2006-01-02"))
+	}
+
+	if len(repo.ModifiedFiles) > 0 {
+		w.fprintf("**Modified Files:**\n\n")
+		for _, modFile := range repo.ModifiedFiles {
+			w.fprintf("- `%s`\n", modFile)
+		}
+		w.fprintf("\n")
+	} else if !repo.Empty {
+		w.fprintf("**Status:** Clean (no local changes)\n\n")
+	}
+
+	if len(repo.Diffs) > 0 {
+		w.addDiffs(repo.Diffs)
+	}
+
+	if timings := repo.Timings.String(); timings != "" {
+		w.fprintf("**Timings:** %s\n\n", timings)
+	}
+
+	if repo.Error != nil {
+		w.fprintf("**Error:** %v\n\n", repo.Error)
+	}
+
+	w.fprintf("---\n\n")
+	w.flush()
+}
+
+// addDiffs writes per-file change stats followed by each file's (possibly truncated) patch
+func (w *MarkdownWriter) addDiffs(diffs []types.FileDiff) {
+	w.fprintf("**Changes:**\n\n")
+	for _, diff := range diffs {
+		if diff.Binary {
+			w.fprintf("- `%s` (binary)\n", diff.Path)
+		} else {
+			w.fprintf("- `%s` (+%d -%d)\n", diff.Path, diff.Added, diff.Deleted)
+		}
+	}
+	w.fprintf("\n")
+
+	for _, diff := range diffs {
+		if diff.Patch != "" {
+			fence := codeFence(diff.Patch)
+			w.fprintf("%sdiff\n%s", fence, diff.Patch)
+			if !strings.HasSuffix(diff.Patch, "\n") {
+				w.fprintf("\n")
+			}
+			w.fprintf("%s\n\n", fence)
+		}
+		if diff.Truncated {
+			w.fprintf("_Diff of `%s` truncated._\n\n", diff.Path)
+		}
+	}
+}
+
+// codeFence returns a backtick fence longer than any backtick run in content, so a patch that
+// itself contains markdown code blocks cannot close the block early
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// Close appends the summary section and closes the file
+func (w *MarkdownWriter) Close(tally *Tally) (err error) {
+	defer func() {
+		err = errors.Join(err, w.file.Close())
+	}()
+
+	w.fprintf("## Summary\n\n")
+	w.fprintf("Total Repositories: %d\n\n", tally.Total)
+	w.fprintf("CI Systems: %s\n", tally.CISummary())
+
+	w.flush()
+	if w.err != nil {
+		return fmt.Errorf("failed to write export file: %w", w.err)
+	}
+	if len(w.rollup) > 0 {
+		if err := w.insertRollup(); err != nil {
+			return fmt.Errorf("failed to write export file: %w", err)
+		}
+	}
+	return nil
+}
+
+// addToRollup counts repo towards its top-level directory
+func (w *MarkdownWriter) addToRollup(repo types.GitRepo) {
+	dir := topLevelDir(w.root, repo.Path)
+	row := w.rollup[dir]
+	if row == nil {
+		row = &rollupRow{}
+		w.rollup[dir] = row
+	}
+
+	row.repos++
+	row.duration += repo.Duration
+	switch {
+	case repo.Error == nil:
+		row.successful++
+	case IsSkipped(repo):
+		row.skipped++
+	default:
+		row.failed++
+	}
+}
+
+// insertRollup rewrites the export with the rollup table between the header and the repository
+// sections. The sections are streamed before the rollup is known, so the finished file is
+// assembled next to the original and renamed over it.
+func (w *MarkdownWriter) insertRollup() (err error) {
+	name := w.file.Name()
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".export-*.md")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	out := bufio.NewWriter(tmp)
+	if _, err := out.WriteString(w.header + w.rollupTable()); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(int64(len(w.header)), io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, w.file); err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// rollupTable renders the per-directory totals, directories in name order
+func (w *MarkdownWriter) rollupTable() string {
+	var b strings.Builder
+	b.WriteString("## Rollup by Directory\n\n")
+	b.WriteString("| Directory | Repositories | Successful | Failed | Skipped | Total Duration |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|\n")
+	for _, dir := range slices.Sorted(maps.Keys(w.rollup)) {
+		row := w.rollup[dir]
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %v |\n",
+			dir, row.repos, row.successful, row.failed, row.skipped, row.duration.Truncate(time.Millisecond))
+	}
+	b.WriteString("\n---\n\n")
+	return b.String()
+}
+
+// topLevelDir returns the first directory of repoPath below root, or "." for root itself
+func topLevelDir(root, repoPath string) string {
+	rel, err := filepath.Rel(root, repoPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "."
+	}
+	top, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return top
+}
+
+// formatManifest renders a dependency manifest as "ecosystem `name` (file; engines)"
+func formatManifest(manifest types.Manifest) string {
+	var b strings.Builder
+	b.WriteString(manifest.Ecosystem)
+	if manifest.Name != "" {
+		fmt.Fprintf(&b, " `%s`", manifest.Name)
+	}
+
+	details := []string{manifest.File}
+	engines := make([]string, 0, len(manifest.Engines))
+	for engine, constraint := range manifest.Engines {
+		engines = append(engines, engine+" "+constraint)
+	}
+	slices.Sort(engines)
+	details = append(details, engines...)
+	fmt.Fprintf(&b, " (%s)", strings.Join(details, "; "))
+
+	return b.String()
+}
@@ -0,0 +1,173 @@
+package report
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestMarkdownWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.md")
+
+	w, err := NewMarkdownWriter(path, "/work")
+	if err != nil {
+		t.Fatalf("NewMarkdownWriter() error = %v", err)
+	}
+
+	results := []types.GitRepo{
+		{
+			Name:          "api",
+			Path:          "/work/api",
+			Branch:        "main",
+			Remote:        "origin",
+			RemoteURL:     "git@github.com:example/api.git",
+			CISystems:     []string{"GitHub Actions"},
+			ModifiedFiles: []string{"go.sum"},
+			DirtySince:    time.Now().Add(-42*24*time.Hour - time.Hour),
+			Manifests: []types.Manifest{
+				{Ecosystem: "go", File: "go.mod", Name: "example.com/api", Engines: map[string]string{"go": "1.25"}},
+			},
+		},
+		{Name: "docs", Path: "/work/docs", Findings: []string{"executable hook: pre-commit"}, Encryption: "git-crypt", Locked: true},
+	}
+
+	var tally Tally
+	for _, result := range results {
+		tally.Add(result)
+		w.Add(result)
+	}
+	if err := w.Close(&tally); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	expected := []string{
+		"# Git Repository Scan Report",
+		"## api",
+		"**Remote URL:** git@github.com:example/api.git",
+		"**CI:** GitHub Actions",
+		"**Ecosystem:** go `example.com/api` (go.mod; go 1.25)",
+		"**Dirty For:** 42 days (oldest change ",
+		"- `go.sum`",
+		"## docs",
+		"**CI:** none",
+		"- executable hook: pre-commit",
+		"**Encryption:** git-crypt (locked)",
+		"**Status:** Clean (no local changes)",
+		"## Summary",
+		"Total Repositories: 2",
+		"CI Systems: GitHub Actions: 1, none: 1",
+	}
+	for _, want := range expected {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected export to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestMarkdownWriterDiffs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.md")
+
+	w, err := NewMarkdownWriter(path, "/work")
+	if err != nil {
+		t.Fatalf("NewMarkdownWriter() error = %v", err)
+	}
+
+	w.Add(types.GitRepo{
+		Name:          "api",
+		Path:          "/work/api",
+		ModifiedFiles: []string{"README.md", "logo.png", "big.go"},
+		Diffs: []types.FileDiff{
+			{Path: "README.md", Added: 1, Deleted: 1, Patch: "-# api\n+# `api` ```\n"},
+			{Path: "logo.png", Binary: true},
+			{Path: "big.go", Added: 900, Patch: "+package big\n", Truncated: true},
+		},
+	})
+	if err := w.Close(&Tally{}); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	expected := []string{
+		"**Changes:**\n\n- `README.md` (+1 -1)\n- `logo.png` (binary)\n- `big.go` (+900 -0)\n",
+		"````diff\n-# api\n+# `api` ```\n````\n",
+		"```diff\n+package big\n```\n\n_Diff of `big.go` truncated._",
+	}
+	for _, want := range expected {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected export to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestMarkdownWriterCreateError(t *testing.T) {
+	_, err := NewMarkdownWriter(filepath.Join(t.TempDir(), "missing", "scan.md"), "/work")
+	if err == nil || !strings.Contains(err.Error(), "failed to create export file") {
+		t.Errorf("Expected create error, got %v", err)
+	}
+}
+
+func TestMarkdownWriterRollup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.md")
+	root := filepath.FromSlash("/work")
+
+	w, err := NewMarkdownWriter(path, root)
+	if err != nil {
+		t.Fatalf("NewMarkdownWriter() error = %v", err)
+	}
+
+	results := []types.GitRepo{
+		{Name: "api", Path: filepath.Join(root, "clients", "acme", "api"), Duration: time.Second},
+		{Name: "web", Path: filepath.Join(root, "clients", "globex", "web"), Duration: 2 * time.Second, Error: errors.New("fetch failed")},
+		{Name: "infra", Path: filepath.Join(root, "teams", "infra"), Duration: 500 * time.Millisecond, Error: errors.New("repository has uncommitted changes (skipped)")},
+		{Name: "work", Path: root},
+	}
+
+	var tally Tally
+	for _, result := range results {
+		tally.Add(result)
+		w.Add(result)
+	}
+	if err := w.Close(&tally); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	export := string(content)
+
+	table := `## Rollup by Directory
+
+| Directory | Repositories | Successful | Failed | Skipped | Total Duration |
+|---|---:|---:|---:|---:|---:|
+| . | 1 | 1 | 0 | 0 | 0s |
+| clients | 2 | 1 | 1 | 0 | 3s |
+| teams | 1 | 0 | 0 | 1 | 500ms |
+`
+	if !strings.Contains(export, table) {
+		t.Errorf("Expected rollup table, got:\n%s", export)
+	}
+
+	rollupAt, detailsAt, summaryAt := strings.Index(export, "## Rollup"), strings.Index(export, "## api"), strings.Index(export, "## Summary")
+	if !strings.HasPrefix(export, "# Git Repository Scan Report") || rollupAt > detailsAt || detailsAt > summaryAt {
+		t.Errorf("Expected header, rollup, repository sections, then summary; got:\n%s", export)
+	}
+	if strings.Count(export, "## api") != 1 {
+		t.Errorf("Expected each repository section once, got:\n%s", export)
+	}
+}
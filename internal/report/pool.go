@@ -0,0 +1,168 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PoolStats measures how the workers of a run spent their time: how long each was busy
+// processing repositories, and how long each repository waited in the queue for a free worker.
+// It is safe for concurrent use by the workers, and a nil PoolStats measures nothing.
+type PoolStats struct {
+	mu    sync.Mutex
+	start time.Time
+	end   time.Time // When the last repository finished
+	free  []int     // Workers waiting for a repository, lowest first
+	busy  []time.Duration
+	repos int
+	wait  time.Duration
+	max   time.Duration
+}
+
+// PoolSlot is a worker processing one repository, between Begin and End
+type PoolSlot struct {
+	worker  int
+	started time.Time
+}
+
+// NewPoolStats starts measuring a pool of workers, every repository queued from now
+func NewPoolStats(workers int) *PoolStats {
+	p := &PoolStats{start: time.Now(), busy: make([]time.Duration, max(workers, 1))}
+	for i := range p.busy {
+		p.free = append(p.free, i)
+	}
+	return p
+}
+
+// Begin records a repository leaving the queue for a free worker
+func (p *PoolStats) Begin() PoolSlot {
+	if p == nil {
+		return PoolSlot{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	wait := now.Sub(p.start)
+	p.repos++
+	p.wait += wait
+	p.max = max(p.max, wait)
+
+	// More workers than the pool was made for are counted as added ones
+	if len(p.free) == 0 {
+		p.busy = append(p.busy, 0)
+		return PoolSlot{worker: len(p.busy) - 1, started: now}
+	}
+	worker := p.free[0]
+	p.free = p.free[1:]
+	return PoolSlot{worker: worker, started: now}
+}
+
+// End records the worker of slot finishing its repository
+func (p *PoolStats) End(slot PoolSlot) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.busy[slot.worker] += now.Sub(slot.started)
+	p.end = now
+	i, _ := slices.BinarySearch(p.free, slot.worker)
+	p.free = slices.Insert(p.free, i, slot.worker)
+}
+
+// Utilization returns what the pool measured so far
+func (p *PoolStats) Utilization() Utilization {
+	if p == nil {
+		return Utilization{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	end := p.end
+	if end.IsZero() {
+		end = p.start
+	}
+	return Utilization{
+		Elapsed: end.Sub(p.start),
+		Busy:    slices.Clone(p.busy),
+		Repos:   p.repos,
+		Wait:    p.wait,
+		MaxWait: p.max,
+	}
+}
+
+// Utilization is how busy the workers of a run were
+type Utilization struct {
+	Elapsed time.Duration   // From the start of processing until the last repository finished
+	Busy    []time.Duration // Time each worker spent processing repositories
+	Repos   int             // Repositories the workers took from the queue
+	Wait    time.Duration   // Time the repositories waited in the queue, all together
+	MaxWait time.Duration   // Longest time a repository waited in the queue
+}
+
+// Percent returns the share of the workers' time spent busy, from 0 to 100
+func (u Utilization) Percent() float64 {
+	if u.Elapsed <= 0 || len(u.Busy) == 0 {
+		return 0
+	}
+	var busy time.Duration
+	for _, d := range u.Busy {
+		busy += d
+	}
+	return min(100, 100*busy.Seconds()/(u.Elapsed.Seconds()*float64(len(u.Busy))))
+}
+
+// WorkerPercent returns the share of the run worker i spent busy, from 0 to 100
+func (u Utilization) WorkerPercent(i int) float64 {
+	if u.Elapsed <= 0 {
+		return 0
+	}
+	return min(100, 100*u.Busy[i].Seconds()/u.Elapsed.Seconds())
+}
+
+// MeanWait returns how long a repository waited in the queue on average
+func (u Utilization) MeanWait() time.Duration {
+	if u.Repos == 0 {
+		return 0
+	}
+	return u.Wait / time.Duration(u.Repos)
+}
+
+// UtilizationSummary describes how busy the workers were, e.g.
+// "10 workers 87% busy over 4m50s, queue wait 12.3s on average, 41s at most"
+func UtilizationSummary(u Utilization) string {
+	return fmt.Sprintf("%s %.0f%% busy over %v, queue wait %v on average, %v at most",
+		plural(len(u.Busy), "worker", "workers"), u.Percent(), u.Elapsed.Round(time.Millisecond),
+		u.MeanWait().Round(time.Millisecond), u.MaxWait.Round(time.Millisecond))
+}
+
+// UtilizationVerdict tells what the utilization says about raising --workers. Workers busy
+// nearly all the time while repositories queue for them bound the run, unless the network or
+// the disk is already saturated, which shows as repositories taking longer with more workers.
+// Workers idle much of the time wait on something else, typically a few slow repositories.
+func UtilizationVerdict(u Utilization) string {
+	switch {
+	case u.Repos == 0:
+		return ""
+	case u.Percent() >= 90 && u.Repos > len(u.Busy):
+		return "worker-bound: raising --workers may help, unless repositories get slower with more of them (network-bound)"
+	case u.Percent() < 60:
+		return "workers were often idle: raising --workers won't help, the slowest repositories set the pace"
+	default:
+		return "workers were mostly busy: raising --workers may help a little"
+	}
+}
+
+// WorkerLines describes how busy each worker was, e.g. "worker 3: busy 4m12s (87%)"
+func WorkerLines(u Utilization) []string {
+	lines := make([]string, len(u.Busy))
+	for i, busy := range u.Busy {
+		lines[i] = fmt.Sprintf("worker %d: busy %v (%.0f%%)", i+1, busy.Round(time.Millisecond), u.WorkerPercent(i))
+	}
+	return lines
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolStats(t *testing.T) {
+	t.Parallel()
+
+	pool := NewPoolStats(2)
+	first, second := pool.Begin(), pool.Begin()
+	if first.worker != 0 || second.worker != 1 {
+		t.Fatalf("Expected the first two repositories on workers 0 and 1, got %d and %d", first.worker, second.worker)
+	}
+	pool.End(first)
+	if third := pool.Begin(); third.worker != 0 {
+		t.Errorf("Expected the freed worker 0 to take the next repository, got %d", third.worker)
+	}
+	// A worker beyond the size of the pool is counted as an added one
+	if extra := pool.Begin(); extra.worker != 2 {
+		t.Errorf("Expected an added worker 2, got %d", extra.worker)
+	}
+	pool.End(second)
+
+	u := pool.Utilization()
+	if u.Repos != 4 || len(u.Busy) != 3 {
+		t.Errorf("Expected 4 repositories on 3 workers, got %d on %d", u.Repos, len(u.Busy))
+	}
+
+	var none *PoolStats
+	none.End(none.Begin())
+	if u := none.Utilization(); u.Repos != 0 {
+		t.Errorf("Expected a nil pool to measure nothing, got %+v", u)
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	t.Parallel()
+
+	u := Utilization{
+		Elapsed: 10 * time.Second,
+		Busy:    []time.Duration{10 * time.Second, 9 * time.Second},
+		Repos:   8,
+		Wait:    16 * time.Second,
+		MaxWait: 6 * time.Second,
+	}
+	if got := u.Percent(); got != 95 {
+		t.Errorf("Percent() = %v, want 95", got)
+	}
+	if got := u.MeanWait(); got != 2*time.Second {
+		t.Errorf("MeanWait() = %v, want 2s", got)
+	}
+	if got, want := UtilizationSummary(u), "2 workers 95% busy over 10s, queue wait 2s on average, 6s at most"; got != want {
+		t.Errorf("UtilizationSummary() = %q, want %q", got, want)
+	}
+	if got := UtilizationVerdict(u); !strings.HasPrefix(got, "worker-bound") {
+		t.Errorf("Expected busy workers with a queue to be worker-bound, got %q", got)
+	}
+	if got, want := WorkerLines(u), []string{"worker 1: busy 10s (100%)", "worker 2: busy 9s (90%)"}; !slices.Equal(got, want) {
+		t.Errorf("WorkerLines() = %q, want %q", got, want)
+	}
+
+	u.Busy = []time.Duration{5 * time.Second, time.Second}
+	if got := UtilizationVerdict(u); !strings.Contains(got, "idle") {
+		t.Errorf("Expected mostly idle workers to be reported idle, got %q", got)
+	}
+	if got := UtilizationVerdict(Utilization{}); got != "" {
+		t.Errorf("Expected no verdict without repositories, got %q", got)
+	}
+}
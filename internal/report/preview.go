@@ -0,0 +1,33 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// RedirectOutputs points every report file the configuration writes into dir instead, keeping
+// the file names, so a run can be previewed without overwriting the real ones
+// (--notify-dry-run). It returns each redirection as "from -> to".
+func RedirectOutputs(config *types.Config, dir string) []string {
+	paths := []*string{
+		&config.SaveReport, &config.ExportScan, &config.SummaryFile, &config.Badge,
+		&config.TmuxSession, &config.VSCodeWorkspace, &config.ProjectList,
+	}
+	// The changelog file has a default, but only the changelog operation writes it
+	if config.Operation == types.OperationChangelog {
+		paths = append(paths, &config.ChangelogFile)
+	}
+
+	var moved []string
+	for _, path := range paths {
+		if *path == "" {
+			continue
+		}
+		target := filepath.Join(dir, filepath.Base(*path))
+		moved = append(moved, fmt.Sprintf("%s -> %s", *path, target))
+		*path = target
+	}
+	return moved
+}
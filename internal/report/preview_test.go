@@ -0,0 +1,28 @@
+package report
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestRedirectOutputs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	config := &types.Config{SaveReport: "/srv/reports/nightly.txt", Badge: "badge.svg"}
+
+	moved := RedirectOutputs(config, dir)
+	want := []string{
+		"/srv/reports/nightly.txt -> " + filepath.Join(dir, "nightly.txt"),
+		"badge.svg -> " + filepath.Join(dir, "badge.svg"),
+	}
+	if !slices.Equal(moved, want) {
+		t.Errorf("RedirectOutputs() = %v, want %v", moved, want)
+	}
+	if config.SaveReport != filepath.Join(dir, "nightly.txt") || config.SummaryFile != "" {
+		t.Errorf("Expected only configured outputs to move into %s, got %+v", dir, config)
+	}
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// sessionName is the tmux session the generated config creates
+const sessionName = "git-herd"
+
+// SessionWriter collects the repositories that need attention and writes them as a tmuxp
+// session config with one window per repository, opened in the repository's directory
+type SessionWriter struct {
+	path    string
+	windows []types.GitRepo
+}
+
+// NewSessionWriter creates a writer for the session config at path; nothing is written until Close
+func NewSessionWriter(path string) *SessionWriter {
+	return &SessionWriter{path: path}
+}
+
+// NeedsAttention reports whether a result is worth a closer look: it failed, has uncommitted
+// changes or security findings, did not pass its audit, has overdue unreleased work, or is
+// behind the version its manifest expects
+func NeedsAttention(result types.GitRepo) bool {
+	if result.Error != nil && !IsSkipped(result) {
+		return true
+	}
+	overdue := result.Release != nil && result.Release.Overdue
+	outdated := result.Version != nil && result.Version.Behind && result.Version.CheckedOut == ""
+	return len(result.ModifiedFiles) > 0 || len(result.Findings) > 0 || !result.Compliant() || overdue || outdated
+}
+
+// Add keeps a window for the result if it needs attention
+func (w *SessionWriter) Add(result types.GitRepo) {
+	if NeedsAttention(result) {
+		w.windows = append(w.windows, types.GitRepo{Name: result.Name, Path: result.Path})
+	}
+}
+
+// Len returns the number of windows collected
+func (w *SessionWriter) Len() int {
+	return len(w.windows)
+}
+
+// Close writes the session config. Values are written as JSON strings, which YAML reads as
+// double-quoted scalars, so no repository name or path needs escaping by hand.
+func (w *SessionWriter) Close() error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "session_name: %s\n", yamlString(sessionName))
+	if len(w.windows) == 0 {
+		b.WriteString("windows: []\n")
+	} else {
+		b.WriteString("windows:\n")
+	}
+	for _, repo := range w.windows {
+		dir, err := filepath.Abs(repo.Path)
+		if err != nil {
+			return fmt.Errorf("failed to write tmux session: %w", err)
+		}
+		fmt.Fprintf(&b, "  - window_name: %s\n", yamlString(repo.Name))
+		fmt.Fprintf(&b, "    start_directory: %s\n", yamlString(dir))
+		b.WriteString("    panes:\n      - blank\n")
+	}
+
+	if err := os.WriteFile(w.path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write tmux session: %w", err)
+	}
+	return nil
+}
+
+// yamlString quotes s as a double-quoted YAML scalar
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
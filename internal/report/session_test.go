@@ -0,0 +1,79 @@
+package report
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestNeedsAttention(t *testing.T) {
+	tests := []struct {
+		name   string
+		result types.GitRepo
+		want   bool
+	}{
+		{"clean", types.GitRepo{Name: "ok"}, false},
+		{"failed", types.GitRepo{Error: errors.New("fetch failed")}, true},
+		{"skipped", types.GitRepo{Error: errors.New("protected repository (policy skipped)")}, false},
+		{"skipped dirty", types.GitRepo{Error: errors.New("repository has uncommitted changes (skipped)"), ModifiedFiles: []string{"a.go"}}, true},
+		{"findings", types.GitRepo{Findings: []string{"executable hook: pre-commit"}}, true},
+		{"audit issue", types.GitRepo{MissingFiles: []string{"LICENSE*"}}, true},
+		{"overdue release", types.GitRepo{Release: &types.Release{Unreleased: 3, Overdue: true}}, true},
+		{"released", types.GitRepo{Release: &types.Release{Tag: "v1.0.0"}}, false},
+		{"behind version", types.GitRepo{Version: &types.TagCheck{Expected: "v1.4.0", Behind: true}}, true},
+		{"caught up", types.GitRepo{Version: &types.TagCheck{Expected: "v1.4.0", Behind: true, CheckedOut: "v1.4.0"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsAttention(tt.result); got != tt.want {
+				t.Errorf("NeedsAttention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "attention.yaml")
+
+	w := NewSessionWriter(path)
+	w.Add(types.GitRepo{Name: "clean", Path: filepath.Join(dir, "clean")})
+	w.Add(types.GitRepo{Name: `acme/"api"`, Path: filepath.Join(dir, "api"), ModifiedFiles: []string{"main.go"}})
+	if w.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", w.Len())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "session_name: \"git-herd\"\n" +
+		"windows:\n" +
+		"  - window_name: \"acme/\\\"api\\\"\"\n" +
+		"    start_directory: " + yamlString(filepath.Join(dir, "api")) + "\n" +
+		"    panes:\n      - blank\n"
+	if string(content) != want {
+		t.Errorf("Session config = %q, want %q", content, want)
+	}
+}
+
+func TestSessionWriterNothingToAttend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attention.yaml")
+	if err := NewSessionWriter(path).Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "session_name: \"git-herd\"\nwindows: []\n"; string(content) != want {
+		t.Errorf("Session config = %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/auditlog"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// Run statuses recorded in the summary file, from best to worst
+const (
+	StatusSuccess     = "success"     // Every repository was processed without failure
+	StatusFailed      = "failed"      // Every repository was processed, some failed
+	StatusInterrupted = "interrupted" // The run stopped early, e.g. on timeout or Ctrl+C
+	StatusError       = "error"       // The run could not get going, e.g. the scan failed
+)
+
+// Summary is the machine-readable outcome of a run written to --summary-file
+type Summary struct {
+	Status          string              `json:"status"`
+	Operation       types.OperationType `json:"operation"`
+	Pipeline        string              `json:"pipeline,omitempty"`
+	DryRun          bool                `json:"dry_run"`
+	Labels          map[string]string   `json:"labels,omitempty"`
+	RunAs           string              `json:"run_as,omitempty"` // OS user the run ran as, e.g. "root (sudo from alice)"
+	Found           int                 `json:"found"`
+	Total           int                 `json:"total"`
+	Successful      int                 `json:"successful"`
+	Failed          int                 `json:"failed"`
+	Skipped         int                 `json:"skipped"`
+	NotAttempted    int                 `json:"not_attempted"`
+	Groups          []GroupCount        `json:"groups,omitempty"`  // Counts per group, when repositories are in groups
+	Steps           []StepCount         `json:"steps,omitempty"`   // Counts per step of the pipeline, in order
+	Workers         *WorkerStats        `json:"workers,omitempty"` // How busy the workers were, once any repository was processed
+	StartedAt       time.Time           `json:"started_at"`
+	DurationSeconds float64             `json:"duration_seconds"`
+	Error           string              `json:"error,omitempty"`
+}
+
+// WorkerStats is how busy the workers of a run were, in the summary file
+type WorkerStats struct {
+	Count              int       `json:"count"`
+	UtilizationPercent float64   `json:"utilization_percent"`
+	BusySeconds        []float64 `json:"busy_seconds"` // By worker
+	MeanWaitSeconds    float64   `json:"mean_wait_seconds"`
+	MaxWaitSeconds     float64   `json:"max_wait_seconds"`
+}
+
+// newWorkerStats converts the utilization of the workers for the summary file
+func newWorkerStats(u Utilization) *WorkerStats {
+	stats := &WorkerStats{
+		Count:              len(u.Busy),
+		UtilizationPercent: math.Round(u.Percent()*10) / 10,
+		MeanWaitSeconds:    u.MeanWait().Seconds(),
+		MaxWaitSeconds:     u.MaxWait.Seconds(),
+	}
+	for _, busy := range u.Busy {
+		stats.BusySeconds = append(stats.BusySeconds, busy.Seconds())
+	}
+	return stats
+}
+
+// NewSummary summarizes a run that found found repositories, tallied tally and ended with err
+func NewSummary(config *types.Config, tally *Tally, found int, start time.Time, err error) Summary {
+	summary := Summary{
+		Status:          runStatus(tally, found, err),
+		Operation:       config.Operation,
+		Pipeline:        config.Pipeline,
+		DryRun:          config.DryRun,
+		Labels:          config.Labels,
+		RunAs:           auditlog.RunAs(),
+		Found:           found,
+		Total:           tally.Total,
+		Successful:      tally.Successful,
+		Failed:          tally.Failed,
+		Skipped:         tally.Skipped,
+		NotAttempted:    tally.NotAttempted,
+		Steps:           tally.Steps,
+		StartedAt:       start,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
+	if tally.Pool.Repos > 0 {
+		summary.Workers = newWorkerStats(tally.Pool)
+	}
+	if tally.GroupSummaries() != nil {
+		summary.Groups = tally.Groups
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	return summary
+}
+
+// runStatus classifies how a run ended
+func runStatus(tally *Tally, found int, err error) string {
+	switch {
+	case tally.Total < found || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return StatusInterrupted
+	case tally.Failed > 0:
+		return StatusFailed
+	case err != nil:
+		return StatusError
+	default:
+		return StatusSuccess
+	}
+}
+
+// WriteSummary writes summary as JSON to path
+func WriteSummary(path string, summary Summary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestRunStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		tally Tally
+		found int
+		err   error
+		want  string
+	}{
+		{"all processed", Tally{Total: 3, Successful: 2, Skipped: 1}, 3, nil, StatusSuccess},
+		{"nothing found", Tally{}, 0, nil, StatusSuccess},
+		{"failures", Tally{Total: 3, Successful: 2, Failed: 1}, 3, fmt.Errorf("1 repositories failed"), StatusFailed},
+		{"stopped early", Tally{Total: 1, Failed: 1}, 3, nil, StatusInterrupted},
+		{"cancelled during scan", Tally{}, 0, context.Canceled, StatusInterrupted},
+		{"scan failed", Tally{}, 0, errors.New("failed to find repositories"), StatusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runStatus(&tt.tally, tt.found, tt.err); got != tt.want {
+				t.Errorf("runStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	cfg := &types.Config{Operation: types.OperationPull, Labels: map[string]string{"host": "laptop"}}
+	tally := Tally{Total: 2, Successful: 1, Failed: 1}
+	tally.Pool = Utilization{Elapsed: 4 * time.Second, Busy: []time.Duration{3 * time.Second, 2 * time.Second}, Repos: 2}
+
+	summary := NewSummary(cfg, &tally, 2, time.Now().Add(-time.Second), errors.New("1 repositories failed"))
+	if err := WriteSummary(path, summary); err != nil {
+		t.Fatalf("WriteSummary() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Summary is not valid JSON: %v\n%s", err, data)
+	}
+
+	expected := map[string]any{
+		"status":     StatusFailed,
+		"operation":  "pull",
+		"found":      2.0,
+		"total":      2.0,
+		"successful": 1.0,
+		"failed":     1.0,
+		"error":      "1 repositories failed",
+	}
+	for key, want := range expected {
+		if decoded[key] != want {
+			t.Errorf("summary[%q] = %v, want %v", key, decoded[key], want)
+		}
+	}
+	if labels, _ := decoded["labels"].(map[string]any); labels["host"] != "laptop" {
+		t.Errorf("labels = %v, want host=laptop", decoded["labels"])
+	}
+	if workers, _ := decoded["workers"].(map[string]any); workers["count"] != 2.0 || workers["utilization_percent"] != 62.5 {
+		t.Errorf("workers = %v, want 2 at 62.5%%", decoded["workers"])
+	}
+	if seconds, _ := decoded["duration_seconds"].(float64); seconds < 1 {
+		t.Errorf("duration_seconds = %v, want at least 1", decoded["duration_seconds"])
+	}
+}
@@ -0,0 +1,935 @@
+package report
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// SlowestCount is how many of the slowest repositories a Tally keeps
+const SlowestCount = 5
+
+// Tally accumulates run-wide counts so results can be discarded once they are reported
+type Tally struct {
+	Total        int
+	Successful   int
+	Failed       int
+	Skipped      int
+	NotAttempted int
+	Unreachable  int // Repositories whose remote failed --preflight
+	Untrusted    int // Repositories skipped as owned by another user
+	Trusted      int // Repositories added to safe.directory, or that would be in dry-run mode
+	Audited      int // Repositories audited without error
+	Compliant    int // Audited repositories that passed their audit
+	CISystems    map[string]int
+	NoUpstream   int             // Repositories whose current branch tracks nothing
+	Empty        int             // Repositories without any commits
+	Pushed       int             // Repositories whose branch was pushed, or would be in dry-run mode
+	Stashed      int             // Repositories whose changes were stashed, or would be in dry-run mode
+	PrunedRefs   int             // Stale remote-tracking branches pruned, or that would be in dry-run mode
+	ReclaimedKiB int64           // Disk space garbage collection freed, in KiB
+	Deleted      int             // Local branches deleted, or that would be in dry-run mode
+	Synced       int             // Default branches sync moved forward
+	Healed       int             // Detached HEADs heal put back on a branch, or would in dry-run mode
+	Rewritten    int             // Remote URLs set-url rewrote, or would in dry-run mode
+	LFSKiB       int64           // Git LFS objects downloaded, in KiB
+	Applied      int             // Repositories apply committed a change to
+	AppliedPush  int             // Branches apply pushed
+	Commits      int             // Commits the changelog collected
+	Changed      int             // Repositories with commits in the changelog
+	Unreleased   int             // Repositories with commits since their latest tag, or without a tag
+	Overdue      int             // Repositories whose unreleased work is older than --unreleased-days
+	Outdated     int             // Repositories on a version below the one their manifest expects
+	TagsChecked  int             // Expected tags checked out to catch up, or that would be in dry-run mode
+	Slowest      []types.GitRepo // Slowest repositories, slowest first, at most SlowestCount
+	Flaky        []types.GitRepo // Repositories alternating between success and failure across runs
+	Failures     []types.GitRepo // Failed repositories, skips aside, with only their name, path and error
+	Groups       []GroupCount    // Counts per group, by name, the repositories in no group first
+	Steps        []StepCount     // Counts per pipeline step, in the order the steps run
+	Pool         Utilization     // How busy the workers were, set once processing ends
+}
+
+// GroupCount is how the repositories of one group fared, for the per-group summary
+type GroupCount struct {
+	Name       string `json:"name"`
+	Total      int    `json:"total"`
+	Successful int    `json:"successful"`
+	Failed     int    `json:"failed"`
+	Skipped    int    `json:"skipped"`
+}
+
+// addGroup counts a result against its group
+func (t *Tally) addGroup(result types.GitRepo) {
+	i, found := slices.BinarySearchFunc(t.Groups, result.Group, func(g GroupCount, name string) int {
+		return strings.Compare(g.Name, name)
+	})
+	if !found {
+		t.Groups = slices.Insert(t.Groups, i, GroupCount{Name: result.Group})
+	}
+	group := &t.Groups[i]
+	group.Total++
+	switch {
+	case result.Error == nil:
+		group.Successful++
+	case IsSkipped(result):
+		group.Skipped++
+	default:
+		group.Failed++
+	}
+}
+
+// GroupSummaries describes how each group fared, one line per group, e.g.
+// "clientA: 12 successful, 1 failed, 0 skipped, 13 total", with the repositories in no group
+// last; nil when no repository is in a group
+func (t *Tally) GroupSummaries() []string {
+	if len(t.Groups) == 0 || (len(t.Groups) == 1 && t.Groups[0].Name == "") {
+		return nil
+	}
+	var lines, ungrouped []string
+	for _, g := range t.Groups {
+		name := g.Name
+		if name == "" {
+			name = "(no group)"
+		}
+		line := fmt.Sprintf("%s: %d successful, %d failed, %d skipped, %d total", name, g.Successful, g.Failed, g.Skipped, g.Total)
+		if g.Name == "" {
+			ungrouped = append(ungrouped, line)
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	return append(lines, ungrouped...)
+}
+
+// StepCount is how the repositories fared in one step of the pipeline, for the per-step summary;
+// repositories a failed step stopped before it are not counted
+type StepCount struct {
+	Operation  types.OperationType `json:"operation"`
+	Successful int                 `json:"successful"`
+	Failed     int                 `json:"failed"`
+	Skipped    int                 `json:"skipped"`
+}
+
+// addSteps counts each step that ran on a result against the step at its position
+func (t *Tally) addSteps(result types.GitRepo) {
+	for i, step := range result.Steps {
+		if i == len(t.Steps) {
+			t.Steps = append(t.Steps, StepCount{Operation: step.Operation})
+		}
+		switch {
+		case step.Error == nil:
+			t.Steps[i].Successful++
+		case strings.Contains(step.Error.Error(), "skipped"):
+			t.Steps[i].Skipped++
+		default:
+			t.Steps[i].Failed++
+		}
+	}
+}
+
+// StepSummaries describes how each step of the pipeline fared, one line per step, e.g.
+// "1. fetch: 12 successful, 1 failed, 0 skipped"; nil without a pipeline
+func (t *Tally) StepSummaries() []string {
+	lines := make([]string, 0, len(t.Steps))
+	for i, s := range t.Steps {
+		lines = append(lines, fmt.Sprintf("%d. %s: %d successful, %d failed, %d skipped", i+1, s.Operation, s.Successful, s.Failed, s.Skipped))
+	}
+	return lines
+}
+
+// StepsLabel describes the steps of the pipeline that ran on a result, noting the ones that did
+// not succeed, e.g. "fetch → prune-branches (skipped) → status"; "" without a pipeline
+func StepsLabel(result types.GitRepo) string {
+	steps := make([]string, len(result.Steps))
+	for i, step := range result.Steps {
+		steps[i] = string(step.Operation)
+		switch {
+		case step.Error == nil:
+		case strings.Contains(step.Error.Error(), "skipped"):
+			steps[i] += " (skipped)"
+		default:
+			steps[i] += " (failed)"
+		}
+	}
+	return strings.Join(steps, " → ")
+}
+
+// StepLine describes one step of the pipeline on a result, e.g. "fetch: ok in 1.2s"
+func StepLine(step types.Step) string {
+	if step.Error != nil {
+		return fmt.Sprintf("%s: %v after %v", step.Operation, step.Error, step.Duration.Truncate(time.Millisecond))
+	}
+	return fmt.Sprintf("%s: ok in %v", step.Operation, step.Duration.Truncate(time.Millisecond))
+}
+
+// IsSkipped reports whether a result was skipped rather than failed
+func IsSkipped(result types.GitRepo) bool {
+	return result.Error != nil && strings.Contains(result.Error.Error(), "skipped")
+}
+
+// Add folds one result into the tally
+func (t *Tally) Add(result types.GitRepo) {
+	t.Total++
+	t.addSlowest(result)
+	t.addGroup(result)
+	t.addSteps(result)
+	if result.Flaky {
+		t.Flaky = append(t.Flaky, types.GitRepo{Name: result.Name, Path: result.Path, Error: result.Error})
+	}
+	if result.Empty {
+		t.Empty++
+	}
+	if result.SafeDirectory {
+		t.Trusted++
+	}
+
+	if result.Error != nil {
+		if IsSkipped(result) {
+			t.Skipped++
+		} else {
+			t.Failed++
+			t.Failures = append(t.Failures, types.GitRepo{Name: result.Name, Path: result.Path, Error: result.Error})
+		}
+		if errors.Is(result.Error, git.ErrBudgetExhausted) {
+			t.NotAttempted++
+		}
+		if errors.Is(result.Error, git.ErrPreflight) {
+			t.Unreachable++
+		}
+		if errors.Is(result.Error, git.ErrDubiousOwnership) {
+			t.Untrusted++
+		}
+		return
+	}
+
+	t.Successful++
+	if result.Pushed != "" {
+		t.Pushed++
+	}
+	if result.Stashed {
+		t.Stashed++
+	}
+	t.PrunedRefs += len(result.PrunedRefs)
+	t.ReclaimedKiB += reclaimedKiB(result)
+	t.Deleted += len(result.DeletedBranches)
+	if result.SyncUpdate != "" {
+		t.Synced++
+	}
+	if result.Healed {
+		t.Healed++
+	}
+	t.Rewritten += len(result.URLRewrites)
+	t.LFSKiB += result.LFSKiB
+	if result.ApplyCommit != "" {
+		t.Applied++
+	}
+	if result.ApplyPushed {
+		t.AppliedPush++
+	}
+	t.Commits += len(result.Commits)
+	if len(result.Commits) > 0 {
+		t.Changed++
+	}
+	if result.Release != nil && result.Release.Unreleased > 0 {
+		t.Unreleased++
+	}
+	if result.Release != nil && result.Release.Overdue {
+		t.Overdue++
+	}
+	if result.Version != nil && result.Version.Behind {
+		t.Outdated++
+	}
+	if result.Version != nil && result.Version.CheckedOut != "" {
+		t.TagsChecked++
+	}
+	if HasNoUpstream(result) {
+		t.NoUpstream++
+	}
+	t.Audited++
+	if result.Compliant() {
+		t.Compliant++
+	}
+
+	if t.CISystems == nil {
+		t.CISystems = make(map[string]int)
+	}
+	if len(result.CISystems) == 0 {
+		t.CISystems["none"]++
+	}
+	for _, system := range result.CISystems {
+		t.CISystems[system]++
+	}
+}
+
+// addSlowest keeps result if it is among the slowest so far. Only the fields needed to show
+// where the time went are kept, so large results are not retained.
+func (t *Tally) addSlowest(result types.GitRepo) {
+	if result.Duration <= 0 {
+		return
+	}
+
+	i, _ := slices.BinarySearchFunc(t.Slowest, result.Duration, func(kept types.GitRepo, d time.Duration) int {
+		return cmp.Compare(d, kept.Duration)
+	})
+	if i >= SlowestCount {
+		return
+	}
+
+	kept := types.GitRepo{
+		Name:     result.Name,
+		Path:     result.Path,
+		Error:    result.Error,
+		Duration: result.Duration,
+		Timings:  result.Timings,
+	}
+	t.Slowest = slices.Insert(t.Slowest, i, kept)
+	if len(t.Slowest) > SlowestCount {
+		t.Slowest = t.Slowest[:SlowestCount]
+	}
+}
+
+// SlowLine describes one of the slowest repositories, e.g. "2.4s  api (analyze 100ms, network 2.3s)"
+func SlowLine(result types.GitRepo) string {
+	line := fmt.Sprintf("%v  %s", result.Duration.Truncate(time.Millisecond), result.Name)
+	if timings := result.Timings.String(); timings != "" {
+		line += " (" + timings + ")"
+	}
+	return line
+}
+
+// FlakyLine describes a flaky repository with this run's outcome, e.g. "api (failed: timed out)"
+func FlakyLine(result types.GitRepo) string {
+	switch {
+	case result.Error == nil:
+		return result.Name + " (ok this run)"
+	case IsSkipped(result):
+		return result.Name + " (skipped this run)"
+	default:
+		return fmt.Sprintf("%s (failed: %v)", result.Name, result.Error)
+	}
+}
+
+// HasNoUpstream reports whether the result's current branch has no upstream configured
+func HasNoUpstream(result types.GitRepo) bool {
+	return result.Upstream == "" && result.Branch != "" && result.Branch != "detached"
+}
+
+// UpstreamLabel describes a result's upstream for reports, e.g. "origin/main (set)" or "none"
+func UpstreamLabel(result types.GitRepo) string {
+	switch {
+	case result.UpstreamSet:
+		return result.Upstream + " (set)"
+	case result.Upstream == "":
+		return "none"
+	default:
+		return result.Upstream
+	}
+}
+
+// DirtyAge describes how long a result has had uncommitted changes as of now, e.g. "42 days",
+// or "" when it has none
+func DirtyAge(result types.GitRepo, now time.Time) string {
+	if result.DirtySince.IsZero() {
+		return ""
+	}
+	return age(now.Sub(result.DirtySince))
+}
+
+// age describes a duration in the largest whole unit, e.g. "42 days" or "3 hours"
+func age(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	case d >= 24*time.Hour:
+		return "1 day"
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d/time.Hour))
+	case d >= time.Hour:
+		return "1 hour"
+	default:
+		return "less than an hour"
+	}
+}
+
+// StatusLabel summarizes a result's branch and working tree for the status operation, e.g.
+// "ahead 2, behind 1, dirty (3 files), 1 stash"
+func StatusLabel(result types.GitRepo) string {
+	var parts []string
+	switch {
+	case result.Empty:
+		parts = append(parts, "no commits yet")
+	case result.Branch == "detached":
+		parts = append(parts, "detached HEAD")
+	case result.Upstream == "":
+		parts = append(parts, "no upstream")
+	case result.UpstreamGone:
+		parts = append(parts, "upstream "+result.Upstream+" gone")
+	case result.Ahead == 0 && result.Behind == 0:
+		parts = append(parts, "up to date")
+	default:
+		if result.Ahead > 0 {
+			parts = append(parts, fmt.Sprintf("ahead %d", result.Ahead))
+		}
+		if result.Behind > 0 {
+			parts = append(parts, fmt.Sprintf("behind %d", result.Behind))
+		}
+	}
+
+	switch n := len(result.ModifiedFiles); {
+	case n == 1:
+		parts = append(parts, "dirty (1 file)")
+	case n > 1:
+		parts = append(parts, fmt.Sprintf("dirty (%d files)", n))
+	}
+
+	switch {
+	case result.Stashes == 1:
+		parts = append(parts, "1 stash")
+	case result.Stashes > 1:
+		parts = append(parts, fmt.Sprintf("%d stashes", result.Stashes))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// EncryptionLabel names a result's transparent encryption tool, e.g. "git-crypt (locked)", or ""
+// when it uses none
+func EncryptionLabel(result types.GitRepo) string {
+	if result.Locked {
+		return result.Encryption + " (locked)"
+	}
+	return result.Encryption
+}
+
+// ShallowLabel describes how much history a shallow clone has, e.g. "depth 50", or "" for a
+// complete clone
+func ShallowLabel(result types.GitRepo) string {
+	switch {
+	case !result.Shallow:
+		return ""
+	case result.Depth == 0:
+		return "yes"
+	default:
+		return fmt.Sprintf("depth %d", result.Depth)
+	}
+}
+
+// OwnersLabel lists a result's top committers with their commit counts, e.g.
+// "Ada <ada@example.com> (42), Linus (7)"
+func OwnersLabel(result types.GitRepo) string {
+	owners := make([]string, 0, len(result.Owners))
+	for _, owner := range result.Owners {
+		owners = append(owners, owner.String())
+	}
+	return strings.Join(owners, ", ")
+}
+
+// OwnersNote names a result's top committers for appending to a one-line result, e.g.
+// " (owners: Ada, Linus)", or "" when none are known
+func OwnersNote(result types.GitRepo) string {
+	if len(result.Owners) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(result.Owners))
+	for _, owner := range result.Owners {
+		names = append(names, owner.Name)
+	}
+	return " (owners: " + strings.Join(names, ", ") + ")"
+}
+
+// PushLabel describes what push did for a result, e.g. "pushed 1a2b3c4d..5e6f7a8b main -> main"
+func PushLabel(result types.GitRepo, dryRun bool) string {
+	switch {
+	case result.Pushed == "":
+		return "nothing to push"
+	case dryRun:
+		return "would push " + result.Pushed
+	default:
+		return "pushed " + result.Pushed
+	}
+}
+
+// CheckoutLabel describes what checkout did for a result, e.g. "switched main -> feature" or,
+// for a lockfile, "switched main at 1a2b3c4d -> detached at 9f8e7d6c (v1.4.0)"
+func CheckoutLabel(result types.GitRepo, dryRun bool) string {
+	switch {
+	case result.Checkout == "" && result.Pin != nil:
+		return "already at " + result.Pin.Ref
+	case result.Checkout == "":
+		return "already on " + result.Branch
+	case dryRun:
+		return "would switch " + result.Checkout
+	default:
+		return "switched " + result.Checkout
+	}
+}
+
+// HealLabel describes what heal did for a result, e.g. "healed detached at 1a2b3c4d -> main
+// (branch at the commit)"
+func HealLabel(result types.GitRepo, dryRun bool) string {
+	switch {
+	case !result.Healed:
+		return "on " + result.Branch + ", not detached"
+	case dryRun:
+		return "would heal " + result.Checkout
+	default:
+		return "healed " + result.Checkout
+	}
+}
+
+// ReleaseLabel describes a result's latest tag and the work since as of now, e.g. "v1.4.0
+// (45 days ago), 12 unreleased commits, oldest 40 days old, overdue"
+func ReleaseLabel(result types.GitRepo, now time.Time) string {
+	release := result.Release
+	if release == nil {
+		return "no commits"
+	}
+
+	label := "no tags"
+	if release.Tag != "" {
+		label = fmt.Sprintf("%s (%s ago)", release.Tag, age(now.Sub(release.Date)))
+	}
+	if release.Unreleased == 0 {
+		return label + ", nothing unreleased"
+	}
+	label += fmt.Sprintf(", %s, oldest %s old", plural(release.Unreleased, "unreleased commit", "unreleased commits"), age(now.Sub(release.Oldest)))
+	if release.Overdue {
+		label += ", overdue"
+	}
+	return label
+}
+
+// ReleasesSummary summarizes a run's release inventory, e.g. "5 repositories with unreleased
+// work, 2 for over 30 days"
+func ReleasesSummary(unreleased, overdue, days int) string {
+	return fmt.Sprintf("%s with unreleased work, %d for over %s", plural(unreleased, "repository", "repositories"), overdue, plural(days, "day", "days"))
+}
+
+// VersionLabel describes the version a result is on against the one its manifest expects, e.g.
+// "on v1.3.0 (v1.3.0-4-g1a2b3c4), behind v1.4.0" or "checked out v1.4.0, was on v1.3.0"
+func VersionLabel(result types.GitRepo, dryRun bool) string {
+	check := result.Version
+	if check == nil {
+		return "no expected version"
+	}
+
+	on := "no version tag"
+	if check.Current != "" {
+		on = "on " + check.Current
+	}
+	switch {
+	case check.CheckedOut != "" && dryRun:
+		return "would check out " + check.CheckedOut + ", " + on
+	case check.CheckedOut != "":
+		return "checked out " + check.CheckedOut + ", was " + on
+	}
+	if check.Describe != "" && check.Describe != check.Current {
+		on += " (" + check.Describe + ")"
+	}
+	if check.Behind {
+		return on + ", behind " + check.Expected
+	}
+	return on + ", expected " + check.Expected
+}
+
+// VersionsSummary summarizes a run's version check, e.g. "3 repositories behind their expected
+// version, 2 checked out"
+func VersionsSummary(outdated, checkedOut int, dryRun bool) string {
+	summary := plural(outdated, "repository", "repositories") + " behind their expected version"
+	switch {
+	case checkedOut > 0 && dryRun:
+		summary += fmt.Sprintf(", %d would be checked out", checkedOut)
+	case checkedOut > 0:
+		summary += fmt.Sprintf(", %d checked out", checkedOut)
+	}
+	return summary
+}
+
+// HealedSummary summarizes the detached HEADs a run healed, e.g. "3 detached HEADs healed"
+func HealedSummary(n int, dryRun bool) string {
+	if dryRun {
+		return plural(n, "detached HEAD", "detached HEADs") + " would be healed"
+	}
+	return plural(n, "detached HEAD", "detached HEADs") + " healed"
+}
+
+// OwnershipSummary summarizes the repositories owned by another user, e.g. "2 repositories
+// owned by another user skipped (see --add-safe-directory)" or "2 repositories added to
+// safe.directory"
+func OwnershipSummary(untrusted, trusted int, dryRun bool) string {
+	var parts []string
+	if untrusted > 0 {
+		parts = append(parts, plural(untrusted, "repository", "repositories")+" owned by another user skipped (see --add-safe-directory)")
+	}
+	if trusted > 0 && dryRun {
+		parts = append(parts, plural(trusted, "repository", "repositories")+" would be added to safe.directory")
+	} else if trusted > 0 {
+		parts = append(parts, plural(trusted, "repository", "repositories")+" added to safe.directory")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SyncLabel describes what sync did for a result, e.g. "updated main 1a2b3c4d..5e6f7a8b, back
+// on feature" or "main already up to date"
+func SyncLabel(result types.GitRepo, dryRun bool) string {
+	var label string
+	switch {
+	case dryRun:
+		label = "would update " + result.SyncBranch
+	case result.SyncUpdate == "":
+		label = result.SyncBranch + " already up to date"
+	default:
+		label = "updated " + result.SyncBranch + " " + result.SyncUpdate
+	}
+	if result.Branch != result.SyncBranch {
+		label += ", back on " + result.Branch
+	}
+	return label
+}
+
+// StashLabel describes what happened to a result's uncommitted changes, e.g. "stashed 3 files",
+// "popped the git-herd stash", or "stashed and restored 2 files" for a pull with --autostash;
+// "" when nothing was stashed or popped
+func StashLabel(result types.GitRepo, dryRun bool) string {
+	files := plural(len(result.ModifiedFiles), "file", "files")
+
+	switch {
+	case result.Stashed && result.Unstashed && dryRun:
+		return "would stash and restore " + files
+	case result.Stashed && result.Unstashed:
+		return "stashed and restored " + files
+	case result.Stashed && dryRun:
+		return "would stash " + files
+	case result.Stashed:
+		return "stashed " + files
+	case result.Unstashed && dryRun:
+		return "would pop the git-herd stash"
+	case result.Unstashed:
+		return "popped the git-herd stash"
+	default:
+		return ""
+	}
+}
+
+// PullLabel describes how pull brought in a result's diverged remote branch and what happened to
+// its uncommitted changes, e.g. "rebased onto origin/main, stashed and restored 2 files"; "" for
+// a plain fast-forward
+func PullLabel(result types.GitRepo, dryRun bool) string {
+	upstream := result.Upstream
+	if upstream == "" {
+		upstream = result.Remote + "/" + result.Branch
+	}
+
+	var parts []string
+	switch types.PullStrategy(result.PulledWith) {
+	case types.PullMerge:
+		parts = append(parts, "merged "+upstream)
+	case types.PullRebase:
+		parts = append(parts, "rebased onto "+upstream)
+	}
+	if stash := StashLabel(result, dryRun); stash != "" {
+		parts = append(parts, stash)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// MaintenanceLabel describes what maintenance did for a result, e.g.
+// "pruned 2 stale branches, 120 objects removed, 3.4 MiB reclaimed"
+func MaintenanceLabel(result types.GitRepo, dryRun bool) string {
+	var parts []string
+	switch n := len(result.PrunedRefs); {
+	case n == 0:
+		parts = append(parts, "no stale branches")
+	case dryRun:
+		parts = append(parts, "would prune "+plural(n, "stale branch", "stale branches"))
+	default:
+		parts = append(parts, "pruned "+plural(n, "stale branch", "stale branches"))
+	}
+
+	// Garbage collection did not run in dry-run mode or with --prune-only
+	if result.ObjectsBefore != (types.ObjectStats{}) {
+		removed := max(result.ObjectsBefore.Objects()-result.ObjectsAfter.Objects(), 0)
+		parts = append(parts, plural(removed, "object", "objects")+" removed", FormatKiB(reclaimedKiB(result))+" reclaimed")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PruneBranchesLabel describes what branch pruning did for a result, e.g.
+// "deleted 2 branches: feature/login (was 4d5e6f7), fix/typo (was 1a2b3c4)", followed by the
+// branches kept for holding unpushed commits
+func PruneBranchesLabel(result types.GitRepo, dryRun bool) string {
+	var label string
+	switch n := len(result.DeletedBranches); {
+	case n == 0:
+		label = "no branches to delete"
+	case dryRun:
+		label = "would delete " + plural(n, "branch", "branches") + ": " + strings.Join(result.DeletedBranches, ", ")
+	default:
+		label = "deleted " + plural(n, "branch", "branches") + ": " + strings.Join(result.DeletedBranches, ", ")
+	}
+	if n := len(result.KeptBranches); n > 0 {
+		label += "; kept " + plural(n, "branch", "branches") + " with unpushed commits: " + strings.Join(result.KeptBranches, ", ")
+	}
+	return label
+}
+
+// FilterSummary summarizes what --filter, --only, --on-branch and --remote-host kept of the
+// repositories discovered, e.g. "42 matched of 310 discovered"
+func FilterSummary(matched, discovered int) string {
+	return fmt.Sprintf("%d matched of %d discovered", matched, discovered)
+}
+
+// SelectionLabel lists the options a run picks its repositories with, e.g.
+// "--filter api-*, --only dirty,behind", or "" when it processes every one discovered
+func SelectionLabel(config *types.Config) string {
+	var options []string
+	if config.Nested == types.NestedSkip || config.Nested == types.NestedOnly {
+		options = append(options, "--nested "+string(config.Nested))
+	}
+	if config.Group != "" {
+		options = append(options, "--group "+config.Group)
+	}
+	if config.Filter != "" {
+		options = append(options, "--filter "+config.Filter)
+	}
+	if len(config.Only) > 0 {
+		options = append(options, "--only "+strings.Join(config.Only, ","))
+	}
+	if config.OnBranch != "" {
+		options = append(options, "--on-branch "+config.OnBranch)
+	}
+	if len(config.RemoteHosts) > 0 {
+		options = append(options, "--remote-host "+strings.Join(config.RemoteHosts, ","))
+	}
+	if len(config.ExcludeRemotes) > 0 {
+		options = append(options, "--exclude-remote "+strings.Join(config.ExcludeRemotes, ","))
+	}
+	return strings.Join(options, ", ")
+}
+
+// DeletedSummary summarizes the branches a run deleted, e.g. "3 branches deleted" or
+// "1 branch would be deleted"
+func DeletedSummary(n int, dryRun bool) string {
+	if dryRun {
+		return plural(n, "branch", "branches") + " would be deleted"
+	}
+	return plural(n, "branch", "branches") + " deleted"
+}
+
+// SetURLLabel describes what set-url did for a result, e.g.
+// "rewrote origin git@old-host:acme/api.git -> git@new-host:acme/api.git"
+func SetURLLabel(result types.GitRepo, dryRun bool) string {
+	if len(result.URLRewrites) == 0 {
+		return "no matching remote URLs"
+	}
+	rewrites := make([]string, len(result.URLRewrites))
+	for i, rewrite := range result.URLRewrites {
+		rewrites[i] = RewriteLabel(rewrite)
+	}
+	if dryRun {
+		return "would rewrite " + strings.Join(rewrites, ", ")
+	}
+	return "rewrote " + strings.Join(rewrites, ", ")
+}
+
+// RewriteLabel describes one remote URL rewrite, e.g. "origin (push) https://host/a.git ->
+// git@host:a.git"
+func RewriteLabel(rewrite types.Rewrite) string {
+	remote := rewrite.Remote
+	if rewrite.Push {
+		remote += " (push)"
+	}
+	return remote + " " + rewrite.From + " -> " + rewrite.To
+}
+
+// RewrittenSummary summarizes the remote URLs a run rewrote, e.g. "12 remote URLs rewritten"
+// or "1 remote URL would be rewritten"
+func RewrittenSummary(n int, dryRun bool) string {
+	if dryRun {
+		return plural(n, "remote URL", "remote URLs") + " would be rewritten"
+	}
+	return plural(n, "remote URL", "remote URLs") + " rewritten"
+}
+
+// ApplyLabel describes what apply did for a result, e.g. "committed 1a2b3c4d to ci-bump (3
+// files), pushed"
+func ApplyLabel(result types.GitRepo, dryRun bool) string {
+	switch {
+	case dryRun && result.ApplyFiles > 0:
+		return "would commit changes to " + plural(result.ApplyFiles, "file", "files")
+	case dryRun:
+		return "would run the script"
+	case result.ApplyCommit == "":
+		return "no changes"
+	}
+	label := fmt.Sprintf("committed %s to %s (%s)", result.ApplyCommit, result.Branch, plural(result.ApplyFiles, "file", "files"))
+	if result.ApplyPushed {
+		label += ", pushed"
+	}
+	return label
+}
+
+// AppliedSummary summarizes what a run's apply committed and pushed, e.g. "12 repositories
+// changed, 12 branches pushed"
+func AppliedSummary(applied, pushed int) string {
+	summary := plural(applied, "repository", "repositories") + " changed"
+	if pushed > 0 {
+		summary += ", " + plural(pushed, "branch", "branches") + " pushed"
+	}
+	return summary
+}
+
+// reclaimedKiB returns how much disk space garbage collection freed in a result's repository
+func reclaimedKiB(result types.GitRepo) int64 {
+	if result.ObjectsBefore == (types.ObjectStats{}) {
+		return 0
+	}
+	return max(result.ObjectsBefore.SizeKiB-result.ObjectsAfter.SizeKiB, 0)
+}
+
+// FormatKiB formats a size in KiB for people, e.g. "512 KiB" or "3.4 MiB"
+func FormatKiB(kib int64) string {
+	switch {
+	case kib >= 1024*1024:
+		return fmt.Sprintf("%.1f GiB", float64(kib)/(1024*1024))
+	case kib >= 1024:
+		return fmt.Sprintf("%.1f MiB", float64(kib)/1024)
+	default:
+		return fmt.Sprintf("%d KiB", kib)
+	}
+}
+
+// plural formats n with the singular or plural noun, e.g. "1 file" or "3 files"
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
+
+// CloneLabel describes what clone did for a result, e.g. "cloned https://github.com/acme/api.git"
+// or "would clone git@github.com:acme/api.git (branch main)"
+func CloneLabel(result types.GitRepo, dryRun bool) string {
+	label := "cloned " + result.RemoteURL
+	if dryRun {
+		label = "would clone " + result.RemoteURL
+	}
+	if result.CloneBranch != "" {
+		label += " (branch " + result.CloneBranch + ")"
+	}
+	return label
+}
+
+// ExecLabel describes the command exec ran in a result's repository, e.g. "exit 0, 12 lines of
+// output" or "would run the command"
+func ExecLabel(result types.GitRepo, dryRun bool) string {
+	if result.Exec == nil {
+		if dryRun {
+			return "would run the command"
+		}
+		return "command not run"
+	}
+	label := fmt.Sprintf("exit %d", result.Exec.ExitCode)
+	if lines := len(ExecOutputLines(result)); lines > 0 {
+		label += ", " + plural(lines, "line", "lines") + " of output"
+	}
+	return label
+}
+
+// ExecOutputLines returns what the command exec ran in a result's repository printed, stdout
+// before stderr, noting where the capture limit cut it short
+func ExecOutputLines(result types.GitRepo) []string {
+	if result.Exec == nil {
+		return nil
+	}
+	lines := append(strings.Split(strings.TrimRight(result.Exec.Stdout, "\n"), "\n"),
+		strings.Split(strings.TrimRight(result.Exec.Stderr, "\n"), "\n")...)
+	lines = slices.DeleteFunc(lines, func(line string) bool { return line == "" })
+	if result.Exec.Truncated {
+		lines = append(lines, "[output truncated]")
+	}
+	return lines
+}
+
+// RemoteLabel names a result's remote, noting how many others it has, e.g. "origin +1"
+func RemoteLabel(result types.GitRepo) string {
+	if others := len(result.Remotes) - 1; others > 0 {
+		return fmt.Sprintf("%s +%d", result.Remote, others)
+	}
+	return result.Remote
+}
+
+// otherRemotes lists the remotes besides the one operations use, e.g. "upstream (https://...)"
+func otherRemotes(result types.GitRepo) string {
+	var others []string
+	for _, remote := range result.Remotes {
+		if remote.Name == result.Remote {
+			continue
+		}
+		if remote.URL == "" {
+			others = append(others, remote.Name)
+		} else {
+			others = append(others, fmt.Sprintf("%s (%s)", remote.Name, remote.URL))
+		}
+	}
+	return strings.Join(others, ", ")
+}
+
+// CompliancePercent returns the share of audited repositories that passed, 0 when none were audited
+func (t *Tally) CompliancePercent() float64 {
+	if t.Audited == 0 {
+		return 0
+	}
+	return float64(t.Compliant) / float64(t.Audited) * 100
+}
+
+// CISummary counts repositories per CI system, e.g. "GitHub Actions: 12, none: 3"
+func (t *Tally) CISummary() string {
+	parts := make([]string, 0, len(t.CISystems))
+	for system, count := range t.CISystems {
+		parts = append(parts, fmt.Sprintf("%s: %d", system, count))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ", ")
+}
+
+// Recent keeps the most recent results in a fixed-size ring
+type Recent struct {
+	items []types.GitRepo
+	next  int
+	count int
+}
+
+// NewRecent creates a ring holding at most size results
+func NewRecent(size int) *Recent {
+	return &Recent{items: make([]types.GitRepo, max(size, 1))}
+}
+
+// Add stores a result, evicting the oldest once the ring is full
+func (r *Recent) Add(result types.GitRepo) {
+	r.items[r.next] = result
+	r.next = (r.next + 1) % len(r.items)
+	if r.count < len(r.items) {
+		r.count++
+	}
+}
+
+// Len returns the number of results held
+func (r *Recent) Len() int {
+	return r.count
+}
+
+// Items returns the held results, oldest first
+func (r *Recent) Items() []types.GitRepo {
+	items := make([]types.GitRepo, 0, r.count)
+	start := (r.next - r.count + len(r.items)) % len(r.items)
+	for i := range r.count {
+		items = append(items, r.items[(start+i)%len(r.items)])
+	}
+	return items
+}
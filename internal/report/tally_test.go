@@ -0,0 +1,669 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestTally(t *testing.T) {
+	var tally Tally
+	results := []types.GitRepo{
+		{Name: "compliant", MissingFiles: []string{}, CISystems: []string{"GitHub Actions"}},
+		{Name: "missing", MissingFiles: []string{"LICENSE*"}, CISystems: []string{"GitHub Actions", "Jenkins"}},
+		{Name: "failed", Error: errors.New("failed to open repository")},
+		{Name: "dirty", Error: errors.New("repository has uncommitted changes (skipped)")},
+		{Name: "late", Error: git.ErrBudgetExhausted},
+		{Name: "also-compliant"},
+	}
+	for _, result := range results {
+		tally.Add(result)
+	}
+
+	if tally.Total != 6 || tally.Successful != 3 || tally.Failed != 1 || tally.Skipped != 2 {
+		t.Errorf("Unexpected counts: %+v", tally)
+	}
+	if tally.NotAttempted != 1 {
+		t.Errorf("Expected 1 not attempted, got %d", tally.NotAttempted)
+	}
+	if tally.Compliant != 2 || tally.Audited != 3 {
+		t.Errorf("Expected 2/3 compliant, got %d/%d", tally.Compliant, tally.Audited)
+	}
+	if percent := tally.CompliancePercent(); percent < 66.6 || percent > 66.7 {
+		t.Errorf("Expected ~66.7%% compliance, got %.2f", percent)
+	}
+
+	expected := "GitHub Actions: 2, Jenkins: 1, none: 1"
+	if summary := tally.CISummary(); summary != expected {
+		t.Errorf("Expected CI summary %q, got %q", expected, summary)
+	}
+
+	var empty Tally
+	if percent := empty.CompliancePercent(); percent != 0 {
+		t.Errorf("Expected 0%% compliance for no results, got %.2f", percent)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	recent := NewRecent(3)
+	if recent.Len() != 0 || len(recent.Items()) != 0 {
+		t.Fatal("Expected empty ring")
+	}
+
+	for i := 1; i <= 2; i++ {
+		recent.Add(types.GitRepo{Name: fmt.Sprintf("repo%d", i)})
+	}
+	assertNames(t, recent.Items(), "repo1", "repo2")
+
+	for i := 3; i <= 7; i++ {
+		recent.Add(types.GitRepo{Name: fmt.Sprintf("repo%d", i)})
+	}
+	if recent.Len() != 3 {
+		t.Errorf("Expected ring to hold 3 results, got %d", recent.Len())
+	}
+	assertNames(t, recent.Items(), "repo5", "repo6", "repo7")
+}
+
+func assertNames(t *testing.T, results []types.GitRepo, names ...string) {
+	t.Helper()
+	if len(results) != len(names) {
+		t.Fatalf("Expected %d results, got %d", len(names), len(results))
+	}
+	for i, name := range names {
+		if results[i].Name != name {
+			t.Errorf("Expected result %d to be %s, got %s", i, name, results[i].Name)
+		}
+	}
+}
+
+func TestTallySlowest(t *testing.T) {
+	var tally Tally
+	for i := range SlowestCount + 3 {
+		tally.Add(types.GitRepo{
+			Name:          fmt.Sprintf("repo-%d", i),
+			Duration:      time.Duration(i) * time.Second,
+			ModifiedFiles: []string{"large.bin"},
+			Timings:       types.Timings{Network: time.Duration(i) * time.Second},
+		})
+	}
+
+	if len(tally.Slowest) != SlowestCount {
+		t.Fatalf("Expected %d slowest repositories, got %d", SlowestCount, len(tally.Slowest))
+	}
+	// repo-0 took no time and is never listed; the rest are kept slowest first
+	for i, result := range tally.Slowest {
+		want := fmt.Sprintf("repo-%d", SlowestCount+2-i)
+		if result.Name != want {
+			t.Errorf("Slowest[%d] = %s, want %s", i, result.Name, want)
+		}
+		if result.ModifiedFiles != nil {
+			t.Errorf("Slowest[%d] retained modified files", i)
+		}
+	}
+
+	if line := SlowLine(tally.Slowest[0]); line != "7s  repo-7 (network 7s)" {
+		t.Errorf("SlowLine() = %q", line)
+	}
+	if line := SlowLine(types.GitRepo{Name: "bare", Duration: time.Second}); line != "1s  bare" {
+		t.Errorf("SlowLine() without timings = %q", line)
+	}
+}
+
+func TestDirtyAge(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := map[time.Duration]string{
+		42*24*time.Hour + 3*time.Hour: "42 days",
+		30 * time.Hour:                "1 day",
+		5 * time.Hour:                 "5 hours",
+		90 * time.Minute:              "1 hour",
+		10 * time.Minute:              "less than an hour",
+	}
+	for age, want := range tests {
+		result := types.GitRepo{DirtySince: now.Add(-age)}
+		if got := DirtyAge(result, now); got != want {
+			t.Errorf("DirtyAge(%v) = %q, want %q", age, got, want)
+		}
+	}
+
+	if got := DirtyAge(types.GitRepo{}, now); got != "" {
+		t.Errorf("DirtyAge() of a clean result = %q, want empty", got)
+	}
+}
+
+func TestStatusLabel(t *testing.T) {
+	tests := []struct {
+		result types.GitRepo
+		want   string
+	}{
+		{types.GitRepo{Branch: "main", Upstream: "origin/main"}, "up to date"},
+		{types.GitRepo{Branch: "main", Upstream: "origin/main", Ahead: 2, Behind: 1, ModifiedFiles: []string{"a", "b", "c"}, Stashes: 1}, "ahead 2, behind 1, dirty (3 files), 1 stash"},
+		{types.GitRepo{Branch: "main", Upstream: "origin/main", Behind: 4, Stashes: 2}, "behind 4, 2 stashes"},
+		{types.GitRepo{Branch: "main", Upstream: "origin/old", UpstreamGone: true}, "upstream origin/old gone"},
+		{types.GitRepo{Branch: "feature", ModifiedFiles: []string{"a"}}, "no upstream, dirty (1 file)"},
+		{types.GitRepo{Branch: "detached"}, "detached HEAD"},
+		{types.GitRepo{Branch: "main", Empty: true}, "no commits yet"},
+	}
+	for _, tt := range tests {
+		if got := StatusLabel(tt.result); got != tt.want {
+			t.Errorf("StatusLabel(%+v) = %q, want %q", tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestOwnersLabels(t *testing.T) {
+	result := types.GitRepo{Owners: []types.Owner{
+		{Name: "Ada", Email: "ada@example.com", Commits: 42},
+		{Name: "Linus", Commits: 7},
+	}}
+
+	if got, want := OwnersLabel(result), "Ada <ada@example.com> (42), Linus (7)"; got != want {
+		t.Errorf("OwnersLabel() = %q, want %q", got, want)
+	}
+	if got, want := OwnersNote(result), " (owners: Ada, Linus)"; got != want {
+		t.Errorf("OwnersNote() = %q, want %q", got, want)
+	}
+	if got := OwnersNote(types.GitRepo{}); got != "" {
+		t.Errorf("OwnersNote() without owners = %q, want empty", got)
+	}
+}
+
+func TestPushLabel(t *testing.T) {
+	result := types.GitRepo{Pushed: "1a2b3c4d..5e6f7a8b main -> main"}
+
+	if got, want := PushLabel(result, false), "pushed 1a2b3c4d..5e6f7a8b main -> main"; got != want {
+		t.Errorf("PushLabel() = %q, want %q", got, want)
+	}
+	if got, want := PushLabel(result, true), "would push 1a2b3c4d..5e6f7a8b main -> main"; got != want {
+		t.Errorf("PushLabel() in dry run = %q, want %q", got, want)
+	}
+	if got, want := PushLabel(types.GitRepo{}, false), "nothing to push"; got != want {
+		t.Errorf("PushLabel() without a push = %q, want %q", got, want)
+	}
+
+	var tally Tally
+	tally.Add(result)
+	tally.Add(types.GitRepo{})
+	tally.Add(types.GitRepo{Pushed: "x", Error: errors.New("push failed")})
+	if tally.Pushed != 1 {
+		t.Errorf("Expected 1 pushed, got %d", tally.Pushed)
+	}
+}
+
+func TestCheckoutLabel(t *testing.T) {
+	result := types.GitRepo{Branch: "feature", Checkout: "main -> feature"}
+
+	if got, want := CheckoutLabel(result, false), "switched main -> feature"; got != want {
+		t.Errorf("CheckoutLabel() = %q, want %q", got, want)
+	}
+	if got, want := CheckoutLabel(result, true), "would switch main -> feature"; got != want {
+		t.Errorf("CheckoutLabel() in dry run = %q, want %q", got, want)
+	}
+	if got, want := CheckoutLabel(types.GitRepo{Branch: "feature"}, false), "already on feature"; got != want {
+		t.Errorf("CheckoutLabel() on the branch = %q, want %q", got, want)
+	}
+	if got, want := CheckoutLabel(types.GitRepo{Branch: "detached", Pin: &types.Pin{Ref: "v1.4.0"}}, false), "already at v1.4.0"; got != want {
+		t.Errorf("CheckoutLabel() at the pinned ref = %q, want %q", got, want)
+	}
+}
+
+func TestStashLabel(t *testing.T) {
+	tests := []struct {
+		result types.GitRepo
+		dryRun bool
+		want   string
+	}{
+		{types.GitRepo{Stashed: true, ModifiedFiles: []string{"a", "b"}}, false, "stashed 2 files"},
+		{types.GitRepo{Stashed: true, ModifiedFiles: []string{"a"}}, true, "would stash 1 file"},
+		{types.GitRepo{Unstashed: true}, false, "popped the git-herd stash"},
+		{types.GitRepo{Stashed: true, Unstashed: true, ModifiedFiles: []string{"a"}}, false, "stashed and restored 1 file"},
+		{types.GitRepo{Stashed: true, Unstashed: true, ModifiedFiles: []string{"a"}}, true, "would stash and restore 1 file"},
+		{types.GitRepo{}, false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := StashLabel(tt.result, tt.dryRun); got != tt.want {
+			t.Errorf("StashLabel(%+v, %v) = %q, want %q", tt.result, tt.dryRun, got, tt.want)
+		}
+	}
+}
+
+func TestPullLabel(t *testing.T) {
+	result := types.GitRepo{Remote: "origin", Branch: "main", Upstream: "origin/trunk", PulledWith: "rebase"}
+	if got, want := PullLabel(result, false), "rebased onto origin/trunk"; got != want {
+		t.Errorf("PullLabel() = %q, want %q", got, want)
+	}
+
+	result = types.GitRepo{Remote: "origin", Branch: "main", PulledWith: "merge", Stashed: true, Unstashed: true, ModifiedFiles: []string{"a"}}
+	if got, want := PullLabel(result, false), "merged origin/main, stashed and restored 1 file"; got != want {
+		t.Errorf("PullLabel() = %q, want %q", got, want)
+	}
+	if got := PullLabel(types.GitRepo{Remote: "origin", Branch: "main"}, false); got != "" {
+		t.Errorf("Expected no label for a fast-forward, got %q", got)
+	}
+}
+
+func TestMaintenanceLabel(t *testing.T) {
+	result := types.GitRepo{
+		PrunedRefs:    []string{"origin/feature", "origin/fix"},
+		ObjectsBefore: types.ObjectStats{Loose: 120, Packed: 1000, SizeKiB: 5120},
+		ObjectsAfter:  types.ObjectStats{Packed: 1000, Packs: 1, SizeKiB: 1536},
+	}
+	if got, want := MaintenanceLabel(result, false), "pruned 2 stale branches, 120 objects removed, 3.5 MiB reclaimed"; got != want {
+		t.Errorf("MaintenanceLabel() = %q, want %q", got, want)
+	}
+
+	planned := types.GitRepo{PrunedRefs: []string{"origin/feature"}}
+	if got, want := MaintenanceLabel(planned, true), "would prune 1 stale branch"; got != want {
+		t.Errorf("MaintenanceLabel() in dry run = %q, want %q", got, want)
+	}
+	if got, want := MaintenanceLabel(types.GitRepo{}, false), "no stale branches"; got != want {
+		t.Errorf("MaintenanceLabel() with nothing to do = %q, want %q", got, want)
+	}
+
+	var tally Tally
+	tally.Add(result)
+	if tally.PrunedRefs != 2 || tally.ReclaimedKiB != 3584 {
+		t.Errorf("Expected 2 pruned refs and 3584 KiB reclaimed, got %d and %d", tally.PrunedRefs, tally.ReclaimedKiB)
+	}
+}
+
+func TestPruneBranchesLabel(t *testing.T) {
+	result := types.GitRepo{DeletedBranches: []string{"feature/login", "fix/typo"}}
+	if got, want := PruneBranchesLabel(result, false), "deleted 2 branches: feature/login, fix/typo"; got != want {
+		t.Errorf("PruneBranchesLabel() = %q, want %q", got, want)
+	}
+
+	planned := types.GitRepo{DeletedBranches: []string{"feature/login"}}
+	if got, want := PruneBranchesLabel(planned, true), "would delete 1 branch: feature/login"; got != want {
+		t.Errorf("PruneBranchesLabel() in dry run = %q, want %q", got, want)
+	}
+	if got, want := PruneBranchesLabel(types.GitRepo{}, false), "no branches to delete"; got != want {
+		t.Errorf("PruneBranchesLabel() with nothing to do = %q, want %q", got, want)
+	}
+	kept := types.GitRepo{DeletedBranches: []string{"old (was 1a2b3c4)"}, KeptBranches: []string{"wip"}}
+	if got, want := PruneBranchesLabel(kept, false), "deleted 1 branch: old (was 1a2b3c4); kept 1 branch with unpushed commits: wip"; got != want {
+		t.Errorf("PruneBranchesLabel() with a kept branch = %q, want %q", got, want)
+	}
+
+	var tally Tally
+	tally.Add(result)
+	tally.Add(planned)
+	if got, want := DeletedSummary(tally.Deleted, false), "3 branches deleted"; got != want {
+		t.Errorf("DeletedSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestSetURLLabel(t *testing.T) {
+	result := types.GitRepo{URLRewrites: []types.Rewrite{
+		{Remote: "origin", From: "git@old-host:acme/api.git", To: "git@new-host:acme/api.git"},
+		{Remote: "origin", Push: true, From: "https://old-host/acme/api.git", To: "https://new-host/acme/api.git"},
+	}}
+	want := "rewrote origin git@old-host:acme/api.git -> git@new-host:acme/api.git, " +
+		"origin (push) https://old-host/acme/api.git -> https://new-host/acme/api.git"
+	if got := SetURLLabel(result, false); got != want {
+		t.Errorf("SetURLLabel() = %q, want %q", got, want)
+	}
+	if got := SetURLLabel(result, true); !strings.HasPrefix(got, "would rewrite origin ") {
+		t.Errorf("SetURLLabel() in dry run = %q, want it to start with %q", got, "would rewrite origin ")
+	}
+	if got, want := SetURLLabel(types.GitRepo{}, false), "no matching remote URLs"; got != want {
+		t.Errorf("SetURLLabel() with nothing to do = %q, want %q", got, want)
+	}
+
+	var tally Tally
+	tally.Add(result)
+	if got, want := RewrittenSummary(tally.Rewritten, true), "2 remote URLs would be rewritten"; got != want {
+		t.Errorf("RewrittenSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestSyncLabel(t *testing.T) {
+	result := types.GitRepo{Branch: "feature", SyncBranch: "main", SyncUpdate: "1a2b3c4d..5e6f7a8b"}
+	if got, want := SyncLabel(result, false), "updated main 1a2b3c4d..5e6f7a8b, back on feature"; got != want {
+		t.Errorf("SyncLabel() = %q, want %q", got, want)
+	}
+	current := types.GitRepo{Branch: "main", SyncBranch: "main"}
+	if got, want := SyncLabel(current, false), "main already up to date"; got != want {
+		t.Errorf("SyncLabel() on the default branch = %q, want %q", got, want)
+	}
+	if got, want := SyncLabel(current, true), "would update main"; got != want {
+		t.Errorf("SyncLabel() in dry run = %q, want %q", got, want)
+	}
+
+	var tally Tally
+	tally.Add(result)
+	tally.Add(current)
+	if tally.Synced != 1 {
+		t.Errorf("Expected 1 default branch counted as updated, got %d", tally.Synced)
+	}
+}
+
+func TestHealLabel(t *testing.T) {
+	healed := types.GitRepo{Branch: "main", Healed: true, Checkout: "detached at 1a2b3c4d -> main (branch at the commit)"}
+	if got, want := HealLabel(healed, false), "healed detached at 1a2b3c4d -> main (branch at the commit)"; got != want {
+		t.Errorf("HealLabel() = %q, want %q", got, want)
+	}
+	if got, want := HealLabel(healed, true), "would heal detached at 1a2b3c4d -> main (branch at the commit)"; got != want {
+		t.Errorf("HealLabel() in dry run = %q, want %q", got, want)
+	}
+	onBranch := types.GitRepo{Branch: "feature"}
+	if got, want := HealLabel(onBranch, false), "on feature, not detached"; got != want {
+		t.Errorf("HealLabel() on a branch = %q, want %q", got, want)
+	}
+
+	var tally Tally
+	tally.Add(healed)
+	tally.Add(onBranch)
+	if got, want := HealedSummary(tally.Healed, false), "1 detached HEAD healed"; got != want {
+		t.Errorf("HealedSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestReleaseLabel(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		release *types.Release
+		want    string
+	}{
+		{"empty", nil, "no commits"},
+		{"released", &types.Release{Tag: "v1.4.0", Date: now.AddDate(0, 0, -45)}, "v1.4.0 (45 days ago), nothing unreleased"},
+		{
+			"overdue",
+			&types.Release{Tag: "v1.4.0", Date: now.AddDate(0, 0, -45), Unreleased: 12, Oldest: now.AddDate(0, 0, -40), Overdue: true},
+			"v1.4.0 (45 days ago), 12 unreleased commits, oldest 40 days old, overdue",
+		},
+		{"never tagged", &types.Release{Unreleased: 1, Oldest: now.Add(-3 * time.Hour)}, "no tags, 1 unreleased commit, oldest 3 hours old"},
+	}
+	var tally Tally
+	for _, tt := range tests {
+		result := types.GitRepo{Release: tt.release}
+		if got := ReleaseLabel(result, now); got != tt.want {
+			t.Errorf("ReleaseLabel(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+		tally.Add(result)
+	}
+
+	if got, want := ReleasesSummary(tally.Unreleased, tally.Overdue, 30), "2 repositories with unreleased work, 1 for over 30 days"; got != want {
+		t.Errorf("ReleasesSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestVersionLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		check  *types.TagCheck
+		dryRun bool
+		want   string
+	}{
+		{"current", &types.TagCheck{Expected: "v1.4.0", Current: "v1.5.0", Describe: "v1.5.0"}, false, "on v1.5.0, expected v1.4.0"},
+		{"behind", &types.TagCheck{Expected: "v1.4.0", Current: "v1.3.0", Describe: "v1.3.0-4-g1a2b3c4", Behind: true}, false, "on v1.3.0 (v1.3.0-4-g1a2b3c4), behind v1.4.0"},
+		{"untagged", &types.TagCheck{Expected: "v1.4.0", Behind: true}, false, "no version tag, behind v1.4.0"},
+		{"checked out", &types.TagCheck{Expected: "1.4.0", Current: "v1.3.0", Behind: true, CheckedOut: "v1.4.0"}, false, "checked out v1.4.0, was on v1.3.0"},
+		{"dry run", &types.TagCheck{Expected: "1.4.0", Current: "v1.3.0", Behind: true, CheckedOut: "v1.4.0"}, true, "would check out v1.4.0, on v1.3.0"},
+	}
+	var tally Tally
+	for _, tt := range tests {
+		result := types.GitRepo{Version: tt.check}
+		if got := VersionLabel(result, tt.dryRun); got != tt.want {
+			t.Errorf("VersionLabel(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+		tally.Add(result)
+	}
+
+	if got, want := VersionsSummary(tally.Outdated, tally.TagsChecked, false), "4 repositories behind their expected version, 2 checked out"; got != want {
+		t.Errorf("VersionsSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestExecLabel(t *testing.T) {
+	result := types.GitRepo{Exec: &types.ExecResult{Stdout: "ok\nPASS\n", Stderr: "warning: slow\n", Truncated: true}}
+	if got, want := ExecLabel(result, false), "exit 0, 4 lines of output"; got != want {
+		t.Errorf("ExecLabel() = %q, want %q", got, want)
+	}
+	if got, want := ExecOutputLines(result), []string{"ok", "PASS", "warning: slow", "[output truncated]"}; !slices.Equal(got, want) {
+		t.Errorf("ExecOutputLines() = %q, want %q", got, want)
+	}
+
+	if got, want := ExecLabel(types.GitRepo{Exec: &types.ExecResult{ExitCode: 2}}, false), "exit 2"; got != want {
+		t.Errorf("ExecLabel() without output = %q, want %q", got, want)
+	}
+	if got, want := ExecLabel(types.GitRepo{}, true), "would run the command"; got != want {
+		t.Errorf("ExecLabel() in dry run = %q, want %q", got, want)
+	}
+}
+
+func TestCloneLabel(t *testing.T) {
+	result := types.GitRepo{RemoteURL: "git@github.com:acme/api.git"}
+
+	if got, want := CloneLabel(result, false), "cloned git@github.com:acme/api.git"; got != want {
+		t.Errorf("CloneLabel() = %q, want %q", got, want)
+	}
+	result.CloneBranch = "main"
+	if got, want := CloneLabel(result, true), "would clone git@github.com:acme/api.git (branch main)"; got != want {
+		t.Errorf("CloneLabel() in dry run = %q, want %q", got, want)
+	}
+}
+
+func TestRemoteLabel(t *testing.T) {
+	result := types.GitRepo{
+		Remote: "origin",
+		Remotes: []types.Remote{
+			{Name: "origin", URL: "git@github.com:me/repo.git"},
+			{Name: "upstream", URL: "https://github.com/org/repo.git"},
+			{Name: "local"},
+		},
+	}
+
+	if label := RemoteLabel(result); label != "origin +2" {
+		t.Errorf("RemoteLabel() = %q, want %q", label, "origin +2")
+	}
+	if others := otherRemotes(result); others != "upstream (https://github.com/org/repo.git), local" {
+		t.Errorf("otherRemotes() = %q", others)
+	}
+	if label := RemoteLabel(types.GitRepo{Remote: "origin", Remotes: result.Remotes[:1]}); label != "origin" {
+		t.Errorf("RemoteLabel() with one remote = %q, want %q", label, "origin")
+	}
+}
+
+func TestUpstreamLabels(t *testing.T) {
+	tests := []struct {
+		result     types.GitRepo
+		label      string
+		noUpstream bool
+	}{
+		{types.GitRepo{Branch: "main", Upstream: "origin/main"}, "origin/main", false},
+		{types.GitRepo{Branch: "main", Upstream: "origin/main", UpstreamSet: true}, "origin/main (set)", false},
+		{types.GitRepo{Branch: "main"}, "none", true},
+		{types.GitRepo{Branch: "detached"}, "none", false},
+	}
+
+	var tally Tally
+	for _, tt := range tests {
+		tally.Add(tt.result)
+		if label := UpstreamLabel(tt.result); label != tt.label {
+			t.Errorf("UpstreamLabel(%+v) = %q, want %q", tt.result, label, tt.label)
+		}
+		if got := HasNoUpstream(tt.result); got != tt.noUpstream {
+			t.Errorf("HasNoUpstream(%+v) = %v, want %v", tt.result, got, tt.noUpstream)
+		}
+	}
+	if tally.NoUpstream != 1 {
+		t.Errorf("Expected 1 repository without upstream, got %d", tally.NoUpstream)
+	}
+}
+
+func TestTallyEmpty(t *testing.T) {
+	var tally Tally
+	tally.Add(types.GitRepo{Name: "scanned", Empty: true})
+	tally.Add(types.GitRepo{Name: "fetched", Empty: true, Error: errors.New("empty repository: no commits yet (skipped)")})
+
+	if tally.Empty != 2 || tally.Skipped != 1 || tally.Failed != 0 {
+		t.Errorf("Expected 2 empty repositories, 1 skipped and none failed, got %+v", tally)
+	}
+}
+
+func TestTallyGroups(t *testing.T) {
+	var tally Tally
+	tally.Add(types.GitRepo{Name: "api"})
+	if got := tally.GroupSummaries(); got != nil {
+		t.Errorf("Expected no group summaries without groups, got %q", got)
+	}
+
+	tally.Add(types.GitRepo{Name: "web", Group: "clientB", Error: errors.New("connection reset")})
+	tally.Add(types.GitRepo{Name: "tools", Group: "clientA"})
+	tally.Add(types.GitRepo{Name: "infra", Group: "clientA", Error: errors.New("uncommitted changes (skipped)")})
+
+	want := []string{
+		"clientA: 1 successful, 0 failed, 1 skipped, 2 total",
+		"clientB: 0 successful, 1 failed, 0 skipped, 1 total",
+		"(no group): 1 successful, 0 failed, 0 skipped, 1 total",
+	}
+	if got := tally.GroupSummaries(); !slices.Equal(got, want) {
+		t.Errorf("GroupSummaries() = %q, want %q", got, want)
+	}
+}
+
+func TestTallySteps(t *testing.T) {
+	t.Parallel()
+
+	skipped := errors.New("repository has uncommitted changes (skipped)")
+	var tally Tally
+	tally.Add(types.GitRepo{Name: "api", Steps: []types.Step{
+		{Operation: types.OperationFetch},
+		{Operation: types.OperationPull, Error: skipped},
+		{Operation: types.OperationStatus},
+	}})
+	web := types.GitRepo{Name: "web", Steps: []types.Step{
+		{Operation: types.OperationFetch, Error: errors.New("connection reset"), Duration: 1500 * time.Millisecond},
+	}}
+	tally.Add(web)
+
+	want := []string{
+		"1. fetch: 1 successful, 1 failed, 0 skipped",
+		"2. pull: 0 successful, 0 failed, 1 skipped",
+		"3. status: 1 successful, 0 failed, 0 skipped",
+	}
+	if got := tally.StepSummaries(); !slices.Equal(got, want) {
+		t.Errorf("StepSummaries() = %q, want %q", got, want)
+	}
+	if got, want := StepsLabel(types.GitRepo{Steps: []types.Step{
+		{Operation: types.OperationFetch},
+		{Operation: types.OperationPull, Error: skipped},
+	}}), "fetch → pull (skipped)"; got != want {
+		t.Errorf("StepsLabel() = %q, want %q", got, want)
+	}
+	if got, want := StepLine(web.Steps[0]), "fetch: connection reset after 1.5s"; got != want {
+		t.Errorf("StepLine() = %q, want %q", got, want)
+	}
+	if got := StepsLabel(types.GitRepo{}); got != "" {
+		t.Errorf("Expected no label without a pipeline, got %q", got)
+	}
+}
+
+func TestTallyFlaky(t *testing.T) {
+	t.Parallel()
+
+	var tally Tally
+	tally.Add(types.GitRepo{Name: "steady"})
+	tally.Add(types.GitRepo{Name: "api", Flaky: true, Error: fmt.Errorf("connection reset")})
+	tally.Add(types.GitRepo{Name: "web", Flaky: true})
+
+	if len(tally.Flaky) != 2 {
+		t.Fatalf("Expected 2 flaky repositories, got %d", len(tally.Flaky))
+	}
+	if tally.Failed != 1 {
+		t.Errorf("Expected flaky failures to still count as failed, got %d", tally.Failed)
+	}
+	if line := FlakyLine(tally.Flaky[0]); line != "api (failed: connection reset)" {
+		t.Errorf("FlakyLine() = %q", line)
+	}
+	if line := FlakyLine(tally.Flaky[1]); line != "web (ok this run)" {
+		t.Errorf("FlakyLine() = %q", line)
+	}
+}
+
+func TestShallowLabel(t *testing.T) {
+	tests := []struct {
+		result types.GitRepo
+		want   string
+	}{
+		{types.GitRepo{}, ""},
+		{types.GitRepo{Shallow: true}, "yes"},
+		{types.GitRepo{Shallow: true, Depth: 50}, "depth 50"},
+	}
+	for _, tt := range tests {
+		if got := ShallowLabel(tt.result); got != tt.want {
+			t.Errorf("ShallowLabel(%+v) = %q, want %q", tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestApplyLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		result types.GitRepo
+		dryRun bool
+		want   string
+	}{
+		{"committed", types.GitRepo{Branch: "ci-bump", ApplyCommit: "1a2b3c4d", ApplyFiles: 3, ApplyPushed: true}, false, "committed 1a2b3c4d to ci-bump (3 files), pushed"},
+		{"no changes", types.GitRepo{Branch: "main"}, false, "no changes"},
+		{"patch dry run", types.GitRepo{ApplyFiles: 1}, true, "would commit changes to 1 file"},
+		{"script dry run", types.GitRepo{}, true, "would run the script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyLabel(tt.result, tt.dryRun); got != tt.want {
+				t.Errorf("ApplyLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := AppliedSummary(2, 1), "2 repositories changed, 1 branch pushed"; got != want {
+		t.Errorf("AppliedSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestTallyUnreachable(t *testing.T) {
+	var tally Tally
+	tally.Add(types.GitRepo{Name: "api", Error: fmt.Errorf("%w: remote origin: could not read from remote repository", git.ErrPreflight)})
+	tally.Add(types.GitRepo{Name: "web", Error: errors.New("fetch failed: timed out")})
+	if tally.Unreachable != 1 || tally.Failed != 2 {
+		t.Errorf("Expected 1 unreachable of 2 failed, got %d of %d", tally.Unreachable, tally.Failed)
+	}
+}
+
+func TestTallyOwnership(t *testing.T) {
+	var tally Tally
+	tally.Add(types.GitRepo{Name: "api", Error: fmt.Errorf("%w: owned by uid 1001, not the current user (skipped)", git.ErrDubiousOwnership)})
+	tally.Add(types.GitRepo{Name: "web", SafeDirectory: true})
+	if tally.Untrusted != 1 || tally.Skipped != 1 || tally.Trusted != 1 {
+		t.Errorf("Expected 1 untrusted skip and 1 trusted, got %d untrusted, %d skipped, %d trusted", tally.Untrusted, tally.Skipped, tally.Trusted)
+	}
+	if got, want := OwnershipSummary(tally.Untrusted, tally.Trusted, false), "1 repository owned by another user skipped (see --add-safe-directory), 1 repository added to safe.directory"; got != want {
+		t.Errorf("OwnershipSummary() = %q, want %q", got, want)
+	}
+	if got, want := OwnershipSummary(0, 2, true), "2 repositories would be added to safe.directory"; got != want {
+		t.Errorf("OwnershipSummary() in dry-run mode = %q, want %q", got, want)
+	}
+}
+
+func TestSelectionLabel(t *testing.T) {
+	if got := SelectionLabel(&types.Config{}); got != "" {
+		t.Errorf("SelectionLabel() without options = %q, want none", got)
+	}
+	config := &types.Config{Nested: types.NestedSkip, Group: "acme", Filter: "api-*", Only: []string{"dirty", "behind"}, OnBranch: "main", RemoteHosts: []string{"github.com"}}
+	if got, want := SelectionLabel(config), "--nested skip, --group acme, --filter api-*, --only dirty,behind, --on-branch main, --remote-host github.com"; got != want {
+		t.Errorf("SelectionLabel() = %q, want %q", got, want)
+	}
+	if got, want := FilterSummary(42, 310), "42 matched of 310 discovered"; got != want {
+		t.Errorf("FilterSummary() = %q, want %q", got, want)
+	}
+}
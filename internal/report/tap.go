@@ -0,0 +1,90 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// TAPWriter streams results in the Test Anything Protocol (version 13), one test point per
+// repository. Failures are "not ok" with the error as a comment; skipped repositories are
+// "ok" with a SKIP directive so TAP consumers don't count them as failures. The plan is
+// written up front, so a run that stops early shows up as missing test points.
+type TAPWriter struct {
+	out  *bufio.Writer
+	next int
+	err  error
+}
+
+// NewTAPWriter writes the TAP header and a plan for planned repositories to out, followed by
+// the run's labels as a comment
+func NewTAPWriter(out io.Writer, planned int, labels []string) *TAPWriter {
+	w := &TAPWriter{out: bufio.NewWriter(out), next: 1}
+	w.fprintf("TAP version 13\n")
+	if planned == 0 {
+		w.fprintf("1..0 # SKIP no git repositories found\n")
+	} else {
+		w.fprintf("1..%d\n", planned)
+	}
+	if len(labels) > 0 {
+		w.fprintf("# labels: %s\n", tapComment(strings.Join(labels, " ")))
+	}
+	w.flush()
+	return w
+}
+
+// fprintf writes a line, remembering the first error so callers can check once at Close
+func (w *TAPWriter) fprintf(format string, a ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.out, format, a...)
+}
+
+// flush hands the test point to the consumer as soon as it is written
+func (w *TAPWriter) flush() {
+	if w.err != nil {
+		return
+	}
+	w.err = w.out.Flush()
+}
+
+// Add writes one repository's test point
+func (w *TAPWriter) Add(result types.GitRepo) {
+	description := tapEscape(result.Name)
+	switch {
+	case result.Error == nil:
+		w.fprintf("ok %d - %s\n", w.next, description)
+	case IsSkipped(result):
+		w.fprintf("ok %d - %s # SKIP %s\n", w.next, description, tapComment(result.Error.Error()))
+	default:
+		w.fprintf("not ok %d - %s # %s\n", w.next, description, tapComment(result.Error.Error()))
+	}
+	w.next++
+	w.flush()
+}
+
+// Close writes the run totals as a trailing comment
+func (w *TAPWriter) Close(tally *Tally) error {
+	if tally.Total > 0 {
+		w.fprintf("# %d successful, %d failed, %d skipped\n", tally.Successful, tally.Failed, tally.Skipped)
+	}
+	w.flush()
+	if w.err != nil {
+		return fmt.Errorf("failed to write TAP output: %w", w.err)
+	}
+	return nil
+}
+
+// tapEscape keeps a description from being read as a directive or spilling onto another line
+func tapEscape(s string) string {
+	return strings.ReplaceAll(tapComment(s), "#", `\#`)
+}
+
+// tapComment flattens text onto a single line
+func tapComment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
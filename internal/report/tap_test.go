@@ -0,0 +1,65 @@
+package report
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestTAPWriter(t *testing.T) {
+	var out strings.Builder
+	var tally Tally
+	w := NewTAPWriter(&out, 4, nil)
+
+	results := []types.GitRepo{
+		{Name: "repo-a"},
+		{Name: "repo-b", Error: errors.New("fetch failed: connection reset\nby peer")},
+		{Name: "repo-c", Error: errors.New("repository has uncommitted changes (skipped)")},
+		{Name: "issue#12"},
+	}
+	for _, result := range results {
+		tally.Add(result)
+		w.Add(result)
+	}
+	if err := w.Close(&tally); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	expected := `TAP version 13
+1..4
+ok 1 - repo-a
+not ok 2 - repo-b # fetch failed: connection reset by peer
+ok 3 - repo-c # SKIP repository has uncommitted changes (skipped)
+ok 4 - issue\#12
+# 2 successful, 1 failed, 1 skipped
+`
+	if out.String() != expected {
+		t.Errorf("TAP output:\n%s\nwant:\n%s", out.String(), expected)
+	}
+}
+
+func TestTAPWriterLabels(t *testing.T) {
+	var out strings.Builder
+	if err := NewTAPWriter(&out, 1, []string{"env=home", "host=laptop"}).Close(&Tally{}); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	expected := "TAP version 13\n1..1\n# labels: env=home host=laptop\n"
+	if out.String() != expected {
+		t.Errorf("TAP output:\n%s\nwant:\n%s", out.String(), expected)
+	}
+}
+
+func TestTAPWriterNoRepositories(t *testing.T) {
+	var out strings.Builder
+	if err := NewTAPWriter(&out, 0, nil).Close(&Tally{}); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	expected := "TAP version 13\n1..0 # SKIP no git repositories found\n"
+	if out.String() != expected {
+		t.Errorf("TAP output = %q, want %q", out.String(), expected)
+	}
+}
@@ -0,0 +1,212 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/history"
+)
+
+// Chart geometry, in SVG user units
+const (
+	chartWidth   = 720
+	chartHeight  = 200
+	chartPadding = 40
+)
+
+// categoryColors colors the failure categories in the stacked bar chart
+var categoryColors = map[string]string{
+	history.CategoryTimeout:  "#dfb317",
+	history.CategoryAuth:     "#e05d44",
+	history.CategoryNetwork:  "#007ec6",
+	history.CategoryDiverged: "#9f5fc0",
+	history.CategoryOther:    "#9f9f9f",
+}
+
+// categoryOrder stacks the failure categories bottom to top
+var categoryOrder = []string{
+	history.CategoryTimeout, history.CategoryAuth, history.CategoryNetwork,
+	history.CategoryDiverged, history.CategoryOther,
+}
+
+// trendLine is a line chart of one value per run
+type trendLine struct {
+	Title  string
+	Points string // SVG polyline points
+	Max    string // Label of the top of the y axis
+	Dots   []trendDot
+}
+
+// trendDot marks one run on a line chart, with its value as tooltip
+type trendDot struct {
+	X, Y  float64
+	Label string
+}
+
+// trendBar is one segment of the stacked failure chart
+type trendBar struct {
+	X, Y, Width, Height float64
+	Color               string
+	Label               string
+}
+
+// trendPage is what the trends template renders
+type trendPage struct {
+	Generated  string
+	Runs       int
+	First      string
+	Last       string
+	Success    trendLine
+	Duration   trendLine
+	Failures   []trendBar
+	MaxFailed  int
+	Categories []trendCategory
+}
+
+// trendCategory is a legend entry of the failure chart
+type trendCategory struct {
+	Name  string
+	Color string
+}
+
+// WriteTrends renders runs, oldest first, as a self-contained HTML page charting success rate,
+// duration and failure categories over time
+func WriteTrends(path string, runs []history.Run) error {
+	if len(runs) == 0 {
+		return fmt.Errorf("no runs in the history to chart")
+	}
+
+	page := trendPage{
+		Generated: time.Now().Format(time.RFC1123),
+		Runs:      len(runs),
+		First:     runs[0].Start.Format("2006-01-02 15:04"),
+		Last:      runs[len(runs)-1].Start.Format("2006-01-02 15:04"),
+	}
+
+	rates := make([]float64, len(runs))
+	durations := make([]float64, len(runs))
+	labels := make([]string, len(runs))
+	for i, run := range runs {
+		rates[i] = run.SuccessRate()
+		durations[i] = run.Duration.Seconds()
+		labels[i] = fmt.Sprintf("%s %s", run.Start.Format("2006-01-02 15:04"), run.Operation)
+		page.MaxFailed = max(page.MaxFailed, run.Failed)
+	}
+
+	page.Success = lineChart("Success rate", rates, 100, labels, func(v float64) string { return fmt.Sprintf("%.1f%%", v) })
+	maxDuration := max(slices.Max(durations), 1)
+	page.Duration = lineChart("Duration", durations, maxDuration, labels, func(v float64) string {
+		return time.Duration(v * float64(time.Second)).Truncate(time.Second).String()
+	})
+	page.Failures = failureBars(runs, max(page.MaxFailed, 1), labels)
+	for _, name := range categoryOrder {
+		page.Categories = append(page.Categories, trendCategory{Name: name, Color: categoryColors[name]})
+	}
+
+	var b strings.Builder
+	if err := trendsTemplate.Execute(&b, page); err != nil {
+		return fmt.Errorf("failed to render trends: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write trends: %w", err)
+	}
+	return nil
+}
+
+// runX returns the x coordinate of run i of n
+func runX(i, n int) float64 {
+	if n == 1 {
+		return chartWidth / 2
+	}
+	return chartPadding + float64(i)*float64(chartWidth-2*chartPadding)/float64(n-1)
+}
+
+// valueY returns the y coordinate of value on an axis from 0 to top
+func valueY(value, top float64) float64 {
+	return chartHeight - chartPadding - value/top*(chartHeight-2*chartPadding)
+}
+
+// lineChart plots values against an axis from 0 to top
+func lineChart(title string, values []float64, top float64, labels []string, format func(float64) string) trendLine {
+	line := trendLine{Title: title, Max: format(top)}
+	points := make([]string, len(values))
+	for i, value := range values {
+		x, y := runX(i, len(values)), valueY(value, top)
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		line.Dots = append(line.Dots, trendDot{X: x, Y: y, Label: labels[i] + ": " + format(value)})
+	}
+	line.Points = strings.Join(points, " ")
+	return line
+}
+
+// failureBars stacks each run's failures by category against an axis from 0 to top
+func failureBars(runs []history.Run, top int, labels []string) []trendBar {
+	width := float64(chartWidth-2*chartPadding) / float64(len(runs)) * 0.6
+	var bars []trendBar
+	for i, run := range runs {
+		base := 0
+		for _, category := range categoryOrder {
+			n := run.Failures[category]
+			if n == 0 {
+				continue
+			}
+			y := valueY(float64(base+n), float64(top))
+			bars = append(bars, trendBar{
+				X:      runX(i, len(runs)) - width/2,
+				Y:      y,
+				Width:  width,
+				Height: valueY(float64(base), float64(top)) - y,
+				Color:  categoryColors[category],
+				Label:  fmt.Sprintf("%s: %d %s", labels[i], n, category),
+			})
+			base += n
+		}
+	}
+	return bars
+}
+
+var trendsTemplate = template.Must(template.New("trends").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>git-herd trends</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2em; color: #24292f; }
+svg { display: block; margin-bottom: 2em; }
+.axis { stroke: #d0d7de; }
+.line { fill: none; stroke: #007ec6; stroke-width: 2; }
+.dot { fill: #007ec6; }
+.legend span { display: inline-block; margin-right: 1em; }
+.legend i { display: inline-block; width: 0.8em; height: 0.8em; margin-right: 0.3em; }
+</style>
+</head>
+<body>
+<h1>git-herd trends</h1>
+<p>{{.Runs}} runs from {{.First}} to {{.Last}}. Generated {{.Generated}}.</p>
+{{template "line" .Success}}
+{{template "line" .Duration}}
+<h2>Failures by category</h2>
+<p class="legend">{{range .Categories}}<span><i style="background: {{.Color}}"></i>{{.Name}}</span>{{end}}</p>
+<svg width="720" height="200" viewBox="0 0 720 200" role="img" aria-label="Failures by category">
+<line class="axis" x1="40" y1="160" x2="680" y2="160"/>
+<line class="axis" x1="40" y1="40" x2="40" y2="160"/>
+<text x="36" y="44" text-anchor="end" font-size="11">{{.MaxFailed}}</text>
+<text x="36" y="164" text-anchor="end" font-size="11">0</text>
+{{range .Failures}}<rect x="{{printf "%.1f" .X}}" y="{{printf "%.1f" .Y}}" width="{{printf "%.1f" .Width}}" height="{{printf "%.1f" .Height}}" fill="{{.Color}}"><title>{{.Label}}</title></rect>
+{{end}}</svg>
+</body>
+</html>
+{{define "line"}}<h2>{{.Title}}</h2>
+<svg width="720" height="200" viewBox="0 0 720 200" role="img" aria-label="{{.Title}}">
+<line class="axis" x1="40" y1="160" x2="680" y2="160"/>
+<line class="axis" x1="40" y1="40" x2="40" y2="160"/>
+<text x="36" y="44" text-anchor="end" font-size="11">{{.Max}}</text>
+<text x="36" y="164" text-anchor="end" font-size="11">0</text>
+<polyline class="line" points="{{.Points}}"/>
+{{range .Dots}}<circle class="dot" cx="{{printf "%.1f" .X}}" cy="{{printf "%.1f" .Y}}" r="3"><title>{{.Label}}</title></circle>
+{{end}}</svg>
+{{end}}`))
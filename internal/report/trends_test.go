@@ -0,0 +1,45 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestWriteTrends(t *testing.T) {
+	start := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	runs := []history.Run{
+		{Start: start, Operation: types.OperationFetch, Successful: 8, Failed: 2, Duration: 90 * time.Second,
+			Failures: map[string]int{history.CategoryAuth: 1, history.CategoryNetwork: 1}},
+		{Start: start.Add(24 * time.Hour), Operation: types.OperationFetch, Successful: 10, Duration: 30 * time.Second,
+			Failures: map[string]int{}},
+	}
+
+	path := filepath.Join(t.TempDir(), "trends.html")
+	if err := WriteTrends(path, runs); err != nil {
+		t.Fatalf("WriteTrends() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := string(data)
+	for _, want := range []string{"2 runs from 2025-01-01 03:00 to 2025-01-02 03:00", "Success rate", "80.0%", "1m30s", "1 auth", "1 network"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected the page to contain %q", want)
+		}
+	}
+	if got := strings.Count(html, "<polyline"); got != 2 {
+		t.Errorf("Expected 2 line charts, got %d", got)
+	}
+
+	if err := WriteTrends(path, nil); err == nil {
+		t.Error("Expected an error without runs to chart")
+	}
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// vscodeWorkspace is the subset of a VS Code .code-workspace file git-herd writes
+type vscodeWorkspace struct {
+	Folders  []vscodeFolder `json:"folders"`
+	Settings struct{}       `json:"settings"`
+}
+
+type vscodeFolder struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// WriteEditorFiles writes the editor workspace files the config asks for, listing repos by name
+func WriteEditorFiles(config *types.Config, repos []types.GitRepo) error {
+	repos = slices.SortedFunc(slices.Values(repos), func(a, b types.GitRepo) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Path, b.Path))
+	})
+
+	if config.VSCodeWorkspace != "" {
+		if err := writeVSCodeWorkspace(config.VSCodeWorkspace, repos); err != nil {
+			return fmt.Errorf("failed to write VS Code workspace: %w", err)
+		}
+	}
+	if config.ProjectList != "" {
+		if err := writeProjectList(config.ProjectList, repos); err != nil {
+			return fmt.Errorf("failed to write project list: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeVSCodeWorkspace writes a multi-root workspace with one folder per repository. Folder
+// paths are relative to the workspace file, so it keeps working when the whole tree moves.
+func writeVSCodeWorkspace(path string, repos []types.GitRepo) error {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	ws := vscodeWorkspace{Folders: make([]vscodeFolder, 0, len(repos))}
+	for _, repo := range repos {
+		folder, err := filepath.Abs(repo.Path)
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(dir, folder); err == nil {
+			folder = rel
+		}
+		ws.Folders = append(ws.Folders, vscodeFolder{Name: repo.Name, Path: filepath.ToSlash(folder)})
+	}
+
+	data, err := json.MarshalIndent(ws, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// writeProjectList writes one absolute repository path per line, the form JetBrains IDEs
+// (`idea <path>`), Toolbox scripts and shell loops take projects in
+func writeProjectList(path string, repos []types.GitRepo) error {
+	var b strings.Builder
+	for _, repo := range repos {
+		abs, err := filepath.Abs(repo.Path)
+		if err != nil {
+			return err
+		}
+		b.WriteString(abs + "\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestWriteEditorFiles(t *testing.T) {
+	root := t.TempDir()
+	repos := []types.GitRepo{
+		{Name: "web", Path: filepath.Join(root, "clients", "web")},
+		{Name: "acme/api", Path: filepath.Join(root, "clients", "acme", "api")},
+	}
+	cfg := &types.Config{
+		VSCodeWorkspace: filepath.Join(root, "all.code-workspace"),
+		ProjectList:     filepath.Join(root, "projects.txt"),
+	}
+
+	if err := WriteEditorFiles(cfg, repos); err != nil {
+		t.Fatalf("WriteEditorFiles() error = %v", err)
+	}
+
+	data, err := os.ReadFile(cfg.VSCodeWorkspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ws vscodeWorkspace
+	if err := json.Unmarshal(data, &ws); err != nil {
+		t.Fatalf("Workspace is not valid JSON: %v\n%s", err, data)
+	}
+	want := []vscodeFolder{
+		{Name: "acme/api", Path: "clients/acme/api"},
+		{Name: "web", Path: "clients/web"},
+	}
+	if !reflect.DeepEqual(ws.Folders, want) {
+		t.Errorf("Workspace folders = %+v, want %+v", ws.Folders, want)
+	}
+
+	list, err := os.ReadFile(cfg.ProjectList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantList := repos[1].Path + "\n" + repos[0].Path + "\n"
+	if string(list) != wantList {
+		t.Errorf("Project list = %q, want %q", list, wantList)
+	}
+}
+
+func TestWriteEditorFilesError(t *testing.T) {
+	cfg := &types.Config{VSCodeWorkspace: filepath.Join(t.TempDir(), "missing", "all.code-workspace")}
+	err := WriteEditorFiles(cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to write VS Code workspace") {
+		t.Errorf("Expected workspace write error, got %v", err)
+	}
+}
@@ -0,0 +1,322 @@
+package report
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/entro314-labs/git-herd/internal/auditlog"
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// Writer streams the detailed --save-report file: repository entries are written as results
+// arrive and the summary is appended when the run finishes, so no result has to be kept around.
+// Every entry reaches the file as soon as it is added, so a run that dies midway still leaves a
+// report of everything it finished; such a report simply has no summary section.
+type Writer struct {
+	config   *types.Config
+	file     *os.File
+	out      *bufio.Writer
+	expected int
+	err      error
+}
+
+// NewWriter creates the report file named by config.SaveReport and writes its header.
+// expected is the number of repositories the run is going to process.
+func NewWriter(config *types.Config, expected int) (*Writer, error) {
+	file, err := os.Create(config.SaveReport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report file: %w", err)
+	}
+
+	w := &Writer{config: config, file: file, out: bufio.NewWriter(file), expected: expected}
+	w.fprintf("git-herd Report - %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	w.fprintf("Operation: %s\n", config.Operation)
+	w.fprintf("Workers: %d\n", config.Workers)
+	w.fprintf("Run As: %s\n", auditlog.RunAs())
+	if labels := config.LabelPairs(); len(labels) > 0 {
+		w.fprintf("Labels: %s\n", strings.Join(labels, ", "))
+	}
+	w.fprintf("Repositories Found: %d\n", expected)
+	w.fprintf("\n")
+	w.fprintf("Repository Details:\n")
+	w.fprintf("==================\n\n")
+	w.flush()
+	return w, nil
+}
+
+// fprintf writes to the report, remembering the first error so callers can check once at Close
+func (w *Writer) fprintf(format string, a ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.out, format, a...)
+}
+
+// flush hands buffered output to the operating system so it survives the process dying
+func (w *Writer) flush() {
+	if w.err != nil {
+		return
+	}
+	w.err = w.out.Flush()
+}
+
+// Add writes one repository's entry
+func (w *Writer) Add(result types.GitRepo) {
+	w.fprintf("Repository: %s\n", result.Name)
+	w.fprintf("Path: %s\n", result.Path)
+	if result.Group != "" {
+		w.fprintf("Group: %s\n", result.Group)
+	}
+	if result.GitDir != "" {
+		w.fprintf("Git Dir: %s\n", result.GitDir)
+	}
+	if result.Worktree {
+		w.fprintf("Worktree: linked\n")
+	}
+	if result.Bare {
+		w.fprintf("Bare: yes\n")
+	}
+	if result.FetchedWith != "" {
+		w.fprintf("Fetched With: %s\n", result.FetchedWith)
+	}
+	if result.Flaky {
+		w.fprintf("Flaky: yes (alternates between success and failure across runs)\n")
+	}
+	if result.Submodules > 0 {
+		w.fprintf("Submodules: %d updated\n", result.Submodules)
+	} else if result.HasSubmodules {
+		w.fprintf("Submodules: yes\n")
+	}
+	if result.LFS {
+		w.fprintf("LFS: %s downloaded\n", FormatKiB(result.LFSKiB))
+	}
+	if shallow := ShallowLabel(result); shallow != "" {
+		w.fprintf("Shallow: %s\n", shallow)
+	}
+
+	if result.Branch != "" {
+		w.fprintf("Branch: %s\n", result.Branch)
+	}
+	if result.Branch != "" && result.Branch != "detached" {
+		w.fprintf("Upstream: %s\n", UpstreamLabel(result))
+	}
+	if result.Remote != "" {
+		w.fprintf("Remote: %s\n", result.Remote)
+	}
+	if result.RemoteURL != "" {
+		w.fprintf("Remote URL: %s\n", result.RemoteURL)
+	}
+	if others := otherRemotes(result); others != "" {
+		w.fprintf("Other Remotes: %s\n", others)
+	}
+
+	w.fprintf("Duration: %v\n", result.Duration.Truncate(time.Millisecond))
+	if timings := result.Timings.String(); timings != "" {
+		w.fprintf("Timings: %s\n", timings)
+	}
+	for _, step := range result.Steps {
+		w.fprintf("Step: %s\n", StepLine(step))
+	}
+
+	if w.config.Operation == types.OperationCheckout && result.Error == nil {
+		w.fprintf("Checkout: %s\n", CheckoutLabel(result, w.config.DryRun))
+	}
+	if w.config.Operation == types.OperationHeal && result.Error == nil {
+		w.fprintf("Heal: %s\n", HealLabel(result, w.config.DryRun))
+	}
+	if w.config.Operation == types.OperationSync && result.Error == nil {
+		w.fprintf("Sync: %s\n", SyncLabel(result, w.config.DryRun))
+	}
+	if result.PulledWith != "" {
+		w.fprintf("Pulled With: %s\n", result.PulledWith)
+	}
+	if stash := StashLabel(result, w.config.DryRun); stash != "" && result.Error == nil {
+		w.fprintf("Stash: %s\n", stash)
+	}
+	if w.config.Operation == types.OperationMaintenance && result.Error == nil {
+		w.fprintf("Maintenance: %s\n", MaintenanceLabel(result, w.config.DryRun))
+		for _, ref := range result.PrunedRefs {
+			w.fprintf("Pruned: %s\n", ref)
+		}
+	}
+	if w.config.Operation == types.OperationPruneBranches && result.Error == nil {
+		w.fprintf("Prune Branches: %s\n", PruneBranchesLabel(result, w.config.DryRun))
+		for _, branch := range result.DeletedBranches {
+			w.fprintf("Deleted: %s\n", branch)
+		}
+		for _, branch := range result.KeptBranches {
+			w.fprintf("Kept: %s (unpushed commits, use --delete-unpushed)\n", branch)
+		}
+	}
+	if w.config.Operation == types.OperationSetURL && result.Error == nil {
+		if len(result.URLRewrites) == 0 {
+			w.fprintf("Set URL: %s\n", SetURLLabel(result, w.config.DryRun))
+		}
+		for _, rewrite := range result.URLRewrites {
+			w.fprintf("Rewrite: %s\n", RewriteLabel(rewrite))
+		}
+	}
+	if w.config.Operation == types.OperationExec {
+		w.fprintf("Command: %s\n", w.config.Exec)
+		if result.Exec != nil {
+			w.fprintf("Exit Code: %d\n", result.Exec.ExitCode)
+			w.writeOutput("Stdout", result.Exec.Stdout)
+			w.writeOutput("Stderr", result.Exec.Stderr)
+			if result.Exec.Truncated {
+				w.fprintf("Output: truncated\n")
+			}
+		}
+	}
+	if w.config.Operation == types.OperationApply {
+		if result.Error == nil {
+			w.fprintf("Apply: %s\n", ApplyLabel(result, w.config.DryRun))
+		}
+		if result.Exec != nil {
+			w.writeOutput("Stdout", result.Exec.Stdout)
+			w.writeOutput("Stderr", result.Exec.Stderr)
+		}
+	}
+	if w.config.Operation == types.OperationChangelog && result.Error == nil {
+		w.fprintf("Changelog: %s\n", ChangelogLabel(result))
+	}
+	if w.config.Operation == types.OperationVersions && result.Error == nil {
+		w.fprintf("Version: %s\n", VersionLabel(result, w.config.DryRun))
+	}
+	if w.config.Operation == types.OperationReleases && result.Error == nil {
+		w.fprintf("Release: %s\n", ReleaseLabel(result, time.Now()))
+	}
+	if w.config.Operation == types.OperationClone && result.Error == nil {
+		w.fprintf("Clone: %s\n", CloneLabel(result, w.config.DryRun))
+	}
+	if w.config.Operation == types.OperationPush && result.Error == nil {
+		w.fprintf("Push: %s\n", PushLabel(result, w.config.DryRun))
+	}
+	if w.config.Operation == types.OperationStatus && result.Error == nil {
+		w.fprintf("State: %s\n", StatusLabel(result))
+	}
+	if age := DirtyAge(result, time.Now()); age != "" {
+		w.fprintf("Dirty For: %s (since %s)\n", age, result.DirtySince.Format("2006-01-02"))
+	}
+
+	if len(result.MissingFiles) > 0 {
+		w.fprintf("Missing Files: %s\n", strings.Join(result.MissingFiles, ", "))
+	}
+	if result.UserEmail != "" {
+		w.fprintf("User Email: %s\n", result.UserEmail)
+	}
+	if result.EmailIssue != "" {
+		w.fprintf("Email Issue: %s\n", result.EmailIssue)
+	}
+
+	if encryption := EncryptionLabel(result); encryption != "" {
+		w.fprintf("Encryption: %s\n", encryption)
+	}
+	for _, finding := range result.Findings {
+		w.fprintf("Finding: %s\n", finding)
+	}
+	if owners := OwnersLabel(result); owners != "" {
+		w.fprintf("Owners (last %d months): %s\n", w.config.OwnersMonths, owners)
+	}
+
+	if result.Empty {
+		w.fprintf("Status: EMPTY - no commits yet\n")
+	} else if result.Error != nil {
+		w.fprintf("Status: FAILED - %v\n", result.Error)
+		var panicErr *git.PanicError
+		if errors.As(result.Error, &panicErr) {
+			w.fprintf("Stack:\n")
+			for _, line := range strings.Split(strings.TrimRight(string(panicErr.Stack), "\n"), "\n") {
+				w.fprintf("    %s\n", line)
+			}
+		}
+	} else if w.config.DryRun {
+		w.fprintf("Status: DRY RUN - Would have succeeded\n")
+	} else {
+		w.fprintf("Status: SUCCESS\n")
+	}
+
+	w.fprintf("\n")
+	w.flush()
+}
+
+// Close appends the run summary and closes the file. A run that ends before every expected
+// repository was reported, e.g. on timeout or Ctrl+C, is marked as interrupted.
+func (w *Writer) Close(tally *Tally) (err error) {
+	defer func() {
+		err = errors.Join(err, w.file.Close())
+	}()
+
+	w.fprintf("Summary:\n")
+	w.fprintf("========\n\n")
+	if tally.Total < w.expected {
+		w.fprintf("Run Status: INTERRUPTED - %d of %d repositories reported\n", tally.Total, w.expected)
+	} else {
+		w.fprintf("Run Status: COMPLETE\n")
+	}
+	w.fprintf("Total Repositories: %d\n", tally.Total)
+	w.fprintf("Successful: %d, Failed: %d, Skipped: %d\n", tally.Successful, tally.Failed, tally.Skipped)
+	if tally.Empty > 0 {
+		w.fprintf("Empty: %d\n", tally.Empty)
+	}
+	if w.config.Operation == types.OperationPush {
+		w.fprintf("Pushed: %d\n", tally.Pushed)
+	}
+	if tally.NoUpstream > 0 {
+		w.fprintf("Without Upstream: %d\n", tally.NoUpstream)
+	}
+	if w.config.Operation.IsAudit() {
+		w.fprintf("Compliance: %d/%d (%.1f%%)\n", tally.Compliant, tally.Audited, tally.CompliancePercent())
+	}
+
+	if groups := tally.GroupSummaries(); len(groups) > 0 {
+		w.fprintf("\nGroups:\n")
+		for _, line := range groups {
+			w.fprintf("%s\n", line)
+		}
+	}
+
+	if steps := tally.StepSummaries(); len(steps) > 0 {
+		w.fprintf("\nPipeline %s:\n", w.config.Pipeline)
+		for _, line := range steps {
+			w.fprintf("%s\n", line)
+		}
+	}
+
+	if tally.Pool.Repos > 0 {
+		w.fprintf("\nWorkers: %s\n", UtilizationSummary(tally.Pool))
+		w.fprintf("%s\n", UtilizationVerdict(tally.Pool))
+		for _, line := range WorkerLines(tally.Pool) {
+			w.fprintf("%s\n", line)
+		}
+	}
+
+	if len(tally.Slowest) > 1 {
+		w.fprintf("\nSlowest Repositories:\n")
+		for _, result := range tally.Slowest {
+			w.fprintf("%s\n", SlowLine(result))
+		}
+	}
+
+	w.flush()
+	if w.err != nil {
+		return fmt.Errorf("failed to write to report file: %w", w.err)
+	}
+	return nil
+}
+
+// writeOutput writes a command's output stream, indented under its name, if there is any
+func (w *Writer) writeOutput(name, output string) {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return
+	}
+	w.fprintf("%s:\n", name)
+	for line := range strings.SplitSeq(output, "\n") {
+		w.fprintf("  %s\n", line)
+	}
+}
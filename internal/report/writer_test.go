@@ -1,17 +1,34 @@
-package tui
+package report
 
 import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/entro314-labs/git-herd/internal/config"
+	"github.com/entro314-labs/git-herd/internal/git"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
+// saveReport writes a complete report for results through a Writer, as a run would
+func saveReport(cfg *types.Config, results []types.GitRepo) error {
+	w, err := NewWriter(cfg, len(results))
+	if err != nil {
+		return err
+	}
+
+	var tally Tally
+	for _, result := range results {
+		tally.Add(result)
+		w.Add(result)
+	}
+	return w.Close(&tally)
+}
+
 func TestSaveReport(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping file I/O test in short mode")
@@ -38,13 +55,14 @@ func TestSaveReport(t *testing.T) {
 
 	results := []types.GitRepo{
 		{
-			Path:     "/test/repo1",
-			Name:     "repo1",
-			Branch:   "main",
-			Remote:   "origin",
-			Duration: 150 * time.Millisecond,
-			HasGit:   true,
-			Clean:    true,
+			Path:      "/test/repo1",
+			Name:      "repo1",
+			Branch:    "main",
+			Remote:    "origin",
+			RemoteURL: "https://github.com/test/repo1.git",
+			Duration:  150 * time.Millisecond,
+			HasGit:    true,
+			Clean:     true,
 		},
 		{
 			Path:     "/test/repo2",
@@ -54,6 +72,7 @@ func TestSaveReport(t *testing.T) {
 			Duration: 200 * time.Millisecond,
 			HasGit:   true,
 			Clean:    true,
+			Timings:  types.Timings{Analyze: 20 * time.Millisecond, Network: 180 * time.Millisecond},
 		},
 		{
 			Path:  "/test/repo3",
@@ -62,7 +81,7 @@ func TestSaveReport(t *testing.T) {
 		},
 	}
 
-	err = saveReport(cfg, results, 2, 1, 0)
+	err = saveReport(cfg, results)
 	if err != nil {
 		t.Errorf("saveReport() error = %v", err)
 	}
@@ -82,6 +101,7 @@ func TestSaveReport(t *testing.T) {
 		"Workers: 5",
 		"Total Repositories: 3",
 		"Successful: 2, Failed: 1, Skipped: 0",
+		"Slowest Repositories:\n200ms  repo2 (analyze 20ms, network 180ms)\n150ms  repo1\n",
 		"Repository Details:",
 		"==================",
 	}
@@ -98,6 +118,7 @@ func TestSaveReport(t *testing.T) {
 		"Path: /test/repo1",
 		"Branch: main",
 		"Remote: origin",
+		"Remote URL: https://github.com/test/repo1.git",
 		"Duration: 150ms",
 		"Status: SUCCESS",
 		"Repository: repo2",
@@ -105,6 +126,7 @@ func TestSaveReport(t *testing.T) {
 		"Branch: develop",
 		"Remote: upstream",
 		"Duration: 200ms",
+		"Timings: analyze 20ms, network 180ms",
 		"Status: SUCCESS",
 		"Repository: repo3",
 		"Path: /test/repo3",
@@ -150,7 +172,7 @@ func TestSaveReportDryRun(t *testing.T) {
 		},
 	}
 
-	err = saveReport(cfg, results, 1, 0, 0)
+	err = saveReport(cfg, results)
 	if err != nil {
 		t.Errorf("saveReport() error = %v", err)
 	}
@@ -199,7 +221,7 @@ func TestSaveReportWithEmptyFields(t *testing.T) {
 		},
 	}
 
-	err = saveReport(cfg, results, 1, 0, 0)
+	err = saveReport(cfg, results)
 	if err != nil {
 		t.Errorf("saveReport() error = %v", err)
 	}
@@ -243,7 +265,7 @@ func TestSaveReportCreateFileError(t *testing.T) {
 		{Path: "/test/repo1", Name: "repo1"},
 	}
 
-	err := saveReport(cfg, results, 1, 0, 0)
+	err := saveReport(cfg, results)
 	if err == nil {
 		t.Error("Expected error when creating file in invalid path")
 	}
@@ -277,7 +299,7 @@ func TestSaveReportEmptyResults(t *testing.T) {
 	// Empty results
 	results := []types.GitRepo{}
 
-	err = saveReport(cfg, results, 0, 0, 0)
+	err = saveReport(cfg, results)
 	if err != nil {
 		t.Errorf("saveReport() error = %v", err)
 	}
@@ -332,7 +354,7 @@ func TestSaveReportLargeResults(t *testing.T) {
 		}
 	}
 
-	err = saveReport(cfg, results, 1000, 0, 0)
+	err = saveReport(cfg, results)
 	if err != nil {
 		t.Errorf("saveReport() error = %v", err)
 	}
@@ -416,7 +438,7 @@ func TestSaveReportDurationFormatting(t *testing.T) {
 				},
 			}
 
-			err := saveReport(cfg, results, 1, 0, 0)
+			err := saveReport(cfg, results)
 			if err != nil {
 				t.Errorf("saveReport() error = %v", err)
 			}
@@ -495,7 +517,7 @@ func TestSaveReportErrorHandling(t *testing.T) {
 				},
 			}
 
-			err := saveReport(cfg, results, 0, 1, 0)
+			err := saveReport(cfg, results)
 			if err != nil {
 				t.Errorf("saveReport() error = %v", err)
 			}
@@ -571,7 +593,7 @@ func BenchmarkSaveReport(b *testing.B) {
 			b.Fatalf("Failed to seek file: %v", err)
 		}
 
-		err := saveReport(cfg, results, 100, 0, 0)
+		err := saveReport(cfg, results)
 		if err != nil {
 			b.Errorf("saveReport() error = %v", err)
 		}
@@ -621,9 +643,174 @@ func BenchmarkSaveReportLarge(b *testing.B) {
 			b.Fatalf("Failed to seek file: %v", err)
 		}
 
-		err := saveReport(cfg, results, 1000, 0, 0)
+		err := saveReport(cfg, results)
 		if err != nil {
 			b.Errorf("saveReport() error = %v", err)
 		}
 	}
 }
+
+func TestWriterStreamsEntriesBeforeClose(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SaveReport = filepath.Join(t.TempDir(), "report.txt")
+
+	w, err := NewWriter(cfg, 3)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	var tally Tally
+	result := types.GitRepo{Path: "/test/repo1", Name: "repo1", Branch: "main"}
+	tally.Add(result)
+	w.Add(result)
+
+	// A run that dies now must still leave the finished entries on disk
+	partial, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read partial report: %v", err)
+	}
+	for _, expected := range []string{"Repositories Found: 3", "Repository: repo1", "Status: SUCCESS"} {
+		if !strings.Contains(string(partial), expected) {
+			t.Errorf("Expected partial report to contain %q, got:\n%s", expected, partial)
+		}
+	}
+	if strings.Contains(string(partial), "Summary:") {
+		t.Error("Expected no summary before the run finishes")
+	}
+
+	if err := w.Close(&tally); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(content), "Run Status: INTERRUPTED - 1 of 3 repositories reported") {
+		t.Errorf("Expected report to be marked interrupted, got:\n%s", content)
+	}
+}
+
+func TestWriterCompleteRun(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SaveReport = filepath.Join(t.TempDir(), "report.txt")
+
+	if err := saveReport(cfg, []types.GitRepo{{Path: "/test/repo1", Name: "repo1"}}); err != nil {
+		t.Fatalf("saveReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(content), "Run Status: COMPLETE") {
+		t.Errorf("Expected report to be marked complete, got:\n%s", content)
+	}
+}
+
+func TestSaveReportPush(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Operation = types.OperationPush
+	cfg.SaveReport = filepath.Join(t.TempDir(), "report.txt")
+
+	results := []types.GitRepo{
+		{Path: "/test/ahead", Name: "ahead", Pushed: "1a2b3c4d..5e6f7a8b main -> main"},
+		{Path: "/test/current", Name: "current"},
+		{Path: "/test/diverged", Name: "diverged", Error: errors.New("diverged from origin/main, not pushing without --force-with-lease (skipped)")},
+	}
+	if err := saveReport(cfg, results); err != nil {
+		t.Fatalf("saveReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	for _, want := range []string{
+		"Push: pushed 1a2b3c4d..5e6f7a8b main -> main",
+		"Push: nothing to push",
+		"Status: FAILED - diverged from origin/main",
+		"Pushed: 1\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestSaveReportExec(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Operation = types.OperationExec
+	cfg.Exec = "make test"
+	cfg.SaveReport = filepath.Join(t.TempDir(), "report.txt")
+
+	results := []types.GitRepo{
+		{Path: "/test/api", Name: "api", Exec: &types.ExecResult{Stdout: "ok  api\n"}},
+		{Path: "/test/web", Name: "web", Exec: &types.ExecResult{ExitCode: 2, Stderr: "FAIL web\nexit 2\n"}, Error: errors.New("command exited with status 2")},
+	}
+	if err := saveReport(cfg, results); err != nil {
+		t.Fatalf("saveReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	for _, want := range []string{
+		"Command: make test",
+		"Exit Code: 0\nStdout:\n  ok  api\n",
+		"Exit Code: 2\nStderr:\n  FAIL web\n  exit 2\n",
+		"Status: FAILED - command exited with status 2",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestWriterPanicStack(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SaveReport = filepath.Join(t.TempDir(), "report.txt")
+
+	results := []types.GitRepo{{
+		Path:  "/test/broken",
+		Name:  "broken",
+		Error: &git.PanicError{Value: "nil pointer", Stack: []byte("goroutine 7 [running]:\nmain.process()\n")},
+	}}
+	if err := saveReport(cfg, results); err != nil {
+		t.Fatalf("saveReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	for _, expected := range []string{
+		"Status: FAILED - internal error: panic: nil pointer",
+		"Stack:\n    goroutine 7 [running]:\n    main.process()\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestSaveReportEmptyRepository(t *testing.T) {
+	cfg := &types.Config{Operation: types.OperationFetch, SaveReport: filepath.Join(t.TempDir(), "report.txt")}
+	results := []types.GitRepo{
+		{Name: "fresh", Path: "/test/fresh", Branch: "main", Empty: true, Error: errors.New("empty repository: no commits yet (skipped)")},
+	}
+	if err := saveReport(cfg, results); err != nil {
+		t.Fatalf("saveReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read report file: %v", err)
+	}
+	for _, expected := range []string{"Status: EMPTY - no commits yet", "Successful: 0, Failed: 0, Skipped: 1", "Empty: 1"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
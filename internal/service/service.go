@@ -0,0 +1,172 @@
+// Package service writes the user-level systemd units or launchd agent that run git-herd on
+// a schedule, so unattended syncing does not need hand-written units
+package service
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Spec describes the scheduled run a service is installed for
+type Spec struct {
+	Name     string        // Name of the units, or the last part of the launchd label
+	Interval time.Duration // Time between the start of one run and the next
+	Command  []string      // The git-herd executable and its arguments
+	Dir      string        // Working directory, where a git-herd.yaml is picked up
+}
+
+// File is a unit or agent file to install
+type File struct {
+	Path    string
+	Content string
+}
+
+// Files returns the files that install spec on goos: a systemd service and timer under dir on
+// Linux, or a launchd agent under dir on macOS. An empty dir means the user's default,
+// ~/.config/systemd/user or ~/Library/LaunchAgents.
+func Files(spec Spec, goos, dir string) ([]File, error) {
+	switch goos {
+	case "linux":
+		if dir == "" {
+			configDir, err := os.UserConfigDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(configDir, "systemd", "user")
+		}
+		return []File{
+			{Path: filepath.Join(dir, spec.Name+".service"), Content: systemdService(spec)},
+			{Path: filepath.Join(dir, spec.Name+".timer"), Content: systemdTimer(spec)},
+		}, nil
+	case "darwin":
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(home, "Library", "LaunchAgents")
+		}
+		plist, err := launchdAgent(spec)
+		if err != nil {
+			return nil, err
+		}
+		return []File{{Path: filepath.Join(dir, Label(spec.Name)+".plist"), Content: plist}}, nil
+	default:
+		return nil, fmt.Errorf("install-service supports systemd on linux and launchd on darwin, not %s", goos)
+	}
+}
+
+// Write writes the files, replacing any installed before
+func Write(files []File) error {
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0o755); err != nil {
+			return fmt.Errorf("create %s: %w", filepath.Dir(file.Path), err)
+		}
+		if err := os.WriteFile(file.Path, []byte(file.Content), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// NextSteps returns the commands that load and start the installed files on goos
+func NextSteps(spec Spec, goos string, files []File) []string {
+	if goos == "darwin" && len(files) > 0 {
+		return []string{"launchctl bootstrap gui/$(id -u) " + files[0].Path}
+	}
+	return []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable --now " + spec.Name + ".timer",
+	}
+}
+
+// Label returns the launchd label of the agent named name
+func Label(name string) string {
+	return "io.github.entro314-labs." + name
+}
+
+// systemdService returns the oneshot service the timer starts
+func systemdService(spec Spec) string {
+	args := make([]string, len(spec.Command))
+	for i, arg := range spec.Command {
+		args[i] = systemdQuote(arg)
+	}
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=git-herd scheduled run\n")
+	b.WriteString("Wants=network-online.target\n")
+	b.WriteString("After=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=oneshot\n")
+	// WorkingDirectory takes the rest of the line as the path, quotes and $ included, and only
+	// expands specifiers
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", strings.ReplaceAll(spec.Dir, "%", "%%"))
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(args, " "))
+	return b.String()
+}
+
+// systemdTimer returns the timer that starts the service shortly after boot and then every
+// interval after the last run started
+func systemdTimer(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=Run git-herd every %v\n\n", spec.Interval)
+	b.WriteString("[Timer]\n")
+	b.WriteString("OnBootSec=5min\n")
+	fmt.Fprintf(&b, "OnUnitActiveSec=%ds\n\n", int(spec.Interval.Seconds()))
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=timers.target\n")
+	return b.String()
+}
+
+// systemdQuote quotes an argument for a unit file, where % starts a specifier and $ a variable
+func systemdQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(arg) + `"`
+}
+
+// launchdAgent returns the agent's property list, which logs to ~/Library/Logs
+func launchdAgent(spec Spec) (string, error) {
+	if len(spec.Command) == 0 {
+		return "", errors.New("no command to run")
+	}
+	escape := func(s string) string {
+		var b strings.Builder
+		_ = xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	logFile := filepath.Join(home, "Library", "Logs", spec.Name+".log")
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t<string>%s</string>\n", escape(Label(spec.Name)))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range spec.Command {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", escape(arg))
+	}
+	b.WriteString("\t</array>\n")
+	fmt.Fprintf(&b, "\t<key>WorkingDirectory</key>\n\t<string>%s</string>\n", escape(spec.Dir))
+	fmt.Fprintf(&b, "\t<key>StartInterval</key>\n\t<integer>%d</integer>\n", int(spec.Interval.Seconds()))
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", escape(logFile))
+	fmt.Fprintf(&b, "\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", escape(logFile))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String(), nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSpec() Spec {
+	return Spec{
+		Name:     "git-herd",
+		Interval: time.Hour,
+		Command:  []string{"/usr/local/bin/git-herd", "--operation=pull", "--exclude=My Archive", "--plain", "/home/me/Projects"},
+		Dir:      "/home/me",
+	}
+}
+
+func TestFilesSystemd(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Files(testSpec(), "linux", dir)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	if len(files) != 2 || files[0].Path != filepath.Join(dir, "git-herd.service") || files[1].Path != filepath.Join(dir, "git-herd.timer") {
+		t.Fatalf("Expected a service and a timer in %s, got %+v", dir, files)
+	}
+
+	for _, want := range []string{
+		"Type=oneshot\n",
+		"WorkingDirectory=/home/me\n",
+		`ExecStart=/usr/local/bin/git-herd --operation=pull "--exclude=My Archive" --plain /home/me/Projects` + "\n",
+	} {
+		if !strings.Contains(files[0].Content, want) {
+			t.Errorf("Expected the service to contain %q, got:\n%s", want, files[0].Content)
+		}
+	}
+	for _, want := range []string{"OnUnitActiveSec=3600s\n", "WantedBy=timers.target\n"} {
+		if !strings.Contains(files[1].Content, want) {
+			t.Errorf("Expected the timer to contain %q, got:\n%s", want, files[1].Content)
+		}
+	}
+
+	if err := Write(files); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if data, err := os.ReadFile(files[1].Path); err != nil || string(data) != files[1].Content {
+		t.Errorf("Expected the timer to be written, got %q (%v)", data, err)
+	}
+}
+
+func TestFilesSystemdWorkingDirectory(t *testing.T) {
+	spec := testSpec()
+	spec.Dir = "/home/me/My Projects/100%"
+	files, err := Files(spec, "linux", t.TempDir())
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	if want := "WorkingDirectory=/home/me/My Projects/100%%\n"; !strings.Contains(files[0].Content, want) {
+		t.Errorf("Expected the service to contain %q, got:\n%s", want, files[0].Content)
+	}
+}
+
+func TestFilesLaunchd(t *testing.T) {
+	dir := t.TempDir()
+	spec := testSpec()
+	spec.Command = append(spec.Command, "--label=team=a&b")
+	files, err := Files(spec, "darwin", dir)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != filepath.Join(dir, "io.github.entro314-labs.git-herd.plist") {
+		t.Fatalf("Expected one agent in %s, got %+v", dir, files)
+	}
+	for _, want := range []string{
+		"<string>io.github.entro314-labs.git-herd</string>",
+		"<string>--exclude=My Archive</string>",
+		"<string>--label=team=a&amp;b</string>",
+		"<key>StartInterval</key>\n\t<integer>3600</integer>",
+	} {
+		if !strings.Contains(files[0].Content, want) {
+			t.Errorf("Expected the agent to contain %q, got:\n%s", want, files[0].Content)
+		}
+	}
+	if steps := NextSteps(spec, "darwin", files); len(steps) != 1 || !strings.HasSuffix(steps[0], files[0].Path) {
+		t.Errorf("Expected the agent to be bootstrapped, got %v", steps)
+	}
+}
+
+func TestFilesUnsupported(t *testing.T) {
+	if _, err := Files(testSpec(), "windows", t.TempDir()); err == nil {
+		t.Error("Expected windows to be unsupported")
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"--plain", "--plain"},
+		{"", `""`},
+		{"/path/with space", `"/path/with space"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"100%", "100%%"},
+		{"$HOME", "$$HOME"},
+	}
+	for _, tt := range tests {
+		if got := systemdQuote(tt.arg); got != tt.want {
+			t.Errorf("systemdQuote(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,15 @@ package tui
 
 import (
 	"context"
+	"time"
 
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/internal/report"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
@@ -16,8 +19,8 @@ type Model struct {
 	rootPath  string
 	ctx       context.Context
 	cancel    context.CancelFunc
-	scanner   *git.Scanner
-	processor *git.Processor
+	scanner   types.RepoFinder
+	processor types.RepoOperator
 
 	// UI state
 	phase     string
@@ -25,7 +28,30 @@ type Model struct {
 	progress  progress.Model
 	repos     []types.GitRepo
 	processed int
-	results   []types.GitRepo
+	results   *report.Recent // Most recent results; older ones live only in tally and the report
+	tally     report.Tally
+	flagged   []types.GitRepo // Repositories with security findings
+	width     int
+	height    int
+
+	// Checklist of the repositories found, to choose the ones to process with --select
+	checklist list.Model
+	chosen    []bool // Whether each of repos is chosen, while they are being chosen
+
+	// Streaming report file, opened when processing starts
+	reportWriter *report.Writer
+	reportErr    error
+	reportSaved  bool
+
+	// tmux session for repositories needing attention, written once processing ends
+	sessionWriter *report.SessionWriter
+	sessionErr    error
+	sessionSaved  bool
+
+	// Changelog collected from every repository, written once processing ends
+	changelogWriter *report.ChangelogWriter
+	changelogErr    error
+	changelogSaved  bool
 
 	// Status
 	scanning   bool
@@ -33,15 +59,27 @@ type Model struct {
 	done       bool
 	err        error
 	nextIndex  int
+	deadline   time.Time         // Time budget deadline, zero when no budget is set
+	pool       *report.PoolStats // How busy the workers are, from the start of processing
 }
 
+// retainedResults bounds how many results the final summary lists
+const retainedResults = 200
+
 type reposFoundMsg []types.GitRepo
 type repoProcessedMsg types.GitRepo
+type preflightDoneMsg struct{}
 type processingDoneMsg struct {
 	err error
 }
 
 func NewModel(config *types.Config, rootPath string) *Model {
+	return NewModelWith(config, rootPath, git.NewScanner(config), git.NewProcessor(config))
+}
+
+// NewModelWith creates a Model that finds repositories with finder and processes them with
+// operator, such as the fakes in herdtest
+func NewModelWith(config *types.Config, rootPath string, finder types.RepoFinder, operator types.RepoOperator) *Model {
 	ctx, cancel := context.WithCancel(context.Background())
 	if config.Timeout > 0 {
 		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
@@ -53,18 +91,25 @@ func NewModel(config *types.Config, rootPath string) *Model {
 
 	p := progress.New(progress.WithDefaultGradient())
 
+	var deadline time.Time
+	if config.Budget > 0 {
+		deadline = time.Now().Add(config.Budget)
+	}
+
 	return &Model{
 		config:    config,
 		rootPath:  rootPath,
 		ctx:       ctx,
 		cancel:    cancel,
-		scanner:   git.NewScanner(config),
-		processor: git.NewProcessor(config),
+		scanner:   finder,
+		processor: operator,
 		phase:     "initializing",
 		spinner:   s,
 		progress:  p,
+		results:   report.NewRecent(retainedResults),
 		scanning:  true,
 		nextIndex: 0,
+		deadline:  deadline,
 	}
 }
 
@@ -78,9 +123,13 @@ func (m *Model) Init() tea.Cmd {
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.phase == "selecting" {
+			return m.updateChecklist(msg)
+		}
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.cancel()
+			m.closeReport()
 			return m, tea.Quit
 		}
 
@@ -90,6 +139,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.phase == "selecting" {
+			m.checklist.SetSize(msg.Width, msg.Height-4)
+		}
 		m.progress.Width = msg.Width - 4
 		// Limit max width to avoid excessively wide progress bars
 		if m.progress.Width > 80 {
@@ -100,26 +153,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case reposFoundMsg:
 		m.repos = []types.GitRepo(msg)
 		m.scanning = false
-		m.processing = true
-		m.phase = "processing"
-		m.nextIndex = 0
 
-		if len(m.repos) == 0 {
-			m.done = true
-			m.phase = "complete"
-			return m, tea.Quit
+		// Nothing touches the network before the repositories to process are chosen
+		if m.config.Select && len(m.repos) > 0 {
+			m.phase = "selecting"
+			m.chosen = make([]bool, len(m.repos))
+			m.checklist = newChecklist(m.repos, m.chosen, m.width, m.height)
+			return m, nil
 		}
+		return m, m.startProcessing()
 
+	case preflightDoneMsg:
+		m.phase = "processing"
 		return m, m.processRepos()
 
 	case repoProcessedMsg:
-		m.results = append(m.results, types.GitRepo(msg))
+		m.recordResult(types.GitRepo(msg))
 		m.processed++
 
 		if m.processed >= len(m.repos) {
 			m.processing = false
 			m.done = true
 			m.phase = "complete"
+			m.closeReport()
 			return m, tea.Sequence(
 				tea.Printf("\n"),
 				tea.Quit,
@@ -137,31 +193,93 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.done = true
 		m.phase = "complete"
 		m.err = msg.err
+		m.closeReport()
 		return m, tea.Sequence(
 			tea.Printf("\n"),
 			tea.Quit,
 		)
 	}
 
+	// The checklist filters in the background
+	if m.phase == "selecting" {
+		var cmd tea.Cmd
+		m.checklist, cmd = m.checklist.Update(msg)
+		return m, cmd
+	}
 	return m, nil
 }
 
+// startProcessing opens the report files and starts processing the repositories found, or
+// preflighting them first
+func (m *Model) startProcessing() tea.Cmd {
+	if err := report.WriteEditorFiles(m.config, m.repos); err != nil {
+		m.done = true
+		m.phase = "complete"
+		m.err = err
+		return tea.Quit
+	}
+	m.processing = true
+	m.phase = "processing"
+	m.nextIndex = 0
+
+	if len(m.repos) == 0 {
+		m.done = true
+		m.phase = "complete"
+		return tea.Quit
+	}
+
+	// Spend a limited budget on the repositories that need it most
+	if m.config.Budget > 0 {
+		git.SortByStaleness(m.repos)
+	}
+
+	if m.config.SaveReport != "" {
+		m.reportWriter, m.reportErr = report.NewWriter(m.config, len(m.repos))
+	}
+	if m.config.TmuxSession != "" {
+		m.sessionWriter = report.NewSessionWriter(m.config.TmuxSession)
+	}
+	if m.config.Operation == types.OperationChangelog {
+		m.changelogWriter = report.NewChangelogWriter(m.config.ChangelogFile, m.config.Since, m.config.Conventional)
+	}
+
+	if m.config.Preflight {
+		m.phase = "preflight"
+		return m.preflightRepos()
+	}
+	m.phase = "processing"
+	return m.processRepos()
+}
+
 func (m *Model) scanRepos() tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		repos, err := m.scanner.FindRepos(m.ctx, m.rootPath, nil)
 		if err != nil {
 			return processingDoneMsg{err: err}
 		}
+		if m.config.SelectsByState() {
+			repos = m.processor.Select(m.ctx, repos)
+		}
 		return reposFoundMsg(repos)
 	})
 }
 
+// preflightRepos checks the remotes of every repository before processing starts. The
+// repositories that fail are failed by the processor once their turn comes.
+func (m *Model) preflightRepos() tea.Cmd {
+	return func() tea.Msg {
+		m.processor.Preflight(m.ctx, m.repos)
+		return preflightDoneMsg{}
+	}
+}
+
 func (m *Model) processRepos() tea.Cmd {
 	var cmds []tea.Cmd
 	workerCount := m.config.Workers
 	if workerCount <= 0 {
 		workerCount = 1
 	}
+	m.pool = report.NewPoolStats(workerCount)
 
 	// Launch initial batch of workers
 	for i := 0; i < workerCount && m.nextIndex < len(m.repos); i++ {
@@ -179,10 +297,74 @@ func (m *Model) processNextRepo() tea.Cmd {
 	if m.nextIndex < len(m.repos) {
 		idx := m.nextIndex
 		m.nextIndex++
+		if !m.deadline.IsZero() && time.Now().After(m.deadline) {
+			return func() tea.Msg {
+				m.pool.End(m.pool.Begin())
+				return repoProcessedMsg(git.NotAttempted(m.repos[idx]))
+			}
+		}
 		return func() tea.Msg {
+			slot := m.pool.Begin()
 			processed := m.processor.ProcessRepo(m.ctx, m.repos[idx])
+			m.pool.End(slot)
 			return repoProcessedMsg(processed)
 		}
 	}
 	return nil
 }
+
+// Outcome returns the run's tally, how many repositories were found, and the error that ended
+// the run, if any. A run quit before it finished ends with the context's error.
+func (m *Model) Outcome() (report.Tally, int, error) {
+	err := m.err
+	if err == nil && !m.done {
+		err = m.ctx.Err()
+	}
+	return m.tally, len(m.repos), err
+}
+
+// recordResult folds a finished repository into the tally, the retained results and the report
+func (m *Model) recordResult(result types.GitRepo) {
+	m.tally.Add(result)
+	m.results.Add(result)
+	if len(result.Findings) > 0 {
+		m.flagged = append(m.flagged, types.GitRepo{Name: result.Name, Path: result.Path, Findings: result.Findings})
+	}
+	if m.reportWriter != nil {
+		m.reportWriter.Add(result)
+	}
+	if m.sessionWriter != nil {
+		m.sessionWriter.Add(result)
+	}
+	if m.changelogWriter != nil {
+		m.changelogWriter.Add(result)
+	}
+}
+
+// closeReport writes the report summary, the tmux session and the changelog, and saves the run's
+// history once processing has finished
+func (m *Model) closeReport() {
+	m.tally.Pool = m.pool.Utilization()
+
+	// History only informs later runs; a failed save is not worth interrupting the summary for
+	_ = m.processor.SaveHistory()
+
+	if m.sessionWriter != nil {
+		m.sessionErr = m.sessionWriter.Close()
+		m.sessionSaved = m.sessionErr == nil
+		m.sessionWriter = nil
+	}
+
+	if m.changelogWriter != nil {
+		m.changelogErr = m.changelogWriter.Close()
+		m.changelogSaved = m.changelogErr == nil
+		m.changelogWriter = nil
+	}
+
+	if m.reportWriter == nil {
+		return
+	}
+	m.reportErr = m.reportWriter.Close(&m.tally)
+	m.reportSaved = m.reportErr == nil
+	m.reportWriter = nil
+}
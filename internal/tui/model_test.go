@@ -1,10 +1,14 @@
 package tui
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +17,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/entro314-labs/git-herd/internal/config"
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/internal/report"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
@@ -242,6 +248,65 @@ func TestModelUpdateReposFound(t *testing.T) {
 	}
 }
 
+func TestModelUpdateReposFoundSelect(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultConfig()
+	cfg.Select = true
+	model := NewModel(cfg, "/test/path")
+	model.Update(reposFoundMsg([]types.GitRepo{
+		{Path: "/test/api", Name: "api"},
+		{Path: "/test/web", Name: "web"},
+		{Path: "/test/docs", Name: "docs"},
+	}))
+	if model.phase != "selecting" || model.processing {
+		t.Fatalf("Expected the repositories to be chosen before processing, got phase %q", model.phase)
+	}
+
+	// Nothing is chosen yet, so enter does not start processing
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.phase != "selecting" {
+		t.Fatalf("Expected enter with nothing chosen to keep the checklist, got phase %q", model.phase)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if !slices.Equal(model.chosen, []bool{true, true, true}) {
+		t.Errorf("Expected a to choose every repository, got %v", model.chosen)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if !slices.Equal(model.chosen, []bool{false, false, false}) {
+		t.Errorf("Expected a again to clear every repository, got %v", model.chosen)
+	}
+
+	// q goes to the filter while one is typed
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if model.ctx.Err() != nil {
+		t.Fatal("Expected q typed into the filter not to quit")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if !strings.Contains(model.View(), "enter process 1") {
+		t.Errorf("Expected the checklist to count one chosen repository, got:\n%s", model.View())
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.phase != "processing" || cmd == nil {
+		t.Fatalf("Expected enter to start processing, got phase %q", model.phase)
+	}
+	var names []string
+	for _, repo := range model.repos {
+		names = append(names, repo.Name)
+	}
+	if !slices.Equal(names, []string{"api", "docs"}) {
+		t.Errorf("Expected only the chosen repositories to be processed, got %v", names)
+	}
+}
+
 func TestModelUpdateReposFoundEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -294,8 +359,8 @@ func TestModelUpdateRepoProcessed(t *testing.T) {
 	updatedModel := newModel.(*Model)
 
 	// Check that results were updated
-	if len(updatedModel.results) != 1 {
-		t.Errorf("Expected 1 result, got %d", len(updatedModel.results))
+	if updatedModel.results.Len() != 1 {
+		t.Errorf("Expected 1 result, got %d", updatedModel.results.Len())
 	}
 
 	if updatedModel.processed != 1 {
@@ -521,6 +586,32 @@ func TestModelProcessNextRepoComplete(t *testing.T) {
 	}
 }
 
+func TestModelProcessNextRepoBudgetExhausted(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultConfig()
+	cfg.Budget = time.Minute
+	model := NewModel(cfg, "/test/path")
+	model.deadline = time.Now().Add(-time.Second)
+
+	model.repos = []types.GitRepo{
+		{Path: "/test/repo1", Name: "repo1", HasGit: true},
+	}
+
+	cmd := model.processNextRepo()
+	if cmd == nil {
+		t.Fatal("Expected processNextRepo to return a command")
+	}
+
+	msg, ok := cmd().(repoProcessedMsg)
+	if !ok {
+		t.Fatalf("Expected repoProcessedMsg, got %T", msg)
+	}
+	if !errors.Is(msg.Error, git.ErrBudgetExhausted) {
+		t.Errorf("Expected repo to be marked not attempted, got %v", msg.Error)
+	}
+}
+
 func TestModelMessageTypes(t *testing.T) {
 	t.Parallel()
 
@@ -671,7 +762,35 @@ func BenchmarkRepoProcessedUpdate(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		// Reset processed count to avoid completing
 		model.processed = 0
-		model.results = model.results[:0]
+		model.tally = report.Tally{}
 		_, _ = model.Update(repoMsg)
 	}
 }
+
+func TestModelQuitFinalizesReport(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SaveReport = filepath.Join(t.TempDir(), "report.txt")
+	model := NewModel(cfg, "/test/path")
+
+	model.Update(reposFoundMsg([]types.GitRepo{
+		{Path: "/test/repo1", Name: "repo1"},
+		{Path: "/test/repo2", Name: "repo2"},
+	}))
+	model.Update(repoProcessedMsg(types.GitRepo{Path: "/test/repo1", Name: "repo1"}))
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	content, err := os.ReadFile(cfg.SaveReport)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	for _, expected := range []string{"Repository: repo1", "Run Status: INTERRUPTED - 1 of 2 repositories reported"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, content)
+		}
+	}
+
+	tally, found, err := model.Outcome()
+	if tally.Total != 1 || found != 2 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Outcome() = %d of %d, %v; want 1 of 2 and context.Canceled", tally.Total, found, err)
+	}
+}
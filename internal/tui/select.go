@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// repoItem is a repository in the checklist shown with --select
+type repoItem struct {
+	repo   types.GitRepo
+	chosen *bool
+}
+
+// FilterValue matches the filter against the name and the path of the repository
+func (i repoItem) FilterValue() string {
+	return i.repo.Name + " " + i.repo.Path
+}
+
+// repoDelegate renders a repository of the checklist on one line, with its checkbox
+type repoDelegate struct{}
+
+func (repoDelegate) Height() int                         { return 1 }
+func (repoDelegate) Spacing() int                        { return 0 }
+func (repoDelegate) Update(tea.Msg, *list.Model) tea.Cmd { return nil }
+
+func (repoDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	repo, ok := item.(repoItem)
+	if !ok {
+		return
+	}
+	cursor, box := "  ", "[ ]"
+	if *repo.chosen {
+		box = successStyle.Render("[x]")
+	}
+	line := fmt.Sprintf("%s %s", box, repo.repo.Name)
+	if repo.repo.Branch != "" {
+		line += fmt.Sprintf(" [%s]", repo.repo.Branch)
+	}
+	line += " " + infoStyle.Render(repo.repo.Path)
+	if index == m.Index() {
+		cursor = spinnerStyle.Render("> ")
+	}
+	fmt.Fprint(w, cursor+line)
+}
+
+// newChecklist lists the repositories to choose from, none of them chosen yet
+func newChecklist(repos []types.GitRepo, chosen []bool, width, height int) list.Model {
+	items := make([]list.Item, len(repos))
+	for i, repo := range repos {
+		items[i] = repoItem{repo: repo, chosen: &chosen[i]}
+	}
+	if width <= 0 || height <= 0 {
+		width, height = 80, 20
+	}
+	l := list.New(items, repoDelegate{}, width, height-4)
+	l.Title = "Choose the repositories to process"
+	l.Styles.Title = titleStyle
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("repository", "repositories")
+	l.DisableQuitKeybindings()
+	return l
+}
+
+// updateChecklist handles a key while the repositories are being chosen: space toggles the
+// repository under the cursor, a toggles every repository the filter shows, and enter processes
+// the chosen ones. While a filter is typed, keys go to the filter.
+func (m *Model) updateChecklist(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		m.cancel()
+		return m, tea.Quit
+	}
+	if m.checklist.SettingFilter() {
+		var cmd tea.Cmd
+		m.checklist, cmd = m.checklist.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		m.cancel()
+		return m, tea.Quit
+	case " ":
+		if item, ok := m.checklist.SelectedItem().(repoItem); ok {
+			*item.chosen = !*item.chosen
+		}
+		return m, nil
+	case "a":
+		visible := m.checklist.VisibleItems()
+		all := true
+		for _, item := range visible {
+			all = all && *item.(repoItem).chosen
+		}
+		for _, item := range visible {
+			*item.(repoItem).chosen = !all
+		}
+		return m, nil
+	case "enter":
+		var repos []types.GitRepo
+		for i, repo := range m.repos {
+			if m.chosen[i] {
+				repos = append(repos, repo)
+			}
+		}
+		if len(repos) == 0 {
+			return m, m.checklist.NewStatusMessage("Choose at least one repository: space, or a for all")
+		}
+		m.repos, m.chosen = repos, nil
+		return m, m.startProcessing()
+	}
+
+	var cmd tea.Cmd
+	m.checklist, cmd = m.checklist.Update(msg)
+	return m, cmd
+}
+
+// checklistView renders the checklist with the keys that drive it
+func (m *Model) checklistView() string {
+	count := 0
+	for _, chosen := range m.chosen {
+		if chosen {
+			count++
+		}
+	}
+	keys := []string{"space toggle", "a all", "/ filter", fmt.Sprintf("enter process %d", count), "q quit"}
+	return m.checklist.View() + "\n\n" + infoStyle.Render(strings.Join(keys, " • "))
+}
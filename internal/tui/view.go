@@ -8,6 +8,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/internal/report"
+	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
 var (
@@ -46,6 +50,9 @@ func (m *Model) View() string {
 	if m.done {
 		return m.renderSummary()
 	}
+	if m.phase == "selecting" {
+		return m.checklistView()
+	}
 
 	var content strings.Builder
 
@@ -62,6 +69,11 @@ func (m *Model) View() string {
 			m.spinner.View(),
 			infoStyle.Render(m.rootPath)))
 
+	case "preflight":
+		content.WriteString(fmt.Sprintf("%s Checking the remotes of %s repositories\n",
+			m.spinner.View(),
+			infoStyle.Render(fmt.Sprintf("%d", len(m.repos)))))
+
 	case "processing":
 		if len(m.repos) > 0 {
 			percent := float64(m.processed) / float64(len(m.repos))
@@ -71,13 +83,12 @@ func (m *Model) View() string {
 			content.WriteString("\n\n")
 
 			// Show recent results
-			start := 0
-			if len(m.results) > 3 {
-				start = len(m.results) - 3
+			recent := m.results.Items()
+			if len(recent) > 3 {
+				recent = recent[len(recent)-3:]
 			}
 
-			for i := start; i < len(m.results); i++ {
-				result := m.results[i]
+			for _, result := range recent {
 				if result.Error != nil {
 					content.WriteString(fmt.Sprintf("%s %s: %s\n",
 						errorStyle.Render("✗"),
@@ -89,7 +100,7 @@ func (m *Model) View() string {
 						successStyle.Render("✓"),
 						result.Name,
 						result.Branch,
-						result.Remote,
+						report.RemoteLabel(result),
 						duration))
 				}
 			}
@@ -110,6 +121,10 @@ func (m *Model) renderSummary() string {
 	if len(m.repos) == 0 {
 		content.WriteString(titleStyle.Render("git-herd"))
 		content.WriteString("\n\n")
+		if label := report.SelectionLabel(m.config); label != "" {
+			content.WriteString(infoStyle.Render(fmt.Sprintf("No Git repositories in %s matched %s (%d discovered)", m.rootPath, label, m.scanner.Discovered())))
+			return content.String()
+		}
 		content.WriteString(infoStyle.Render(fmt.Sprintf("No Git repositories found in %s", m.rootPath)))
 		return content.String()
 	}
@@ -119,13 +134,14 @@ func (m *Model) renderSummary() string {
 	content.WriteString("\n\n")
 
 	// Results
-	successful := 0
-	failed := 0
+	if hidden := m.tally.Total - m.results.Len(); hidden > 0 {
+		content.WriteString(infoStyle.Render(fmt.Sprintf("… %d earlier repositories not shown", hidden)))
+		content.WriteString("\n")
+	}
 
-	for _, result := range m.results {
+	for _, result := range m.results.Items() {
 		if result.Error != nil {
-			failed++
-			if strings.Contains(result.Error.Error(), "skipped") {
+			if report.IsSkipped(result) {
 				content.WriteString(fmt.Sprintf("%s %s (%s): %s\n",
 					infoStyle.Render("⊝"),
 					result.Name,
@@ -139,51 +155,244 @@ func (m *Model) renderSummary() string {
 					result.Error.Error()))
 			}
 		} else {
-			successful++
 			status := "✓"
 			if m.config.DryRun {
 				status = "👁"
 			}
 			duration := result.Duration.Truncate(time.Millisecond)
-			content.WriteString(fmt.Sprintf("%s %s (%s) [%s@%s] - %v\n",
+			content.WriteString(fmt.Sprintf("%s %s (%s) [%s@%s] - %v%s\n",
 				successStyle.Render(status),
 				result.Name,
 				result.Path,
 				result.Branch,
-				result.Remote,
-				duration))
+				report.RemoteLabel(result),
+				duration,
+				m.resultSuffix(result)))
 		}
 	}
 
-	// Count skipped separately from failed
-	skipped := 0
-	actualFailed := 0
-	for _, result := range m.results {
-		if result.Error != nil {
-			if strings.Contains(result.Error.Error(), "skipped") {
-				skipped++
-			} else {
-				actualFailed++
-			}
+	// Summary box
+	summaryText := fmt.Sprintf("📊 Summary: %s successful, %s failed, %s skipped, %s total",
+		successStyle.Render(fmt.Sprintf("%d", m.tally.Successful)),
+		errorStyle.Render(fmt.Sprintf("%d", m.tally.Failed)),
+		infoStyle.Render(fmt.Sprintf("%d", m.tally.Skipped)),
+		infoStyle.Render(fmt.Sprintf("%d", m.tally.Total)))
+
+	if label := report.SelectionLabel(m.config); label != "" {
+		summaryText += "\n🔎 " + infoStyle.Render(report.FilterSummary(len(m.repos), m.scanner.Discovered())) + " (" + label + ")"
+	}
+	for _, line := range m.tally.GroupSummaries() {
+		summaryText += "\n📁 " + infoStyle.Render(line)
+	}
+	if steps := m.tally.StepSummaries(); len(steps) > 0 {
+		summaryText += "\n🪜 Pipeline " + m.config.Pipeline + ":"
+		for _, line := range steps {
+			summaryText += "\n   " + infoStyle.Render(line)
 		}
 	}
 
-	// Summary box
-	summaryText := fmt.Sprintf("📊 Summary: %s successful, %s failed, %s skipped, %s total",
-		successStyle.Render(fmt.Sprintf("%d", successful)),
-		errorStyle.Render(fmt.Sprintf("%d", actualFailed)),
-		infoStyle.Render(fmt.Sprintf("%d", skipped)),
-		infoStyle.Render(fmt.Sprintf("%d", len(m.results))))
+	if m.config.Operation == types.OperationPush {
+		summaryText += fmt.Sprintf("\n⬆️  %s repositories pushed", successStyle.Render(fmt.Sprintf("%d", m.tally.Pushed)))
+	}
+
+	if m.config.Operation == types.OperationMaintenance {
+		summaryText += fmt.Sprintf("\n🧹 %s stale branches pruned, %s reclaimed",
+			infoStyle.Render(fmt.Sprintf("%d", m.tally.PrunedRefs)), infoStyle.Render(report.FormatKiB(m.tally.ReclaimedKiB)))
+	}
+
+	if m.config.Operation == types.OperationPruneBranches {
+		summaryText += "\n🌿 " + infoStyle.Render(report.DeletedSummary(m.tally.Deleted, m.config.DryRun))
+	}
+
+	if m.config.LFS && !m.config.DryRun {
+		summaryText += fmt.Sprintf("\n🗄️  %s of Git LFS objects downloaded", infoStyle.Render(report.FormatKiB(m.tally.LFSKiB)))
+	}
+
+	if m.config.Operation == types.OperationSetURL {
+		summaryText += "\n🔗 " + infoStyle.Render(report.RewrittenSummary(m.tally.Rewritten, m.config.DryRun))
+	}
+
+	if m.config.Operation == types.OperationApply && !m.config.DryRun {
+		summaryText += "\n📝 " + infoStyle.Render(report.AppliedSummary(m.tally.Applied, m.tally.AppliedPush))
+	}
+	if m.config.Operation == types.OperationChangelog {
+		summaryText += "\n📰 " + infoStyle.Render(report.ChangelogSummary(m.tally.Commits, m.tally.Changed)+" since "+m.config.Since)
+	}
+
+	if m.config.Preflight {
+		summaryText += fmt.Sprintf("\n🛫 %s remotes failed preflight", errorStyle.Render(fmt.Sprintf("%d", m.tally.Unreachable)))
+	}
+	if m.tally.Untrusted > 0 || m.tally.Trusted > 0 {
+		summaryText += "\n🔒 " + infoStyle.Render(report.OwnershipSummary(m.tally.Untrusted, m.tally.Trusted, m.config.DryRun))
+	}
+
+	if m.config.Operation == types.OperationVersions {
+		summaryText += "\n📌 " + infoStyle.Render(report.VersionsSummary(m.tally.Outdated, m.tally.TagsChecked, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationReleases {
+		summaryText += "\n🏷️  " + infoStyle.Render(report.ReleasesSummary(m.tally.Unreleased, m.tally.Overdue, m.config.UnreleasedDays))
+	}
+	if m.config.Operation == types.OperationHeal {
+		summaryText += "\n🩹 " + infoStyle.Render(report.HealedSummary(m.tally.Healed, m.config.DryRun))
+	}
+
+	if m.config.Operation == types.OperationSync && !m.config.DryRun {
+		summaryText += fmt.Sprintf("\n🔄 %s default branches updated", infoStyle.Render(fmt.Sprintf("%d", m.tally.Synced)))
+	}
+
+	if m.tally.Stashed > 0 {
+		summaryText += fmt.Sprintf("\n📦 %s repositories had uncommitted changes stashed", infoStyle.Render(fmt.Sprintf("%d", m.tally.Stashed)))
+	}
+
+	if m.tally.NotAttempted > 0 {
+		summaryText += fmt.Sprintf("\n⏱️  Time budget of %v exhausted: %s repositories not attempted",
+			m.config.Budget, infoStyle.Render(fmt.Sprintf("%d", m.tally.NotAttempted)))
+	}
+
+	if m.config.Operation.IsAudit() {
+		summaryText += fmt.Sprintf("\n📋 Compliance: %s/%d repositories (%.1f%%)",
+			successStyle.Render(fmt.Sprintf("%d", m.tally.Compliant)), m.tally.Audited, m.tally.CompliancePercent())
+	}
+
+	if m.tally.NoUpstream > 0 {
+		summaryText += fmt.Sprintf("\n🔗 %s repositories have no upstream for their current branch (use --set-upstream)",
+			infoStyle.Render(fmt.Sprintf("%d", m.tally.NoUpstream)))
+	}
+
+	if len(m.tally.Slowest) > 1 {
+		summaryText += "\n\n🐢 Slowest repositories:"
+		for _, result := range m.tally.Slowest {
+			summaryText += "\n  " + report.SlowLine(result)
+		}
+	}
+
+	if m.tally.Pool.Repos > 0 {
+		summaryText += "\n\n⚙️  Workers: " + infoStyle.Render(report.UtilizationSummary(m.tally.Pool))
+		summaryText += "\n  " + report.UtilizationVerdict(m.tally.Pool)
+	}
+
+	if len(m.tally.Flaky) > 0 {
+		summaryText += "\n\n🎲 Flaky repositories (alternating between success and failure in recent runs):"
+		for _, result := range m.tally.Flaky {
+			summaryText += "\n  " + report.FlakyLine(result)
+		}
+	}
 
 	content.WriteString("\n")
 	content.WriteString(summaryStyle.Render(summaryText))
 
-	// Save report if requested
-	if m.config.SaveReport != "" {
-		if err := saveReport(m.config, m.results, successful, actualFailed, skipped); err == nil {
-			content.WriteString(fmt.Sprintf("\n📄 Detailed report saved to: %s", m.config.SaveReport))
+	for _, result := range m.flagged {
+		content.WriteString(fmt.Sprintf("\n%s %s (%s)", errorStyle.Render("⚠"), result.Name, result.Path))
+		for _, finding := range result.Findings {
+			content.WriteString(fmt.Sprintf("\n   - %s", finding))
 		}
 	}
 
+	// The report is written while processing; mention it once it has been finalized
+	if m.reportSaved {
+		content.WriteString(fmt.Sprintf("\n📄 Detailed report saved to: %s", m.config.SaveReport))
+	} else if m.reportErr != nil {
+		content.WriteString(fmt.Sprintf("\n%s Error saving report: %v", errorStyle.Render("✗"), m.reportErr))
+	}
+	if m.changelogSaved {
+		content.WriteString(fmt.Sprintf("\n📰 Changelog written to: %s", m.config.ChangelogFile))
+	} else if m.changelogErr != nil {
+		content.WriteString(fmt.Sprintf("\n%s Error writing changelog: %v", errorStyle.Render("✗"), m.changelogErr))
+	}
+	if m.sessionSaved {
+		content.WriteString(fmt.Sprintf("\n🖥️  tmux session saved to: %s (tmuxp load %s)", m.config.TmuxSession, m.config.TmuxSession))
+	} else if m.sessionErr != nil {
+		content.WriteString(fmt.Sprintf("\n%s Error writing tmux session: %v", errorStyle.Render("✗"), m.sessionErr))
+	}
+	if err := m.processor.AuditErr(); err != nil {
+		content.WriteString(fmt.Sprintf("\n%s Error writing audit log: %v", errorStyle.Render("✗"), err))
+	}
+
 	return content.String()
 }
+
+// resultSuffix describes what the operation did to a repository, after the steps of the
+// pipeline that ran on it
+func (m *Model) resultSuffix(result types.GitRepo) string {
+	if steps := report.StepsLabel(result); steps != "" {
+		return " - " + infoStyle.Render(steps) + m.operationSuffix(result)
+	}
+	return m.operationSuffix(result)
+}
+
+// operationSuffix describes a repository's compliance for audit results, its branch and working
+// tree for status results, the worktree whose fetch it shared, what was pulled, pushed, synced,
+// cloned, checked out, stashed or cleaned up for the operations doing so, how the command
+// exited for exec results, and how long it has been dirty for scan results
+func (m *Model) operationSuffix(result types.GitRepo) string {
+	if result.FetchedWith != "" {
+		return " - " + infoStyle.Render("fetched along with "+result.FetchedWith)
+	}
+	if m.config.Operation == types.OperationPull {
+		if pull := report.PullLabel(result, m.config.DryRun); pull != "" {
+			return " - " + infoStyle.Render(pull)
+		}
+		return ""
+	}
+	if stash := report.StashLabel(result, m.config.DryRun); stash != "" {
+		return " - " + infoStyle.Render(stash)
+	}
+	if m.config.Operation == types.OperationStash {
+		return " - " + infoStyle.Render("nothing to stash")
+	}
+	if m.config.Operation == types.OperationMaintenance {
+		return " - " + infoStyle.Render(report.MaintenanceLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationPruneBranches {
+		return " - " + infoStyle.Render(report.PruneBranchesLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationSetURL {
+		return " - " + infoStyle.Render(report.SetURLLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationExec {
+		return " - " + infoStyle.Render(report.ExecLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationApply {
+		return " - " + infoStyle.Render(report.ApplyLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationChangelog {
+		return " - " + infoStyle.Render(report.ChangelogLabel(result))
+	}
+	if m.config.Operation == types.OperationReleases {
+		return " - " + infoStyle.Render(report.ReleaseLabel(result, time.Now()))
+	}
+	if m.config.Operation == types.OperationVersions {
+		return " - " + infoStyle.Render(report.VersionLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationCheckout {
+		return " - " + infoStyle.Render(report.CheckoutLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationSync {
+		return " - " + infoStyle.Render(report.SyncLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationHeal {
+		return " - " + infoStyle.Render(report.HealLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationClone {
+		return " - " + infoStyle.Render(report.CloneLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationPush {
+		return " - " + infoStyle.Render(report.PushLabel(result, m.config.DryRun))
+	}
+	if m.config.Operation == types.OperationStatus {
+		return " - " + infoStyle.Render(report.StatusLabel(result))
+	}
+	if m.config.Operation == types.OperationScan {
+		if age := report.DirtyAge(result, time.Now()); age != "" {
+			return " - " + infoStyle.Render("dirty for "+age)
+		}
+		return ""
+	}
+	if !m.config.Operation.IsAudit() {
+		return ""
+	}
+	if issue := git.AuditIssue(result); issue != "" {
+		return " - " + errorStyle.Render(issue) + report.OwnersNote(result)
+	}
+	return " - " + successStyle.Render("compliant")
+}
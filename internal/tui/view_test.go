@@ -55,7 +55,7 @@ func TestModelView(t *testing.T) {
 					{Path: "/test/repo2", Name: "repo2"},
 				}
 				m.processed = 1
-				m.results = []types.GitRepo{
+				recordResults(m, []types.GitRepo{
 					{
 						Path:     "/test/repo1",
 						Name:     "repo1",
@@ -63,7 +63,7 @@ func TestModelView(t *testing.T) {
 						Remote:   "origin",
 						Duration: 150 * time.Millisecond,
 					},
-				}
+				})
 			},
 			expectContains: []string{
 				"Processing repositories",
@@ -82,13 +82,13 @@ func TestModelView(t *testing.T) {
 					{Path: "/test/repo1", Name: "repo1"},
 				}
 				m.processed = 1
-				m.results = []types.GitRepo{
+				recordResults(m, []types.GitRepo{
 					{
 						Path:  "/test/repo1",
 						Name:  "repo1",
 						Error: &testError{msg: "operation failed"},
 					},
-				}
+				})
 			},
 			expectContains: []string{
 				"Processing repositories",
@@ -153,7 +153,7 @@ func TestModelRenderSummary(t *testing.T) {
 			name: "no repositories found",
 			setupModel: func(m *Model) {
 				m.repos = []types.GitRepo{}
-				m.results = []types.GitRepo{}
+				recordResults(m, []types.GitRepo{})
 			},
 			expectContains: []string{
 				"git-herd",
@@ -168,7 +168,7 @@ func TestModelRenderSummary(t *testing.T) {
 					{Path: "/test/repo1", Name: "repo1"},
 					{Path: "/test/repo2", Name: "repo2"},
 				}
-				m.results = []types.GitRepo{
+				recordResults(m, []types.GitRepo{
 					{
 						Path:     "/test/repo1",
 						Name:     "repo1",
@@ -183,7 +183,7 @@ func TestModelRenderSummary(t *testing.T) {
 						Remote:   "origin",
 						Duration: 200 * time.Millisecond,
 					},
-				}
+				})
 			},
 			expectContains: []string{
 				"🎉 git-herd Results",
@@ -210,7 +210,7 @@ func TestModelRenderSummary(t *testing.T) {
 					{Path: "/test/repo1", Name: "repo1"},
 					{Path: "/test/repo2", Name: "repo2"},
 				}
-				m.results = []types.GitRepo{
+				recordResults(m, []types.GitRepo{
 					{
 						Path:     "/test/repo1",
 						Name:     "repo1",
@@ -223,7 +223,7 @@ func TestModelRenderSummary(t *testing.T) {
 						Name:  "repo2",
 						Error: &testError{msg: "operation failed"},
 					},
-				}
+				})
 			},
 			expectContains: []string{
 				"✓",
@@ -243,7 +243,7 @@ func TestModelRenderSummary(t *testing.T) {
 					{Path: "/test/repo1", Name: "repo1"},
 					{Path: "/test/repo2", Name: "repo2"},
 				}
-				m.results = []types.GitRepo{
+				recordResults(m, []types.GitRepo{
 					{
 						Path:     "/test/repo1",
 						Name:     "repo1",
@@ -256,7 +256,7 @@ func TestModelRenderSummary(t *testing.T) {
 						Name:  "repo2",
 						Error: &testError{msg: "skipped: dirty working directory"},
 					},
-				}
+				})
 			},
 			expectContains: []string{
 				"✓",
@@ -276,7 +276,7 @@ func TestModelRenderSummary(t *testing.T) {
 				m.repos = []types.GitRepo{
 					{Path: "/test/repo1", Name: "repo1"},
 				}
-				m.results = []types.GitRepo{
+				recordResults(m, []types.GitRepo{
 					{
 						Path:     "/test/repo1",
 						Name:     "repo1",
@@ -284,7 +284,7 @@ func TestModelRenderSummary(t *testing.T) {
 						Remote:   "origin",
 						Duration: 150 * time.Millisecond,
 					},
-				}
+				})
 			},
 			expectContains: []string{
 				"👁", // Dry run icon
@@ -338,18 +338,29 @@ func TestModelRenderSummaryWithReport(t *testing.T) {
 	cfg.SaveReport = tmpFile.Name()
 
 	model := NewModel(cfg, "/test/path")
-	model.done = true
-	model.repos = []types.GitRepo{
+	model.Update(reposFoundMsg([]types.GitRepo{
 		{Path: "/test/repo1", Name: "repo1"},
+	}))
+	model.Update(repoProcessedMsg(types.GitRepo{
+		Path:     "/test/repo1",
+		Name:     "repo1",
+		Branch:   "main",
+		Remote:   "origin",
+		Duration: 150 * time.Millisecond,
+	}))
+
+	if !model.done {
+		t.Fatal("Expected model to be done after its only repository was processed")
 	}
-	model.results = []types.GitRepo{
-		{
-			Path:     "/test/repo1",
-			Name:     "repo1",
-			Branch:   "main",
-			Remote:   "origin",
-			Duration: 150 * time.Millisecond,
-		},
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read report file: %v", err)
+	}
+	for _, expected := range []string{"Repository: repo1", "Total Repositories: 1", "Successful: 1, Failed: 0, Skipped: 0"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, content)
+		}
 	}
 
 	summary := model.renderSummary()
@@ -436,13 +447,13 @@ func TestViewRecentResults(t *testing.T) {
 	model.repos = make([]types.GitRepo, 5)
 
 	// Add more than 3 results to test the "recent results" limiting
-	model.results = []types.GitRepo{
+	recordResults(model, []types.GitRepo{
 		{Name: "repo1", Branch: "main", Remote: "origin", Duration: 100 * time.Millisecond},
 		{Name: "repo2", Branch: "main", Remote: "origin", Duration: 150 * time.Millisecond},
 		{Name: "repo3", Branch: "main", Remote: "origin", Duration: 200 * time.Millisecond},
 		{Name: "repo4", Branch: "main", Remote: "origin", Duration: 250 * time.Millisecond},
 		{Name: "repo5", Branch: "main", Remote: "origin", Duration: 300 * time.Millisecond},
-	}
+	})
 
 	view := model.View()
 
@@ -509,7 +520,7 @@ func TestViewEmptyStates(t *testing.T) {
 
 	// Test view with repos but no results yet
 	model.repos = []types.GitRepo{{Path: "/test", Name: "test"}}
-	model.results = []types.GitRepo{}
+	recordResults(model, []types.GitRepo{})
 
 	view = model.View()
 	if !strings.Contains(view, "Processing repositories") {
@@ -517,6 +528,13 @@ func TestViewEmptyStates(t *testing.T) {
 	}
 }
 
+// recordResults feeds results to the model the way finished repositories arrive
+func recordResults(m *Model, results []types.GitRepo) {
+	for _, result := range results {
+		m.recordResult(result)
+	}
+}
+
 // Test helper for error interface
 type testError struct {
 	msg string
@@ -535,14 +553,13 @@ func BenchmarkModelView(b *testing.B) {
 	// Set up a typical processing state
 	model.phase = "processing"
 	model.repos = make([]types.GitRepo, 10)
-	model.results = make([]types.GitRepo, 5)
 	for i := 0; i < 5; i++ {
-		model.results[i] = types.GitRepo{
+		model.recordResult(types.GitRepo{
 			Name:     fmt.Sprintf("repo%d", i),
 			Branch:   "main",
 			Remote:   "origin",
 			Duration: time.Duration(i*100) * time.Millisecond,
-		}
+		})
 	}
 	model.processed = 5
 
@@ -560,15 +577,14 @@ func BenchmarkModelRenderSummary(b *testing.B) {
 	// Set up results for summary
 	model.done = true
 	model.repos = make([]types.GitRepo, 100)
-	model.results = make([]types.GitRepo, 100)
 	for i := 0; i < 100; i++ {
-		model.results[i] = types.GitRepo{
+		model.recordResult(types.GitRepo{
 			Path:     fmt.Sprintf("/test/repo%d", i),
 			Name:     fmt.Sprintf("repo%d", i),
 			Branch:   "main",
 			Remote:   "origin",
 			Duration: time.Duration(i*10) * time.Millisecond,
-		}
+		})
 	}
 
 	b.ResetTimer()
@@ -576,3 +592,33 @@ func BenchmarkModelRenderSummary(b *testing.B) {
 		_ = model.renderSummary()
 	}
 }
+
+func TestModelRenderSummaryBoundsRetainedResults(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultConfig()
+	model := NewModel(cfg, "/test/path")
+	defer model.cancel()
+
+	total := retainedResults + 50
+	model.repos = make([]types.GitRepo, total)
+	for i := 0; i < total; i++ {
+		model.recordResult(types.GitRepo{Path: fmt.Sprintf("/test/repo%d", i), Name: fmt.Sprintf("repo%d", i)})
+	}
+	model.done = true
+
+	summary := model.renderSummary()
+
+	if model.results.Len() != retainedResults {
+		t.Errorf("Expected %d retained results, got %d", retainedResults, model.results.Len())
+	}
+	if !strings.Contains(summary, "50 earlier repositories not shown") {
+		t.Error("Expected summary to note the results that are no longer held")
+	}
+	if strings.Contains(summary, "/test/repo0)") {
+		t.Error("Expected the oldest result to have been evicted")
+	}
+	if !strings.Contains(summary, fmt.Sprintf("%d total", total)) {
+		t.Errorf("Expected summary to count all %d repositories", total)
+	}
+}
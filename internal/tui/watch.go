@@ -0,0 +1,339 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/internal/report"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// WatchModel keeps the repositories below a directory fresh for git-herd watch: every interval
+// it scans the directory again and runs the operation, a status with --fetch-first, on every
+// repository found, and shows the latest outcome of each in a table that stays on screen
+// between rounds
+type WatchModel struct {
+	config      *types.Config
+	rootPath    string
+	interval    time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	finder      types.RepoFinder
+	newOperator func() types.RepoOperator // A fresh operator each round, so its history groups by round
+
+	spinner spinner.Model
+	table   table.Model
+
+	// Latest outcome of each repository, by path, and the paths in the order of the last scan
+	latest map[string]watchEntry
+	order  []string
+
+	// The round in progress, or the last one
+	round      int
+	refreshing bool
+	operator   types.RepoOperator
+	results    chan types.GitRepo
+	processed  int
+	total      int
+	finished   time.Time // When the last round finished
+	next       time.Time // When the next round starts
+	err        error     // Why the last round's scan failed
+}
+
+// watchEntry is the latest outcome of a repository and when it came
+type watchEntry struct {
+	repo    types.GitRepo
+	checked time.Time
+}
+
+type watchRoundMsg struct{ after int } // The interval after round after is over
+type watchScanMsg struct {
+	repos []types.GitRepo
+	err   error
+}
+type watchResultMsg types.GitRepo
+type watchDoneMsg struct{}
+
+// NewWatchModel creates a WatchModel running a round every interval
+func NewWatchModel(config *types.Config, rootPath string, interval time.Duration) *WatchModel {
+	return NewWatchModelWith(config, rootPath, interval, git.NewScanner(config), func() types.RepoOperator {
+		return git.NewProcessor(config)
+	})
+}
+
+// NewWatchModelWith creates a WatchModel that finds repositories with finder and processes them
+// with an operator newOperator creates for each round, such as the fakes in herdtest
+func NewWatchModelWith(config *types.Config, rootPath string, interval time.Duration, finder types.RepoFinder, newOperator func() types.RepoOperator) *WatchModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = spinnerStyle
+
+	t := table.New(table.WithFocused(true), table.WithHeight(20))
+	styles := table.DefaultStyles()
+	styles.Selected = styles.Selected.Foreground(lipgloss.Color("#01FAC6")).Bold(false)
+	t.SetStyles(styles)
+
+	m := &WatchModel{
+		config:      config,
+		rootPath:    rootPath,
+		interval:    interval,
+		ctx:         ctx,
+		cancel:      cancel,
+		finder:      finder,
+		newOperator: newOperator,
+		spinner:     s,
+		table:       t,
+		latest:      make(map[string]watchEntry),
+	}
+	m.resize(80, 24)
+	return m
+}
+
+// Stop cancels the round in progress, e.g. once the program running the model is killed
+func (m *WatchModel) Stop() {
+	m.cancel()
+}
+
+func (m *WatchModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.startRound())
+}
+
+func (m *WatchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			m.cancel()
+			return m, tea.Quit
+		case "r":
+			if m.refreshing {
+				return m, nil
+			}
+			return m, m.startRound()
+		}
+		var cmd tea.Cmd
+		m.table, cmd = m.table.Update(msg)
+		return m, cmd
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+		return m, nil
+
+	case watchRoundMsg:
+		// A refresh asked for in the meantime already took this round's place
+		if msg.after != m.round || m.refreshing {
+			return m, nil
+		}
+		return m, m.startRound()
+
+	case watchScanMsg:
+		m.err = msg.err
+		if msg.err != nil {
+			return m, m.endRound()
+		}
+		m.order = m.order[:0]
+		seen := make(map[string]bool, len(msg.repos))
+		for _, repo := range msg.repos {
+			m.order = append(m.order, repo.Path)
+			seen[repo.Path] = true
+		}
+		// Repositories gone since the last scan leave the table
+		for path := range m.latest {
+			if !seen[path] {
+				delete(m.latest, path)
+			}
+		}
+		m.total = len(msg.repos)
+		m.refreshRows()
+		return m, m.processRepos(msg.repos)
+
+	case watchResultMsg:
+		m.latest[msg.Path] = watchEntry{repo: types.GitRepo(msg), checked: time.Now()}
+		m.processed++
+		m.refreshRows()
+		return m, m.nextResult()
+
+	case watchDoneMsg:
+		return m, m.endRound()
+	}
+
+	return m, nil
+}
+
+// startRound scans the directory again for the next round
+func (m *WatchModel) startRound() tea.Cmd {
+	m.round++
+	m.refreshing = true
+	m.processed, m.total = 0, 0
+	m.operator = m.newOperator()
+	operator, ctx := m.operator, m.ctx
+	return func() tea.Msg {
+		repos, err := m.finder.FindRepos(ctx, m.rootPath, nil)
+		if err == nil && m.config.SelectsByState() {
+			repos = operator.Select(ctx, repos)
+		}
+		return watchScanMsg{repos: repos, err: err}
+	}
+}
+
+// processRepos runs the operation on the repositories of the round, --workers at a time and
+// within --timeout, with their results arriving one at a time
+func (m *WatchModel) processRepos(repos []types.GitRepo) tea.Cmd {
+	ctx, cancel := m.ctx, context.CancelFunc(func() {})
+	if m.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, m.config.Timeout)
+	}
+	m.results = make(chan types.GitRepo)
+	results, operator := m.results, m.operator
+	go func() {
+		defer cancel()
+		defer close(results)
+		g := new(errgroup.Group)
+		g.SetLimit(max(m.config.Workers, 1))
+		for _, repo := range repos {
+			g.Go(func() error {
+				result := operator.ProcessRepo(ctx, repo)
+				select {
+				case results <- result:
+				case <-m.ctx.Done():
+				}
+				return nil
+			})
+		}
+		_ = g.Wait()
+	}()
+	return m.nextResult()
+}
+
+// nextResult waits for the next result of the round
+func (m *WatchModel) nextResult() tea.Cmd {
+	results := m.results
+	return func() tea.Msg {
+		result, ok := <-results
+		if !ok {
+			return watchDoneMsg{}
+		}
+		return watchResultMsg(result)
+	}
+}
+
+// endRound saves the round's history and schedules the next round
+func (m *WatchModel) endRound() tea.Cmd {
+	// History only informs later runs; a failed save is not worth interrupting the watch for
+	_ = m.operator.SaveHistory()
+	m.refreshing = false
+	m.finished = time.Now()
+	m.next = m.finished.Add(m.interval)
+	round := m.round
+	return tea.Tick(m.interval, func(time.Time) tea.Msg { return watchRoundMsg{after: round} })
+}
+
+// resize fits the table to the terminal
+func (m *WatchModel) resize(width, height int) {
+	status := max(width-24-16-10-8, 20)
+	m.table.SetColumns([]table.Column{
+		{Title: "Repository", Width: 24},
+		{Title: "Branch", Width: 16},
+		{Title: "Checked", Width: 10},
+		{Title: "Status", Width: status},
+	})
+	m.table.SetHeight(max(height-8, 3))
+	m.refreshRows()
+}
+
+// refreshRows shows the latest outcome of every repository of the last scan
+func (m *WatchModel) refreshRows() {
+	rows := make([]table.Row, 0, len(m.order))
+	for _, path := range m.order {
+		entry, ok := m.latest[path]
+		if !ok {
+			rows = append(rows, table.Row{watchName(m.rootPath, path), "", "", "…"})
+			continue
+		}
+		rows = append(rows, table.Row{entry.repo.Name, entry.repo.Branch, entry.checked.Format(time.TimeOnly), watchStatus(entry.repo)})
+	}
+	m.table.SetRows(rows)
+}
+
+// watchName names a repository not processed yet by its path below the root
+func watchName(rootPath, path string) string {
+	if rel, ok := strings.CutPrefix(path, rootPath); ok && rel != "" {
+		return strings.TrimLeft(rel, `/\`)
+	}
+	return path
+}
+
+// watchStatus describes the outcome of a repository in the table, e.g. "✓ behind 3, dirty
+// (2 files)" or "✗ fetch failed: authentication required"
+func watchStatus(repo types.GitRepo) string {
+	switch {
+	case repo.Error == nil:
+		return "✓ " + report.StatusLabel(repo)
+	case report.IsSkipped(repo):
+		return "⊝ " + repo.Error.Error()
+	default:
+		return "✗ " + repo.Error.Error()
+	}
+}
+
+// counts tallies the latest outcomes: repositories behind their upstream, with uncommitted
+// changes, and failed
+func (m *WatchModel) counts() (behind, dirty, failed int) {
+	for _, path := range m.order {
+		entry, ok := m.latest[path]
+		switch {
+		case !ok:
+		case entry.repo.Error != nil && !report.IsSkipped(entry.repo):
+			failed++
+		default:
+			if entry.repo.Behind > 0 {
+				behind++
+			}
+			if len(entry.repo.ModifiedFiles) > 0 {
+				dirty++
+			}
+		}
+	}
+	return behind, dirty, failed
+}
+
+func (m *WatchModel) View() string {
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("git-herd watch - " + m.rootPath))
+	content.WriteString("\n\n")
+
+	if m.refreshing {
+		content.WriteString(fmt.Sprintf("%s Round %d: refreshing %s\n", m.spinner.View(), m.round,
+			statusStyle.Render(fmt.Sprintf("(%d/%d)", m.processed, m.total))))
+	} else {
+		content.WriteString(fmt.Sprintf("Round %d done at %s, next at %s\n", m.round,
+			m.finished.Format(time.TimeOnly), m.next.Format(time.TimeOnly)))
+	}
+	if m.err != nil {
+		content.WriteString(errorStyle.Render("Scan failed: "+m.err.Error()) + "\n")
+	}
+	behind, dirty, failed := m.counts()
+	content.WriteString(infoStyle.Render(fmt.Sprintf("%d repositories: %d behind, %d dirty, %d failed", len(m.order), behind, dirty, failed)))
+	content.WriteString("\n\n")
+
+	content.WriteString(m.table.View())
+	content.WriteString("\n\n")
+	content.WriteString(infoStyle.Render(strings.Join([]string{"↑/↓ scroll", "r refresh now", "q quit"}, " • ")))
+	return content.String()
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/entro314-labs/git-herd/pkg/herdtest"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// runWatchRound runs a round of model to its end, like the program would, returning the
+// command scheduling the next one
+func runWatchRound(t *testing.T, model *WatchModel, start tea.Cmd) tea.Cmd {
+	t.Helper()
+	msg := start()
+	for range 100 {
+		_, cmd := model.Update(msg)
+		if _, done := msg.(watchDoneMsg); done {
+			return cmd
+		}
+		if _, failed := msg.(watchScanMsg); failed && model.err != nil {
+			return cmd
+		}
+		msg = cmd()
+	}
+	t.Fatal("Round did not end")
+	return nil
+}
+
+func TestWatchModel(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"api/.git/HEAD": {},
+		"web/.git/HEAD": {},
+	}
+	finder := &herdtest.Finder{FS: fsys}
+	var operators []*herdtest.Operator
+	newOperator := func() types.RepoOperator {
+		operator := &herdtest.Operator{
+			Process: func(_ context.Context, repo types.GitRepo) types.GitRepo {
+				if repo.Name == "web" {
+					repo.Error = errors.New("fetch failed: authentication required")
+					return repo
+				}
+				repo.Branch, repo.Upstream, repo.Behind = "main", "origin/main", 2
+				return repo
+			},
+		}
+		operators = append(operators, operator)
+		return operator
+	}
+	config := &types.Config{Workers: 2, Operation: types.OperationStatus, FetchFirst: true}
+	model := NewWatchModelWith(config, "/work", 15*time.Minute, finder, newOperator)
+	defer model.Stop()
+
+	runWatchRound(t, model, model.startRound())
+	if model.refreshing || model.round != 1 || model.processed != 2 {
+		t.Fatalf("Expected round 1 done with 2 repositories, got round %d, refreshing %v, %d processed", model.round, model.refreshing, model.processed)
+	}
+	if operators[0].Saved() != 1 {
+		t.Errorf("Expected the round's history saved once, got %d", operators[0].Saved())
+	}
+	rows := model.table.Rows()
+	if len(rows) != 2 || rows[0][0] != "api" || rows[0][1] != "main" || rows[0][3] != "✓ behind 2" || !strings.HasPrefix(rows[1][3], "✗ fetch failed") {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+	if behind, dirty, failed := model.counts(); behind != 1 || dirty != 0 || failed != 1 {
+		t.Errorf("Expected 1 behind, 0 dirty and 1 failed, got %d, %d and %d", behind, dirty, failed)
+	}
+	if view := model.View(); !strings.Contains(view, "Round 1 done") || !strings.Contains(view, "2 repositories: 1 behind") {
+		t.Errorf("Unexpected view:\n%s", view)
+	}
+
+	// A refresh replaces the scheduled round, which is then ignored; a repository gone since the
+	// last scan leaves the table
+	delete(fsys, "web/.git/HEAD")
+	_, refresh := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if refresh == nil || !model.refreshing || model.round != 2 {
+		t.Fatalf("Expected r to start round 2, got round %d", model.round)
+	}
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd != nil || model.round != 2 {
+		t.Error("Expected r to be ignored while a round is in progress")
+	}
+	if _, cmd := model.Update(watchRoundMsg{after: 1}); cmd != nil || model.round != 2 {
+		t.Error("Expected the round scheduled after round 1 to be ignored")
+	}
+	runWatchRound(t, model, refresh)
+	if rows := model.table.Rows(); len(rows) != 1 || rows[0][0] != "api" {
+		t.Errorf("Expected only api left, got %v", rows)
+	}
+	if len(operators) != 2 || operators[1].Saved() != 1 {
+		t.Errorf("Expected a fresh operator for round 2, got %d", len(operators))
+	}
+
+	// A failed scan keeps the watch going
+	finder.Err = errors.New("walk interrupted")
+	runWatchRound(t, model, model.startRound())
+	if model.refreshing || !strings.Contains(model.View(), "Scan failed: walk interrupted") {
+		t.Errorf("Expected the failed scan shown, got:\n%s", model.View())
+	}
+
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil || model.ctx.Err() == nil {
+		t.Error("Expected q to stop the watch and quit")
+	}
+}
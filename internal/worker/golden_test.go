@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entro314-labs/git-herd/internal/auditlog"
+	"github.com/entro314-labs/git-herd/internal/console"
+	"github.com/entro314-labs/git-herd/internal/fixtures"
+	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata with the output of the tests")
+
+// dropTimings removes the lines of output that depend on how fast the run went: the slowest
+// repositories, which come in order of their duration, and how busy the workers were
+func dropTimings(output string) string {
+	var kept []string
+	slowest := false
+	for line := range strings.SplitSeq(output, "\n") {
+		switch {
+		case strings.Contains(line, "Slowest"):
+			slowest = true
+		case slowest && line == "":
+			slowest = false
+		case slowest, strings.Contains(line, "busy"), strings.Contains(line, "raising --workers"):
+		default:
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// normalizeSummary keeps the fields of a summary file that stay the same from run to run
+func normalizeSummary(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var summary map[string]any
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Invalid summary JSON: %v\n%s", err, data)
+	}
+	for _, key := range []string{"workers", "started_at", "duration_seconds", "run_as"} {
+		delete(summary, key)
+	}
+	normalized, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(normalized, '\n')
+}
+
+// TestGolden runs operations end to end over a herd of fixture repositories, one of each kind,
+// and compares the plain output, the summary file and the report with the golden files in
+// testdata/golden. Run it with -update to rewrite them after a deliberate change of output.
+func TestGolden(t *testing.T) {
+	fixtures.RequireGit(t)
+
+	tests := []struct {
+		name   string
+		config types.Config
+	}{
+		{"status", types.Config{Operation: types.OperationStatus}},
+		{"fetch", types.Config{Operation: types.OperationFetch}},
+		{"pull-dry-run", types.Config{Operation: types.OperationPull, DryRun: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, upstreams := fixtures.Herd(t)
+			dir := t.TempDir()
+
+			config := tt.config
+			config.Workers = 1 // Results and logs in the order of the scan
+			config.Remote = "origin"
+			config.PlainMode = true
+			config.SummaryFile = filepath.Join(dir, "summary.json")
+			config.SaveReport = filepath.Join(dir, "report.txt")
+
+			var buf bytes.Buffer
+			printer := console.New(&buf)
+			manager := New(&config)
+			manager.out, manager.log = printer, printer
+			manager.logger = slog.New(slog.NewTextHandler(printer, nil))
+			manager.processor.(*git.Processor).SetPrinter(printer)
+
+			if err := manager.Execute(t.Context(), root); err != nil {
+				buf.WriteString("error: " + err.Error() + "\n")
+			}
+
+			normalize := func(output string) []byte {
+				output = strings.ReplaceAll(output, auditlog.RunAs(), "$USER")
+				return []byte(dropTimings(fixtures.Normalize(output, root, upstreams, dir)))
+			}
+			golden := filepath.Join("testdata", "golden", tt.name)
+			fixtures.Golden(t, golden+".txt", normalize(buf.String()), *update)
+
+			summary, err := os.ReadFile(config.SummaryFile)
+			if err != nil {
+				t.Fatalf("Expected a summary file: %v", err)
+			}
+			fixtures.Golden(t, golden+".summary.json", normalizeSummary(t, summary), *update)
+
+			report, err := os.ReadFile(config.SaveReport)
+			if err != nil {
+				t.Fatalf("Expected a report: %v", err)
+			}
+			fixtures.Golden(t, golden+".report.txt", normalize(string(report)), *update)
+		})
+	}
+}
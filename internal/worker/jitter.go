@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// JitterDelay derives a start delay in [0, limit) from seed. The same seed always gets the same
+// delay, so a machine keeps its slot across runs while different machines spread out.
+func JitterDelay(seed string, limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(seed))
+	return time.Duration(h.Sum64() % uint64(limit))
+}
+
+// jitterSeed returns the configured seed, falling back to the hostname
+func jitterSeed(config *types.Config) string {
+	if config.JitterSeed != "" {
+		return config.JitterSeed
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return hostname
+}
+
+// WaitForJitter sleeps for this machine's jitter delay before a run starts, so machines sharing
+// a schedule don't hit the forge at the same moment. It returns early if ctx is cancelled.
+func WaitForJitter(ctx context.Context, config *types.Config) error {
+	delay := JitterDelay(jitterSeed(config), config.Jitter)
+	if delay <= 0 {
+		return nil
+	}
+
+	if config.PlainMode || config.Verbose {
+		_, log := printers(config)
+		log.Printf("⏳ Waiting %v (jitter) before starting...\n", delay.Truncate(time.Second))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestJitterDelay(t *testing.T) {
+	limit := 5 * time.Minute
+
+	first := JitterDelay("build-01", limit)
+	if again := JitterDelay("build-01", limit); again != first {
+		t.Errorf("Expected the same delay for the same seed, got %v and %v", first, again)
+	}
+	if first < 0 || first >= limit {
+		t.Errorf("Expected delay in [0, %v), got %v", limit, first)
+	}
+
+	distinct := map[time.Duration]bool{}
+	for _, host := range []string{"build-01", "build-02", "build-03", "build-04"} {
+		distinct[JitterDelay(host, limit)] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("Expected different hosts to get different delays")
+	}
+
+	if delay := JitterDelay("build-01", 0); delay != 0 {
+		t.Errorf("Expected no delay without jitter, got %v", delay)
+	}
+}
+
+func TestWaitForJitter(t *testing.T) {
+	if err := WaitForJitter(context.Background(), &types.Config{}); err != nil {
+		t.Errorf("Expected no wait without jitter, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	config := &types.Config{Jitter: time.Hour, JitterSeed: "build-01"}
+	if err := WaitForJitter(ctx, config); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation to end the wait, got %v", err)
+	}
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/entro314-labs/git-herd/internal/console"
 	"github.com/entro314-labs/git-herd/internal/git"
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/internal/issues"
+	"github.com/entro314-labs/git-herd/internal/report"
 	"github.com/entro314-labs/git-herd/internal/tui"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
@@ -20,35 +24,112 @@ import (
 // Manager handles bulk git operations with worker pools
 type Manager struct {
 	config    *types.Config
+	out       io.Writer // Human-readable results; stderr when stdout carries machine-readable results
+	log       io.Writer // Progress messages and logs, which --log-dest can keep apart from the results
 	logger    *slog.Logger
-	scanner   *git.Scanner
-	processor *git.Processor
+	scanner   types.RepoFinder
+	processor types.RepoOperator
+	deadline  time.Time         // Time budget deadline, zero when no budget is set
+	pool      *report.PoolStats // How busy the workers are, from the start of processing
+
+	// Outcome of the run, for the summary file
+	found int
+	tally report.Tally
 }
 
 // New creates a new Manager instance
 func New(config *types.Config) *Manager {
+	return NewWith(config, git.NewScanner(config), git.NewProcessor(config))
+}
+
+// NewWith creates a Manager that finds repositories with finder and processes them with
+// operator, such as the fakes in herdtest
+func NewWith(config *types.Config, finder types.RepoFinder, operator types.RepoOperator) *Manager {
 	level := slog.LevelInfo
 	if config.Verbose {
 		level = slog.LevelDebug
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	// Logs, progress messages from the workers and results all go through printers, so their
+	// lines never interleave however many repositories are processed at once
+	out, log := printers(config)
+
+	handler := slog.NewTextHandler(log, &slog.HandlerOptions{
 		Level: level,
 	})
 
+	if p, ok := operator.(interface{ SetPrinter(*console.Printer) }); ok {
+		p.SetPrinter(log)
+	}
+
 	return &Manager{
 		config:    config,
+		out:       out,
+		log:       log,
 		logger:    slog.New(handler),
-		scanner:   git.NewScanner(config),
-		processor: git.NewProcessor(config),
+		scanner:   finder,
+		processor: operator,
 	}
 }
 
-// Execute runs the bulk git operation
-func (m *Manager) Execute(ctx context.Context, rootPath string) error {
-	// Use TUI if not in plain mode and not verbose (TUI doesn't work well with verbose logging)
-	if !m.config.PlainMode && !m.config.Verbose {
-		model := tui.NewModel(m.config, rootPath)
+// printers returns the printer for results and the one for progress messages and logs. Both
+// are the printer of the stream they write to, so writes to the same stream stay serialized.
+func printers(config *types.Config) (out, log *console.Printer) {
+	out = console.Stdout
+	if config.Output == types.OutputTAP {
+		out = console.Stderr
+	}
+
+	switch config.LogDest {
+	case types.LogDestStdout:
+		return out, console.Stdout
+	case types.LogDestStderr:
+		return out, console.Stderr
+	default:
+		return out, out
+	}
+}
+
+// Execute runs the bulk git operation. With a summary file or badge configured, they are written
+// however the run ends, and with an issue repository the persistent failures are filed once
+// the run has been recorded in the history. With --notify-dry-run, report files go to a
+// temporary directory and issues are only printed.
+func (m *Manager) Execute(ctx context.Context, rootPath string) (err error) {
+	// Fewer workers that finish beat many that run out of file descriptors halfway through
+	if message := git.CapWorkers(m.config); message != "" {
+		fmt.Fprintf(m.log, "⚠️  %s\n", message)
+	}
+	if m.config.NotifyDryRun {
+		dir, err := os.MkdirTemp("", "git-herd-preview-")
+		if err != nil {
+			return fmt.Errorf("failed to create preview directory: %w", err)
+		}
+		for _, moved := range report.RedirectOutputs(m.config, dir) {
+			fmt.Fprintf(m.log, "🧪 Preview: %s\n", moved)
+		}
+	}
+	if m.config.SummaryFile != "" {
+		start := time.Now()
+		defer func() {
+			summary := report.NewSummary(m.config, &m.tally, m.found, start, err)
+			err = errors.Join(err, report.WriteSummary(m.config.SummaryFile, summary))
+		}()
+	}
+	if m.config.Badge != "" {
+		defer func() {
+			err = errors.Join(err, report.WriteBadge(m.config.Badge, report.NewBadge(&m.tally, m.found, err)))
+		}()
+	}
+	if m.config.IssueRepo != "" && (!m.config.DryRun || m.config.NotifyDryRun) {
+		defer func() {
+			err = errors.Join(err, m.fileIssues(ctx))
+		}()
+	}
+
+	// Use TUI if not in plain mode and not verbose (TUI doesn't work well with verbose logging),
+	// and never when stdout carries TAP
+	if !m.config.PlainMode && !m.config.Verbose && m.config.Output != types.OutputTAP {
+		model := tui.NewModelWith(m.config, rootPath, m.scanner, m.processor)
 		p := tea.NewProgram(model)
 
 		if _, err := p.Run(); err != nil {
@@ -56,7 +137,8 @@ func (m *Manager) Execute(ctx context.Context, rootPath string) error {
 			m.logger.Error("TUI failed, falling back to plain mode", "error", err)
 			return m.executeInPlainMode(ctx, rootPath)
 		}
-		return nil
+		m.tally, m.found, err = model.Outcome()
+		return err
 	}
 
 	return m.executeInPlainMode(ctx, rootPath)
@@ -64,6 +146,10 @@ func (m *Manager) Execute(ctx context.Context, rootPath string) error {
 
 // executeInPlainMode runs the operation with plain text output
 func (m *Manager) executeInPlainMode(ctx context.Context, rootPath string) error {
+	if m.config.Budget > 0 {
+		m.deadline = time.Now().Add(m.config.Budget)
+	}
+
 	m.logger.InfoContext(ctx, "Starting bulk git operation",
 		"operation", m.config.Operation,
 		"path", rootPath,
@@ -71,155 +157,380 @@ func (m *Manager) executeInPlainMode(ctx context.Context, rootPath string) error
 
 	// Find all git repositories
 	if m.config.PlainMode || m.config.Verbose {
-		fmt.Printf("🔍 Scanning for Git repositories in %s...\n", rootPath)
+		fmt.Fprintf(m.log, "🔍 Scanning for Git repositories in %s...\n", rootPath)
 	}
 
 	repos, err := m.scanner.FindRepos(ctx, rootPath, func(count int) {
 		if (m.config.PlainMode || m.config.Verbose) && count%10 == 0 {
-			fmt.Printf("   Found %d repositories so far...\n", count)
+			fmt.Fprintf(m.log, "   Found %d repositories so far...\n", count)
 		}
 	})
 	if err != nil {
 		return fmt.Errorf("failed to find repositories: %w", err)
 	}
 
-	if m.config.PlainMode || m.config.Verbose {
-		fmt.Printf("✅ Scan complete: found %d Git repositories\n", len(repos))
+	discovered := m.scanner.Discovered()
+	if indexed := m.scanner.Indexed(); !indexed.IsZero() && (m.config.PlainMode || m.config.Verbose) {
+		fmt.Fprintf(m.log, "✅ Found %d Git repositories in the index of %s (--refresh to walk again)\n", discovered, indexed.Format(time.DateTime))
+	} else if m.config.PlainMode || m.config.Verbose {
+		fmt.Fprintf(m.log, "✅ Scan complete: found %d Git repositories\n", discovered)
+	}
+	if m.config.SelectsByState() {
+		if m.config.PlainMode || m.config.Verbose {
+			fmt.Fprintf(m.log, "🔎 Checking the state of %d repositories...\n", len(repos))
+		}
+		repos = m.processor.Select(ctx, repos)
+	}
+	if label := report.SelectionLabel(m.config); label != "" && (m.config.PlainMode || m.config.Verbose) {
+		fmt.Fprintf(m.log, "🔎 %s: %s\n", label, report.FilterSummary(len(repos), discovered))
+	}
+	m.found = len(repos)
+
+	if err := report.WriteEditorFiles(m.config, repos); err != nil {
+		return err
 	}
 
 	if len(repos) == 0 {
 		m.logger.InfoContext(ctx, "No git repositories found")
+		if m.config.Output == types.OutputTAP {
+			return report.NewTAPWriter(os.Stdout, 0, m.config.LabelPairs()).Close(&m.tally)
+		}
 		return nil
 	}
 
 	m.logger.InfoContext(ctx, "Found repositories", "count", len(repos))
 
+	// Spend a limited budget on the repositories that need it most
+	if m.config.Budget > 0 {
+		git.SortByStaleness(repos)
+	}
+
+	if m.config.Preflight {
+		m.preflight(ctx, repos)
+	}
+
 	// Process repositories concurrently
-	return m.processReposConcurrently(ctx, repos)
+	return m.processReposConcurrently(ctx, rootPath, repos)
+}
+
+// preflight checks the remotes of every repository before any is processed, listing those that
+// failed up front; the processor fails them again once their turn comes
+func (m *Manager) preflight(ctx context.Context, repos []types.GitRepo) {
+	show := m.config.PlainMode || m.config.Verbose
+	if show {
+		fmt.Fprintf(m.log, "🛫 Preflight: checking the remotes of %d repositories...\n", len(repos))
+	}
+	failed := m.processor.Preflight(ctx, repos)
+	if !show {
+		return
+	}
+	for _, repo := range failed {
+		fmt.Fprintf(m.log, "   ❌ %s: %v\n", repo.Name, repo.Error)
+	}
+	fmt.Fprintf(m.log, "🛫 Preflight: %d of %d remotes failed\n", len(failed), len(repos))
 }
 
 // processReposConcurrently processes repositories using worker pools
-func (m *Manager) processReposConcurrently(ctx context.Context, repos []types.GitRepo) error {
+func (m *Manager) processReposConcurrently(ctx context.Context, rootPath string, repos []types.GitRepo) error {
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(m.config.Workers)
 
-	resultChan := make(chan types.GitRepo, len(repos))
-
-	// Start workers
-	for _, repo := range repos {
-		repo := repo // capture loop variable
-		g.Go(func() error {
-			processedRepo := m.processor.ProcessRepo(ctx, repo)
-			select {
-			case resultChan <- processedRepo:
-				return nil
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		})
-	}
+	resultChan := make(chan types.GitRepo, m.config.Workers)
+	m.pool = report.NewPoolStats(m.config.Workers)
 
-	// Start result collector
+	// Start workers alongside the result collector: starting one waits for a free worker, and
+	// a worker is only free once its result is collected
 	go func() {
 		defer close(resultChan)
+		for _, repo := range repos {
+			g.Go(func() error {
+				var processedRepo types.GitRepo
+				slot := m.pool.Begin()
+				if !m.deadline.IsZero() && time.Now().After(m.deadline) {
+					processedRepo = git.NotAttempted(repo)
+				} else {
+					processedRepo = m.processor.ProcessRepo(ctx, repo)
+				}
+				m.pool.End(slot)
+				var panicErr *git.PanicError
+				if errors.As(processedRepo.Error, &panicErr) {
+					m.logger.ErrorContext(ctx, "Recovered panic while processing repository",
+						"path", repo.Path, "panic", panicErr.Value, "stack", string(panicErr.Stack))
+				}
+				select {
+				case resultChan <- processedRepo:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}
 		if err := g.Wait(); err != nil {
 			m.logger.Error("Worker group failed", "error", err)
 		}
 	}()
 
 	// Collect and display results
-	return m.displayResults(ctx, resultChan, len(repos))
+	return m.displayResults(ctx, resultChan, rootPath, len(repos))
 }
 
-// displayResults shows the results of the operations
-func (m *Manager) displayResults(ctx context.Context, resultChan <-chan types.GitRepo, total int) error {
-	var successful, failed, skipped int
-	var allResults []types.GitRepo
+// condensedCount is how many results the condensed view shows from each end of the run
+const condensedCount = 5
+
+// displayResults shows the results of the operations. Results are streamed to the report
+// writers as they arrive; only counts, the results shown in the condensed view, and
+// repositories with security findings are kept in memory.
+func (m *Manager) displayResults(ctx context.Context, resultChan <-chan types.GitRepo, rootPath string, total int) error {
+	var first []types.GitRepo
+	last := report.NewRecent(condensedCount)
+	var flagged []types.GitRepo
+
+	var reportWriter *report.Writer
+	var reportErr error
+	if m.config.SaveReport != "" {
+		reportWriter, reportErr = report.NewWriter(m.config, total)
+	}
+
+	var exportWriter *report.MarkdownWriter
+	var exportErr error
+	if m.config.ExportScan != "" {
+		exportWriter, exportErr = report.NewMarkdownWriter(m.config.ExportScan, rootPath)
+	}
+
+	var sessionWriter *report.SessionWriter
+	if m.config.TmuxSession != "" {
+		sessionWriter = report.NewSessionWriter(m.config.TmuxSession)
+	}
+
+	var changelogWriter *report.ChangelogWriter
+	if m.config.Operation == types.OperationChangelog {
+		changelogWriter = report.NewChangelogWriter(m.config.ChangelogFile, m.config.Since, m.config.Conventional)
+	}
+
+	var tapWriter *report.TAPWriter
+	if m.config.Output == types.OutputTAP {
+		tapWriter = report.NewTAPWriter(os.Stdout, total, m.config.LabelPairs())
+	}
 
-	fmt.Printf("\n📊 Processing Results:\n")
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(m.out, "\n📊 Processing Results:\n")
+	fmt.Fprintf(m.out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 
 	for result := range resultChan {
-		allResults = append(allResults, result)
+		m.tally.Add(result)
+		if reportWriter != nil {
+			reportWriter.Add(result)
+		}
+		if exportWriter != nil {
+			exportWriter.Add(result)
+		}
+		if tapWriter != nil {
+			tapWriter.Add(result)
+		}
+		if changelogWriter != nil {
+			changelogWriter.Add(result)
+		}
+		if sessionWriter != nil {
+			sessionWriter.Add(result)
+		}
+		if len(result.Findings) > 0 {
+			flagged = append(flagged, types.GitRepo{Name: result.Name, Path: result.Path, Findings: result.Findings})
+		}
 
-		if result.Error != nil {
-			if strings.Contains(result.Error.Error(), "skipped") {
-				skipped++
+		if m.config.FullSummary {
+			if result.Error != nil {
+				fmt.Fprintf(m.out, "❌ %s (%s): %v\n", result.Name, result.Path, result.Error)
 			} else {
-				failed++
-			}
-			if m.config.FullSummary {
-				fmt.Printf("❌ %s (%s): %v\n", result.Name, result.Path, result.Error)
+				status := "✅"
+				if m.config.DryRun {
+					status = "🔍"
+				}
+				fmt.Fprintf(m.out, "%s %s (%s) [%s@%s] - %v%s\n",
+					status, result.Name, result.Path, result.Branch, report.RemoteLabel(result), result.Duration.Truncate(time.Millisecond), m.resultSuffix(result))
 			}
+			m.displayExecOutput(result)
+		} else if len(first) < condensedCount {
+			first = append(first, result)
 		} else {
-			successful++
-			status := "✅"
-			if m.config.DryRun {
-				status = "🔍"
-			}
-			if m.config.FullSummary {
-				fmt.Printf("%s %s (%s) [%s@%s] - %v\n",
-					status, result.Name, result.Path, result.Branch, result.Remote, result.Duration.Truncate(time.Millisecond))
-			}
+			last.Add(result)
 		}
 	}
 
-	// Show condensed view if not full summary
+	// Show condensed view if not full summary: the first and last few results
 	if !m.config.FullSummary {
-		// Show only first few and last few results
-		displayCount := 5
-		if len(allResults) <= displayCount*2 {
-			displayCount = len(allResults) / 2
+		for i, result := range first {
+			m.displaySingleResult(result, i == 0)
 		}
 
-		for i, result := range allResults[:displayCount] {
-			m.displaySingleResult(result, i == 0)
+		if hidden := m.tally.Total - len(first) - last.Len(); hidden > 0 {
+			fmt.Fprintf(m.out, "... (%d more repositories) ...\n", hidden)
 		}
 
-		if len(allResults) > displayCount*2 {
-			fmt.Printf("... (%d more repositories) ...\n", len(allResults)-displayCount*2)
+		for _, result := range last.Items() {
+			m.displaySingleResult(result, false)
 		}
+	}
 
-		if len(allResults) > displayCount {
-			start := len(allResults) - displayCount
-			if len(allResults) <= displayCount*2 {
-				start = displayCount
-			}
-			for _, result := range allResults[start:] {
-				m.displaySingleResult(result, false)
-			}
+	// Every worker has finished its last repository once the results are all in
+	m.tally.Pool = m.pool.Utilization()
+
+	fmt.Fprintf(m.out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(m.out, "📈 Summary: %d successful, %d failed, %d skipped, %d total\n", m.tally.Successful, m.tally.Failed, m.tally.Skipped, total)
+	if label := report.SelectionLabel(m.config); label != "" {
+		fmt.Fprintf(m.out, "🔎 %s (%s)\n", report.FilterSummary(total, m.scanner.Discovered()), label)
+	}
+	for _, line := range m.tally.GroupSummaries() {
+		fmt.Fprintf(m.out, "📁 %s\n", line)
+	}
+	if steps := m.tally.StepSummaries(); len(steps) > 0 {
+		fmt.Fprintf(m.out, "🪜 Pipeline %s:\n", m.config.Pipeline)
+		for _, line := range steps {
+			fmt.Fprintf(m.out, "   %s\n", line)
 		}
 	}
 
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("📈 Summary: %d successful, %d failed, %d skipped, %d total\n", successful, failed, skipped, total)
+	if m.config.Operation.IsAudit() {
+		fmt.Fprintf(m.out, "📋 Compliance: %d/%d repositories compliant (%.1f%%)\n", m.tally.Compliant, m.tally.Audited, m.tally.CompliancePercent())
+	}
+
+	if m.config.Operation == types.OperationPush {
+		fmt.Fprintf(m.out, "⬆️  %d repositories pushed\n", m.tally.Pushed)
+	}
+
+	if m.config.Operation == types.OperationMaintenance {
+		fmt.Fprintf(m.out, "🧹 %d stale branches pruned, %s reclaimed\n", m.tally.PrunedRefs, report.FormatKiB(m.tally.ReclaimedKiB))
+	}
+
+	if m.config.Operation == types.OperationPruneBranches {
+		fmt.Fprintf(m.out, "🌿 %s\n", report.DeletedSummary(m.tally.Deleted, m.config.DryRun))
+	}
+
+	if m.config.LFS && !m.config.DryRun {
+		fmt.Fprintf(m.out, "🗄️  %s of Git LFS objects downloaded\n", report.FormatKiB(m.tally.LFSKiB))
+	}
+
+	if m.config.Operation == types.OperationSetURL {
+		fmt.Fprintf(m.out, "🔗 %s\n", report.RewrittenSummary(m.tally.Rewritten, m.config.DryRun))
+	}
+
+	if m.config.Operation == types.OperationApply && !m.config.DryRun {
+		fmt.Fprintf(m.out, "📝 %s\n", report.AppliedSummary(m.tally.Applied, m.tally.AppliedPush))
+	}
+
+	if m.config.Operation == types.OperationChangelog {
+		fmt.Fprintf(m.out, "📰 %s since %s\n", report.ChangelogSummary(m.tally.Commits, m.tally.Changed), m.config.Since)
+	}
+
+	if m.config.Preflight {
+		fmt.Fprintf(m.out, "🛫 %d remotes failed preflight\n", m.tally.Unreachable)
+	}
+
+	if m.tally.Untrusted > 0 || m.tally.Trusted > 0 {
+		fmt.Fprintf(m.out, "🔒 %s\n", report.OwnershipSummary(m.tally.Untrusted, m.tally.Trusted, m.config.DryRun))
+	}
+
+	if m.config.Operation == types.OperationVersions {
+		fmt.Fprintf(m.out, "📌 %s\n", report.VersionsSummary(m.tally.Outdated, m.tally.TagsChecked, m.config.DryRun))
+	}
+
+	if m.config.Operation == types.OperationReleases {
+		fmt.Fprintf(m.out, "🏷️  %s\n", report.ReleasesSummary(m.tally.Unreleased, m.tally.Overdue, m.config.UnreleasedDays))
+	}
+
+	if m.config.Operation == types.OperationHeal {
+		fmt.Fprintf(m.out, "🩹 %s\n", report.HealedSummary(m.tally.Healed, m.config.DryRun))
+	}
+
+	if m.config.Operation == types.OperationSync && !m.config.DryRun {
+		fmt.Fprintf(m.out, "🔄 %d default branches updated\n", m.tally.Synced)
+	}
+
+	if m.tally.Stashed > 0 {
+		fmt.Fprintf(m.out, "📦 %d repositories had uncommitted changes stashed\n", m.tally.Stashed)
+	}
+
+	if m.tally.NotAttempted > 0 {
+		fmt.Fprintf(m.out, "⏱️  Time budget of %v exhausted: %d repositories not attempted\n", m.config.Budget, m.tally.NotAttempted)
+	}
+
+	if m.tally.NoUpstream > 0 {
+		fmt.Fprintf(m.out, "🔗 %d repositories have no upstream for their current branch (use --set-upstream)\n", m.tally.NoUpstream)
+	}
+
+	m.displaySlowest(m.tally.Slowest)
+
+	m.displayUtilization(m.tally.Pool)
+
+	m.displayFlaky(m.tally.Flaky)
+
+	m.displayFindings(flagged)
 
-	// Save report to file if requested
+	if tapWriter != nil {
+		if err := tapWriter.Close(&m.tally); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to write TAP output", "error", err)
+		}
+	}
+
+	// Finish the detailed report if requested
 	if m.config.SaveReport != "" {
-		if err := m.saveReport(allResults, successful, failed, skipped); err != nil {
-			m.logger.ErrorContext(ctx, "Failed to save report", "error", err)
-			fmt.Fprintf(os.Stderr, "Error saving report: %v\n", err)
+		if reportWriter != nil {
+			reportErr = reportWriter.Close(&m.tally)
+		}
+		if reportErr != nil {
+			m.logger.ErrorContext(ctx, "Failed to save report", "error", reportErr)
+			fmt.Fprintf(os.Stderr, "Error saving report: %v\n", reportErr)
 		} else {
-			fmt.Printf("📄 Detailed report saved to: %s\n", m.config.SaveReport)
+			fmt.Fprintf(m.out, "📄 Detailed report saved to: %s\n", m.config.SaveReport)
 		}
 	}
 
-	// Export scan results to markdown if requested
+	// Finish the markdown scan export if requested
 	if m.config.ExportScan != "" {
-		if err := m.exportScanToMarkdown(allResults, m.config.ExportScan); err != nil {
-			m.logger.ErrorContext(ctx, "Failed to export scan", "error", err)
-			fmt.Fprintf(os.Stderr, "Error exporting scan: %v\n", err)
+		if exportWriter != nil {
+			exportErr = exportWriter.Close(&m.tally)
+		}
+		if exportErr != nil {
+			m.logger.ErrorContext(ctx, "Failed to export scan", "error", exportErr)
+			fmt.Fprintf(os.Stderr, "Error exporting scan: %v\n", exportErr)
+		} else {
+			fmt.Fprintf(m.out, "📋 Scan report exported to: %s\n", m.config.ExportScan)
+		}
+	}
+
+	// Write the changelog collected from every repository
+	if changelogWriter != nil {
+		if err := changelogWriter.Close(); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to write changelog", "error", err)
+			fmt.Fprintf(os.Stderr, "Error writing changelog: %v\n", err)
 		} else {
-			fmt.Printf("📋 Scan report exported to: %s\n", m.config.ExportScan)
+			fmt.Fprintf(m.out, "📰 Changelog written to: %s\n", m.config.ChangelogFile)
 		}
 	}
 
-	if !m.config.FullSummary && len(allResults) > 10 {
-		fmt.Printf("💡 Use --full-summary flag to see all %d repositories\n", len(allResults))
+	// Write the tmux session for the repositories needing attention if requested
+	if sessionWriter != nil {
+		if err := sessionWriter.Close(); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to write tmux session", "error", err)
+			fmt.Fprintf(os.Stderr, "Error writing tmux session: %v\n", err)
+		} else {
+			fmt.Fprintf(m.out, "🖥️  tmux session with %d repositories needing attention written to: %s (tmuxp load %s)\n",
+				sessionWriter.Len(), m.config.TmuxSession, m.config.TmuxSession)
+		}
 	}
 
-	if failed > 0 {
-		return fmt.Errorf("%d repositories failed", failed)
+	// History only informs later runs, so failing to save it does not fail this one
+	if err := m.processor.SaveHistory(); err != nil {
+		m.logger.WarnContext(ctx, "Failed to save history", "error", err)
+	}
+	if err := m.processor.AuditErr(); err != nil {
+		m.logger.WarnContext(ctx, "Failed to write the audit log", "error", err)
+	}
+
+	if !m.config.FullSummary && m.tally.Total > condensedCount*2 {
+		fmt.Fprintf(m.out, "💡 Use --full-summary flag to see all %d repositories\n", m.tally.Total)
+	}
+
+	if m.tally.Failed > 0 {
+		return fmt.Errorf("%d repositories failed", m.tally.Failed)
 	}
 
 	return nil
@@ -228,184 +539,220 @@ func (m *Manager) displayResults(ctx context.Context, resultChan <-chan types.Gi
 // displaySingleResult displays a single repository result
 func (m *Manager) displaySingleResult(result types.GitRepo, isFirst bool) {
 	if result.Error != nil {
-		if strings.Contains(result.Error.Error(), "skipped") {
-			fmt.Printf("⊝ %s (%s): %v\n", result.Name, result.Path, result.Error)
+		if report.IsSkipped(result) {
+			fmt.Fprintf(m.out, "⊝ %s (%s): %v\n", result.Name, result.Path, result.Error)
 		} else {
-			fmt.Printf("❌ %s (%s): %v\n", result.Name, result.Path, result.Error)
+			fmt.Fprintf(m.out, "❌ %s (%s): %v\n", result.Name, result.Path, result.Error)
 		}
 	} else {
 		status := "✅"
 		if m.config.DryRun {
 			status = "🔍"
 		}
-		fmt.Printf("%s %s (%s) [%s@%s] - %v\n",
-			status, result.Name, result.Path, result.Branch, result.Remote, result.Duration.Truncate(time.Millisecond))
+		fmt.Fprintf(m.out, "%s %s (%s) [%s@%s] - %v%s\n",
+			status, result.Name, result.Path, result.Branch, report.RemoteLabel(result), result.Duration.Truncate(time.Millisecond), m.resultSuffix(result))
 	}
+	m.displayExecOutput(result)
 }
 
-// saveReport saves a detailed report to a file
-func (m *Manager) saveReport(results []types.GitRepo, successful, failed, skipped int) (err error) {
-	file, err := os.Create(m.config.SaveReport)
-	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
+// displayExecOutput shows what the command exec ran printed, indented under its result
+func (m *Manager) displayExecOutput(result types.GitRepo) {
+	for _, line := range report.ExecOutputLines(result) {
+		fmt.Fprintf(m.out, "   │ %s\n", line)
 	}
-	defer func() {
-		err = errors.Join(err, file.Close())
-	}()
+}
 
-	// Write header
-	if _, err := fmt.Fprintf(file, "git-herd Report - %s\n", time.Now().Format("2006-01-02 15:04:05")); err != nil {
-		return fmt.Errorf("failed to write report header: %w", err)
-	}
-	if _, err := fmt.Fprintf(file, "Operation: %s\n", m.config.Operation); err != nil {
-		return fmt.Errorf("failed to write operation: %w", err)
-	}
-	if _, err := fmt.Fprintf(file, "Workers: %d\n", m.config.Workers); err != nil {
-		return fmt.Errorf("failed to write workers: %w", err)
-	}
-	if _, err := fmt.Fprintf(file, "Total Repositories: %d\n", len(results)); err != nil {
-		return fmt.Errorf("failed to write total repositories: %w", err)
-	}
-	if _, err := fmt.Fprintf(file, "Successful: %d, Failed: %d, Skipped: %d\n\n", successful, failed, skipped); err != nil {
-		return fmt.Errorf("failed to write summary: %w", err)
+// displaySlowest lists the slowest repositories with where their time went
+func (m *Manager) displaySlowest(slowest []types.GitRepo) {
+	if len(slowest) < 2 {
+		return
 	}
 
-	if _, err := fmt.Fprintf(file, "Repository Details:\n"); err != nil {
-		return fmt.Errorf("failed to write details header: %w", err)
+	fmt.Fprintf(m.out, "\n🐢 Slowest repositories:\n")
+	for _, result := range slowest {
+		fmt.Fprintf(m.out, "  %s\n", report.SlowLine(result))
 	}
-	if _, err := fmt.Fprintf(file, "==================\n\n"); err != nil {
-		return fmt.Errorf("failed to write details separator: %w", err)
+}
+
+// displayUtilization shows how busy the workers were, each of them with --full-summary, so
+// --workers can be tuned on evidence
+func (m *Manager) displayUtilization(u report.Utilization) {
+	if u.Repos == 0 {
+		return
 	}
 
-	for _, result := range results {
-		if _, err := fmt.Fprintf(file, "Repository: %s\n", result.Name); err != nil {
-			return fmt.Errorf("failed to write repository name: %w", err)
-		}
-		if _, err := fmt.Fprintf(file, "Path: %s\n", result.Path); err != nil {
-			return fmt.Errorf("failed to write repository path: %w", err)
+	fmt.Fprintf(m.out, "\n⚙️  Workers: %s\n", report.UtilizationSummary(u))
+	fmt.Fprintf(m.out, "  %s\n", report.UtilizationVerdict(u))
+	if m.config.FullSummary {
+		for _, line := range report.WorkerLines(u) {
+			fmt.Fprintf(m.out, "  %s\n", line)
 		}
+	}
+}
 
-		if result.Branch != "" {
-			if _, err := fmt.Fprintf(file, "Branch: %s\n", result.Branch); err != nil {
-				return fmt.Errorf("failed to write branch: %w", err)
-			}
-		}
-		if result.Remote != "" {
-			if _, err := fmt.Fprintf(file, "Remote: %s\n", result.Remote); err != nil {
-				return fmt.Errorf("failed to write remote: %w", err)
-			}
-		}
+// displayFlaky lists the flaky repositories apart from the rest, so their failures are not
+// mistaken for the repositories that are genuinely broken
+func (m *Manager) displayFlaky(flaky []types.GitRepo) {
+	if len(flaky) == 0 {
+		return
+	}
 
-		if _, err := fmt.Fprintf(file, "Duration: %v\n", result.Duration.Truncate(time.Millisecond)); err != nil {
-			return fmt.Errorf("failed to write duration: %w", err)
-		}
+	fmt.Fprintf(m.out, "\n🎲 Flaky repositories (alternating between success and failure in recent runs):\n")
+	for _, result := range flaky {
+		fmt.Fprintf(m.out, "  %s\n", report.FlakyLine(result))
+	}
+}
 
-		if result.Error != nil {
-			if _, err := fmt.Fprintf(file, "Status: FAILED - %v\n", result.Error); err != nil {
-				return fmt.Errorf("failed to write failed status: %w", err)
-			}
-		} else if m.config.DryRun {
-			if _, err := fmt.Fprintf(file, "Status: DRY RUN - Would have succeeded\n"); err != nil {
-				return fmt.Errorf("failed to write dry run status: %w", err)
-			}
-		} else {
-			if _, err := fmt.Fprintf(file, "Status: SUCCESS\n"); err != nil {
-				return fmt.Errorf("failed to write success status: %w", err)
-			}
+// displayFindings lists repositories with hook or local config anomalies
+func (m *Manager) displayFindings(results []types.GitRepo) {
+	for _, result := range results {
+		if len(result.Findings) == 0 {
+			continue
 		}
-
-		if _, err := fmt.Fprintf(file, "\n"); err != nil {
-			return fmt.Errorf("failed to write separator: %w", err)
+		fmt.Fprintf(m.out, "⚠️  %s (%s):\n", result.Name, result.Path)
+		for _, finding := range result.Findings {
+			fmt.Fprintf(m.out, "   - %s\n", finding)
 		}
 	}
-
-	return nil
 }
 
-// exportScanToMarkdown exports repository scan results to a markdown file
-func (m *Manager) exportScanToMarkdown(results []types.GitRepo, filePath string) (err error) {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create export file: %w", err)
+// resultSuffix describes what the operation did to a repository, after the steps of the
+// pipeline that ran on it
+func (m *Manager) resultSuffix(result types.GitRepo) string {
+	if steps := report.StepsLabel(result); steps != "" {
+		return " - " + steps + m.operationSuffix(result)
 	}
-	defer func() {
-		err = errors.Join(err, file.Close())
-	}()
+	return m.operationSuffix(result)
+}
 
-	// Write header
-	if _, err := fmt.Fprintf(file, "# Git Repository Scan Report\n\n"); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+// operationSuffix describes a repository's compliance for audit results, its branch and working
+// tree for status results, the worktree whose fetch it shared, what was pulled, pushed, synced,
+// cloned, checked out, stashed or cleaned up for the operations doing so, how the command
+// exited for exec results, and how long it has been dirty for scan results
+func (m *Manager) operationSuffix(result types.GitRepo) string {
+	if result.FetchedWith != "" {
+		return " - fetched along with " + result.FetchedWith
 	}
-	if _, err := fmt.Fprintf(file, "Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05")); err != nil {
-		return fmt.Errorf("failed to write timestamp: %w", err)
+	if m.config.Operation == types.OperationPull {
+		if pull := report.PullLabel(result, m.config.DryRun); pull != "" {
+			return " - " + pull
+		}
+		return ""
 	}
-	if _, err := fmt.Fprintf(file, "Total Repositories: %d\n\n", len(results)); err != nil {
-		return fmt.Errorf("failed to write total: %w", err)
+	if stash := report.StashLabel(result, m.config.DryRun); stash != "" {
+		return " - " + stash
 	}
-	if _, err := fmt.Fprintf(file, "---\n\n"); err != nil {
-		return fmt.Errorf("failed to write separator: %w", err)
+	if m.config.Operation == types.OperationStash {
+		return " - " + "nothing to stash"
 	}
-
-	// Write repository details
-	for _, repo := range results {
-		if _, err := fmt.Fprintf(file, "## %s\n\n", repo.Name); err != nil {
-			return fmt.Errorf("failed to write repo name: %w", err)
-		}
-		if _, err := fmt.Fprintf(file, "**Path:** `%s`\n\n", repo.Path); err != nil {
-			return fmt.Errorf("failed to write path: %w", err)
-		}
-
-		if repo.Branch != "" {
-			if _, err := fmt.Fprintf(file, "**Branch:** %s\n\n", repo.Branch); err != nil {
-				return fmt.Errorf("failed to write branch: %w", err)
-			}
-		}
-
-		if repo.Remote != "" {
-			if _, err := fmt.Fprintf(file, "**Remote:** %s\n\n", repo.Remote); err != nil {
-				return fmt.Errorf("failed to write remote: %w", err)
-			}
+	if m.config.Operation == types.OperationMaintenance {
+		return " - " + report.MaintenanceLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationPruneBranches {
+		return " - " + report.PruneBranchesLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationSetURL {
+		return " - " + report.SetURLLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationExec {
+		return " - " + report.ExecLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationApply {
+		return " - " + report.ApplyLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationChangelog {
+		return " - " + report.ChangelogLabel(result)
+	}
+	if m.config.Operation == types.OperationReleases {
+		return " - " + report.ReleaseLabel(result, time.Now())
+	}
+	if m.config.Operation == types.OperationVersions {
+		return " - " + report.VersionLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationCheckout {
+		return " - " + report.CheckoutLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationSync {
+		return " - " + report.SyncLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationHeal {
+		return " - " + report.HealLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationClone {
+		return " - " + report.CloneLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationPush {
+		return " - " + report.PushLabel(result, m.config.DryRun)
+	}
+	if m.config.Operation == types.OperationStatus {
+		return " - " + report.StatusLabel(result)
+	}
+	if m.config.Operation == types.OperationScan {
+		if age := report.DirtyAge(result, time.Now()); age != "" {
+			return " - dirty for " + age
 		}
+		return ""
+	}
+	if !m.config.Operation.IsAudit() {
+		return ""
+	}
+	if issue := git.AuditIssue(result); issue != "" {
+		return " - " + issue + report.OwnersNote(result)
+	}
+	return " - compliant"
+}
 
-		if repo.LastCommit != "" {
-			if _, err := fmt.Fprintf(file, "**Last Commit:** `%s`\n\n", repo.LastCommit); err != nil {
-				return fmt.Errorf("failed to write commit: %w", err)
-			}
-			if repo.LastCommitMsg != "" {
-				if _, err := fmt.Fprintf(file, "**Commit Message:** %s\n\n", repo.LastCommitMsg); err != nil {
-					return fmt.Errorf("failed to write commit message: %w", err)
-				}
-			}
-		}
+// issueTimeout bounds filing issues, which still happens when the run itself timed out
+const issueTimeout = time.Minute
 
-		if len(repo.ModifiedFiles) > 0 {
-			if _, err := fmt.Fprintf(file, "**Modified Files:**\n\n"); err != nil {
-				return fmt.Errorf("failed to write modified files header: %w", err)
-			}
-			for _, modFile := range repo.ModifiedFiles {
-				if _, err := fmt.Fprintf(file, "- `%s`\n", modFile); err != nil {
-					return fmt.Errorf("failed to write modified file: %w", err)
-				}
-			}
-			if _, err := fmt.Fprintf(file, "\n"); err != nil {
-				return fmt.Errorf("failed to write newline: %w", err)
-			}
-		} else {
-			if _, err := fmt.Fprintf(file, "**Status:** Clean (no local changes)\n\n"); err != nil {
-				return fmt.Errorf("failed to write clean status: %w", err)
-			}
-		}
+// fileIssues opens or updates an issue in the issue repository for every repository that has now
+// failed the operation in at least --issue-after runs in a row, per the history the run was
+// saved to. With --notify-dry-run the issues are printed instead.
+func (m *Manager) fileIssues(ctx context.Context) error {
+	if len(m.tally.Failures) == 0 {
+		return nil
+	}
 
-		if repo.Error != nil {
-			if _, err := fmt.Fprintf(file, "**Error:** %v\n\n", repo.Error); err != nil {
-				return fmt.Errorf("failed to write error: %w", err)
-			}
+	store, err := history.Load(m.config.HistoryFile)
+	if err != nil {
+		return fmt.Errorf("failed to file issues: %w", err)
+	}
+	var failures []issues.Failure
+	for _, result := range m.tally.Failures {
+		records := store.Records(result.Path)
+		if streak := history.FailureStreak(records, m.config.Operation); streak >= m.config.IssueAfter {
+			failures = append(failures, issues.Failure{
+				Name: result.Name, Path: result.Path, Operation: m.config.Operation, Streak: streak, Records: records,
+				Labels: m.config.LabelPairs(),
+			})
 		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
 
-		if _, err := fmt.Fprintf(file, "---\n\n"); err != nil {
-			return fmt.Errorf("failed to write separator: %w", err)
+	var filer issues.Filer
+	if m.config.NotifyDryRun {
+		filer = issues.NewPreview(m.config.IssueRepo, m.out)
+	} else if filer, err = issues.NewTracker(m.config.IssueRepo); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), issueTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, failure := range failures {
+		url, opened, err := filer.File(ctx, failure)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to file issue for %s: %w", failure.Name, err))
+		case url == "":
+			// Previewed, not filed
+		case opened:
+			fmt.Fprintf(m.log, "📮 %s has failed %d runs in a row: opened %s\n", failure.Name, failure.Streak, url)
+		default:
+			fmt.Fprintf(m.log, "📮 %s has failed %d runs in a row: updated %s\n", failure.Name, failure.Streak, url)
 		}
 	}
-
-	return nil
+	return errors.Join(errs...)
 }
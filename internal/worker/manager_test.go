@@ -1,9 +1,23 @@
 package worker
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"github.com/entro314-labs/git-herd/internal/console"
+	"github.com/entro314-labs/git-herd/internal/history"
+	"github.com/entro314-labs/git-herd/internal/report"
+	"github.com/entro314-labs/git-herd/pkg/herdtest"
 	"github.com/entro314-labs/git-herd/pkg/types"
 )
 
@@ -41,6 +55,27 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestPrinters(t *testing.T) {
+	tests := []struct {
+		output  types.OutputFormat
+		logDest types.LogDest
+		out     *console.Printer
+		log     *console.Printer
+	}{
+		{types.OutputText, types.LogDestAuto, console.Stdout, console.Stdout},
+		{types.OutputText, types.LogDestStderr, console.Stdout, console.Stderr},
+		{types.OutputTAP, types.LogDestAuto, console.Stderr, console.Stderr},
+		{types.OutputTAP, types.LogDestStderr, console.Stderr, console.Stderr},
+	}
+
+	for _, tt := range tests {
+		out, log := printers(&types.Config{Output: tt.output, LogDest: tt.logDest})
+		if out != tt.out || log != tt.log {
+			t.Errorf("printers(%s, %s) returned the wrong streams", tt.output, tt.logDest)
+		}
+	}
+}
+
 func TestConfig_OperationType(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -59,3 +94,172 @@ func TestConfig_OperationType(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteWritesSummaryFile(t *testing.T) {
+	summaryFile := filepath.Join(t.TempDir(), "summary.json")
+	badge := filepath.Join(t.TempDir(), "badge.svg")
+	config := &types.Config{
+		Workers:     1,
+		Operation:   types.OperationFetch,
+		PlainMode:   true,
+		SummaryFile: summaryFile,
+		Badge:       badge,
+	}
+
+	err := New(config).Execute(t.Context(), filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Fatal("Expected scanning a missing directory to fail")
+	}
+
+	data, readErr := os.ReadFile(summaryFile)
+	if readErr != nil {
+		t.Fatalf("Expected summary file to be written on failure: %v", readErr)
+	}
+	var summary report.Summary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Invalid summary JSON: %v", err)
+	}
+	if summary.Status != report.StatusError || summary.Error == "" {
+		t.Errorf("Expected error status with a message, got %+v", summary)
+	}
+
+	svg, readErr := os.ReadFile(badge)
+	if readErr != nil {
+		t.Fatalf("Expected badge to be written on failure: %v", readErr)
+	}
+	if !strings.Contains(string(svg), "repos: error") {
+		t.Errorf("Expected an error badge, got\n%s", svg)
+	}
+}
+
+func TestExecuteMoreReposThanWorkers(t *testing.T) {
+	root := t.TempDir()
+	for i := range 12 {
+		if err := os.MkdirAll(filepath.Join(root, fmt.Sprintf("repo-%02d", i), ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reportFile := filepath.Join(t.TempDir(), "report.txt")
+	config := &types.Config{Workers: 2, Operation: types.OperationScan, Recursive: true, PlainMode: true, SaveReport: reportFile}
+
+	// Results arrive faster than they are collected, so workers must never wait on a
+	// collector that has not started
+	done := make(chan error, 1)
+	go func() { done <- New(config).Execute(t.Context(), root) }()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("Execute did not finish processing 12 repositories with 2 workers")
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Total Repositories: 12") {
+		t.Errorf("Expected all 12 repositories reported, got\n%s", data)
+	}
+}
+
+func TestExecuteWithFakes(t *testing.T) {
+	finder := &herdtest.Finder{FS: fstest.MapFS{
+		"api/.git/HEAD":                 {},
+		"api/vendor/lib/.git/HEAD":      {},
+		"web/.git/HEAD":                 {},
+		"docs/.git/HEAD":                {},
+		"notes/README.md":               {},
+		"notes/drafts/.keep":            {},
+		"web/node_modules/pkg/index.js": {},
+	}}
+	operator := &herdtest.Operator{Errors: map[string]error{"web": errors.New("fetch failed: authentication required")}}
+	config := &types.Config{Workers: 2, Operation: types.OperationFetch, PlainMode: true}
+
+	manager := NewWith(config, finder, operator)
+	if err := manager.Execute(t.Context(), "/work"); err == nil || !strings.Contains(err.Error(), "1 repositories failed") {
+		t.Fatalf("Expected the failed web to fail the run, got %v", err)
+	}
+
+	processed := operator.Processed()
+	slices.Sort(processed)
+	if want := []string{"api", "docs", "lib", "web"}; !slices.Equal(processed, want) {
+		t.Errorf("Expected the repositories %v to be processed, got %v", want, processed)
+	}
+	if manager.tally.Successful != 3 || manager.tally.Failed != 1 {
+		t.Errorf("Expected 3 successful and 1 failed, got %d and %d", manager.tally.Successful, manager.tally.Failed)
+	}
+	if operator.Saved() != 1 {
+		t.Errorf("Expected the history to be saved once, got %d", operator.Saved())
+	}
+}
+
+func TestFileIssues(t *testing.T) {
+	var opened []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte("[]"))
+		case http.MethodPost:
+			var request struct{ Title string }
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			opened = append(opened, request.Title)
+			_, _ = w.Write([]byte(`{"number": 1, "html_url": "https://github.com/acme/ops/issues/1"}`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("GITHUB_API_URL", server.URL)
+
+	historyFile := filepath.Join(t.TempDir(), "history.json")
+	store, err := history.Load(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		store.Add("/repos/broken", history.Record{Operation: types.OperationFetch, Error: "authentication required"})
+	}
+	store.Add("/repos/new", history.Record{Operation: types.OperationFetch, Error: "connection reset"})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(&types.Config{Workers: 1, Operation: types.OperationFetch, PlainMode: true, HistoryFile: historyFile, IssueRepo: "acme/ops", IssueAfter: 3})
+	m.tally.Add(types.GitRepo{Name: "broken", Path: "/repos/broken", Error: errors.New("authentication required")})
+	m.tally.Add(types.GitRepo{Name: "new", Path: "/repos/new", Error: errors.New("connection reset")})
+
+	if err := m.fileIssues(t.Context()); err != nil {
+		t.Fatalf("fileIssues() error = %v", err)
+	}
+	if want := []string{"git-herd: /repos/broken fails to fetch"}; !slices.Equal(opened, want) {
+		t.Errorf("Expected issues %v, got %v", want, opened)
+	}
+}
+
+func TestFileIssuesNotifyDryRun(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	historyFile := filepath.Join(t.TempDir(), "history.json")
+	store, err := history.Load(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		store.Add("/repos/broken", history.Record{Operation: types.OperationFetch, Error: "authentication required"})
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(&types.Config{Workers: 1, Operation: types.OperationFetch, PlainMode: true, HistoryFile: historyFile, IssueRepo: "acme/ops", IssueAfter: 3, NotifyDryRun: true})
+	var out strings.Builder
+	m.out = &out
+	m.tally.Add(types.GitRepo{Name: "broken", Path: "/repos/broken", Error: errors.New("authentication required")})
+
+	// No token is needed, since nothing is sent
+	if err := m.fileIssues(t.Context()); err != nil {
+		t.Fatalf("fileIssues() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "POST /repos/acme/ops/issues") || !strings.Contains(out.String(), "git-herd: /repos/broken fails to fetch") {
+		t.Errorf("Expected the issue to be previewed, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/entro314-labs/git-herd/internal/tui"
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+// Watch runs the configured operation on the repositories below rootPath every interval until
+// ctx is done, scanning for them again each round. The TUI keeps a table of the latest outcome
+// of every repository on screen; in plain mode each round prints its results like a run does.
+// Each round has --timeout to finish. A round that fails does not end the watch.
+func Watch(ctx context.Context, config *types.Config, rootPath string, interval time.Duration) error {
+	if !config.PlainMode && !config.Verbose && config.Output != types.OutputTAP {
+		model := tui.NewWatchModel(config, rootPath, interval)
+		defer model.Stop()
+		if _, err := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx)).Run(); err == nil || ctx.Err() != nil {
+			return nil
+		}
+		// Fall back to plain mode if the TUI fails
+	}
+
+	_, log := printers(config)
+	return watchPlain(ctx, log, interval, func(ctx context.Context) error {
+		if config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+			defer cancel()
+		}
+		return New(config).Execute(ctx, rootPath)
+	})
+}
+
+// watchPlain runs a round every interval until ctx is done, reporting the rounds that fail
+func watchPlain(ctx context.Context, log io.Writer, interval time.Duration, round func(context.Context) error) error {
+	for n := 1; ; n++ {
+		fmt.Fprintf(log, "👀 Watch round %d at %s\n", n, time.Now().Format(time.TimeOnly))
+		if err := round(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(log, "⚠️  Round %d: %v\n", n, err)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		fmt.Fprintf(log, "⏱️  Next round at %s\n\n", time.Now().Add(interval).Format(time.TimeOnly))
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
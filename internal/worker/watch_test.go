@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchPlain(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var log bytes.Buffer
+	rounds := 0
+	err := watchPlain(ctx, &log, time.Millisecond, func(context.Context) error {
+		rounds++
+		if rounds == 3 {
+			cancel()
+			return ctx.Err()
+		}
+		if rounds == 2 {
+			return errors.New("1 repositories failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected an interrupted watch to end cleanly, got %v", err)
+	}
+	if rounds != 3 {
+		t.Errorf("Expected 3 rounds, got %d", rounds)
+	}
+
+	output := log.String()
+	for _, want := range []string{"Watch round 1 at", "Watch round 3 at", "Round 2: 1 repositories failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in:\n%s", want, output)
+		}
+	}
+	// The interrupted round neither reports its error nor schedules another
+	if strings.Contains(output, "Round 3:") || strings.Count(output, "Next round at") != 2 {
+		t.Errorf("Unexpected output after the interruption:\n%s", output)
+	}
+}
@@ -0,0 +1,197 @@
+// Package herdtest provides fakes of git-herd's components, so code driving a run, and
+// git-herd's own tests, can run without temporary directories or git repositories: a Finder
+// returning fixed repositories or those in an fs.FS such as fstest.MapFS, and an Operator
+// recording the repositories it is given and failing the ones it is told to.
+package herdtest
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+var (
+	_ types.RepoFinder   = (*Finder)(nil)
+	_ types.RepoOperator = (*Operator)(nil)
+)
+
+// Finder is a types.RepoFinder returning Repos, or the repositories in FS when it is set
+type Finder struct {
+	Repos []types.GitRepo
+	FS    fs.FS // Directories holding a .git entry are repositories, found below the root path
+	Err   error // Returned by FindRepos along with the repositories
+
+	mu         sync.Mutex
+	discovered int
+}
+
+// FindRepos returns a copy of Repos, or the repositories below rootPath in FS, with their paths
+// joined to rootPath and ordered like a walk of the filesystem. A repository inside another has
+// its Nesting set.
+func (f *Finder) FindRepos(ctx context.Context, rootPath string, onProgress func(int)) ([]types.GitRepo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repos := slices.Clone(f.Repos)
+	if f.FS != nil {
+		var err error
+		if repos, err = findInFS(f.FS); err != nil {
+			return nil, err
+		}
+		for i := range repos {
+			repos[i].Path = filepath.Join(rootPath, filepath.FromSlash(repos[i].Path))
+			if repos[i].Name == "." {
+				repos[i].Name = filepath.Base(repos[i].Path)
+			}
+		}
+	}
+
+	f.mu.Lock()
+	f.discovered = len(repos)
+	f.mu.Unlock()
+	if onProgress != nil {
+		for i := range repos {
+			onProgress(i + 1)
+		}
+	}
+	return repos, f.Err
+}
+
+// findInFS walks fsys for directories holding a .git entry, with their paths relative to its root
+func findInFS(fsys fs.FS) ([]types.GitRepo, error) {
+	var repos []types.GitRepo
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if _, err := fs.Stat(fsys, path.Join(p, ".git")); err != nil {
+			return nil
+		}
+		nesting := 0
+		for _, repo := range repos {
+			if repo.Path == "." || strings.HasPrefix(p, repo.Path+"/") {
+				nesting++
+			}
+		}
+		repos = append(repos, types.GitRepo{Path: p, Name: path.Base(p), HasGit: true, Nesting: nesting})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// Discovered returns how many repositories the last FindRepos returned
+func (f *Finder) Discovered() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.discovered
+}
+
+// Indexed returns the zero time: a Finder never reads an index
+func (f *Finder) Indexed() time.Time {
+	return time.Time{}
+}
+
+// Operator is a types.RepoOperator that runs nothing: each repository is returned as it came,
+// failed with the error in Errors under its name, if any, or as Process returns it when that
+// is set. It records the repositories it is given, and is safe for concurrent use.
+type Operator struct {
+	Errors    map[string]error                                            // Errors to fail repositories with, by name
+	Process   func(ctx context.Context, repo types.GitRepo) types.GitRepo // Replaces the default outcome when set
+	Unreached []string                                                    // Names of repositories whose remote fails Preflight
+	Delay     time.Duration                                               // How long each ProcessRepo takes
+
+	mu        sync.Mutex
+	processed []string
+	saved     int
+}
+
+// errUnreachable is the preflight failure of the repositories in Unreached
+var errUnreachable = errors.New("remote unreachable")
+
+// Select returns every repository: an Operator knows no state to select them by
+func (o *Operator) Select(ctx context.Context, repos []types.GitRepo) []types.GitRepo {
+	return repos
+}
+
+// Preflight fails the repositories named in Unreached
+func (o *Operator) Preflight(ctx context.Context, repos []types.GitRepo) []types.GitRepo {
+	var failed []types.GitRepo
+	for _, repo := range repos {
+		if slices.Contains(o.Unreached, repo.Name) {
+			repo.Error = errUnreachable
+			failed = append(failed, repo)
+		}
+	}
+	return failed
+}
+
+// ProcessRepo records the repository and returns its outcome
+func (o *Operator) ProcessRepo(ctx context.Context, repo types.GitRepo) types.GitRepo {
+	o.mu.Lock()
+	o.processed = append(o.processed, repo.Name)
+	o.mu.Unlock()
+
+	start := time.Now()
+	if o.Delay > 0 {
+		select {
+		case <-time.After(o.Delay):
+		case <-ctx.Done():
+			repo.Error = ctx.Err()
+			return repo
+		}
+	}
+	switch {
+	case o.Process != nil:
+		repo = o.Process(ctx, repo)
+	case o.Errors[repo.Name] != nil:
+		repo.Error = o.Errors[repo.Name]
+	case slices.Contains(o.Unreached, repo.Name):
+		repo.Error = errUnreachable
+	}
+	repo.Duration = time.Since(start)
+	return repo
+}
+
+// Processed returns the names of the repositories ProcessRepo was given, in the order it was
+func (o *Operator) Processed() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return slices.Clone(o.processed)
+}
+
+// SaveHistory counts the saves, which Saved returns
+func (o *Operator) SaveHistory() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.saved++
+	return nil
+}
+
+// Saved returns how many times SaveHistory was called
+func (o *Operator) Saved() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.saved
+}
+
+// AuditErr returns nil: an Operator keeps no audit log
+func (o *Operator) AuditErr() error {
+	return nil
+}
@@ -0,0 +1,113 @@
+package herdtest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/entro314-labs/git-herd/pkg/types"
+)
+
+func TestFinder_FS(t *testing.T) {
+	t.Parallel()
+
+	finder := &Finder{FS: fstest.MapFS{
+		"app/.git/HEAD":                          {},
+		"app/vendor/lib/.git":                    {Data: []byte("gitdir: ../../.git/modules/lib\n")},
+		"app/vendor/lib/testdata/fixture/.git/x": {},
+		"tools/.git/HEAD":                        {},
+		"notes/README.md":                        {},
+	}}
+	var counts []int
+	repos, err := finder.FindRepos(t.Context(), "/work", func(n int) { counts = append(counts, n) })
+	if err != nil {
+		t.Fatalf("FindRepos failed: %v", err)
+	}
+
+	var got []string
+	for _, repo := range repos {
+		got = append(got, filepath.ToSlash(repo.Path)+"="+repo.Name)
+		if want := map[string]int{"app": 0, "lib": 1, "fixture": 2, "tools": 0}[repo.Name]; repo.Nesting != want {
+			t.Errorf("Expected %s nested %d deep, got %d", repo.Name, want, repo.Nesting)
+		}
+	}
+	want := []string{"/work/app=app", "/work/app/vendor/lib=lib", "/work/app/vendor/lib/testdata/fixture=fixture", "/work/tools=tools"}
+	if !slices.Equal(got, want) {
+		t.Errorf("FindRepos() = %v, want %v", got, want)
+	}
+	if finder.Discovered() != 4 || !slices.Equal(counts, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected 4 repositories discovered and progress up to 4, got %d and %v", finder.Discovered(), counts)
+	}
+
+	// The root itself can be the one repository
+	root := &Finder{FS: fstest.MapFS{".git/HEAD": {}}}
+	if repos, _ := root.FindRepos(t.Context(), "/work/api", nil); len(repos) != 1 || repos[0].Name != "api" {
+		t.Errorf("Expected the root repository api, got %+v", repos)
+	}
+}
+
+func TestFinder_Repos(t *testing.T) {
+	t.Parallel()
+
+	finder := &Finder{Repos: []types.GitRepo{{Path: "/work/api", Name: "api"}}, Err: errors.New("walk interrupted")}
+	repos, err := finder.FindRepos(t.Context(), "/work", nil)
+	if err == nil || len(repos) != 1 {
+		t.Errorf("Expected the repositories along with the error, got %d and %v", len(repos), err)
+	}
+	repos[0].Name = "changed"
+	if finder.Repos[0].Name != "api" {
+		t.Error("Expected FindRepos to return a copy of Repos")
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := finder.FindRepos(ctx, "/work", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a canceled FindRepos to fail, got %v", err)
+	}
+}
+
+func TestOperator(t *testing.T) {
+	t.Parallel()
+
+	errAuth := errors.New("fetch failed: authentication required")
+	operator := &Operator{Errors: map[string]error{"web": errAuth}, Unreached: []string{"docs"}}
+	repos := []types.GitRepo{{Name: "api"}, {Name: "web"}, {Name: "docs"}}
+
+	if failed := operator.Preflight(t.Context(), repos); len(failed) != 1 || failed[0].Name != "docs" || failed[0].Error == nil {
+		t.Errorf("Expected docs to fail the preflight, got %+v", failed)
+	}
+	for _, repo := range operator.Select(t.Context(), repos) {
+		result := operator.ProcessRepo(t.Context(), repo)
+		switch repo.Name {
+		case "api":
+			if result.Error != nil {
+				t.Errorf("Expected api to succeed, got %v", result.Error)
+			}
+		case "web":
+			if !errors.Is(result.Error, errAuth) {
+				t.Errorf("Expected web to fail with its error, got %v", result.Error)
+			}
+		case "docs":
+			if result.Error == nil {
+				t.Error("Expected the unreachable docs to fail")
+			}
+		}
+	}
+	if got := operator.Processed(); !slices.Equal(got, []string{"api", "web", "docs"}) {
+		t.Errorf("Processed() = %v", got)
+	}
+
+	operator.Process = func(ctx context.Context, repo types.GitRepo) types.GitRepo {
+		repo.Behind = 3
+		return repo
+	}
+	if result := operator.ProcessRepo(t.Context(), types.GitRepo{Name: "web"}); result.Error != nil || result.Behind != 3 {
+		t.Errorf("Expected Process to decide the outcome, got %+v", result)
+	}
+	if err := operator.SaveHistory(); err != nil || operator.Saved() != 1 {
+		t.Errorf("Expected one save, got %d, %v", operator.Saved(), err)
+	}
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// RepoFinder finds the repositories a run works on. git-herd's own walks the filesystem; the
+// fakes in herdtest let code driving a run be tested without one.
+type RepoFinder interface {
+	// FindRepos returns the repositories to work on below rootPath, in a stable order,
+	// reporting the count found so far to onProgress, which may be nil
+	FindRepos(ctx context.Context, rootPath string, onProgress func(int)) ([]GitRepo, error)
+
+	// Discovered returns how many repositories the last FindRepos found before selecting
+	// the ones to work on, e.g. with --filter
+	Discovered() int
+
+	// Indexed returns when the walk the last FindRepos took its repositories from was made,
+	// or the zero time when it walked
+	Indexed() time.Time
+}
+
+// RepoOperator runs the configured operation on repositories. git-herd's own runs git on them;
+// the fakes in herdtest let code driving a run be tested without real repositories.
+type RepoOperator interface {
+	// Select returns the repositories --only, --on-branch and --remote-host pick, in order
+	Select(ctx context.Context, repos []GitRepo) []GitRepo
+
+	// Preflight checks the remotes of the repositories before any is processed, returning
+	// those that failed
+	Preflight(ctx context.Context, repos []GitRepo) []GitRepo
+
+	// ProcessRepo runs the operation on a repository, returning it with the outcome. It is
+	// called for several repositories at once.
+	ProcessRepo(ctx context.Context, repo GitRepo) GitRepo
+
+	// SaveHistory persists the outcomes of the run, for later runs to learn from
+	SaveHistory() error
+
+	// AuditErr returns why recording the run's changes in the audit log failed, if it did
+	AuditErr() error
+}
@@ -1,6 +1,12 @@
 package types
 
 import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 )
 
@@ -8,42 +14,532 @@ import (
 type OperationType string
 
 const (
-	OperationFetch OperationType = "fetch"
-	OperationPull  OperationType = "pull"
-	OperationScan  OperationType = "scan"
+	OperationFetch         OperationType = "fetch"
+	OperationPull          OperationType = "pull"
+	OperationScan          OperationType = "scan"
+	OperationAuditFiles    OperationType = "audit-files"
+	OperationAuditEmail    OperationType = "audit-email"
+	OperationStatus        OperationType = "status"
+	OperationPush          OperationType = "push"
+	OperationClone         OperationType = "clone"
+	OperationCheckout      OperationType = "checkout"
+	OperationStash         OperationType = "stash"
+	OperationStashPop      OperationType = "stash-pop"
+	OperationMaintenance   OperationType = "maintenance"
+	OperationPruneBranches OperationType = "prune-branches"
+	OperationExec          OperationType = "exec"
+	OperationSync          OperationType = "sync"
+	OperationSetURL        OperationType = "set-url"
+	OperationApply         OperationType = "apply"
+	OperationChangelog     OperationType = "changelog"
+	OperationHeal          OperationType = "heal"
+	OperationReleases      OperationType = "releases"
+	OperationVersions      OperationType = "versions"
+)
+
+// IsAnalysis reports whether the operation only inspects repositories
+// without touching remotes or the working tree. Status only fetches with --fetch-first.
+func (o OperationType) IsAnalysis() bool {
+	switch o {
+	case OperationScan, OperationAuditFiles, OperationAuditEmail, OperationStatus, OperationChangelog, OperationReleases:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsMutating reports whether the operation changes the working tree, local branches, the
+// remote, or the object store beyond what a fetch adds. Fetch only updates remote-tracking refs
+// and is not considered mutating; maintenance deletes refs and rewrites packs; exec runs an
+// arbitrary command, so it may change anything.
+func (o OperationType) IsMutating() bool {
+	switch o {
+	case OperationPull, OperationPush, OperationCheckout, OperationStash, OperationStashPop, OperationPruneBranches,
+		OperationExec, OperationSync, OperationSetURL, OperationApply, OperationHeal, OperationMaintenance:
+		return true
+	default:
+		return false
+	}
+}
+
+// WorksOnBare reports whether the operation can run on a bare repository, which has refs and
+// objects but no worktree: fetching and maintaining it, rewriting its remote URLs, reading its
+// history, and running commands in it
+func (o OperationType) WorksOnBare() bool {
+	switch o {
+	case OperationFetch, OperationScan, OperationAuditEmail, OperationMaintenance, OperationExec,
+		OperationSetURL, OperationChangelog, OperationReleases:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsAudit reports whether the operation checks repositories for compliance
+func (o OperationType) IsAudit() bool {
+	return o == OperationAuditFiles || o == OperationAuditEmail
+}
+
+// OutputFormat selects how results are written to standard output
+type OutputFormat string
+
+const (
+	OutputText OutputFormat = "text" // Interactive TUI or plain text, for people
+	OutputTAP  OutputFormat = "tap"  // Test Anything Protocol, for TAP consumers such as prove
+)
+
+// LogDest selects where progress messages and logs go in plain mode
+type LogDest string
+
+const (
+	LogDestAuto   LogDest = "auto"   // Wherever results go: stdout, or stderr when stdout carries TAP
+	LogDestStdout LogDest = "stdout" // Mixed with the results
+	LogDestStderr LogDest = "stderr" // Apart from the results, which keep stdout to themselves
+)
+
+// IPFamily restricts which IP protocol version network connections use
+type IPFamily string
+
+const (
+	IPFamilyAuto IPFamily = "auto"
+	IPFamily4    IPFamily = "4"
+	IPFamily6    IPFamily = "6"
+)
+
+// RepoState is a state of a repository --only selects it by
+type RepoState string
+
+const (
+	StateDirty      RepoState = "dirty"       // Uncommitted changes
+	StateClean      RepoState = "clean"       // No uncommitted changes
+	StateAhead      RepoState = "ahead"       // Commits not pushed to the upstream
+	StateBehind     RepoState = "behind"      // Upstream commits not pulled
+	StateDetached   RepoState = "detached"    // HEAD is not on a branch
+	StateNoUpstream RepoState = "no-upstream" // On a branch without an upstream
+)
+
+// RepoStates lists the states --only accepts
+var RepoStates = []RepoState{StateDirty, StateClean, StateAhead, StateBehind, StateDetached, StateNoUpstream}
+
+// NestedMode selects whether repositories found inside other repositories, such as vendored
+// checkouts or test fixtures, are processed
+type NestedMode string
+
+const (
+	NestedInclude NestedMode = "include" // Along with the others
+	NestedSkip    NestedMode = "skip"    // Not at all: only the outermost repositories are processed
+	NestedOnly    NestedMode = "only"    // Alone: only the repositories inside others are processed
+)
+
+// PullStrategy selects how pull handles a branch that has diverged from the remote
+type PullStrategy string
+
+const (
+	PullFastForward PullStrategy = "ff-only" // Refuse diverged branches
+	PullMerge       PullStrategy = "merge"   // Merge the remote branch into the local one
+	PullRebase      PullStrategy = "rebase"  // Rebase local commits onto the remote branch
 )
 
 // GitRepo represents a git repository with its path and status
 type GitRepo struct {
-	Path          string
-	Name          string
-	HasGit        bool
-	Clean         bool
-	Branch        string
-	Remote        string
-	Error         error
-	Duration      time.Duration
-	LastCommit    string   // Last commit hash
-	LastCommitMsg string   // Last commit message
-	ModifiedFiles []string // List of modified files
+	Path            string
+	Name            string
+	Group           string // Group the repository is in, declared under groups or its top-level directory, "" for none
+	HasGit          bool
+	Nesting         int    // Repositories this one is inside, 0 when in none, 2 for a fixture in a vendored checkout
+	GitDir          string // Git directory when it lives outside the worktree (--separate-git-dir, linked worktrees)
+	Worktree        bool   // Linked worktree (git worktree add) sharing its repository with another checkout
+	Bare            bool   // Bare repository without a worktree, e.g. a mirror; only fetched, never pulled
+	FetchedWith     string // Worktree of the same repository whose fetch this one shared, empty if fetched itself
+	HasSubmodules   bool   // The repository declares submodules in .gitmodules
+	Submodules      int    // Submodules fetched or updated along with the repository (--submodules)
+	LFS             bool   // The repository stores files in Git LFS (detected with --lfs)
+	LFSKiB          int64  // Git LFS objects downloaded along with the repository, in KiB (--lfs)
+	Shallow         bool   // Shallow clone with truncated history
+	Depth           int    // Commits of the current branch's history a shallow clone has
+	Clean           bool
+	Empty           bool // No commits yet (unborn HEAD)
+	Flaky           bool // Alternates between success and failure across recent runs, per the history
+	Branch          string
+	Remote          string      // Remote that fetch and pull use, or the first remote if that one is missing
+	RemoteURL       string      // Fetch URL of Remote, without credentials
+	Remotes         []Remote    // Every configured remote, Remote first
+	Upstream        string      // Upstream of the current branch as remote/branch, empty if none
+	UpstreamSet     bool        // Upstream was set by this run (--set-upstream)
+	UpstreamGone    bool        // Upstream is configured but its branch no longer exists (status)
+	Ahead           int         // Commits on the current branch not in its upstream (status)
+	Behind          int         // Commits in the upstream not on the current branch (status)
+	Stashes         int         // Number of stash entries (status)
+	Pushed          string      // What push sent, e.g. "1a2b3c4d..5e6f7a8b main -> main"; empty if nothing
+	PulledWith      string      // Strategy a diverged branch was pulled with, merge or rebase; empty for fast-forwards
+	Checkout        string      // What checkout or heal did, e.g. "main -> feature"; empty if already on the branch
+	Healed          bool        // A detached HEAD was put back on a branch, or would be in dry-run mode (heal)
+	SyncBranch      string      // Default branch sync brought up to date (sync)
+	SyncUpdate      string      // What the pull moved SyncBranch by, e.g. "1a2b3c4d..5e6f7a8b"; empty if up to date (sync)
+	Stashed         bool        // Uncommitted changes were stashed by this run (stash, pull --autostash)
+	Unstashed       bool        // The git-herd stash was popped back by this run (stash pop, pull --autostash)
+	PrunedRefs      []string    // Remote-tracking branches pruned because their branch is gone (maintenance)
+	ObjectsBefore   ObjectStats // Object store before garbage collection (maintenance)
+	ObjectsAfter    ObjectStats // Object store after garbage collection (maintenance)
+	DeletedBranches []string    // Local branches deleted as merged or with their upstream gone, each with its tip, e.g. "fix (was 1a2b3c4)" (prune-branches)
+	KeptBranches    []string    // Local branches with their upstream gone kept for holding unpushed commits (prune-branches)
+	Exec            *ExecResult // What the command run in the repository printed and exited with, nil if not run (exec)
+	URLRewrites     []Rewrite   // Remote URLs rewritten, or that would be in dry-run mode (set-url)
+	ApplyCommit     string      // Commit apply made, abbreviated; empty if the change left the repository alone (apply)
+	ApplyFiles      int         // Files the change touched, or that a patch would in dry-run mode (apply)
+	ApplyPushed     bool        // The branch apply committed to was pushed (apply)
+	Commits         []Commit    // Commits on the current branch since the configured date, newest first (changelog)
+	Release         *Release    // Latest tag and the work since, nil if not read (releases)
+	Version         *TagCheck   // Version the checkout is on against the one its manifest expects (versions)
+	Pin             *Pin        // State the lockfile pins the repository to (checkout --lock)
+	SafeDirectory   bool        // Owned by another user and added to safe.directory, or would be in dry-run mode (--add-safe-directory)
+	CloneURL        string      // Where clone clones the repository from, credentials included
+	CloneBranch     string      // Branch clone checks out, empty for the remote's default
+	Error           error
+	Duration        time.Duration
+	LastCommit      string     // Last commit hash
+	LastCommitMsg   string     // Last commit message
+	ModifiedFiles   []string   // List of modified files
+	DirtySince      time.Time  // Oldest modification time among ModifiedFiles still on disk
+	MissingFiles    []string   // Required files absent from the repository (audit-files)
+	Manifests       []Manifest // Dependency manifests found at the repository root
+	CISystems       []string   // CI systems configured in the repository (scan)
+	Encryption      string     // Transparent encryption tool the repository uses, git-crypt or transcrypt
+	Locked          bool       // Encrypted files are checked out as ciphertext: the tool is not unlocked
+	Findings        []string   // Hook and local config anomalies (scan with security checks)
+	UserEmail       string     // Effective user.email for new commits (audit-email)
+	EmailIssue      string     // Why UserEmail is not allowed, empty when compliant (audit-email)
+	Diffs           []FileDiff // Uncommitted changes to tracked files (scan with export diffs)
+	Owners          []Owner    // Top committers over the configured period, .mailmap applied
+	Steps           []Step     // Outcome of each step of the pipeline that ran, in order (run <pipeline>)
+	Timings         Timings    // Where the repository's time went
+}
+
+// Compliant reports whether the repository passed the audit it was checked with
+func (r GitRepo) Compliant() bool {
+	return len(r.MissingFiles) == 0 && r.EmailIssue == ""
+}
+
+// ExecResult captures a command run in a repository by the exec operation
+type ExecResult struct {
+	ExitCode  int
+	Stdout    string
+	Stderr    string
+	Truncated bool // Stdout or Stderr was cut at the capture limit
+}
+
+// Step is the outcome of one operation of a pipeline on a repository
+type Step struct {
+	Operation OperationType
+	Error     error // Why the step failed or skipped the repository, nil if it succeeded
+	Duration  time.Duration
+}
+
+// FileDiff summarizes the uncommitted changes to one tracked file against HEAD
+type FileDiff struct {
+	Path      string
+	Added     int    // Lines added, 0 for binary files
+	Deleted   int    // Lines deleted, 0 for binary files
+	Binary    bool   // Git reports the file as binary, so there are no line counts
+	Patch     string // Unified diff, cut at a line boundary to the configured size
+	Truncated bool   // Patch was cut short
+}
+
+// Owner is one of a repository's most active committers
+type Owner struct {
+	Name    string
+	Email   string
+	Commits int
+}
+
+// String formats the owner as "Name <email> (commits)"
+func (o Owner) String() string {
+	if o.Email == "" {
+		return fmt.Sprintf("%s (%d)", o.Name, o.Commits)
+	}
+	return fmt.Sprintf("%s <%s> (%d)", o.Name, o.Email, o.Commits)
+}
+
+// Commit is a commit listed in the changelog
+type Commit struct {
+	Hash    string // Abbreviated
+	Author  string
+	Date    time.Time
+	Subject string
+}
+
+// Release describes a repository's latest tag and the work on its current branch since
+type Release struct {
+	Tag        string    // Latest tag reachable from HEAD, empty if there is none
+	Date       time.Time // When the tag was made: the tagger date, or the commit date of a lightweight tag
+	Unreleased int       // Commits on the current branch since the tag, or in all of its history without one
+	Oldest     time.Time // Commit date of the oldest unreleased commit, zero if there are none
+	Overdue    bool      // The oldest unreleased commit is older than --unreleased-days
+}
+
+// TagCheck compares the version tag a checkout is on with the minimum its manifest expects
+type TagCheck struct {
+	Expected   string // Minimum tag from the manifest
+	Current    string // Highest semver tag HEAD contains, empty if none
+	Describe   string // Exactly where HEAD is, from git describe, e.g. "v1.3.0-4-g1a2b3c4d"
+	Behind     bool   // Current is below Expected, or there is no Current
+	CheckedOut string // Tag checked out to catch up, or that would be in dry-run mode; empty if none
+}
+
+// Pin is the state a lockfile records for a repository
+type Pin struct {
+	Ref    string // Commit or tag to check out
+	Branch string // Branch to check out when it points at Ref, rather than detaching HEAD; empty for none
+}
+
+// ObjectStats describes a repository's object store as reported by git count-objects
+type ObjectStats struct {
+	Loose   int   // Loose objects
+	Packed  int   // Objects in packs
+	Packs   int   // Pack files
+	SizeKiB int64 // Disk space taken by loose objects, packs and garbage, in KiB
+}
+
+// Objects returns the number of loose and packed objects
+func (s ObjectStats) Objects() int {
+	return s.Loose + s.Packed
+}
+
+// Remote is a configured git remote
+type Remote struct {
+	Name string
+	URL  string // Fetch URL without credentials
+}
+
+// Rewrite is a remote URL set-url rewrote
+type Rewrite struct {
+	Remote string
+	Push   bool   // The push URL (remote.<name>.pushurl) rather than the fetch URL
+	From   string // Without credentials, like To
+	To     string
+}
+
+// Timings breaks a repository's time down by phase, to tell a slow worktree (scan, analyze,
+// discard) apart from a slow forge (network)
+type Timings struct {
+	Scan    time.Duration // Walking the repository's directory tree during discovery
+	Analyze time.Duration // Reading HEAD and worktree status, plus analysis operations
+	Discard time.Duration // Discarding configured files before the operation
+	Network time.Duration // Fetch or pull, including rate-limit pauses
+}
+
+// String lists the phases that took any time, e.g. "analyze 120ms, network 2.3s"
+func (t Timings) String() string {
+	phases := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"scan", t.Scan},
+		{"analyze", t.Analyze},
+		{"discard", t.Discard},
+		{"network", t.Network},
+	}
+
+	var parts []string
+	for _, phase := range phases {
+		if phase.duration > 0 {
+			parts = append(parts, fmt.Sprintf("%s %v", phase.name, phase.duration.Truncate(time.Millisecond)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Manifest describes a dependency manifest detected in a repository
+type Manifest struct {
+	Ecosystem string            // Package ecosystem (go, node, python)
+	File      string            // Manifest file relative to the repository root
+	Name      string            // Module, package or project name
+	Engines   map[string]string // Runtime constraints such as package.json engines
 }
 
 // Config holds application configuration
 // Config holds application configuration
 type Config struct {
-	Workers      int           `mapstructure:"workers" json:"workers,omitzero"`
-	Operation    OperationType `mapstructure:"operation" json:"operation,omitzero"`
-	DryRun       bool          `mapstructure:"dry-run" json:"dry_run,omitzero"`
-	Recursive    bool          `mapstructure:"recursive" json:"recursive,omitzero"`
-	SkipDirty    bool          `mapstructure:"skip-dirty" json:"skip_dirty,omitzero"`
-	Verbose      bool          `mapstructure:"verbose" json:"verbose,omitzero"`
-	Timeout      time.Duration `mapstructure:"timeout" json:"timeout,omitzero"`
-	ExcludeDirs  []string      `mapstructure:"exclude" json:"exclude_dirs,omitzero"`
-	PlainMode    bool          `mapstructure:"plain" json:"plain_mode,omitzero"`            // Disable TUI for plain text output
-	FullSummary  bool          `mapstructure:"full-summary" json:"full_summary,omitzero"`   // Show full summary of all repositories
-	SaveReport   string        `mapstructure:"save-report" json:"save_report,omitzero"`     // File path to save detailed report
-	DiscardFiles []string      `mapstructure:"discard-files" json:"discard_files,omitzero"` // File patterns to discard before pull/fetch
-	ExportScan   string        `mapstructure:"export-scan" json:"export_scan,omitzero"`     // Export scan results to markdown file
+	Workers        int           `mapstructure:"workers" json:"workers,omitzero"`
+	Operation      OperationType `mapstructure:"operation" json:"operation,omitzero"`
+	DryRun         bool          `mapstructure:"dry-run" json:"dry_run,omitzero"`
+	Recursive      bool          `mapstructure:"recursive" json:"recursive,omitzero"`
+	SkipDirty      bool          `mapstructure:"skip-dirty" json:"skip_dirty,omitzero"`
+	Verbose        bool          `mapstructure:"verbose" json:"verbose,omitzero"`
+	Timeout        time.Duration `mapstructure:"timeout" json:"timeout,omitzero"`
+	ExcludeDirs    []string      `mapstructure:"exclude" json:"exclude_dirs,omitzero"`
+	IncludeDirs    []string      `mapstructure:"include" json:"include_dirs,omitzero"`            // Only process repositories in directories matching these patterns
+	MaxDepth       int           `mapstructure:"max-depth" json:"max_depth,omitzero"`             // Directory levels below the root discovery descends, 0 for no limit
+	FollowSymlinks bool          `mapstructure:"follow-symlinks" json:"follow_symlinks,omitzero"` // Walk symlinked directories during discovery, each directory once
+	Nested         NestedMode    `mapstructure:"nested" json:"nested,omitzero"`                   // Whether repositories inside other repositories are processed
+	ForceRoot      bool          `mapstructure:"force-root" json:"force_root,omitzero"`           // Walk the filesystem root, the home directory, or any number of directories
+	Cached         bool          `mapstructure:"cached" json:"cached,omitzero"`                   // Take the repositories from the index instead of walking
+	Refresh        bool          `mapstructure:"refresh" json:"refresh,omitzero"`                 // Walk and rebuild the index even with Cached
+	IndexFile      string        `mapstructure:"index-file" json:"index_file,omitzero"`           // Repositories found by the latest walk of each root, empty disables
+	Filter         string        `mapstructure:"filter" json:"filter,omitzero"`                   // Glob or /regexp/ the name or path of processed repositories matches
+	Group          string        `mapstructure:"group" json:"group,omitzero"`                     // Group processed repositories are in
+	Only           []string      `mapstructure:"only" json:"only,omitzero"`                       // States (RepoStates) processed repositories are all in
+	OnBranch       string        `mapstructure:"on-branch" json:"on_branch,omitzero"`             // Glob the current branch of processed repositories matches
+	RemoteHosts    []string      `mapstructure:"remote-host" json:"remote_hosts,omitzero"`        // Hosts the remote of processed repositories is on, any of them
+	ExcludeRemotes []string      `mapstructure:"exclude-remote" json:"exclude_remotes,omitzero"`  // Host/path patterns of remotes whose repositories are skipped
+	PlainMode      bool          `mapstructure:"plain" json:"plain_mode,omitzero"`                // Disable TUI for plain text output
+	Select         bool          `mapstructure:"select" json:"select,omitzero"`                   // Choose the repositories to process from a checklist in the TUI
+	FullSummary    bool          `mapstructure:"full-summary" json:"full_summary,omitzero"`       // Show full summary of all repositories
+	SaveReport     string        `mapstructure:"save-report" json:"save_report,omitzero"`         // File path to save detailed report
+	DiscardFiles   []string      `mapstructure:"discard-files" json:"discard_files,omitzero"`     // File patterns to discard before pull/fetch
+	ExportScan     string        `mapstructure:"export-scan" json:"export_scan,omitzero"`         // Export scan results to markdown file
+	SummaryFile    string        `mapstructure:"summary-file" json:"summary_file,omitzero"`       // Machine-readable run summary for CI
+	Badge          string        `mapstructure:"badge" json:"badge,omitzero"`                     // SVG status badge with the run's success ratio
+	Output         OutputFormat  `mapstructure:"output" json:"output,omitzero"`                   // Format of results on standard output
+	LogDest        LogDest       `mapstructure:"log-dest" json:"log_dest,omitzero"`               // Where progress messages and logs go
+	RequiredFiles  []string      `mapstructure:"required-files" json:"required_files,omitzero"`   // Files every repository must contain (audit-files)
+	Manifests      bool          `mapstructure:"manifests" json:"manifests,omitzero"`             // Detect dependency manifests during scan
+	SecurityCheck  bool          `mapstructure:"security-check" json:"security_check,omitzero"`   // Report hooks and local config anomalies during scan
+	EmailDomains   []string      `mapstructure:"email-domains" json:"email_domains,omitzero"`     // Allowed user.email domains (audit-email)
+	Protected      []string      `mapstructure:"protected" json:"protected,omitzero"`             // Repository paths/globs that are never mutated
+	Budget         time.Duration `mapstructure:"budget" json:"budget,omitzero"`                   // Stop starting repositories once this much time has passed
+	MinFreeMB      int           `mapstructure:"min-free-mb" json:"min_free_mb,omitzero"`         // Free disk space (MiB) downloads need to start, 0 disables
+	SetUpstream    bool          `mapstructure:"set-upstream" json:"set_upstream,omitzero"`       // Track <remote>/<branch> where a branch has no upstream
+	SkipLocked     bool          `mapstructure:"skip-locked" json:"skip_locked,omitzero"`         // Don't pull repositories whose encrypted files are locked
+	FetchFirst     bool          `mapstructure:"fetch-first" json:"fetch_first,omitzero"`         // Fetch before computing status so ahead/behind is current
+	OwnersMonths   int           `mapstructure:"owners-months" json:"owners_months,omitzero"`     // Report top committers over this many months, 0 disables
+	ExportDiffs    bool          `mapstructure:"export-diffs" json:"export_diffs,omitzero"`       // Include per-file diff stats and patches in the scan export
+	DiffMaxBytes   int           `mapstructure:"diff-max-bytes" json:"diff_max_bytes,omitzero"`   // Size cap of each exported patch, 0 for stats only
+	CloneManifest  string        `mapstructure:"manifest" json:"clone_manifest,omitzero"`         // Repositories the clone and versions operations work on
+	Lock           string        `mapstructure:"lock" json:"lock,omitzero"`                       // Lockfile whose refs checkout checks out instead of a branch
+	Branch         string        `mapstructure:"branch" json:"branch,omitzero"`                   // Branch the checkout operation switches to
+	CreateBranch   bool          `mapstructure:"create" json:"create_branch,omitzero"`            // Let checkout create branches that only exist on the remote
+	AutoStash      bool          `mapstructure:"autostash" json:"autostash,omitzero"`             // Stash uncommitted changes before pulling and restore them after
+	PullStrategy   PullStrategy  `mapstructure:"pull-strategy" json:"pull_strategy,omitzero"`     // How pull handles branches that diverged from the remote
+	PruneOnly      bool          `mapstructure:"prune-only" json:"prune_only,omitzero"`           // Maintenance only prunes stale remote-tracking branches
+	Repack         bool          `mapstructure:"repack" json:"repack,omitzero"`                   // Maintenance also repacks all objects into one pack
+	DeleteUnpushed bool          `mapstructure:"delete-unpushed" json:"delete_unpushed,omitzero"` // Prune-branches also deletes gone branches holding unpushed commits
+	Submodules     bool          `mapstructure:"submodules" json:"submodules,omitzero"`           // Fetch and pull recurse into submodules
+	LFS            bool          `mapstructure:"lfs" json:"lfs,omitzero"`                         // Fetch and pull download Git LFS objects
+	Depth          int           `mapstructure:"depth" json:"depth,omitzero"`                     // Fetch and pull keep this many commits of history, 0 for no limit
+	Unshallow      bool          `mapstructure:"unshallow" json:"unshallow,omitzero"`             // Fetch and pull convert shallow clones to full ones
+	Exec           string        `mapstructure:"exec" json:"exec,omitzero"`                       // Shell command the exec operation runs in every repository
+	URLMatch       string        `mapstructure:"url-match" json:"url_match,omitzero"`             // Part of the remote URLs set-url rewrites
+	URLReplace     string        `mapstructure:"url-replace" json:"url_replace,omitzero"`         // What set-url replaces URLMatch with
+	ApplyScript    string        `mapstructure:"apply-script" json:"apply_script,omitzero"`       // Executable the apply operation runs in every repository
+	ApplyPatch     string        `mapstructure:"apply-patch" json:"apply_patch,omitzero"`         // Patch file the apply operation applies to every repository
+	CommitMessage  string        `mapstructure:"commit-message" json:"commit_message,omitzero"`   // Template of the message apply commits with
+	ApplyBranch    string        `mapstructure:"apply-branch" json:"apply_branch,omitzero"`       // New branch apply commits to, empty for the current branch
+	ApplyPush      bool          `mapstructure:"apply-push" json:"apply_push,omitzero"`           // Push ApplyBranch to the remote after committing
+	Since          string        `mapstructure:"since" json:"since,omitzero"`                     // Date (YYYY-MM-DD) the changelog collects commits from
+	Conventional   bool          `mapstructure:"conventional" json:"conventional,omitzero"`       // Group the changelog by conventional-commit type
+	ChangelogFile  string        `mapstructure:"changelog-file" json:"changelog_file,omitzero"`   // Markdown file the changelog is written to
+	UnreleasedDays int           `mapstructure:"unreleased-days" json:"unreleased_days,omitzero"` // Flag repositories whose oldest unreleased commit is older than this many days
+	CheckoutTag    bool          `mapstructure:"checkout-tag" json:"checkout_tag,omitzero"`       // Check out the expected tag in repositories behind it (versions)
+
+	// Editor integration
+	VSCodeWorkspace string `mapstructure:"emit-vscode-workspace" json:"vscode_workspace,omitzero"` // VS Code multi-root workspace of the scanned repositories
+	ProjectList     string `mapstructure:"emit-project-list" json:"project_list,omitzero"`         // Scanned repository paths, one per line, for JetBrains IDEs
+	TmuxSession     string `mapstructure:"emit-tmux-session" json:"tmux_session,omitzero"`         // tmuxp session with a window per repository needing attention
+
+	// Network behavior
+	RateLimitRetries int           `mapstructure:"rate-limit-retries" json:"rate_limit_retries,omitzero"` // Retries after a forge rate-limits a fetch/pull
+	SSHMultiplex     bool          `mapstructure:"ssh-multiplex" json:"ssh_multiplex,omitzero"`           // Share SSH connections per host across git CLI invocations
+	IPFamily         IPFamily      `mapstructure:"ip-family" json:"ip_family,omitzero"`                   // Restrict connections to IPv4 or IPv6
+	Remote           string        `mapstructure:"remote" json:"remote,omitzero"`                         // Remote that fetch, pull and push use
+	ForceWithLease   bool          `mapstructure:"force-with-lease" json:"force_with_lease,omitzero"`     // Push diverged branches if the remote is where it was last fetched
+	Preflight        bool          `mapstructure:"preflight" json:"preflight,omitzero"`                   // Check every remote answers git ls-remote before processing
+	PreflightTimeout time.Duration `mapstructure:"preflight-timeout" json:"preflight_timeout,omitzero"`   // How long each preflight check may take
+	AddSafeDirectory bool          `mapstructure:"add-safe-directory" json:"add_safe_directory,omitzero"` // Trust repositories owned by other users in git's safe.directory
+	SharedWorkspace  bool          `mapstructure:"shared-workspace" json:"shared_workspace,omitzero"`     // Never mutate repositories other users own, bar AllowOwners
+	AllowOwners      []string      `mapstructure:"allow-owner" json:"allow_owners,omitzero"`              // Users (names or IDs) whose repositories a shared workspace mutates
+	AuditLog         string        `mapstructure:"audit-log" json:"audit_log,omitzero"`                   // Log of every mutating operation and who ran it, empty disables
+
+	// Profiles
+	Profile string `mapstructure:"profile" json:"profile,omitzero"` // Profile under profiles in the config file whose settings apply
+	Path    string `mapstructure:"path" json:"path,omitzero"`       // Directory processed when none is given on the command line
+
+	// Pipelines
+	Pipeline       string              `mapstructure:"pipeline" json:"pipeline,omitzero"`               // Pipeline under pipelines whose steps run on every repository
+	Pipelines      map[string][]string `mapstructure:"pipelines" json:"pipelines,omitzero"`             // Pipelines declared by name, with the operations they run in order
+	ResumePipeline bool                `mapstructure:"resume-pipeline" json:"resume_pipeline,omitzero"` // Pick each repository up at the step the last run of the pipeline on it failed at
+
+	// Grouping
+	Groups map[string][]string `mapstructure:"groups" json:"groups,omitzero"` // Groups declared by name, with --exclude-style patterns of the directories in them
+
+	// Concurrency
+	OperationWorkers map[string]int `mapstructure:"operation-workers" json:"operation_workers,omitzero"` // Workers per operation, e.g. fetch=20, unless --workers is given
+
+	// Scheduling
+	Jitter     time.Duration `mapstructure:"jitter" json:"jitter,omitzero"`           // Upper bound of the deterministic start delay
+	JitterSeed string        `mapstructure:"jitter-seed" json:"jitter_seed,omitzero"` // Seed for the start delay, defaults to the hostname
+
+	// History
+	HistoryFile     string        `mapstructure:"history-file" json:"history_file,omitzero"`         // Per-repository outcomes across runs, empty disables
+	RepoTimeout     time.Duration `mapstructure:"repo-timeout" json:"repo_timeout,omitzero"`         // Timeout for each repository, 0 for none
+	AdaptiveTimeout float64       `mapstructure:"adaptive-timeout" json:"adaptive_timeout,omitzero"` // Per-repo timeout as a multiple of its p95 duration
+	IssueRepo       string        `mapstructure:"issue-repo" json:"issue_repo,omitzero"`             // GitHub repository (owner/name) persistent failures are filed in
+	IssueAfter      int           `mapstructure:"issue-after" json:"issue_after,omitzero"`           // Consecutive failed runs before an issue is filed
+	NotifyDryRun    bool          `mapstructure:"notify-dry-run" json:"notify_dry_run,omitzero"`     // Print issues and write reports to a temporary directory
+
+	// Run metadata
+	Labels map[string]string `mapstructure:"label" json:"labels,omitzero"` // Attached to every output, e.g. host=laptop, to tell machines apart
+}
+
+// LabelPairs returns the run's labels as key=value pairs, sorted by key
+func (c *Config) LabelPairs() []string {
+	pairs := make([]string, 0, len(c.Labels))
+	for _, key := range slices.Sorted(maps.Keys(c.Labels)) {
+		pairs = append(pairs, key+"="+c.Labels[key])
+	}
+	return pairs
+}
+
+// RenderCommitMessage fills in the commit message template of the apply operation for repo, e.g.
+// "Bump CI config in {{.Name}}"
+func (c *Config) RenderCommitMessage(repo GitRepo) (string, error) {
+	tmpl, err := template.New("commit-message").Parse(c.CommitMessage)
+	if err != nil {
+		return "", err
+	}
+	var message strings.Builder
+	if err := tmpl.Execute(&message, repo); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
+
+// FilterRegexp compiles --filter when it is a regular expression between slashes, e.g.
+// "/^api-v[0-9]+$/"; it returns nil for a glob or no filter
+func (c *Config) FilterRegexp() (*regexp.Regexp, error) {
+	expr, ok := strings.CutPrefix(c.Filter, "/")
+	if !ok || len(expr) < 2 || !strings.HasSuffix(expr, "/") {
+		return nil, nil
+	}
+	return regexp.Compile(strings.TrimSuffix(expr, "/"))
+}
+
+// SelectsByState reports whether --only, --on-branch or --remote-host pick the repositories to
+// process by their state, which takes analyzing each one first
+func (c *Config) SelectsByState() bool {
+	return len(c.Only) > 0 || c.OnBranch != "" || len(c.RemoteHosts) > 0
+}
+
+// PipelineStep is one step of a pipeline: an operation, and the condition a repository must
+// meet for it to run, e.g. "behind > 0 && clean"
+type PipelineStep struct {
+	Operation OperationType
+	Condition string // Empty when the step always runs
+}
+
+// PipelineSteps returns the steps of the pipeline Pipeline names, in the order they run on each
+// repository; nil without a pipeline. Each step is declared as "operation" or "operation if
+// condition".
+func (c *Config) PipelineSteps() []PipelineStep {
+	if c.Pipeline == "" {
+		return nil
+	}
+	steps := make([]PipelineStep, len(c.Pipelines[c.Pipeline]))
+	for i, step := range c.Pipelines[c.Pipeline] {
+		op, condition, _ := strings.Cut(step, " if ")
+		steps[i] = PipelineStep{Operation: OperationType(strings.TrimSpace(op)), Condition: strings.TrimSpace(condition)}
+	}
+	return steps
 }
 
 // GitRepoResult represents the result of processing a git repository
@@ -25,6 +25,11 @@ func TestOperationType(t *testing.T) {
 			operation: OperationPull,
 			expected:  "pull",
 		},
+		{
+			name:      "push operation",
+			operation: OperationPush,
+			expected:  "push",
+		},
 	}
 
 	for _, tt := range tests {
@@ -795,3 +800,30 @@ func TestErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestTimingsString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		timings Timings
+		want    string
+	}{
+		{"empty", Timings{}, ""},
+		{"network only", Timings{Network: 2300 * time.Millisecond}, "network 2.3s"},
+		{
+			"all phases truncated",
+			Timings{Scan: 12345 * time.Microsecond, Analyze: 120 * time.Millisecond, Discard: time.Second, Network: 3 * time.Second},
+			"scan 12ms, analyze 120ms, discard 1s, network 3s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.timings.String(); got != tt.want {
+				t.Errorf("Timings.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}